@@ -185,6 +185,13 @@ type KluctlDeploymentSpec struct {
 	// +optional
 	AbortOnError bool `json:"abortOnError,omitempty"`
 
+	// PreflightCheck instructs kluctl to verify that all required permissions are granted before applying or deleting
+	// any objects.
+	// Equivalent to using '--preflight-check' when calling kluctl.
+	// +kubebuilder:default:=false
+	// +optional
+	PreflightCheck bool `json:"preflightCheck,omitempty"`
+
 	// IncludeTags instructs kluctl to only include deployments with given tags.
 	// Equivalent to using '--include-tag' when calling kluctl.
 	// +optional
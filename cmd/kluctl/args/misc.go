@@ -41,6 +41,10 @@ type AbortOnErrorFlags struct {
 	AbortOnError bool `group:"misc" help:"Abort deploying when an error occurs instead of trying the remaining deployments"`
 }
 
+type PreflightCheckFlags struct {
+	PreflightCheck bool `group:"misc" help:"Check via SelfSubjectAccessReviews that all required permissions are granted before applying or deleting any objects."`
+}
+
 type OutputFormatFlags struct {
 	OutputFormat []string `group:"misc" short:"o" help:"Specify output format and target file, in the format 'format=path'. Format can either be 'text' or 'yaml'. Can be specified multiple times. The actual format for yaml is currently not documented and subject to change."`
 	NoObfuscate  bool     `group:"misc" help:"Disable obfuscation of sensitive/secret data"`
@@ -2,7 +2,9 @@ package args
 
 import (
 	"github.com/kluctl/kluctl/v2/pkg/kluctl_project"
+	"github.com/kluctl/kluctl/v2/pkg/sops/decryptor"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/kluctl/kluctl/v2/pkg/vars/file_formats"
 	"os"
 	"path/filepath"
 	"time"
@@ -31,11 +33,23 @@ type ProjectFlags struct {
 
 	Timeout                time.Duration `group:"project" help:"Specify timeout for all operations, including loading of the project, all external api calls and waiting for readiness." default:"10m"`
 	GitCacheUpdateInterval time.Duration `group:"project" help:"Specify the time to wait between git cache updates. Defaults to not wait at all and always updating caches."`
+
+	AllowExecVars             bool     `group:"project" help:"Allow the 'command' vars source to execute local commands. Only enable this for projects you trust."`
+	AllowVaultTokenFiles      bool     `group:"project" help:"Allow the 'vault' vars source to read credentials from local files, e.g. via 'auth.tokenFile'. Only enable this for projects you trust."`
+	VarsPlugin                []string `group:"project" help:"Path to a vars source plugin binary. Vars sources provided by the plugin become available in all vars lists. Can be specified multiple times."`
+	AllowTemplatingExtensions bool     `group:"project" help:"Allow the project's 'templating.extensions' and 'templating.pythonPath', which load and execute Python code from the project or its templating libraries. Only enable this for projects you trust."`
+	AllowKrmExec              []string `group:"project" help:"Allow exec based KRM functions to run the given executables, if also allowed by the project's 'krmFunctions.execAllowList'. Executables from the project are matched by their path relative to the project root, executables from PATH by their absolute path. Supports shell patterns and can be specified multiple times. Only enable this for projects you trust."`
+	AllowKrmExecFromProject   bool     `group:"project" help:"Allow exec based KRM functions to run executables that are part of the project. These must also be allowed via --allow-krm-exec. Only enable this for projects you trust."`
+
+	VarsCache    string `group:"project" help:"Controls caching of vars sources that specify 'cacheTTL'. 'use' uses cached values until their TTL expires, 'refresh' always reloads values and 'off' disables the cache. In 'use' and 'refresh' mode, stale values are used when reloading fails." default:"use"`
+	VarsCacheKey string `group:"project" help:"Encryption key for the local vars cache. If set, the cache is encrypted and values of sensitive vars sources are cached as well. If not set, the cache is NOT encrypted and only contains values of non-sensitive vars sources."`
+
+	NoRenderCache bool `group:"project" help:"Disable the render cache. By default, deployment items with unchanged inputs reuse the objects from previous runs instead of rendering Helm Charts and building kustomizations again. The cache is not encrypted, items using sensitive vars or resulting in Secrets are never cached."`
 }
 
 type ArgsFlags struct {
 	Arg          []string `group:"project" short:"a" help:"Passes a template argument in the form of name=value. Nested args can be set with the '-a my.nested.arg=value' syntax. Values are interpreted as yaml values, meaning that 'true' and 'false' will lead to boolean values and numbers will be treated as numbers. Use quotes if you want these to be treated as strings. If the value starts with @, it is treated as a file, meaning that the contents of the file will be loaded and treated as yaml."`
-	ArgsFromFile []string `group:"project" help:"Loads a yaml file and makes it available as arguments, meaning that they will be available thought the global 'args' variable."`
+	ArgsFromFile []string `group:"project" help:"Loads a yaml file and makes it available as arguments, meaning that they will be available thought the global 'args' variable. JSON, TOML, INI, dotenv and HCL files are supported as well and detected by their file extension. SOPS encrypted files are decrypted with locally available keys."`
 }
 
 func (a *ArgsFlags) LoadArgs() (*uo.UnstructuredObject, error) {
@@ -53,7 +67,14 @@ func (a *ArgsFlags) LoadArgs() (*uo.UnstructuredObject, error) {
 		return nil, err
 	}
 	for _, a := range a.ArgsFromFile {
-		optionArgs2, err := uo.FromFile(a)
+		absPath, err := filepath.Abs(a)
+		if err != nil {
+			return nil, err
+		}
+		// args files are loaded before any project or target is known, so only local keys can be used for decryption
+		d := decryptor.NewDecryptor(filepath.Dir(absPath), decryptor.MaxEncryptedFileSize)
+		d.AddLocalKeyService()
+		optionArgs2, _, err := file_formats.ParseFile(d, absPath)
 		if err != nil {
 			return nil, err
 		}
@@ -85,9 +106,16 @@ type CommandResultWriteFlags struct {
 	ForceWriteCommandResult  bool `group:"results" help:"Force writing of command results, even if the command is run in dry-run mode."`
 	KeepCommandResultsCount  int  `group:"results" help:"Configure how many old command results to keep." default:"5"`
 	KeepValidateResultsCount int  `group:"results" help:"Configure how many old validate results to keep." default:"2"`
+	RecordVarsProvenance     bool `group:"results" help:"Embed a compact map into the command result that describes where each variable came from. Values are not included."`
 }
 
 type CommandResultFlags struct {
 	CommandResultReadOnlyFlags
 	CommandResultWriteFlags
 }
+
+type ValidateHistoryFlags struct {
+	KeepValidateHistory time.Duration `group:"results" help:"Configure how long readiness transitions are kept in the validation history. Set to 0 to disable the validation history." default:"168h"`
+	FlappingThreshold   int           `group:"results" help:"Report objects as flapping when their readiness changed more than this number of times within --flapping-window." default:"4"`
+	FlappingWindow      time.Duration `group:"results" help:"Configure the time window used for flapping detection." default:"1h"`
+}
@@ -6,9 +6,12 @@ import (
 	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
 	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
 	"github.com/kluctl/kluctl/v2/pkg/controllers"
+	"github.com/kluctl/kluctl/v2/pkg/deployment"
 	ssh_pool "github.com/kluctl/kluctl/v2/pkg/git/ssh-pool"
 	"github.com/kluctl/kluctl/v2/pkg/sourceoverride"
 	"github.com/kluctl/kluctl/v2/pkg/utils/flux_utils/metrics"
+	"github.com/kluctl/kluctl/v2/pkg/vars"
+	vars_plugin "github.com/kluctl/kluctl/v2/pkg/vars/plugin"
 	log "github.com/sirupsen/logrus"
 	"k8s.io/apimachinery/pkg/runtime"
 	utilruntime "k8s.io/apimachinery/pkg/util/runtime"
@@ -28,6 +31,7 @@ import (
 	crtlmetrics "sigs.k8s.io/controller-runtime/pkg/metrics"
 	metricsserver "sigs.k8s.io/controller-runtime/pkg/metrics/server"
 	"testing"
+	"time"
 )
 
 var (
@@ -52,10 +56,20 @@ type controllerRunCmd struct {
 	LeaderElect bool `group:"misc" help:"Enable leader election for controller manager. Enabling this will ensure there is only one active controller manager."`
 	Concurrency int  `group:"misc" help:"Configures how many KluctlDeployments can be be reconciled concurrently." default:"4"`
 
-	DefaultServiceAccount string `group:"misc" help:"Default service account used for impersonation."`
-	DryRun                bool   `group:"misc" help:"Run all deployments in dryRun=true mode."`
+	DefaultServiceAccount     string        `group:"misc" help:"Default service account used for impersonation."`
+	DryRun                    bool          `group:"misc" help:"Run all deployments in dryRun=true mode."`
+	AllowExecVars             bool          `group:"misc" help:"Allow the 'command' vars source to execute commands inside the controller. Only enable this if you trust all deployed projects."`
+	AllowVaultTokenFiles      bool          `group:"misc" help:"Allow the 'vault' vars source to read credentials from files inside the controller, e.g. via 'auth.tokenFile'. Only enable this if you trust all deployed projects, as this allows to send any file readable by the controller to arbitrary Vault addresses."`
+	AllowTemplatingExtensions bool          `group:"misc" help:"Allow the projects' 'templating.extensions' and 'templating.pythonPath', which execute Python code inside the controller. Only enable this if you trust all deployed projects."`
+	AllowKrmExec              []string      `group:"misc" help:"Allow exec based KRM functions to run the given executables inside the controller, if also allowed by the project's 'krmFunctions.execAllowList'. Executables from PATH are matched by their absolute path. Supports shell patterns and can be specified multiple times. The executables must be available inside the controller image."`
+	AllowKrmExecFromProject   bool          `group:"misc" help:"Allow exec based KRM functions to run executables that are part of the deployed projects. These must also be allowed via --allow-krm-exec. Only enable this if you trust all deployed projects."`
+	AllowKustomizeRemoteHosts []string      `group:"misc" help:"Allow remote kustomize resources and manifest urls to be fetched from the given hosts, if also listed in the project's 'kustomize.remoteHosts'. Supports shell patterns and can be specified multiple times. If not specified, no remote hosts are allowed."`
+	VarsPlugin                []string      `group:"misc" help:"Path to a vars source plugin binary. The plugin must be available inside the controller image. Can be specified multiple times."`
+	RenderCacheDir            string        `group:"misc" help:"Directory used to cache rendered deployment items between reconciliations. Mount an emptyDir or PersistentVolume at this path. If empty, the render cache is disabled. The cache is not encrypted, so the volume must not be shared with untrusted workloads."`
+	VarsCacheMaxStale         time.Duration `group:"misc" help:"How long values of the in-memory vars cache are kept after their 'cacheTTL' has expired. Such stale values are only used when reloading the vars source fails." default:"1h"`
 
 	args.CommandResultFlags
+	args.ValidateHistoryFlags
 }
 
 func (cmd *controllerRunCmd) Help() string {
@@ -171,18 +185,46 @@ func (cmd *controllerRunCmd) Run(ctx context.Context) error {
 		defer soProxyServer.Stop()
 	}
 
+	varsSourceRegistry, closePlugins, err := vars_plugin.NewRegistryWithPlugins(ctx, cmd.VarsPlugin)
+	if err != nil {
+		return err
+	}
+	defer closePlugins()
+
+	var renderCache *deployment.RenderCache
+	if cmd.RenderCacheDir != "" {
+		renderCache, err = deployment.NewRenderCache(cmd.RenderCacheDir)
+		if err != nil {
+			return err
+		}
+	}
+
 	r := controllers.KluctlDeploymentReconciler{
-		ControllerName:        controllerName,
-		ControllerNamespace:   cmd.ControllerNamespace,
-		DefaultServiceAccount: cmd.DefaultServiceAccount,
-		DryRun:                cmd.DryRun,
-		RestConfig:            restConfig,
-		ApiReader:             mgr.GetAPIReader(),
-		Client:                mgr.GetClient(),
-		Scheme:                mgr.GetScheme(),
-		EventRecorder:         eventRecorder,
-		MetricsRecorder:       metricsRecorder,
-		SshPool:               sshPool,
+		ControllerName:            controllerName,
+		ControllerNamespace:       cmd.ControllerNamespace,
+		DefaultServiceAccount:     cmd.DefaultServiceAccount,
+		DryRun:                    cmd.DryRun,
+		AllowExecVars:             cmd.AllowExecVars,
+		AllowVaultTokenFiles:      cmd.AllowVaultTokenFiles,
+		AllowTemplatingExtensions: cmd.AllowTemplatingExtensions,
+		AllowKrmExec:              cmd.AllowKrmExec,
+		AllowKrmExecFromProject:   cmd.AllowKrmExecFromProject,
+		AllowKustomizeRemoteHosts: cmd.AllowKustomizeRemoteHosts,
+		VarsSourceRegistry:        varsSourceRegistry,
+		VarsCache:                 vars.NewMemoryVarsCache(cmd.VarsCacheMaxStale),
+		RecordVarsProvenance:      cmd.RecordVarsProvenance,
+		RenderCache:               renderCache,
+		RestConfig:                restConfig,
+		ApiReader:                 mgr.GetAPIReader(),
+		Client:                    mgr.GetClient(),
+		Scheme:                    mgr.GetScheme(),
+		EventRecorder:             eventRecorder,
+		MetricsRecorder:           metricsRecorder,
+		SshPool:                   sshPool,
+
+		KeepValidateHistory: cmd.KeepValidateHistory,
+		FlappingThreshold:   cmd.FlappingThreshold,
+		FlappingWindow:      cmd.FlappingWindow,
 	}
 
 	r.ResultStore, err = buildResultStoreRW(ctx, restConfig, mgr.GetRESTMapper(), &cmd.CommandResultFlags, true)
@@ -24,6 +24,7 @@ type deployCmd struct {
 	args.ForceApplyFlags
 	args.ReplaceOnErrorFlags
 	args.AbortOnErrorFlags
+	args.PreflightCheckFlags
 	args.HookFlags
 	args.OutputFormatFlags
 	args.RenderOutputDirFlags
@@ -82,6 +83,7 @@ func (cmd *deployCmd) runCmdDeploy(cmdCtx *commandCtx) error {
 	cmd2.NoWait = cmd.NoWait
 	cmd2.Prune = cmd.Prune
 	cmd2.WaitPrune = !cmd.NoWait
+	cmd2.PreflightCheck = cmd.PreflightCheck
 
 	cb := func(diffResult *result.CommandResult) error {
 		return cmd.diffResultCb(cmdCtx, diffResult)
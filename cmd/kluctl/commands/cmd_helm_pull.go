@@ -10,7 +10,6 @@ import (
 	"github.com/kluctl/kluctl/v2/pkg/status"
 	"github.com/kluctl/kluctl/v2/pkg/utils"
 	"github.com/kluctl/kluctl/v2/pkg/yaml"
-	"io/fs"
 	"os"
 	"path/filepath"
 )
@@ -59,7 +58,7 @@ func doHelmPull(ctx context.Context, projectDir string, helmAuthProvider helm_au
 
 	baseChartsDir := filepath.Join(projectDir, ".helm-charts")
 
-	releases, charts, err := loadHelmReleases(ctx, projectDir, baseChartsDir, helmAuthProvider, ociAuthProvider)
+	releases, charts, err := helm.LoadProjectReleases(ctx, projectDir, baseChartsDir, helmAuthProvider, ociAuthProvider)
 	if err != nil {
 		return actions, err
 	}
@@ -139,46 +138,3 @@ func doHelmPull(ctx context.Context, projectDir string, helmAuthProvider helm_au
 
 	return actions, nil
 }
-
-func loadHelmReleases(ctx context.Context, projectDir string, baseChartsDir string, helmAuthProvider helm_auth.HelmAuthProvider, ociAuthProvider auth_provider.OciAuthProvider) ([]*helm.Release, []*helm.Chart, error) {
-	var releases []*helm.Release
-	chartsMap := make(map[string]*helm.Chart)
-	err := filepath.WalkDir(projectDir, func(p string, d fs.DirEntry, err error) error {
-		fname := filepath.Base(p)
-		if fname != "helm-chart.yml" && fname != "helm-chart.yaml" {
-			return nil
-		}
-
-		relDir, err := filepath.Rel(projectDir, filepath.Dir(p))
-		if err != nil {
-			return err
-		}
-
-		hr, err := helm.NewRelease(ctx, projectDir, relDir, p, baseChartsDir, helmAuthProvider, ociAuthProvider)
-		if err != nil {
-			return err
-		}
-
-		if hr.Chart.IsLocalChart() {
-			return nil
-		}
-
-		releases = append(releases, hr)
-		chart := hr.Chart
-		key := fmt.Sprintf("%s / %s", chart.GetRepo(), chart.GetChartName())
-		if x, ok := chartsMap[key]; !ok {
-			chartsMap[key] = chart
-		} else {
-			hr.Chart = x
-		}
-		return nil
-	})
-	if err != nil {
-		return nil, nil, err
-	}
-	charts := make([]*helm.Chart, 0, len(chartsMap))
-	for _, chart := range chartsMap {
-		charts = append(charts, chart)
-	}
-	return releases, charts, nil
-}
@@ -0,0 +1,140 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_project"
+	"github.com/kluctl/kluctl/v2/pkg/lint"
+	"github.com/kluctl/kluctl/v2/pkg/status"
+	"github.com/kluctl/kluctl/v2/pkg/vars"
+	"github.com/kluctl/kluctl/v2/pkg/yaml"
+	"path/filepath"
+)
+
+type lintCmd struct {
+	args.ProjectFlags
+	args.ArgsFlags
+	args.HelmCredentials
+	args.RegistryCredentials
+
+	Output         []string `group:"misc" short:"o" help:"Specify output format and target file, in the format 'format=path'. Format can either be 'text', 'yaml' or 'json'. Can be specified multiple times."`
+	FailOnWarnings bool     `group:"misc" help:"Also fail when warnings are found. By default, only errors cause a failure."`
+}
+
+func (cmd *lintCmd) Help() string {
+	return `Renders all targets of the project in offline mode and statically analyses the results, without
+requiring access to any cluster. Reports unused and undefined variables, constant 'when' conditions,
+deployment items not selected by any KluctlDeployment, unreachable directories, Helm Charts that were
+not pre-pulled and objects rendered by multiple deployment items. Fails if errors are found.`
+}
+
+func (cmd *lintCmd) Run(ctx context.Context) error {
+	var res *lint.Result
+	err := withKluctlProjectFromArgs(ctx, nil, cmd.ProjectFlags, &cmd.ArgsFlags, &cmd.HelmCredentials, &cmd.RegistryCredentials, false, false, true, false, func(ctx context.Context, p *kluctl_project.LoadedKluctlProject) error {
+		var err error
+		res, err = cmd.runLint(ctx, p)
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	err = outputHelper(ctx, cmd.Output, func(format string) (string, error) {
+		switch format {
+		case "text":
+			return res.FormatText(), nil
+		case "yaml":
+			return yaml.WriteYamlString(res)
+		case "json":
+			b, err := json.MarshalIndent(res, "", "  ")
+			if err != nil {
+				return "", err
+			}
+			return string(b) + "\n", nil
+		default:
+			return "", fmt.Errorf("invalid format: %s", format)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	if res.Count(lint.SeverityError) != 0 {
+		return fmt.Errorf("lint found %d errors", res.Count(lint.SeverityError))
+	}
+	if cmd.FailOnWarnings && res.Count(lint.SeverityWarning) != 0 {
+		return fmt.Errorf("lint found %d warnings", res.Count(lint.SeverityWarning))
+	}
+	return nil
+}
+
+func (cmd *lintCmd) runLint(ctx context.Context, p *kluctl_project.LoadedKluctlProject) (*lint.Result, error) {
+	repoRoot, err := filepath.Abs(p.LoadArgs.RepoRoot)
+	if err != nil {
+		return nil, err
+	}
+	projectDir, err := filepath.Abs(p.LoadArgs.ProjectDir)
+	if err != nil {
+		return nil, err
+	}
+	tracer := p.LoadArgs.TemplateTracer
+
+	linter := lint.NewLinter(repoRoot, projectDir)
+
+	// accesses while loading the project, e.g. from .kluctl.yaml
+	report, err := tracer.Report(repoRoot)
+	if err != nil {
+		return nil, err
+	}
+	linter.AddTemplateTrace(report)
+
+	var targets []string
+	for _, t := range p.Targets {
+		targets = append(targets, t.Name)
+	}
+	if len(targets) == 0 {
+		// target-less project
+		targets = append(targets, "")
+	}
+
+	for _, t := range targets {
+		err = tracer.Reset()
+		if err != nil {
+			return nil, err
+		}
+
+		ptArgs := projectTargetCommandArgs{
+			projectFlags:        cmd.ProjectFlags,
+			argsFlags:           cmd.ArgsFlags,
+			helmCredentials:     cmd.HelmCredentials,
+			registryCredentials: cmd.RegistryCredentials,
+			offlineKubernetes:   true,
+			varsProvenance:      vars.NewProvenanceRecorder(),
+			traceTemplates:      true,
+		}
+		ptArgs.targetFlags.Target = t
+
+		s := status.Startf(ctx, "Linting target %s", t)
+		err = withProjectTargetCommandContext(ctx, ptArgs, p, func(cmdCtx *commandCtx) error {
+			report, err := tracer.Report(repoRoot)
+			if err != nil {
+				return err
+			}
+			return linter.AddTarget(t, cmdCtx.targetCtx, ptArgs.varsProvenance.Result(), report)
+		})
+		if err != nil {
+			s.Failed()
+			linter.AddTargetError(t, err)
+			continue
+		}
+		s.Success()
+	}
+	err = tracer.Reset()
+	if err != nil {
+		return nil, err
+	}
+
+	return linter.Finish(ctx, p.LoadArgs.HelmAuthProvider, p.LoadArgs.OciAuthProvider)
+}
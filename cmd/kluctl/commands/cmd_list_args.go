@@ -0,0 +1,218 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_project"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/kluctl/kluctl/v2/pkg/yaml"
+	"github.com/spf13/cobra"
+	"path/filepath"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+type listArgsCmd struct {
+	args.ProjectFlags
+
+	Output []string `group:"misc" short:"o" help:"Specify output format and target file, in the format 'format=path'. Format can either be 'text' or 'yaml'. Can be specified multiple times."`
+}
+
+func (cmd *listArgsCmd) Help() string {
+	return `Outputs all arguments declared in .kluctl.yaml, including their type, default value and description.
+Type information is derived from the JSON Schema of each argument. Nested object properties are listed
+as separate rows. The same information is shown by --help of all commands that accept arguments.`
+}
+
+type argInfo struct {
+	Name        string `json:"name"`
+	Type        string `json:"type,omitempty"`
+	Required    bool   `json:"required"`
+	Default     any    `json:"default,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+func (cmd *listArgsCmd) Run(ctx context.Context) error {
+	return withKluctlProjectFromArgs(ctx, nil, cmd.ProjectFlags, nil, nil, nil, false, true, false, false, func(ctx context.Context, p *kluctl_project.LoadedKluctlProject) error {
+		infos, err := buildArgInfos(p.Config.Args)
+		if err != nil {
+			return err
+		}
+		return outputHelper(ctx, cmd.Output, func(format string) (string, error) {
+			switch format {
+			case "text":
+				return formatArgInfosText(infos), nil
+			case "yaml":
+				return yaml.WriteYamlString(infos)
+			default:
+				return "", fmt.Errorf("invalid format: %s", format)
+			}
+		})
+	})
+}
+
+func buildArgInfos(argsDef []types.DeploymentArg) ([]argInfo, error) {
+	var ret []argInfo
+	for _, a := range argsDef {
+		var def any
+		if a.Default != nil {
+			err := yaml.ReadYamlBytes(a.Default.Raw, &def)
+			if err != nil {
+				return nil, err
+			}
+		}
+		var schema map[string]any
+		if a.Schema != nil {
+			err := yaml.ReadYamlBytes(a.Schema.Raw, &schema)
+			if err != nil {
+				return nil, fmt.Errorf("invalid schema for argument %s: %w", a.Name, err)
+			}
+		}
+		desc := a.Description
+		if desc == "" {
+			desc, _ = schema["description"].(string)
+		}
+		ret = append(ret, argInfo{
+			Name:        a.Name,
+			Type:        describeSchemaType(schema),
+			Required:    a.Default == nil,
+			Default:     def,
+			Description: desc,
+		})
+		ret = append(ret, buildNestedArgInfos(a.Name, schema)...)
+	}
+	return ret, nil
+}
+
+func buildNestedArgInfos(prefix string, schema map[string]any) []argInfo {
+	props, _ := schema["properties"].(map[string]any)
+	if len(props) == 0 {
+		return nil
+	}
+	required := map[string]bool{}
+	if l, ok := schema["required"].([]any); ok {
+		for _, x := range l {
+			if s, ok := x.(string); ok {
+				required[s] = true
+			}
+		}
+	}
+
+	keys := make([]string, 0, len(props))
+	for k := range props {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var ret []argInfo
+	for _, k := range keys {
+		ps, _ := props[k].(map[string]any)
+		desc, _ := ps["description"].(string)
+		name := prefix + "." + k
+		ret = append(ret, argInfo{
+			Name:        name,
+			Type:        describeSchemaType(ps),
+			Required:    required[k] && ps["default"] == nil,
+			Default:     ps["default"],
+			Description: desc,
+		})
+		ret = append(ret, buildNestedArgInfos(name, ps)...)
+	}
+	return ret
+}
+
+func describeSchemaType(schema map[string]any) string {
+	if schema == nil {
+		return ""
+	}
+	var t string
+	switch x := schema["type"].(type) {
+	case string:
+		t = x
+	case []any:
+		var l []string
+		for _, y := range x {
+			l = append(l, fmt.Sprint(y))
+		}
+		t = strings.Join(l, "|")
+	}
+	if t == "array" {
+		if items, ok := schema["items"].(map[string]any); ok {
+			if it := describeSchemaType(items); it != "" {
+				t = fmt.Sprintf("array of %s", it)
+			}
+		}
+	}
+	if enum, ok := schema["enum"].([]any); ok {
+		var l []string
+		for _, e := range enum {
+			l = append(l, yaml.WriteJsonStringMust(e))
+		}
+		t = fmt.Sprintf("%s (one of %s)", t, strings.Join(l, ", "))
+	}
+	if p, ok := schema["pattern"].(string); ok {
+		t = fmt.Sprintf("%s (pattern %s)", t, p)
+	}
+	return strings.TrimSpace(t)
+}
+
+// projectArgsHelp returns the help section for the arguments declared in the .kluctl.yaml of the current project. It
+// returns an empty string for commands that don't accept arguments and when no project is found, so that --help
+// keeps working outside of projects.
+func projectArgsHelp(cmd *cobra.Command) string {
+	if cmd.Flag("arg") == nil {
+		return ""
+	}
+
+	var configPath string
+	if f := cmd.Flag("project-config"); f != nil {
+		configPath = f.Value.String()
+	}
+	if configPath == "" {
+		dir := "."
+		if f := cmd.Flag("project-dir"); f != nil && f.Value.String() != "" {
+			dir = f.Value.String()
+		}
+		configPath = yaml.FixPathExt(filepath.Join(dir, ".kluctl.yml"))
+	}
+	if !utils.IsFile(configPath) {
+		return ""
+	}
+
+	var config types.KluctlProject
+	err := yaml.ReadYamlFile(configPath, &config)
+	if err != nil {
+		return ""
+	}
+	infos, err := buildArgInfos(config.Args)
+	if err != nil || len(infos) == 0 {
+		return ""
+	}
+
+	h := "\nDeclared template arguments:\n"
+	h += fmt.Sprintf("  Arguments declared in %s, to be passed via -a name=value.\n\n", configPath)
+	for _, l := range strings.Split(strings.TrimRightFunc(formatArgInfosText(infos), unicode.IsSpace), "\n") {
+		h += "  " + l + "\n"
+	}
+	return h
+}
+
+func formatArgInfosText(infos []argInfo) string {
+	if len(infos) == 0 {
+		return "No arguments declared.\n"
+	}
+
+	var t utils.PrettyTable
+	t.AddRow("Name", "Type", "Required", "Default", "Description")
+	for _, a := range infos {
+		def := ""
+		if a.Default != nil {
+			def = strings.TrimSpace(yaml.WriteJsonStringMust(a.Default))
+		}
+		t.AddRow(a.Name, a.Type, fmt.Sprintf("%v", a.Required), def, a.Description)
+	}
+	return t.Render([]int{40, 30, 8, 30, 60}) + "\n"
+}
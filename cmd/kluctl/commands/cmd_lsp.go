@@ -0,0 +1,25 @@
+package commands
+
+import (
+	"context"
+	"os"
+
+	"github.com/kluctl/kluctl/v2/pkg/lsp"
+)
+
+type lspCmd struct {
+	Target string `group:"misc" help:"The target to use when completing args and vars. Can be changed by the client via initializationOptions or workspace/didChangeConfiguration."`
+}
+
+func (cmd *lspCmd) Help() string {
+	return `The language server communicates via stdin/stdout and offers completion, hover documentation, diagnostics
+and go-to-definition for .kluctl.yaml, deployment.yml and helm-chart.yaml files. It also completes and documents
+kluctl.io/* annotations in Kubernetes resources and completes args and vars inside Jinja2 templates.`
+}
+
+func (cmd *lspCmd) Run(ctx context.Context) error {
+	s := lsp.NewServer(ctx, lsp.ServerSettings{
+		Target: cmd.Target,
+	})
+	return s.Serve(os.Stdin, os.Stdout)
+}
@@ -0,0 +1,191 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/go-git/go-git/v5"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	git2 "github.com/kluctl/kluctl/v2/pkg/git"
+	"github.com/kluctl/kluctl/v2/pkg/git/auth"
+	"github.com/kluctl/kluctl/v2/pkg/git/messages"
+	ssh_pool "github.com/kluctl/kluctl/v2/pkg/git/ssh-pool"
+	"github.com/kluctl/kluctl/v2/pkg/oci/auth_provider"
+	"github.com/kluctl/kluctl/v2/pkg/prompts"
+	"github.com/kluctl/kluctl/v2/pkg/status"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/kluctl/kluctl/v2/pkg/yaml"
+	"path/filepath"
+)
+
+type manifestUpdateCmd struct {
+	args.ProjectDir
+	args.RegistryCredentials
+
+	Upgrade bool `group:"misc" help:"Write new versions and checksums into the deployment.yml files"`
+	Commit  bool `group:"misc" help:"Create a git commit for every updated manifest"`
+
+	Interactive bool `group:"misc" short:"i" help:"Ask for every manifest if it should be upgraded."`
+}
+
+func (cmd *manifestUpdateCmd) Help() string {
+	return `Only manifests with a semantic version in their url or with a fixed OCI tag are checked. New versions of urls
+are looked up in the tags of the GitHub repository the url belongs to or in the tags of 'updateGitUrl'.
+Optionally performs the actual upgrade and/or add a commit to version control.`
+}
+
+func (cmd *manifestUpdateCmd) Run(ctx context.Context) error {
+	projectDir, err := cmd.ProjectDir.GetProjectDir()
+	if err != nil {
+		return err
+	}
+
+	if !yaml.Exists(filepath.Join(projectDir, ".kluctl.yaml")) && !yaml.Exists(filepath.Join(projectDir, ".kluctl-library.yaml")) {
+		return fmt.Errorf("manifest-update can only be used on the root of a Kluctl project that must have a .kluctl.yaml or .kluctl-library.yaml file")
+	}
+
+	gitRootPath, err := git2.DetectGitRepositoryRoot(projectDir)
+	if err != nil {
+		return err
+	}
+
+	ociAuthProvider := auth_provider.NewDefaultAuthProviders("KLUCTL_REGISTRY")
+	if x, err := cmd.RegistryCredentials.BuildAuthProvider(ctx); err != nil {
+		return err
+	} else {
+		ociAuthProvider.RegisterAuthProvider(x, false)
+	}
+	gitAuthProvider := auth.NewDefaultAuthProviders("KLUCTL_GIT", &messages.MessageCallbacks{
+		WarningFn:            func(s string) { status.Warning(ctx, s) },
+		TraceFn:              func(s string) { status.Trace(ctx, s) },
+		AskForPasswordFn:     func(s string) (string, error) { return prompts.AskForPassword(ctx, s) },
+		AskForConfirmationFn: func(s string) bool { return prompts.AskForConfirmation(ctx, s) },
+	})
+	sshPool := &ssh_pool.SshPool{}
+
+	if cmd.Commit {
+		gitStatus, err := git2.GetWorktreeStatus(ctx, gitRootPath)
+		if err != nil {
+			return err
+		}
+		for _, s := range gitStatus {
+			if (s.Staging != git.Untracked && s.Staging != git.Unmodified) || (s.Worktree != git.Untracked && s.Worktree != git.Unmodified) {
+				status.Tracef(ctx, "gitStatus=%s", gitStatus.String())
+				return fmt.Errorf("--commit can only be used when the git worktree is unmodified")
+			}
+		}
+	}
+
+	manifests, err := deployment.LoadProjectManifests(ctx, projectDir)
+	if err != nil {
+		return err
+	}
+
+	g := utils.NewGoHelper(ctx, 8)
+	for _, mu := range manifests {
+		mu := mu
+		g.RunE(func() error {
+			s := status.Startf(ctx, "%s: Querying versions", mu.GetName())
+			defer s.Failed()
+			err := mu.QueryVersions(ctx, ociAuthProvider, gitAuthProvider, sshPool)
+			if err != nil {
+				s.FailedWithMessagef("%s: %s", mu.GetName(), err.Error())
+				return err
+			}
+			s.Success()
+			return nil
+		})
+	}
+	g.Wait()
+	if g.ErrorOrNil() != nil {
+		return g.ErrorOrNil()
+	}
+
+	for _, mu := range manifests {
+		relPath, err := filepath.Rel(projectDir, mu.ConfigFile)
+		if err != nil {
+			return err
+		}
+
+		latestVersion, err := mu.GetLatestVersion()
+		if err != nil {
+			return err
+		}
+		if mu.Version == latestVersion {
+			continue
+		}
+
+		if mu.Config.SkipUpdate {
+			status.Infof(ctx, "%s: Skipped update of %s to version %s", relPath, mu.GetName(), latestVersion)
+			continue
+		}
+
+		status.Infof(ctx, "%s: Manifest %s has new version %s available", relPath, mu.GetName(), latestVersion)
+
+		if !cmd.Upgrade {
+			continue
+		}
+
+		if cmd.Interactive {
+			if !prompts.AskForConfirmation(ctx, fmt.Sprintf("%s: Do you want to upgrade manifest %s to version %s?",
+				relPath, mu.GetName(), latestVersion)) {
+				continue
+			}
+		}
+
+		err = cmd.upgradeAndCommit(ctx, gitRootPath, mu, latestVersion)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (cmd *manifestUpdateCmd) upgradeAndCommit(ctx context.Context, gitRootPath string, mu *deployment.ManifestUpdate, newVersion string) error {
+	name := mu.GetName()
+	oldVersion := mu.Version
+
+	s := status.Startf(ctx, "Upgrading manifest %s from version %s to %s", name, oldVersion, newVersion)
+	defer s.Failed()
+
+	doError := func(err error) error {
+		s.FailedWithMessage(err.Error())
+		return err
+	}
+
+	err := mu.Upgrade(ctx, newVersion)
+	if err != nil {
+		return doError(err)
+	}
+
+	if cmd.Commit {
+		r, err := git.PlainOpen(gitRootPath)
+		if err != nil {
+			return doError(err)
+		}
+		wt, err := r.Worktree()
+		if err != nil {
+			return doError(err)
+		}
+		relToGit, err := filepath.Rel(gitRootPath, mu.ConfigFile)
+		if err != nil {
+			return doError(err)
+		}
+		_, err = wt.Add(filepath.ToSlash(relToGit))
+		if err != nil {
+			return doError(err)
+		}
+
+		commitMsg := fmt.Sprintf("Updated manifest %s from version %s to version %s", name, oldVersion, newVersion)
+		_, err = wt.Commit(commitMsg, &git.CommitOptions{})
+		if err != nil {
+			return doError(fmt.Errorf("failed to commit: %w", err))
+		}
+
+		s.UpdateAndInfoFallbackf("Committed manifest %s with version %s", name, newVersion)
+	}
+	s.Success()
+
+	return nil
+}
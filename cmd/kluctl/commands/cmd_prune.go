@@ -19,6 +19,7 @@ type pruneCmd struct {
 	args.RegistryCredentials
 	args.YesFlags
 	args.DryRunFlags
+	args.PreflightCheckFlags
 	args.OutputFormatFlags
 	args.RenderOutputDirFlags
 	args.CommandResultFlags
@@ -56,6 +57,7 @@ func (cmd *pruneCmd) Run(ctx context.Context) error {
 
 func (cmd *pruneCmd) runCmdPrune(cmdCtx *commandCtx) error {
 	cmd2 := commands.NewPruneCommand(cmdCtx.targetCtx.Target.Discriminator, cmdCtx.targetCtx, true)
+	cmd2.PreflightCheck = cmd.PreflightCheck
 	result := cmd2.Run(func(refs []k8s2.ObjectRef) error {
 		return confirmDeletion(cmdCtx.ctx, refs, cmd.DryRun, cmd.Yes)
 	})
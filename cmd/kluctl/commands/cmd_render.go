@@ -3,11 +3,14 @@ package commands
 import (
 	"context"
 	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_jinja2"
 	"github.com/kluctl/kluctl/v2/pkg/status"
 	"github.com/kluctl/kluctl/v2/pkg/utils"
 	"github.com/kluctl/kluctl/v2/pkg/yaml"
 	"io/ioutil"
 	"os"
+	"path/filepath"
 )
 
 type renderCmd struct {
@@ -22,7 +25,8 @@ type renderCmd struct {
 	args.RenderOutputDirFlags
 	args.OfflineKubernetesFlags
 
-	PrintAll bool `group:"misc" help:"Write all rendered manifests to stdout"`
+	PrintAll       bool `group:"misc" help:"Write all rendered manifests to stdout"`
+	TraceTemplates bool `group:"misc" help:"Record the accessed variables, undefined lookups, includes and deployment item chain of every rendered template. The trace also contains the render cache statistics and the objects that transformations were applied to. It is written to template-trace.yaml inside the render output directory or to stderr if --print-all is used."`
 }
 
 func (cmd *renderCmd) Help() string {
@@ -53,8 +57,16 @@ func (cmd *renderCmd) Run(ctx context.Context) error {
 		renderOutputDirFlags: cmd.RenderOutputDirFlags,
 		offlineKubernetes:    cmd.OfflineKubernetes,
 		kubernetesVersion:    cmd.KubernetesVersion,
+		traceTemplates:       cmd.TraceTemplates,
 	}
 	return withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
+		if cmd.TraceTemplates {
+			err := cmd.writeTemplateTrace(cmdCtx)
+			if err != nil {
+				return err
+			}
+		}
+
 		if cmd.PrintAll {
 			var all []any
 			for _, d := range cmdCtx.targetCtx.DeploymentCollection.Deployments {
@@ -73,3 +85,47 @@ func (cmd *renderCmd) Run(ctx context.Context) error {
 		return nil
 	})
 }
+
+type templateTraceOutput struct {
+	Templates       []*kluctl_jinja2.TemplateTrace   `json:"templates"`
+	RenderCache     *deployment.RenderCacheStats     `json:"renderCache,omitempty"`
+	Transformations []deployment.TransformationTrace `json:"transformations,omitempty"`
+}
+
+func (cmd *renderCmd) writeTemplateTrace(cmdCtx *commandCtx) error {
+	p := cmdCtx.targetCtx.KluctlProject
+	repoRoot, err := filepath.Abs(p.LoadArgs.RepoRoot)
+	if err != nil {
+		return err
+	}
+	report, err := p.LoadArgs.TemplateTracer.Report(repoRoot)
+	if err != nil {
+		return err
+	}
+	trace := templateTraceOutput{
+		Templates:       report,
+		Transformations: cmdCtx.targetCtx.DeploymentCollection.TransformationTraces(),
+	}
+	if cmdCtx.targetCtx.SharedContext.RenderCache != nil {
+		stats := cmdCtx.targetCtx.DeploymentCollection.RenderCacheStats()
+		trace.RenderCache = &stats
+	}
+
+	if cmd.PrintAll {
+		s, err := yaml.WriteYamlString(trace)
+		if err != nil {
+			return err
+		}
+		status.Flush(cmdCtx.ctx)
+		_, err = getStderr(cmdCtx.ctx).WriteString(s)
+		return err
+	}
+
+	tracePath := filepath.Join(cmdCtx.targetCtx.SharedContext.RenderDir, "template-trace.yaml")
+	err = yaml.WriteYamlFile(tracePath, trace)
+	if err != nil {
+		return err
+	}
+	status.Infof(cmdCtx.ctx, "Written template trace to %s", tracePath)
+	return nil
+}
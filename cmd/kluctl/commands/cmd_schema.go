@@ -0,0 +1,47 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/yaml"
+	"strings"
+)
+
+type schemaCmd struct {
+	Type   string   `group:"misc" help:"The configuration file type to print the schema for. Can be 'kluctl-project', 'deployment' or 'helm-chart'." required:"true"`
+	Output []string `group:"misc" short:"o" help:"Specify output format and target file, in the format 'format=path'. Format can either be 'json' or 'yaml'. Can be specified multiple times."`
+}
+
+func (cmd *schemaCmd) Help() string {
+	return `The schemas are generated from the same types that Kluctl uses to load .kluctl.yaml, deployment.yml and
+helm-chart.yaml files. They can be used to configure editors (e.g. via yaml-language-server) for
+auto-completion and validation.`
+}
+
+func (cmd *schemaCmd) Run(ctx context.Context) error {
+	s := types.GetConfigSchema(cmd.Type)
+	if s == nil {
+		return fmt.Errorf("unknown schema type %s, must be one of %s", cmd.Type, strings.Join(types.ConfigSchemaNames(), ", "))
+	}
+
+	output := cmd.Output
+	if len(output) == 0 {
+		output = []string{"json"}
+	}
+	return outputHelper(ctx, output, func(format string) (string, error) {
+		switch format {
+		case "json":
+			b, err := json.MarshalIndent(s, "", "  ")
+			if err != nil {
+				return "", err
+			}
+			return string(b) + "\n", nil
+		case "yaml":
+			return yaml.WriteYamlString(s)
+		default:
+			return "", fmt.Errorf("invalid format: %s", format)
+		}
+	})
+}
@@ -0,0 +1,6 @@
+package commands
+
+type secretsCmd struct {
+	Rotate secretsRotateCmd `cmd:"" help:"Generate a new value for a generated secret"`
+	List   secretsListCmd   `cmd:"" help:"List generated secrets of a target"`
+}
@@ -0,0 +1,46 @@
+package commands
+
+import (
+	"context"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"sort"
+)
+
+type secretsListCmd struct {
+	args.ProjectFlags
+	args.KubeconfigFlags
+	args.TargetFlags
+	args.ArgsFlags
+	args.InclusionFlags
+	args.HelmCredentials
+	args.RegistryCredentials
+	args.OutputFlags
+	args.RenderOutputDirFlags
+}
+
+func (cmd *secretsListCmd) Help() string {
+	return `Outputs the names and generation parameters of all generated secrets of the target. Values are not printed.`
+}
+
+func (cmd *secretsListCmd) Run(ctx context.Context) error {
+	ptArgs := projectTargetCommandArgs{
+		projectFlags:         cmd.ProjectFlags,
+		kubeconfigFlags:      cmd.KubeconfigFlags,
+		targetFlags:          cmd.TargetFlags,
+		argsFlags:            cmd.ArgsFlags,
+		inclusionFlags:       cmd.InclusionFlags,
+		helmCredentials:      cmd.HelmCredentials,
+		registryCredentials:  cmd.RegistryCredentials,
+		renderOutputDirFlags: cmd.RenderOutputDirFlags,
+	}
+	return withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
+		l, err := cmdCtx.targetCtx.SharedContext.GeneratedSecrets.List()
+		if err != nil {
+			return err
+		}
+		sort.Slice(l, func(i, j int) bool {
+			return l[i].Name < l[j].Name
+		})
+		return outputYamlResult(ctx, cmd.Output, l, false)
+	})
+}
@@ -0,0 +1,59 @@
+package commands
+
+import (
+	"context"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/status"
+	"github.com/spf13/cobra"
+)
+
+type secretsRotateCmd struct {
+	args.ProjectFlags
+	args.KubeconfigFlags
+	args.TargetFlags
+	args.ArgsFlags
+	args.InclusionFlags
+	args.HelmCredentials
+	args.RegistryCredentials
+	args.RenderOutputDirFlags
+
+	names []string
+}
+
+func (cmd *secretsRotateCmd) Help() string {
+	return `Generates new values for the generated secrets passed as arguments, e.g. 'kluctl secrets rotate db-password -t prod',
+and stores them in the target cluster.
+The secrets keep the length and charset they were originally generated with. Deploy the target afterwards
+to roll out the new values.`
+}
+
+func (cmd *secretsRotateCmd) PositionalArgs() cobra.PositionalArgs {
+	return cobra.MinimumNArgs(1)
+}
+
+func (cmd *secretsRotateCmd) SetPositionalArgs(args []string) {
+	cmd.names = args
+}
+
+func (cmd *secretsRotateCmd) Run(ctx context.Context) error {
+	ptArgs := projectTargetCommandArgs{
+		projectFlags:         cmd.ProjectFlags,
+		kubeconfigFlags:      cmd.KubeconfigFlags,
+		targetFlags:          cmd.TargetFlags,
+		argsFlags:            cmd.ArgsFlags,
+		inclusionFlags:       cmd.InclusionFlags,
+		helmCredentials:      cmd.HelmCredentials,
+		registryCredentials:  cmd.RegistryCredentials,
+		renderOutputDirFlags: cmd.RenderOutputDirFlags,
+	}
+	return withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
+		for _, name := range cmd.names {
+			err := cmdCtx.targetCtx.SharedContext.GeneratedSecrets.Rotate(name)
+			if err != nil {
+				return err
+			}
+			status.Infof(ctx, "Rotated generated secret %s", name)
+		}
+		return nil
+	})
+}
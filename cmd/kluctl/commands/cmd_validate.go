@@ -23,6 +23,8 @@ type validateCmd struct {
 	Wait             time.Duration `group:"misc" help:"Wait for the given amount of time until the deployment validates"`
 	Sleep            time.Duration `group:"misc" help:"Sleep duration between validation attempts" default:"5s"`
 	WarningsAsErrors bool          `group:"misc" help:"Consider warnings as failures"`
+
+	History validateHistoryCmd `cmd:"" help:"Show the validation history of GitOps deployments"`
 }
 
 func (cmd *validateCmd) Help() string {
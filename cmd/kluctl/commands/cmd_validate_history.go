@@ -0,0 +1,69 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/results"
+	"github.com/kluctl/kluctl/v2/pkg/status"
+	"time"
+)
+
+type validateHistoryCmd struct {
+	args.GitOpsArgs
+	args.OutputFlags
+
+	Since time.Duration `group:"misc" help:"Only show readiness transitions that happened within the given duration. Shows the full history by default."`
+}
+
+func (cmd *validateHistoryCmd) Help() string {
+	return `This command shows the history of readiness transitions recorded by the Kluctl controller while validating
+KluctlDeployments. Only changes in readiness are recorded, for the target as a whole and for each individual object.
+
+The history is kept for the duration configured via '--keep-validate-history' of the controller.`
+}
+
+func (cmd *validateHistoryCmd) Run(ctx context.Context) error {
+	g := gitopsCmdHelper{
+		args:        cmd.GitOpsArgs,
+		noArgsReact: noArgsAutoDetectProject,
+	}
+	err := g.init(ctx)
+	if err != nil {
+		return err
+	}
+
+	var since time.Time
+	if cmd.Since != 0 {
+		since = time.Now().Add(-cmd.Since)
+	}
+
+	for _, kd := range g.kds {
+		if kd.Status.ProjectKey == nil || kd.Status.TargetKey == nil {
+			status.Warningf(ctx, "KluctlDeployment %s/%s has not been reconciled yet", kd.Namespace, kd.Name)
+			continue
+		}
+
+		vh, err := g.resultStore.GetValidateHistory(results.GetValidateHistoryOptions{
+			ProjectKey: *kd.Status.ProjectKey,
+			TargetKey:  *kd.Status.TargetKey,
+		})
+		if err != nil {
+			return err
+		}
+		if vh == nil {
+			status.Warningf(ctx, "No validation history found for KluctlDeployment %s/%s", kd.Namespace, kd.Name)
+			continue
+		}
+
+		status.Flush(ctx)
+		err = outputHelper(ctx, cmd.Output, func(format string) (string, error) {
+			return formatValidateHistory(vh, format, since)
+		})
+		if err != nil {
+			return fmt.Errorf("failed to output validation history: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,5 @@
+package commands
+
+type varsCmd struct {
+	Explain varsExplainCmd `cmd:"" help:"Explain where variables came from"`
+}
@@ -0,0 +1,108 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/vars"
+	"github.com/kluctl/kluctl/v2/pkg/yaml"
+	"strings"
+)
+
+type varsExplainCmd struct {
+	args.ProjectFlags
+	args.KubeconfigFlags
+	args.TargetFlags
+	args.ArgsFlags
+	args.ImageFlags
+	args.InclusionFlags
+	args.HelmCredentials
+	args.RegistryCredentials
+	args.RenderOutputDirFlags
+	args.OfflineKubernetesFlags
+
+	Path   string   `group:"misc" help:"Only explain the variable at the given path and all its children, e.g. 'args.environment'."`
+	Output []string `group:"misc" short:"o" help:"Specify output format and target file, in the format 'format=path'. Format can either be 'text' or 'yaml'. Can be specified multiple times."`
+}
+
+func (cmd *varsExplainCmd) Help() string {
+	return `Loads the target and records which vars source set or overrode each variable, in the order
+the changes happened. Vars sources that were skipped due to their 'when' condition are listed as well.
+Sensitive values are masked.`
+}
+
+func (cmd *varsExplainCmd) Run(ctx context.Context) error {
+	ptArgs := projectTargetCommandArgs{
+		projectFlags:         cmd.ProjectFlags,
+		kubeconfigFlags:      cmd.KubeconfigFlags,
+		targetFlags:          cmd.TargetFlags,
+		argsFlags:            cmd.ArgsFlags,
+		imageFlags:           cmd.ImageFlags,
+		inclusionFlags:       cmd.InclusionFlags,
+		helmCredentials:      cmd.HelmCredentials,
+		registryCredentials:  cmd.RegistryCredentials,
+		renderOutputDirFlags: cmd.RenderOutputDirFlags,
+		offlineKubernetes:    cmd.OfflineKubernetes,
+		kubernetesVersion:    cmd.KubernetesVersion,
+		varsProvenance:       vars.NewProvenanceRecorder(),
+	}
+	return withProjectCommandContext(ctx, ptArgs, func(cmdCtx *commandCtx) error {
+		p := filterVarsProvenance(ptArgs.varsProvenance.Result(), cmd.Path)
+		return outputHelper(ctx, cmd.Output, func(format string) (string, error) {
+			switch format {
+			case "text":
+				return formatVarsProvenanceText(p), nil
+			case "yaml":
+				return yaml.WriteYamlString(p)
+			default:
+				return "", fmt.Errorf("invalid format: %s", format)
+			}
+		})
+	})
+}
+
+func filterVarsProvenance(p *result.VarsProvenance, path string) *result.VarsProvenance {
+	if path == "" {
+		return p
+	}
+	ret := &result.VarsProvenance{
+		Skipped: p.Skipped,
+	}
+	for _, k := range p.Keys {
+		if k.Path == path || strings.HasPrefix(k.Path, path+".") || strings.HasPrefix(k.Path, path+"[") {
+			ret.Keys = append(ret.Keys, k)
+		}
+	}
+	return ret
+}
+
+func formatVarsProvenanceText(p *result.VarsProvenance) string {
+	buf := bytes.NewBuffer(nil)
+
+	for _, k := range p.Keys {
+		finalValue := ""
+		for _, e := range k.Entries {
+			if e.Action != vars.ProvenanceActionIgnored {
+				finalValue = e.Value
+			}
+		}
+		buf.WriteString(fmt.Sprintf("%s = %s\n", k.Path, finalValue))
+		for i, e := range k.Entries {
+			prefix := "├──"
+			if i == len(k.Entries)-1 {
+				prefix = "└──"
+			}
+			buf.WriteString(fmt.Sprintf("%s %-8s %s, from %s (%s)\n", prefix, e.Action, e.Value, e.Source, e.Origin))
+		}
+	}
+
+	if len(p.Skipped) != 0 {
+		buf.WriteString("\nSkipped vars sources:\n")
+		for _, s := range p.Skipped {
+			buf.WriteString(fmt.Sprintf("  %s: %s (when: %s)\n", s.Origin, s.Source, s.When))
+		}
+	}
+	return buf.String()
+}
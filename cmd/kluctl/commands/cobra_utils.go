@@ -25,6 +25,12 @@ type runProvider interface {
 	Run(ctx context.Context) error
 }
 
+// positionalArgsProvider is implemented by commands that accept positional arguments
+type positionalArgsProvider interface {
+	PositionalArgs() cobra.PositionalArgs
+	SetPositionalArgs(args []string)
+}
+
 type rootCommand struct {
 	rootCmd    *commandAndGroups
 	groupInfos []groupInfo
@@ -67,9 +73,17 @@ func (c *rootCommand) buildCobraCmd(parent *commandAndGroups, cmdStruct interfac
 		},
 	}
 
+	argsP, hasArgs := cmdStruct.(positionalArgsProvider)
+	if hasArgs {
+		cg.cmd.Args = argsP.PositionalArgs()
+	}
+
 	runP, ok := cmdStruct.(runProvider)
 	if ok {
 		cg.cmd.RunE = func(cmd *cobra.Command, args []string) error {
+			if hasArgs {
+				argsP.SetPositionalArgs(args)
+			}
 			return runP.Run(cmd.Context())
 		}
 	}
@@ -320,6 +334,9 @@ func (c *rootCommand) helpFunc(cg *commandAndGroups, cmd *cobra.Command) {
 		h += cmd.UseLine()
 	}
 	if cmd.HasAvailableSubCommands() {
+		if cmd.Runnable() {
+			h += "\n       "
+		}
 		h += fmt.Sprintf("%s [command]", cmd.CommandPath())
 	}
 
@@ -349,6 +366,8 @@ func (c *rootCommand) helpFunc(cg *commandAndGroups, cmd *cobra.Command) {
 		h += usages
 	}
 
+	h += projectArgsHelp(cmd)
+
 	if cmd.HasAvailableSubCommands() {
 		h += "\nCommands:\n"
 		for _, subCmd := range cmd.Commands() {
@@ -365,6 +384,11 @@ func (c *rootCommand) buildGroupedFlagSets(cg *commandAndGroups) map[string]*pfl
 
 	x := cg
 	for x != nil {
+		if x != cg && x.parent != nil && x.cmd.Runnable() {
+			// flags of runnable parent commands are specific to the parent, e.g. 'validate' vs 'validate history'
+			x = x.parent
+			continue
+		}
 		x.cmd.PersistentFlags().VisitAll(func(flag *pflag.Flag) {
 			group, ok := x.groups[flag.Name]
 			if !ok {
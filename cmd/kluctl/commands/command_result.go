@@ -14,6 +14,7 @@ import (
 	"io"
 	"os"
 	"strings"
+	"time"
 )
 
 func formatCommandResultText(cr *result.CommandResult, short bool) string {
@@ -197,6 +198,57 @@ func formatValidateResult(vr *result.ValidateResult, format string) (string, err
 	}
 }
 
+func prettyValidateHistoryTransitions(buf io.StringWriter, ref *k8s.ObjectRef, transitions []result.ValidateHistoryTransition, since time.Time) {
+	for _, t := range transitions {
+		if t.Time.Time.Before(since) {
+			continue
+		}
+		state := "ready"
+		if !t.Ready {
+			state = "not ready"
+		}
+		s := fmt.Sprintf("  %s: %s", t.Time.Format(time.RFC3339), state)
+		if ref != nil {
+			s = fmt.Sprintf("  %s: %s is %s", t.Time.Format(time.RFC3339), ref.String(), state)
+		}
+		if t.Message != "" {
+			s += fmt.Sprintf(" (%s)", t.Message)
+		}
+		_, _ = buf.WriteString(s + "\n")
+	}
+}
+
+func formatValidateHistoryText(vh *result.ValidateHistory, since time.Time) string {
+	buf := bytes.NewBuffer(nil)
+
+	if vh.KluctlDeployment != nil {
+		buf.WriteString(fmt.Sprintf("KluctlDeployment %s/%s, last validation at %s\n", vh.KluctlDeployment.Namespace, vh.KluctlDeployment.Name, vh.LastValidateTime.Format(time.RFC3339)))
+	}
+
+	buf.WriteString("\nTarget readiness:\n")
+	prettyValidateHistoryTransitions(buf, nil, vh.Transitions, since)
+
+	if len(vh.Objects) != 0 {
+		buf.WriteString("\nObject readiness:\n")
+		for _, o := range vh.Objects {
+			prettyValidateHistoryTransitions(buf, &o.Ref, o.Transitions, since)
+		}
+	}
+
+	return buf.String()
+}
+
+func formatValidateHistory(vh *result.ValidateHistory, format string, since time.Time) (string, error) {
+	switch format {
+	case "text":
+		return formatValidateHistoryText(vh, since), nil
+	case "yaml":
+		return yaml.WriteYamlString(vh)
+	default:
+		return "", fmt.Errorf("invalid validation history format: %s", format)
+	}
+}
+
 func outputHelper(ctx context.Context, output []string, cb func(format string) (string, error)) error {
 	if len(output) == 0 {
 		output = []string{"text"}
@@ -249,6 +301,9 @@ func outputCommandResult(ctx *commandCtx, flags args.OutputFormatFlags, cr *resu
 		}
 
 		resultStoreErr = ctx.resultStore.WriteCommandResult(cr)
+		if resultStoreErr == nil && cr.Outputs != nil && len(cr.Errors) == 0 && !cr.Command.DryRun {
+			resultStoreErr = ctx.resultStore.WriteProjectOutputs(result.NewProjectOutputs(cr))
+		}
 		if resultStoreErr != nil {
 			s.FailedWithMessagef("Failed to write result to result store: %s", resultStoreErr.Error())
 		} else {
@@ -60,22 +60,29 @@ type GlobalFlags struct {
 type cli struct {
 	GlobalFlags
 
-	Delete      deleteCmd      `cmd:"" help:"Delete a target (or parts of it) from the corresponding cluster"`
-	Deploy      deployCmd      `cmd:"" help:"Deploys a target to the corresponding cluster"`
-	Diff        diffCmd        `cmd:"" help:"Perform a diff between the locally rendered target and the already deployed target"`
-	HelmPull    helmPullCmd    `cmd:"" help:"Recursively searches for 'helm-chart.yaml' files and pre-pulls the specified Helm charts"`
-	HelmUpdate  helmUpdateCmd  `cmd:"" help:"Recursively searches for 'helm-chart.yaml' files and checks for new available versions"`
-	ListImages  listImagesCmd  `cmd:"" help:"Renders the target and outputs all images used via 'images.get_image(...)"`
-	ListTargets listTargetsCmd `cmd:"" help:"Outputs a yaml list with all targets"`
-	PokeImages  pokeImagesCmd  `cmd:"" help:"Replace all images in target"`
-	Prune       pruneCmd       `cmd:"" help:"Searches the target cluster for prunable objects and deletes them"`
-	Render      renderCmd      `cmd:"" help:"Renders all resources and configuration files"`
-	Seal        sealCmd        `cmd:"" help:"Seal secrets based on target's sealingConfig"`
-	Validate    validateCmd    `cmd:"" help:"Validates the already deployed deployment"`
-	Controller  controllerCmd  `cmd:"" help:"Kluctl controller sub-commands"`
-	Gitops      gitopsCmd      `cmd:"" help:"GitOps sub-commands"`
-	Webui       webuiCmd       `cmd:"" help:"Kluctl Webui sub-commands"`
-	Oci         ociCmd         `cmd:"" help:"Oci sub-commands"`
+	Delete         deleteCmd         `cmd:"" help:"Delete a target (or parts of it) from the corresponding cluster"`
+	Deploy         deployCmd         `cmd:"" help:"Deploys a target to the corresponding cluster"`
+	Diff           diffCmd           `cmd:"" help:"Perform a diff between the locally rendered target and the already deployed target"`
+	HelmPull       helmPullCmd       `cmd:"" help:"Recursively searches for 'helm-chart.yaml' files and pre-pulls the specified Helm charts"`
+	HelmUpdate     helmUpdateCmd     `cmd:"" help:"Recursively searches for 'helm-chart.yaml' files and checks for new available versions"`
+	ManifestUpdate manifestUpdateCmd `cmd:"" help:"Recursively searches for manifest deployment items and checks for new available versions"`
+	ListArgs       listArgsCmd       `cmd:"" help:"Outputs all arguments declared by the project"`
+	Lint           lintCmd           `cmd:"" help:"Statically analyses all targets of a project without a cluster"`
+	ListImages     listImagesCmd     `cmd:"" help:"Renders the target and outputs all images used via 'images.get_image(...)"`
+	ListTargets    listTargetsCmd    `cmd:"" help:"Outputs a yaml list with all targets"`
+	Lsp            lspCmd            `cmd:"" help:"Starts the Kluctl language server"`
+	PokeImages     pokeImagesCmd     `cmd:"" help:"Replace all images in target"`
+	Prune          pruneCmd          `cmd:"" help:"Searches the target cluster for prunable objects and deletes them"`
+	Render         renderCmd         `cmd:"" help:"Renders all resources and configuration files"`
+	Schema         schemaCmd         `cmd:"" help:"Prints the JSON Schema for Kluctl configuration files"`
+	Seal           sealCmd           `cmd:"" help:"Seal secrets based on target's sealingConfig"`
+	Secrets        secretsCmd        `cmd:"" help:"Generated secrets sub-commands"`
+	Validate       validateCmd       `cmd:"" help:"Validates the already deployed deployment"`
+	Vars           varsCmd           `cmd:"" help:"Vars sub-commands"`
+	Controller     controllerCmd     `cmd:"" help:"Kluctl controller sub-commands"`
+	Gitops         gitopsCmd         `cmd:"" help:"GitOps sub-commands"`
+	Webui          webuiCmd          `cmd:"" help:"Kluctl Webui sub-commands"`
+	Oci            ociCmd            `cmd:"" help:"Oci sub-commands"`
 
 	Version versionCmd `cmd:"" help:"Print kluctl version"`
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/google/uuid"
+	"github.com/kluctl/go-jinja2"
 	"github.com/kluctl/kluctl/v2/cmd/kluctl/args"
 	"github.com/kluctl/kluctl/v2/pkg/deployment"
 	"github.com/kluctl/kluctl/v2/pkg/git"
@@ -21,17 +22,32 @@ import (
 	"github.com/kluctl/kluctl/v2/pkg/results"
 	"github.com/kluctl/kluctl/v2/pkg/status"
 	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/kluctl/kluctl/v2/pkg/vars"
+	vars_plugin "github.com/kluctl/kluctl/v2/pkg/vars/plugin"
 	"k8s.io/apimachinery/pkg/api/errors"
 	"k8s.io/apimachinery/pkg/api/meta"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/clientcmd/api"
 	"os"
+	"path/filepath"
 	client2 "sigs.k8s.io/controller-runtime/pkg/client"
 )
 
-func withKluctlProjectFromArgs(ctx context.Context, kubeconfigFlags *args.KubeconfigFlags, projectFlags args.ProjectFlags, argsFlags *args.ArgsFlags, helmCredentials *args.HelmCredentials, registryCredentials *args.RegistryCredentials, internalDeploy bool, strictTemplates bool, forCompletion bool, cb func(ctx context.Context, p *kluctl_project.LoadedKluctlProject) error) error {
-	j2, err := kluctl_jinja2.NewKluctlJinja2(ctx, strictTemplates)
+func withKluctlProjectFromArgs(ctx context.Context, kubeconfigFlags *args.KubeconfigFlags, projectFlags args.ProjectFlags, argsFlags *args.ArgsFlags, helmCredentials *args.HelmCredentials, registryCredentials *args.RegistryCredentials, internalDeploy bool, strictTemplates bool, traceTemplates bool, forCompletion bool, cb func(ctx context.Context, p *kluctl_project.LoadedKluctlProject) error) error {
+	var tracer *kluctl_jinja2.TemplateTracer
+	var j2Opts []jinja2.Jinja2Opt
+	if !forCompletion {
+		var err error
+		tracer, err = kluctl_jinja2.NewTemplateTracer(utils.GetTmpBaseDir(ctx), traceTemplates)
+		if err != nil {
+			return err
+		}
+		defer tracer.Close()
+		j2Opts = tracer.Opts()
+	}
+
+	j2, err := kluctl_jinja2.NewKluctlJinja2(ctx, strictTemplates, j2Opts...)
 	if err != nil {
 		return err
 	}
@@ -105,14 +121,19 @@ func withKluctlProjectFromArgs(ctx context.Context, kubeconfigFlags *args.Kubeco
 		OciAuthProvider:    ociAuth,
 		HelmAuthProvider:   helmAuth,
 		ClientConfigGetter: clientConfigGetter(kubeconfigFlags, forCompletion),
+		TemplateTracer:     tracer,
+
+		AllowTemplatingExtensions: projectFlags.AllowTemplatingExtensions,
 	}
 
 	p, err := kluctl_project.LoadKluctlProject(ctx, loadArgs, j2)
-	if err != nil {
-		return err
+	if err == nil {
+		err = cb(ctx, p)
 	}
-
-	return cb(ctx, p)
+	if tracer != nil {
+		tracer.WarnUndefined(ctx, repoRoot)
+	}
+	return err
 }
 
 type projectTargetCommandArgs struct {
@@ -127,6 +148,7 @@ type projectTargetCommandArgs struct {
 	dryRunArgs           *args.DryRunFlags
 	renderOutputDirFlags args.RenderOutputDirFlags
 	commandResultFlags   *args.CommandResultFlags
+	varsProvenance       *vars.ProvenanceRecorder
 
 	discriminator string
 
@@ -135,6 +157,7 @@ type projectTargetCommandArgs struct {
 	forCompletion     bool
 	offlineKubernetes bool
 	kubernetesVersion string
+	traceTemplates    bool
 }
 
 type commandCtx struct {
@@ -147,7 +170,7 @@ type commandCtx struct {
 }
 
 func withProjectCommandContext(ctx context.Context, args projectTargetCommandArgs, cb func(cmdCtx *commandCtx) error) error {
-	return withKluctlProjectFromArgs(ctx, &args.kubeconfigFlags, args.projectFlags, &args.argsFlags, &args.helmCredentials, &args.registryCredentials, args.internalDeploy, true, false, func(ctx context.Context, p *kluctl_project.LoadedKluctlProject) error {
+	return withKluctlProjectFromArgs(ctx, &args.kubeconfigFlags, args.projectFlags, &args.argsFlags, &args.helmCredentials, &args.registryCredentials, args.internalDeploy, true, args.traceTemplates, false, func(ctx context.Context, p *kluctl_project.LoadedKluctlProject) error {
 		return withProjectTargetCommandContext(ctx, args, p, cb)
 	})
 }
@@ -174,6 +197,41 @@ func withProjectTargetCommandContext(ctx context.Context, args projectTargetComm
 		return err
 	}
 
+	var varsSourceRegistry *vars.VarsSourceRegistry
+	if len(args.projectFlags.VarsPlugin) != 0 {
+		r, closePlugins, err := vars_plugin.NewRegistryWithPlugins(ctx, args.projectFlags.VarsPlugin)
+		if err != nil {
+			return err
+		}
+		defer closePlugins()
+		varsSourceRegistry = r
+	}
+
+	varsCacheMode, err := vars.ParseVarsCacheMode(args.projectFlags.VarsCache)
+	if err != nil {
+		return err
+	}
+	var varsCache vars.VarsCache
+	if varsCacheMode != vars.VarsCacheOff {
+		varsCache, err = vars.NewFileVarsCache(filepath.Join(utils.GetCacheDir(ctx), "vars-cache"), args.projectFlags.VarsCacheKey)
+		if err != nil {
+			return err
+		}
+	}
+
+	var renderCache *deployment.RenderCache
+	if !args.projectFlags.NoRenderCache && !args.forSeal && !args.forCompletion {
+		renderCache, err = deployment.NewRenderCache(filepath.Join(utils.GetCacheDir(ctx), "render-cache"))
+		if err != nil {
+			return err
+		}
+	}
+
+	varsProvenance := args.varsProvenance
+	if varsProvenance == nil && args.commandResultFlags != nil && args.commandResultFlags.RecordVarsProvenance {
+		varsProvenance = vars.NewProvenanceRecorder()
+	}
+
 	renderOutputDir := args.renderOutputDirFlags.RenderOutputDir
 	if renderOutputDir == "" {
 		tmpDir, err := os.MkdirTemp(tmpDir, "rendered")
@@ -185,19 +243,28 @@ func withProjectTargetCommandContext(ctx context.Context, args projectTargetComm
 	}
 
 	targetParams := target_context.TargetContextParams{
-		TargetName:         args.targetFlags.Target,
-		TargetNameOverride: args.targetFlags.TargetNameOverride,
-		ContextOverride:    args.targetFlags.Context,
-		Discriminator:      args.discriminator,
-		OfflineK8s:         args.offlineKubernetes,
-		K8sVersion:         args.kubernetesVersion,
-		DryRun:             args.dryRunArgs == nil || args.dryRunArgs.DryRun || args.forCompletion,
-		ForSeal:            args.forSeal,
-		Images:             images,
-		Inclusion:          inclusion,
-		OciAuthProvider:    p.LoadArgs.OciAuthProvider,
-		HelmAuthProvider:   p.LoadArgs.HelmAuthProvider,
-		RenderOutputDir:    renderOutputDir,
+		TargetName:              args.targetFlags.Target,
+		TargetNameOverride:      args.targetFlags.TargetNameOverride,
+		ContextOverride:         args.targetFlags.Context,
+		Discriminator:           args.discriminator,
+		OfflineK8s:              args.offlineKubernetes,
+		K8sVersion:              args.kubernetesVersion,
+		DryRun:                  args.dryRunArgs == nil || args.dryRunArgs.DryRun || args.forCompletion,
+		ForSeal:                 args.forSeal,
+		Images:                  images,
+		Inclusion:               inclusion,
+		OciAuthProvider:         p.LoadArgs.OciAuthProvider,
+		HelmAuthProvider:        p.LoadArgs.HelmAuthProvider,
+		RenderOutputDir:         renderOutputDir,
+		AllowExecVars:           args.projectFlags.AllowExecVars,
+		AllowVaultTokenFiles:    args.projectFlags.AllowVaultTokenFiles,
+		AllowKrmExec:            args.projectFlags.AllowKrmExec,
+		AllowKrmExecFromProject: args.projectFlags.AllowKrmExecFromProject,
+		VarsSourceRegistry:      varsSourceRegistry,
+		VarsProvenance:          varsProvenance,
+		VarsCache:               varsCache,
+		VarsCacheMode:           varsCacheMode,
+		RenderCache:             renderCache,
 	}
 
 	commandResultId := uuid.NewString()
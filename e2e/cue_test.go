@@ -0,0 +1,66 @@
+package e2e
+
+import (
+	test_utils "github.com/kluctl/kluctl/v2/e2e/test_project"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/kluctl/kluctl/v2/pkg/yaml"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCueDeployment(t *testing.T) {
+	t.Parallel()
+
+	p := test_utils.NewTestProject(t)
+
+	p.UpdateTarget("test", func(target *uo.UnstructuredObject) {
+		_ = target.SetNestedField("prod", "args", "env")
+	})
+
+	p.UpdateFile("app/main.cue", func(f string) (string, error) {
+		return `package app
+
+vars: {
+	args: env: "prod" | "dev"
+	target: name: string
+	...
+}
+
+objects: cm: {
+	apiVersion: "v1"
+	kind:       "ConfigMap"
+	metadata: {
+		name:      "cm-\(vars.args.env)"
+		namespace: "` + p.TestSlug() + `"
+	}
+	data: target: vars.target.name
+}
+`, nil
+	}, "")
+	p.AddDeploymentItem("", uo.FromMap(map[string]any{
+		"cue": map[string]any{
+			"dir": "app",
+		},
+	}))
+
+	stdout, _ := p.KluctlMust(t, "render", "-t", "test", "--print-all")
+	y, err := yaml.ReadYamlAllString(stdout)
+	assert.NoError(t, err)
+	if !assert.Len(t, y, 1) {
+		return
+	}
+
+	cm := uo.FromMap(y[0].(map[string]any))
+	assert.Equal(t, "cm-prod", cm.GetK8sName())
+	assert.Equal(t, map[string]any{"target": "test"}, cm.Object["data"])
+	assert.Equal(t, utils.Ptr("app"), cm.GetK8sAnnotation("kluctl.io/deployment-item-dir"))
+	assert.Equal(t, utils.Ptr(p.Discriminator("test")), cm.GetK8sLabel("kluctl.io/discriminator"))
+
+	p.UpdateTarget("test", func(target *uo.UnstructuredObject) {
+		_ = target.SetNestedField("qa", "args", "env")
+	})
+	_, _, err = p.Kluctl(t, "render", "-t", "test", "--print-all")
+	assert.ErrorContains(t, err, `vars.args.env: conflicting values "prod" and "qa"`)
+	assert.ErrorContains(t, err, "app/main.cue:4:")
+}
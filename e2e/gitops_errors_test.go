@@ -136,7 +136,7 @@ data:
 			return "a: b", nil
 		}, "")
 		kd := suite.waitForReconcile(key)
-		suite.assertErrors(kd, metav1.ConditionFalse, kluctlv1.PrepareFailedReason, "prepare failed. Check status.lastPrepareError for details", ".kluctl.yml failed: error unmarshaling JSON: while decoding JSON: json: unknown field \"a\"", nil, nil)
+		suite.assertErrors(kd, metav1.ConditionFalse, kluctlv1.PrepareFailedReason, "prepare failed. Check status.lastPrepareError for details", ".kluctl.yml:1:1: a: unknown field \"a\"", nil, nil)
 		p.UpdateFile(".kluctl.yml", func(f string) (string, error) {
 			return kluctlBackup, nil
 		}, "")
@@ -150,7 +150,21 @@ data:
 			return "a: b", nil
 		}, "")
 		kd := suite.waitForReconcile(key)
-		suite.assertErrors(kd, metav1.ConditionFalse, kluctlv1.PrepareFailedReason, "prepare failed. Check status.lastPrepareError for details", "failed to load deployment.yml: error unmarshaling JSON: while decoding JSON: json: unknown field \"a\"", nil, nil)
+		suite.assertErrors(kd, metav1.ConditionFalse, kluctlv1.PrepareFailedReason, "prepare failed. Check status.lastPrepareError for details", "deployment.yml:1:1: a: unknown field \"a\"", nil, nil)
+		p.UpdateFile("deployment.yml", func(f string) (string, error) {
+			return deploymentBackup, nil
+		}, "")
+		suite.waitForCommit(key, getHeadRevision(suite.T(), p))
+	})
+
+	suite.Run("unknown vars source", func() {
+		deploymentBackup := ""
+		p.UpdateFile("deployment.yml", func(f string) (string, error) {
+			deploymentBackup = f
+			return "vars:\n  - clustrConfigMap:\n      name: cm\n" + f, nil
+		}, "")
+		kd := suite.waitForReconcile(key)
+		suite.assertErrors(kd, metav1.ConditionFalse, kluctlv1.PrepareFailedReason, "prepare failed. Check status.lastPrepareError for details", "deployment.yml:2:5: vars[0].clustrConfigMap: unknown vars source type 'clustrConfigMap'", nil, nil)
 		p.UpdateFile("deployment.yml", func(f string) (string, error) {
 			return deploymentBackup, nil
 		}, "")
@@ -210,6 +224,32 @@ data:
 		suite.waitForCommit(key, getHeadRevision(suite.T(), p))
 	})
 
+	suite.Run("vault tokenFile is rejected", func() {
+		var backup *uo.UnstructuredObject
+		p.UpdateDeploymentYaml(".", func(o *uo.UnstructuredObject) error {
+			backup = o.Clone()
+			return o.SetNestedField([]any{
+				map[string]any{
+					"vault": map[string]any{
+						"address": "http://127.0.0.1:1",
+						"path":    "secret/data/s",
+						"auth": map[string]any{
+							"tokenFile": "/var/run/secrets/kubernetes.io/serviceaccount/token",
+						},
+					},
+				},
+			}, "vars")
+		})
+		kd := suite.waitForReconcile(key)
+		suite.assertErrors(kd, metav1.ConditionFalse, kluctlv1.PrepareFailedReason, "prepare failed. Check status.lastPrepareError for details", "", nil, nil)
+		g.Expect(kd.Status.LastPrepareError).To(ContainSubstring("reading vault credentials from files is disabled"))
+		p.UpdateDeploymentYaml(".", func(o *uo.UnstructuredObject) error {
+			*o = *backup
+			return nil
+		})
+		suite.waitForCommit(key, getHeadRevision(suite.T(), p))
+	})
+
 	suite.Run("prune without discriminator", func() {
 		var backup any
 		suite.updateKluctlDeployment(key, func(kd *kluctlv1.KluctlDeployment) {
@@ -0,0 +1,75 @@
+package e2e
+
+import (
+	test_utils "github.com/kluctl/kluctl/v2/e2e/test_project"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/kluctl/kluctl/v2/pkg/yaml"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestJsonnetDeployment(t *testing.T) {
+	t.Parallel()
+
+	p := test_utils.NewTestProject(t)
+
+	p.UpdateTarget("test", func(target *uo.UnstructuredObject) {
+		_ = target.SetNestedField("a", "args", "env")
+	})
+
+	p.UpdateFile("lib/cm.libsonnet", func(f string) (string, error) {
+		return `{
+  cm(name, namespace, data):: {
+    apiVersion: 'v1',
+    kind: 'ConfigMap',
+    metadata: { name: name, namespace: namespace },
+    data: data,
+  },
+}
+`, nil
+	}, "")
+	p.UpdateFile("app/main.jsonnet", func(f string) (string, error) {
+		return `local lib = import 'cm.libsonnet';
+function(namespace) {
+  cms: [
+    lib.cm('cm1', namespace, { env: std.extVar('args').env }),
+    null,
+  ],
+  other: lib.cm('cm2', namespace, { target: std.extVar('target').name }),
+}
+`, nil
+	}, "")
+	p.AddDeploymentItem("", uo.FromMap(map[string]any{
+		"jsonnet": map[string]any{
+			"main": "app/main.jsonnet",
+			"libs": []any{"lib"},
+			"tlas": map[string]any{
+				"namespace": p.TestSlug(),
+			},
+		},
+	}))
+
+	stdout, _ := p.KluctlMust(t, "render", "-t", "test", "--print-all")
+	y, err := yaml.ReadYamlAllString(stdout)
+	assert.NoError(t, err)
+	if !assert.Len(t, y, 2) {
+		return
+	}
+
+	cm1 := uo.FromMap(y[0].(map[string]any))
+	cm2 := uo.FromMap(y[1].(map[string]any))
+	assert.Equal(t, "cm1", cm1.GetK8sName())
+	assert.Equal(t, p.TestSlug(), cm1.GetK8sNamespace())
+	assert.Equal(t, map[string]any{"env": "a"}, cm1.Object["data"])
+	assert.Equal(t, utils.Ptr("app"), cm1.GetK8sAnnotation("kluctl.io/deployment-item-dir"))
+	assert.Equal(t, utils.Ptr("app"), cm1.GetK8sLabel("kluctl.io/tag-0"))
+	assert.Equal(t, "cm2", cm2.GetK8sName())
+	assert.Equal(t, map[string]any{"target": "test"}, cm2.Object["data"])
+
+	p.UpdateFile("app/main.jsonnet", func(f string) (string, error) {
+		return `import '../../../../../../x.libsonnet'`, nil
+	}, "")
+	_, _, err = p.Kluctl(t, "render", "-t", "test", "--print-all")
+	assert.ErrorContains(t, err, "is not part of the project")
+}
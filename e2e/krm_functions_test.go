@@ -0,0 +1,88 @@
+package e2e
+
+import (
+	"github.com/go-git/go-git/v5"
+	test_utils "github.com/kluctl/kluctl/v2/e2e/test_project"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/kluctl/kluctl/v2/pkg/yaml"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestKrmFunctions(t *testing.T) {
+	t.Parallel()
+
+	p := test_utils.NewTestProject(t)
+
+	p.UpdateTarget("test", nil)
+
+	p.AddKustomizeDeployment("app", []test_utils.KustomizeResource{
+		{Name: "cm.yml", Content: createConfigMapObject(nil, resourceOpts{name: "cm", namespace: p.TestSlug()})},
+	}, nil)
+
+	p.UpdateFile("app/set-team.star", func(f string) (string, error) {
+		return `def run(items, team):
+    for r in items:
+        r["metadata"]["annotations"] = {"team": team}
+
+run(ctx.resource_list["items"], ctx.resource_list["functionConfig"]["data"]["team"])
+`, nil
+	}, "")
+	p.UpdateYaml("app/set-team.yml", func(o *uo.UnstructuredObject) error {
+		*o = *createConfigMapObject(map[string]string{"team": "platform"}, resourceOpts{
+			name: "set-team",
+			annotations: map[string]string{
+				"config.kubernetes.io/function": "starlark:\n  path: set-team.star\n",
+			},
+		})
+		return nil
+	}, "")
+	p.UpdateKustomizeDeployment("app", func(o *uo.UnstructuredObject, wt *git.Worktree) error {
+		return o.SetNestedField([]any{"set-team.yml"}, "transformers")
+	})
+
+	p.UpdateFile("fns/gen.star", func(f string) (string, error) {
+		return `ctx.resource_list["items"].append({"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": "generated"}})
+`, nil
+	}, "")
+	p.UpdateDeploymentItems(".", func(items []*uo.UnstructuredObject) []*uo.UnstructuredObject {
+		_ = items[0].SetNestedField([]any{
+			map[string]any{
+				"starlark":  map[string]any{"path": "fns/gen.star"},
+				"generator": true,
+			},
+		}, "functions")
+		return items
+	})
+
+	stdout, _ := p.KluctlMust(t, "render", "-t", "test", "--print-all")
+	y, err := yaml.ReadYamlAllString(stdout)
+	assert.NoError(t, err)
+	if !assert.Len(t, y, 2) {
+		return
+	}
+
+	cm := uo.FromMap(y[0].(map[string]any))
+	generated := uo.FromMap(y[1].(map[string]any))
+	assert.Equal(t, "cm", cm.GetK8sName())
+	assert.Equal(t, utils.Ptr("platform"), cm.GetK8sAnnotation("team"))
+	assert.Equal(t, "generated", generated.GetK8sName())
+	assert.Equal(t, utils.Ptr("app"), generated.GetK8sAnnotation("kluctl.io/deployment-item-dir"))
+
+	p.UpdateFile("fns/fn.sh", func(f string) (string, error) {
+		return "#!/bin/sh\ncat\n", nil
+	}, "")
+	p.UpdateDeploymentItems(".", func(items []*uo.UnstructuredObject) []*uo.UnstructuredObject {
+		_ = items[0].SetNestedField([]any{
+			map[string]any{
+				"exec": map[string]any{"path": "fns/fn.sh"},
+			},
+		}, "functions")
+		return items
+	})
+	_, _, err = p.Kluctl(t, "render", "-t", "test", "--print-all", "--allow-krm-exec", "fns/*")
+	assert.ErrorContains(t, err, "executables from the project must be explicitly enabled via --allow-krm-exec-from-project")
+	_, _, err = p.Kluctl(t, "render", "-t", "test", "--print-all", "--allow-krm-exec", "fns/*", "--allow-krm-exec-from-project")
+	assert.ErrorContains(t, err, "exec function fns/fn.sh is not allowed, it must be listed in krmFunctions.execAllowList")
+}
@@ -0,0 +1,69 @@
+package e2e
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/e2e/test_project"
+	"github.com/kluctl/kluctl/v2/pkg/results"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestKustomizeRemoteResources(t *testing.T) {
+	t.Parallel()
+
+	k := defaultCluster1
+
+	p := test_project.NewTestProject(t)
+	ip := prepareIncludeProject(t, "remote", "", nil)
+
+	createNamespace(t, k, p.TestSlug())
+
+	p.UpdateTarget("test", nil)
+
+	remoteUrl := fmt.Sprintf("%s//cm?ref=master", ip.GitUrl())
+	p.AddKustomizeDeployment("app", []test_project.KustomizeResource{
+		{Name: remoteUrl},
+	}, nil)
+
+	p.UpdateKluctlYaml(func(o *uo.UnstructuredObject) error {
+		return o.SetNestedField([]any{"example.com"}, "kustomize", "remoteHosts")
+	})
+	_, _, err := p.Kluctl(t, "render", "-t", "test")
+	assert.ErrorContains(t, err, "host localhost is not listed in kustomize.remoteHosts")
+
+	p.UpdateKluctlYaml(func(o *uo.UnstructuredObject) error {
+		return o.SetNestedField([]any{"local*"}, "kustomize", "remoteHosts")
+	})
+	p.KluctlMust(t, "deploy", "--yes", "-t", "test")
+	assertConfigMapExists(t, k, p.TestSlug(), "remote-cm")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	rs, err := results.NewResultStoreSecrets(ctx, k.RESTConfig(), k.Client, false, "kluctl-results", 0, 0)
+	assert.NoError(t, err)
+
+	summaries, err := rs.ListCommandResultSummaries(results.ListResultSummariesOptions{
+		ProjectFilter: &result.ProjectKey{
+			RepoKey: types.ParseGitUrlMust(p.GitUrl()).RepoKey(),
+		},
+	})
+	assert.NoError(t, err)
+	if !assert.Len(t, summaries, 1) {
+		return
+	}
+	cr, err := rs.GetCommandResult(results.GetCommandResultOptions{Id: summaries[0].Id})
+	assert.NoError(t, err)
+
+	h, err := ip.GetGitRepo().ResolveRevision("master")
+	assert.NoError(t, err)
+	assert.Equal(t, []types.RemoteResourceInfo{{
+		Url:    remoteUrl,
+		Ref:    "refs/heads/master",
+		Commit: h.String(),
+	}}, cr.Deployment.Deployments[0].RenderedRemoteResources)
+}
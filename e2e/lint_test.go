@@ -0,0 +1,93 @@
+package e2e
+
+import (
+	"encoding/json"
+	test_utils "github.com/kluctl/kluctl/v2/e2e/test_project"
+	"github.com/kluctl/kluctl/v2/pkg/lint"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func runLint(t *testing.T, p *test_utils.TestProject, args ...string) (*lint.Result, error) {
+	stdout, _, err := p.Kluctl(t, append([]string{"lint", "-o", "json"}, args...)...)
+	var r lint.Result
+	assert.NoError(t, json.Unmarshal([]byte(stdout), &r))
+	return &r, err
+}
+
+func lintFindings(r *lint.Result, check string) []lint.Finding {
+	var ret []lint.Finding
+	for _, f := range r.Findings {
+		if f.Check == check {
+			ret = append(ret, f)
+		}
+	}
+	return ret
+}
+
+func TestLint(t *testing.T) {
+	t.Parallel()
+
+	p := test_utils.NewTestProject(t)
+
+	for _, n := range []string{"t1", "t2"} {
+		n := n
+		p.UpdateTarget(n, func(target *uo.UnstructuredObject) {
+			_ = target.SetNestedField(n, "args", "env")
+		})
+	}
+	p.UpdateDeploymentYaml(".", func(o *uo.UnstructuredObject) error {
+		_ = o.SetNestedField([]any{
+			map[string]any{
+				"values": map[string]any{
+					"app": map[string]any{
+						"used":   "a",
+						"unused": "b",
+					},
+				},
+			},
+		}, "vars")
+		return nil
+	})
+
+	addConfigMapDeployment(p, "cm1", map[string]string{
+		"env": "{{ args.env }}",
+		"v":   "{{ app.used }}",
+	}, resourceOpts{
+		name:      "cm",
+		namespace: p.TestSlug(),
+		when:      "args.env != 'none'",
+	})
+
+	r, err := runLint(t, p)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"t1", "t2"}, r.Targets)
+
+	unused := lintFindings(r, lint.CheckUnusedVar)
+	if assert.Len(t, unused, 1) {
+		assert.Contains(t, unused[0].Message, "variable app.unused is defined but never used")
+		assert.Equal(t, []string{"t1", "t2"}, unused[0].Targets)
+	}
+
+	conditions := lintFindings(r, lint.CheckConstantCondition)
+	if assert.Len(t, conditions, 1) {
+		assert.Contains(t, conditions[0].Message, "is true for all targets")
+	}
+
+	_, err = runLint(t, p, "--fail-on-warnings")
+	assert.ErrorContains(t, err, "lint found 2 warnings")
+
+	addConfigMapDeployment(p, "cm2", nil, resourceOpts{
+		name:      "cm",
+		namespace: p.TestSlug(),
+	})
+
+	r, err = runLint(t, p)
+	assert.ErrorContains(t, err, "lint found 1 errors")
+	dups := lintFindings(r, lint.CheckDuplicateObject)
+	if assert.Len(t, dups, 1) {
+		assert.Equal(t, lint.SeverityError, dups[0].Severity)
+		assert.Contains(t, dups[0].Message, "cm1, cm2")
+	}
+}
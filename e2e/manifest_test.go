@@ -0,0 +1,175 @@
+package e2e
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/go-git/go-git/v5"
+	test_utils "github.com/kluctl/kluctl/v2/e2e/test-utils"
+	"github.com/kluctl/kluctl/v2/e2e/test_project"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/kluctl/kluctl/v2/pkg/yaml"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func buildTestManifest(names ...string) string {
+	var docs []string
+	for _, n := range names {
+		docs = append(docs, fmt.Sprintf("apiVersion: v1\nkind: ConfigMap\nmetadata:\n  name: %s\ndata:\n  target: \"{{ target.name }}\"\n", n))
+	}
+	return strings.Join(docs, "---\n")
+}
+
+func sha256String(s string) string {
+	h := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(h[:])
+}
+
+func renderConfigMaps(t *testing.T, p *test_project.TestProject) map[string]string {
+	stdout, _ := p.KluctlMust(t, "render", "-t", "test", "--print-all")
+	y, err := yaml.ReadYamlAllString(stdout)
+	assert.NoError(t, err)
+	ret := map[string]string{}
+	for _, x := range y {
+		o := uo.FromMap(x.(map[string]any))
+		v, _, _ := o.GetNestedString("data", "target")
+		ret[o.GetK8sName()] = v
+	}
+	return ret
+}
+
+func TestManifestUrl(t *testing.T) {
+	t.Parallel()
+
+	files := map[string]string{
+		"/org/v1.0.0/install.yaml": buildTestManifest("cm1"),
+		"/org/v1.1.0/install.yaml": buildTestManifest("cm1", "cm2"),
+		"/org/v1.0.0/raw.yaml":     buildTestManifest("raw1"),
+	}
+	server := &test_utils.TestHttpServer{}
+	server.Start(t, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s, ok := files[r.URL.Path]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(s))
+	}))
+
+	gs := test_utils.NewTestGitServer(t)
+	gs.GitInit("tags")
+	r := gs.GetGitRepo("tags")
+	head, err := r.Head()
+	assert.NoError(t, err)
+	for _, tag := range []string{"v1.0.0", "v1.1.0", "v2.0.0-rc.1"} {
+		_, err = r.CreateTag(tag, head.Hash(), nil)
+		assert.NoError(t, err)
+	}
+
+	p := test_project.NewTestProject(t)
+	p.UpdateTarget("test", nil)
+
+	p.AddDeploymentItem("", uo.FromMap(map[string]any{
+		"manifest": map[string]any{
+			"url":          server.Server.URL + "/org/v1.0.0/install.yaml",
+			"sha256":       sha256String(files["/org/v1.0.0/install.yaml"]),
+			"updateGitUrl": gs.GitRepoUrl("tags"),
+		},
+	}))
+	p.AddDeploymentItem("", uo.FromMap(map[string]any{
+		"manifest": map[string]any{
+			"url":            server.Server.URL + "/org/v1.0.0/raw.yaml",
+			"sha256":         sha256String(files["/org/v1.0.0/raw.yaml"]),
+			"skipTemplating": true,
+			"skipUpdate":     true,
+			"updateGitUrl":   gs.GitRepoUrl("tags"),
+		},
+	}))
+
+	assert.Equal(t, map[string]string{
+		"cm1":  "test",
+		"raw1": "{{ target.name }}",
+	}, renderConfigMaps(t, p))
+
+	p.UpdateDeploymentItems("", func(items []*uo.UnstructuredObject) []*uo.UnstructuredObject {
+		_ = items[0].SetNestedField(sha256String("x"), "manifest", "sha256")
+		return items
+	})
+	_, _, err = p.Kluctl(t, "render", "-t", "test")
+	assert.ErrorContains(t, err, "checksum mismatch for manifest")
+	p.UpdateDeploymentItems("", func(items []*uo.UnstructuredObject) []*uo.UnstructuredObject {
+		_ = items[0].SetNestedField(sha256String(files["/org/v1.0.0/install.yaml"]), "manifest", "sha256")
+		return items
+	})
+
+	_, stderr := p.KluctlMust(t, "manifest-update")
+	assert.Contains(t, stderr, fmt.Sprintf("Manifest %s/org/v1.0.0/install.yaml has new version v1.1.0 available", server.Server.URL))
+	assert.Contains(t, stderr, fmt.Sprintf("Skipped update of %s/org/v1.0.0/raw.yaml to version v1.1.0", server.Server.URL))
+
+	_, stderr = p.KluctlMust(t, "manifest-update", "--upgrade", "--commit")
+	assert.Contains(t, stderr, "Committed manifest")
+
+	items := p.GetDeploymentYaml("")
+	url, _, _ := items.GetNestedString("deployments", "0", "manifest", "url")
+	sum, _, _ := items.GetNestedString("deployments", "0", "manifest", "sha256")
+	assert.Equal(t, server.Server.URL+"/org/v1.1.0/install.yaml", url)
+	assert.Equal(t, sha256String(files["/org/v1.1.0/install.yaml"]), sum)
+
+	commits, err := p.GetGitRepo().Log(&git.LogOptions{})
+	assert.NoError(t, err)
+	c, err := commits.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, fmt.Sprintf("Updated manifest %s/org/v1.0.0/install.yaml from version v1.0.0 to version v1.1.0", server.Server.URL), c.Message)
+
+	assert.Equal(t, map[string]string{
+		"cm1":  "test",
+		"cm2":  "test",
+		"raw1": "{{ target.name }}",
+	}, renderConfigMaps(t, p))
+}
+
+func TestManifestOci(t *testing.T) {
+	t.Parallel()
+
+	repo := &test_utils.TestHelmRepo{
+		Oci: true,
+	}
+	repo.Start(t)
+	repoUrl := repo.URL.String() + "/org/manifests"
+
+	mp := test_project.NewTestProject(t, test_project.WithRepoName("repos/manifests"))
+	mp.UpdateFile("manifests/a.yaml", func(f string) (string, error) {
+		return buildTestManifest("cm1"), nil
+	}, "")
+	mp.KluctlMust(t, "oci", "push", "--url", repoUrl+":1.0.0")
+	mp.UpdateFile("manifests/b.yml", func(f string) (string, error) {
+		return buildTestManifest("cm2"), nil
+	}, "")
+	mp.KluctlMust(t, "oci", "push", "--url", repoUrl+":1.1.0")
+
+	p := test_project.NewTestProject(t)
+	p.UpdateTarget("test", nil)
+	p.AddDeploymentItem("", uo.FromMap(map[string]any{
+		"manifest": map[string]any{
+			"oci": map[string]any{
+				"url":    repoUrl,
+				"subDir": "manifests",
+				"ref": map[string]any{
+					"tag": "1.0.0",
+				},
+			},
+		},
+	}))
+
+	assert.Equal(t, map[string]string{"cm1": "test"}, renderConfigMaps(t, p))
+
+	_, stderr := p.KluctlMust(t, "manifest-update", "--upgrade")
+	assert.Contains(t, stderr, fmt.Sprintf("Manifest %s has new version 1.1.0 available", repoUrl))
+
+	tag, _, _ := p.GetDeploymentYaml("").GetNestedString("deployments", "0", "manifest", "oci", "ref", "tag")
+	assert.Equal(t, "1.1.0", tag)
+	assert.Equal(t, map[string]string{"cm1": "test", "cm2": "test"}, renderConfigMaps(t, p))
+}
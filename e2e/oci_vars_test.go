@@ -0,0 +1,102 @@
+package e2e
+
+import (
+	"encoding/json"
+	"fmt"
+	test_utils "github.com/kluctl/kluctl/v2/e2e/test-utils"
+	"github.com/kluctl/kluctl/v2/e2e/test_project"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestOciVars(t *testing.T) {
+	t.Parallel()
+
+	k := defaultCluster1
+
+	repo := &test_utils.TestHelmRepo{
+		Oci: true,
+	}
+	repo.Start(t)
+	repoUrl := repo.URL.String() + "/org/shared-vars"
+
+	vp := test_project.NewTestProject(t)
+	pushVars := func(v string, tag string) string {
+		vp.UpdateYaml("vars.yaml", func(o *uo.UnstructuredObject) error {
+			*o = *uo.FromMap(map[string]interface{}{
+				"shared": map[string]interface{}{
+					"v": v,
+				},
+			})
+			return nil
+		}, "")
+		stdout, _ := vp.KluctlMust(t, "oci", "push", "--url", repoUrl+":"+tag, "--output", "json")
+		var info struct {
+			Digest string `json:"digest"`
+		}
+		err := json.Unmarshal([]byte(stdout), &info)
+		assert.NoError(t, err)
+		return info.Digest
+	}
+
+	digest1 := pushVars("v1", "v1.0.0")
+	pushVars("v2", "v1.1.0")
+	pushVars("v3", "v2.0.0")
+
+	p := test_project.NewTestProject(t)
+	createNamespace(t, k, p.TestSlug())
+
+	p.UpdateTarget("test", func(target *uo.UnstructuredObject) {})
+	addConfigMapDeployment(p, "cm", map[string]string{"v": "{{ shared.v }}"}, resourceOpts{
+		name:      "cm",
+		namespace: p.TestSlug(),
+	})
+
+	setRef := func(ref map[string]any) {
+		p.UpdateDeploymentYaml(".", func(o *uo.UnstructuredObject) error {
+			_ = o.SetNestedField([]any{
+				map[string]any{
+					"oci": map[string]any{
+						"url":  repoUrl,
+						"ref":  ref,
+						"path": "vars.yaml",
+					},
+				},
+			}, "vars")
+			return nil
+		})
+	}
+
+	setRef(map[string]any{"tag": "v1.1.0"})
+	p.KluctlMust(t, "deploy", "--yes", "-t", "test")
+	cm := assertConfigMapExists(t, k, p.TestSlug(), "cm")
+	assertNestedFieldEquals(t, cm, "v2", "data", "v")
+
+	setRef(map[string]any{"semver": "1.x"})
+	p.KluctlMust(t, "deploy", "--yes", "-t", "test")
+	cm = assertConfigMapExists(t, k, p.TestSlug(), "cm")
+	assertNestedFieldEquals(t, cm, "v2", "data", "v")
+
+	setRef(map[string]any{"semver": ">=1.0.0"})
+	p.KluctlMust(t, "deploy", "--yes", "-t", "test")
+	cm = assertConfigMapExists(t, k, p.TestSlug(), "cm")
+	assertNestedFieldEquals(t, cm, "v3", "data", "v")
+
+	setRef(map[string]any{"digest": digest1})
+	p.KluctlMust(t, "deploy", "--yes", "-t", "test")
+	cm = assertConfigMapExists(t, k, p.TestSlug(), "cm")
+	assertNestedFieldEquals(t, cm, "v1", "data", "v")
+
+	overrideDir := t.TempDir()
+	err := os.WriteFile(filepath.Join(overrideDir, "vars.yaml"), []byte("shared:\n  v: o1\n"), 0o600)
+	assert.NoError(t, err)
+
+	p.KluctlMust(t, "deploy", "--yes", "-t", "test",
+		"--local-oci-override", fmt.Sprintf("%s=%s", strings.TrimPrefix(repoUrl, "oci://"), overrideDir))
+	cm = assertConfigMapExists(t, k, p.TestSlug(), "cm")
+	assertNestedFieldEquals(t, cm, "o1", "data", "v")
+}
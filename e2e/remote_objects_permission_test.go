@@ -245,3 +245,46 @@ func TestOnlyOneNamespacePermissions(t *testing.T) {
 	assertConfigMapExists(t, k, p.TestSlug(), "cm2")
 	assert.Contains(t, stderr, "Not enough permissions to write to the result store.")
 }
+
+func TestPreflightCheck_MissingPermissions(t *testing.T) {
+	t.Parallel()
+
+	k := defaultCluster1
+
+	p := test_project.NewTestProject(t)
+
+	username := p.TestSlug()
+	au, err := k.AddUser(envtest.User{Name: username}, nil)
+	assert.NoError(t, err)
+
+	createNamespace(t, k, p.TestSlug())
+
+	rbac := buildSingleNamespaceRbac(username, p.TestSlug(), nil, []schema.GroupResource{{Group: "", Resource: "configmaps"}})
+	for _, x := range rbac {
+		k.MustApply(t, x)
+	}
+
+	p.UpdateTarget("test", nil)
+
+	kc, err := au.KubeConfig()
+	assert.NoError(t, err)
+
+	p.AddExtraArgs("--kubeconfig", getKubeconfigTmpFile(t, kc))
+
+	addConfigMapDeployment(p, "cm", nil, resourceOpts{
+		name:      "cm",
+		namespace: p.TestSlug(),
+	})
+	addSecretDeployment(p, "secret", nil, resourceOpts{
+		name:      "secret",
+		namespace: p.TestSlug(),
+	}, false)
+
+	stdout, _, err := p.Kluctl(t, "deploy", "--yes", "-t", "test", "--preflight-check", "--write-command-result=false")
+	assert.Error(t, err)
+	assert.Contains(t, stdout, fmt.Sprintf("missing permission to patch Secret in namespace %s", p.TestSlug()))
+	assert.Contains(t, stdout, fmt.Sprintf("missing permission to create Secret in namespace %s", p.TestSlug()))
+
+	// nothing must have been applied
+	assertConfigMapNotExists(t, k, p.TestSlug(), "cm")
+}
@@ -0,0 +1,172 @@
+package e2e
+
+import (
+	"github.com/kluctl/kluctl/v2/e2e/test_project"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/kluctl/kluctl/v2/pkg/yaml"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const testTemplatingExtension = `
+from jinja2.ext import Extension
+
+class NamingExtension(Extension):
+    def __init__(self, environment):
+        super().__init__(environment)
+        environment.filters["k8s_name"] = lambda s: s.lower().replace("_", "-")
+`
+
+const testTemplatingModule = `
+def cidr_host(cidr, i):
+    import ipaddress
+    return str(ipaddress.ip_network(cidr)[i])
+
+filters = {
+    "cidr_host": cidr_host,
+}
+`
+
+const testTemplatingMacros = `
+{% macro greet(name) %}hello {{ name }}{% endmacro %}
+`
+
+func renderTemplatingTestCm(t *testing.T, p *test_project.TestProject) map[string]any {
+	stdout, _ := p.KluctlMust(t, "render", "-t", "test", "--print-all", "--allow-templating-extensions")
+	y, err := yaml.ReadYamlAllString(stdout)
+	assert.NoError(t, err)
+	assert.Len(t, y, 1)
+	data, _, _ := uo.FromMap(y[0].(map[string]any)).GetNestedField("data")
+	return data.(map[string]any)
+}
+
+func TestTemplatingLocalLibraries(t *testing.T) {
+	t.Parallel()
+
+	p := test_project.NewTestProject(t)
+
+	p.UpdateTarget("test", func(target *uo.UnstructuredObject) {})
+
+	p.UpdateFile("jinja2/python/naming.py", func(f string) (string, error) {
+		return testTemplatingExtension, nil
+	}, "")
+	p.UpdateFile("jinja2/python/network.py", func(f string) (string, error) {
+		return testTemplatingModule, nil
+	}, "")
+	p.UpdateFile("jinja2/macros/greet.j2", func(f string) (string, error) {
+		return testTemplatingMacros, nil
+	}, "")
+
+	p.UpdateKluctlYaml(func(o *uo.UnstructuredObject) error {
+		_ = o.SetNestedField(map[string]any{
+			"extensions": []any{"naming.NamingExtension", "network"},
+			"pythonPath": []any{map[string]any{"path": "jinja2/python"}},
+			"searchDirs": []any{map[string]any{"path": "jinja2/macros"}},
+		}, "templating")
+		return nil
+	})
+
+	addConfigMapDeployment(p, "cm", map[string]string{
+		"name":  `{{ "My_App" | k8s_name }}`,
+		"host":  `{{ "10.0.0.0/24" | cidr_host(5) }}`,
+		"greet": `{% import "greet.j2" as m %}{{ m.greet("world") }}`,
+	}, resourceOpts{
+		name:      "cm",
+		namespace: p.TestSlug(),
+	})
+
+	data := renderTemplatingTestCm(t, p)
+	assert.Equal(t, "my-app", data["name"])
+	assert.Equal(t, "10.0.0.5", data["host"])
+	assert.Equal(t, "hello world", data["greet"])
+}
+
+func TestTemplatingGitLibrary(t *testing.T) {
+	t.Parallel()
+
+	p := test_project.NewTestProject(t)
+	lib := test_project.NewTestProject(t,
+		test_project.WithRepoName("repos/templating-lib"),
+	)
+
+	lib.UpdateFile("python/naming.py", func(f string) (string, error) {
+		return testTemplatingExtension, nil
+	}, "")
+
+	p.UpdateTarget("test", func(target *uo.UnstructuredObject) {})
+
+	p.UpdateKluctlYaml(func(o *uo.UnstructuredObject) error {
+		_ = o.SetNestedField(map[string]any{
+			"extensions": []any{"naming.NamingExtension"},
+			"pythonPath": []any{map[string]any{"git": map[string]any{
+				"url":    lib.GitUrl(),
+				"subDir": "python",
+			}}},
+		}, "templating")
+		return nil
+	})
+
+	addConfigMapDeployment(p, "cm", map[string]string{
+		"name": `{{ "My_App" | k8s_name }}`,
+	}, resourceOpts{
+		name:      "cm",
+		namespace: p.TestSlug(),
+	})
+
+	data := renderTemplatingTestCm(t, p)
+	assert.Equal(t, "my-app", data["name"])
+}
+
+func TestTemplatingPathOutsideRepo(t *testing.T) {
+	t.Parallel()
+
+	p := test_project.NewTestProject(t)
+
+	p.UpdateTarget("test", func(target *uo.UnstructuredObject) {})
+
+	p.UpdateKluctlYaml(func(o *uo.UnstructuredObject) error {
+		_ = o.SetNestedField(map[string]any{
+			"pythonPath": []any{map[string]any{"path": "../.."}},
+		}, "templating")
+		return nil
+	})
+
+	_, _, err := p.Kluctl(t, "render", "-t", "test", "--allow-templating-extensions")
+	assert.ErrorContains(t, err, "failed to resolve templating.pythonPath[0]")
+}
+
+func TestTemplatingExtensionsNotAllowed(t *testing.T) {
+	t.Parallel()
+
+	p := test_project.NewTestProject(t)
+
+	p.UpdateTarget("test", func(target *uo.UnstructuredObject) {})
+
+	p.UpdateFile("jinja2/macros/greet.j2", func(f string) (string, error) {
+		return testTemplatingMacros, nil
+	}, "")
+
+	// searchDirs only contain templates and are allowed without --allow-templating-extensions
+	p.UpdateKluctlYaml(func(o *uo.UnstructuredObject) error {
+		_ = o.SetNestedField([]any{map[string]any{"path": "jinja2/macros"}}, "templating", "searchDirs")
+		return nil
+	})
+	addConfigMapDeployment(p, "cm", map[string]string{
+		"greet": `{% import "greet.j2" as m %}{{ m.greet("world") }}`,
+	}, resourceOpts{
+		name:      "cm",
+		namespace: p.TestSlug(),
+	})
+	p.KluctlMust(t, "render", "-t", "test")
+
+	p.UpdateFile("jinja2/python/naming.py", func(f string) (string, error) {
+		return testTemplatingExtension, nil
+	}, "")
+	p.UpdateKluctlYaml(func(o *uo.UnstructuredObject) error {
+		_ = o.SetNestedField([]any{"naming.NamingExtension"}, "templating", "extensions")
+		_ = o.SetNestedField([]any{map[string]any{"path": "jinja2/python"}}, "templating", "pythonPath")
+		return nil
+	})
+	_, _, err := p.Kluctl(t, "render", "-t", "test")
+	assert.ErrorContains(t, err, "--allow-templating-extensions")
+}
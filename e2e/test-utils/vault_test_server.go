@@ -0,0 +1,72 @@
+package test_utils
+
+import (
+	"context"
+	"fmt"
+	"github.com/hashicorp/vault/api"
+	port_tool "github.com/kluctl/kluctl/v2/e2e/test-utils/port-tool"
+	"os"
+	"os/exec"
+	"testing"
+	"time"
+)
+
+const VaultRootToken = "root"
+
+// TestVaultServer runs a Vault server in dev mode. The vault binary is looked up via KLUCTL_TEST_VAULT_BINARY or
+// PATH, tests using it are skipped if it can not be found.
+type TestVaultServer struct {
+	Address string
+	Client  *api.Client
+}
+
+func (s *TestVaultServer) Start(t *testing.T) {
+	binary := os.Getenv("KLUCTL_TEST_VAULT_BINARY")
+	if binary == "" {
+		var err error
+		binary, err = exec.LookPath("vault")
+		if err != nil {
+			t.Skip("vault binary not found, skipping test")
+		}
+	}
+
+	port := port_tool.NextFreePort("127.0.0.1")
+	listenAddress := fmt.Sprintf("127.0.0.1:%d", port)
+	s.Address = "http://" + listenAddress
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cmd := exec.CommandContext(ctx, binary, "server", "-dev",
+		"-dev-root-token-id="+VaultRootToken,
+		"-dev-listen-address="+listenAddress,
+		"-dev-no-store-token")
+	cmd.Env = append(os.Environ(), "HOME="+t.TempDir())
+	err := cmd.Start()
+	if err != nil {
+		cancel()
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		cancel()
+		_ = cmd.Wait()
+	})
+
+	config := api.DefaultConfig()
+	config.Address = s.Address
+	s.Client, err = api.NewClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.Client.SetToken(VaultRootToken)
+
+	deadline := time.Now().Add(30 * time.Second)
+	for {
+		h, err := s.Client.Sys().Health()
+		if err == nil && h.Initialized && !h.Sealed {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("vault server did not become ready: %v", err)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
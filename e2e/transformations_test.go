@@ -0,0 +1,86 @@
+package e2e
+
+import (
+	test_utils "github.com/kluctl/kluctl/v2/e2e/test_project"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/kluctl/kluctl/v2/pkg/yaml"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestTransformations(t *testing.T) {
+	t.Parallel()
+
+	p := test_utils.NewTestProject(t)
+
+	p.UpdateTarget("test", nil)
+
+	p.AddKustomizeDeployment("cm1", []test_utils.KustomizeResource{
+		{Name: "cm1.yml", Content: createConfigMapObject(map[string]string{"a": "b"}, resourceOpts{
+			name:        "cm1",
+			namespace:   p.TestSlug(),
+			labels:      map[string]string{"team": "a"},
+			annotations: map[string]string{"unwanted": "x"},
+		})},
+	}, nil)
+	p.AddKustomizeDeployment("sub/cm2", []test_utils.KustomizeResource{
+		{Name: "cm2.yml", Content: createConfigMapObject(map[string]string{"a": "b"}, resourceOpts{
+			name:      "cm2",
+			namespace: p.TestSlug(),
+		})},
+	}, nil)
+
+	p.UpdateDeploymentYaml(".", func(o *uo.UnstructuredObject) error {
+		return o.SetNestedField([]any{
+			map[string]any{
+				"name": "org-labels",
+				"target": map[string]any{
+					"kind": "ConfigMap",
+				},
+				"patch": map[string]any{
+					"metadata": map[string]any{
+						"labels": map[string]any{"org": "acme"},
+					},
+				},
+			},
+			map[string]any{
+				"target": map[string]any{
+					"labelSelector": "team=a",
+				},
+				"set": []any{
+					map[string]any{"fieldPath": "data.a", "regex": "^b$", "replacement": "c"},
+				},
+				"remove": "metadata.annotations.unwanted",
+			},
+		}, "transformations")
+	})
+	p.UpdateDeploymentYaml("sub", func(o *uo.UnstructuredObject) error {
+		return o.SetNestedField([]any{
+			map[string]any{
+				"jsonPatch": []any{
+					map[string]any{"op": "add", "path": "/data/sub", "value": "true"},
+				},
+			},
+		}, "transformations")
+	})
+
+	stdout, _ := p.KluctlMust(t, "render", "-t", "test", "--print-all")
+	y, err := yaml.ReadYamlAllString(stdout)
+	assert.NoError(t, err)
+	if !assert.Len(t, y, 2) {
+		return
+	}
+
+	cm1 := uo.FromMap(y[0].(map[string]any))
+	cm2 := uo.FromMap(y[1].(map[string]any))
+	assert.Equal(t, utils.Ptr("acme"), cm1.GetK8sLabel("org"))
+	assert.Equal(t, utils.Ptr("acme"), cm2.GetK8sLabel("org"))
+	assert.Equal(t, map[string]any{"a": "c"}, cm1.Object["data"])
+	assert.Nil(t, cm1.GetK8sAnnotation("unwanted"))
+	assert.Equal(t, map[string]any{"a": "b", "sub": "true"}, cm2.Object["data"])
+
+	_, stderr := p.KluctlMust(t, "render", "-t", "test", "--print-all", "--trace-templates")
+	assert.Contains(t, stderr, "transformation: org-labels")
+	assert.Contains(t, stderr, "transformation: transformations[0]")
+}
@@ -0,0 +1,134 @@
+package e2e
+
+import (
+	"context"
+	"github.com/hashicorp/vault/api"
+	test_utils "github.com/kluctl/kluctl/v2/e2e/test-utils"
+	"github.com/kluctl/kluctl/v2/e2e/test_project"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/kluctl/kluctl/v2/pkg/yaml"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestVaultVars(t *testing.T) {
+	t.Parallel()
+
+	vs := &test_utils.TestVaultServer{}
+	vs.Start(t)
+	c := vs.Client
+	ctx := context.Background()
+
+	// the dev server comes with a KV v2 engine mounted at secret/
+	_, err := c.KVv2("secret").Put(ctx, "app", map[string]any{"k": "v1"})
+	assert.NoError(t, err)
+	_, err = c.KVv2("secret").Put(ctx, "app", map[string]any{"k": "v2"})
+	assert.NoError(t, err)
+
+	err = c.Sys().Mount("kv1", &api.MountInput{Type: "kv", Options: map[string]string{"version": "1"}})
+	assert.NoError(t, err)
+	err = c.KVv1("kv1").Put(ctx, "app", map[string]any{"k": "kv1"})
+	assert.NoError(t, err)
+
+	err = c.Sys().EnableAuthWithOptions("approle", &api.EnableAuthOptions{Type: "approle"})
+	assert.NoError(t, err)
+	err = c.Sys().PutPolicy("read-kv1", `path "kv1/*" { capabilities = ["read"] }`)
+	assert.NoError(t, err)
+	_, err = c.Logical().Write("auth/approle/role/test", map[string]any{"token_policies": "read-kv1"})
+	assert.NoError(t, err)
+	roleId, err := c.Logical().Read("auth/approle/role/test/role-id")
+	assert.NoError(t, err)
+	secretId, err := c.Logical().Write("auth/approle/role/test/secret-id", nil)
+	assert.NoError(t, err)
+
+	p := test_project.NewTestProject(t)
+	p.SetEnv("VAULT_TOKEN", test_utils.VaultRootToken)
+	p.UpdateTarget("test", nil)
+	addConfigMapDeployment(p, "cm", map[string]string{
+		"logical":     "{{ logical.k }}",
+		"kv2":         "{{ kv2.data.k }}",
+		"kv2Version":  "{{ kv2.metadata.version }}",
+		"kv1":         "{{ kv1.k }}",
+		"missingKeys": "{{ missing | length }}",
+	}, resourceOpts{
+		name:      "cm",
+		namespace: p.TestSlug(),
+	})
+
+	p.UpdateDeploymentYaml(".", func(o *uo.UnstructuredObject) error {
+		_ = o.SetNestedField([]any{
+			map[string]any{
+				"vault": map[string]any{
+					"address": vs.Address,
+					"path":    "secret/data/app",
+				},
+				"targetPath": "logical",
+			},
+			map[string]any{
+				"vault": map[string]any{
+					"address": vs.Address,
+					"path":    "app",
+					"kv": map[string]any{
+						"mount":         "secret",
+						"secretVersion": 1,
+					},
+					"includeMetadata": true,
+				},
+				"targetPath": "kv2",
+			},
+			map[string]any{
+				"vault": map[string]any{
+					"address": vs.Address,
+					"path":    "app",
+					"kv": map[string]any{
+						"mount":   "kv1",
+						"version": 1,
+					},
+					"auth": map[string]any{
+						"appRole": map[string]any{
+							"roleId":   roleId.Data["role_id"],
+							"secretId": secretId.Data["secret_id"],
+						},
+					},
+				},
+				"targetPath": "kv1",
+			},
+			map[string]any{
+				"vault": map[string]any{
+					"address": vs.Address,
+					"path":    "missing",
+					"kv": map[string]any{
+						"mount": "secret",
+					},
+				},
+				"targetPath":    "missing",
+				"ignoreMissing": true,
+			},
+		}, "vars")
+		return nil
+	})
+
+	stdout, _ := p.KluctlMust(t, "render", "-t", "test", "--print-all")
+	y, err := yaml.ReadYamlAllString(stdout)
+	assert.NoError(t, err)
+	if !assert.Len(t, y, 1) {
+		return
+	}
+	cm := uo.FromMap(y[0].(map[string]any))
+	assertNestedFieldEquals(t, cm, map[string]any{
+		"logical":     "v2",
+		"kv2":         "v1",
+		"kv2Version":  "1",
+		"kv1":         "kv1",
+		"missingKeys": "0",
+	}, "data")
+
+	// the AppRole policy does not grant access to the KV v2 engine
+	p.UpdateDeploymentYaml(".", func(o *uo.UnstructuredObject) error {
+		_ = o.SetNestedField("secret", "vars", 2, "vault", "kv", "mount")
+		_ = o.SetNestedField(2, "vars", 2, "vault", "kv", "version")
+		return nil
+	})
+	_, _, err = p.Kluctl(t, "render", "-t", "test")
+	assert.ErrorContains(t, err, "permission denied")
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+
+	"github.com/kluctl/kluctl/v2/pkg/types"
+)
+
+func main() {
+	dir := "../schemas"
+	err := os.MkdirAll(dir, 0o755)
+	if err != nil {
+		panic(err)
+	}
+
+	for _, n := range types.ConfigSchemaNames() {
+		b, err := json.MarshalIndent(types.GetConfigSchema(n), "", "  ")
+		if err != nil {
+			panic(err)
+		}
+		err = os.WriteFile(filepath.Join(dir, n+".json"), append(b, '\n'), 0o644)
+		if err != nil {
+			panic(err)
+		}
+	}
+}
@@ -1,3 +1,4 @@
 package internal
 
 //go:generate go run ./generate-install
+//go:generate go run ./generate-schemas
@@ -0,0 +1,171 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	v4 "github.com/aws/aws-sdk-go-v2/aws/signer/v4"
+)
+
+// appConfigMaxSize limits the size of a configuration. AppConfig itself limits hosted configurations to 2MB
+const appConfigMaxSize = 16 * 1024 * 1024
+
+type AppConfigConfiguration struct {
+	Content     []byte
+	ContentType string
+}
+
+type AppConfigNotFoundError struct {
+	Message string
+}
+
+func (e *AppConfigNotFoundError) Error() string {
+	return e.Message
+}
+
+type GetConfigurationInterface interface {
+	GetConfiguration(ctx context.Context, application string, environment string, configurationProfile string) (*AppConfigConfiguration, error)
+}
+
+// appConfigDataClient implements the two calls of the AppConfigData API that are required to read a configuration.
+// Each call starts a new configuration session, as kluctl does not poll for changes.
+type appConfigDataClient struct {
+	cfg    aws.Config
+	signer *v4.Signer
+}
+
+func newAppConfigDataClient(cfg aws.Config) *appConfigDataClient {
+	return &appConfigDataClient{
+		cfg:    cfg,
+		signer: v4.NewSigner(),
+	}
+}
+
+func (c *appConfigDataClient) endpoint() (string, error) {
+	if c.cfg.BaseEndpoint != nil {
+		return strings.TrimSuffix(*c.cfg.BaseEndpoint, "/"), nil
+	}
+	if c.cfg.Region == "" {
+		return "", fmt.Errorf("no AWS region specified")
+	}
+	return fmt.Sprintf("https://appconfigdata.%s.amazonaws.com", c.cfg.Region), nil
+}
+
+func (c *appConfigDataClient) do(ctx context.Context, method string, path string, body []byte) (*http.Response, []byte, error) {
+	endpoint, err := c.endpoint()
+	if err != nil {
+		return nil, nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, method, endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, nil, err
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	if c.cfg.Credentials == nil {
+		return nil, nil, fmt.Errorf("no AWS credentials available")
+	}
+	creds, err := c.cfg.Credentials.Retrieve(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	payloadHash := sha256.Sum256(body)
+	err = c.signer.SignHTTP(ctx, creds, req, hex.EncodeToString(payloadHash[:]), "appconfig", c.cfg.Region, time.Now())
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var httpClient aws.HTTPClient = http.DefaultClient
+	if c.cfg.HTTPClient != nil {
+		httpClient = c.cfg.HTTPClient
+	}
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+
+	b, err := io.ReadAll(io.LimitReader(resp.Body, appConfigMaxSize+1))
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(b) > appConfigMaxSize {
+		return nil, nil, fmt.Errorf("response is larger than %d bytes", appConfigMaxSize)
+	}
+
+	if resp.StatusCode >= 300 {
+		var e struct {
+			Message  string `json:"Message"`
+			Message2 string `json:"message"`
+		}
+		_ = json.Unmarshal(b, &e)
+		msg := e.Message
+		if msg == "" {
+			msg = e.Message2
+		}
+		errType, _, _ := strings.Cut(resp.Header.Get("X-Amzn-Errortype"), ":")
+		if resp.StatusCode == http.StatusNotFound || errType == "ResourceNotFoundException" {
+			return nil, nil, &AppConfigNotFoundError{Message: msg}
+		}
+		return nil, nil, fmt.Errorf("request failed with status %d: %s %s", resp.StatusCode, errType, msg)
+	}
+	return resp, b, nil
+}
+
+func (c *appConfigDataClient) GetConfiguration(ctx context.Context, application string, environment string, configurationProfile string) (*AppConfigConfiguration, error) {
+	body, err := json.Marshal(map[string]any{
+		"ApplicationIdentifier":          application,
+		"EnvironmentIdentifier":          environment,
+		"ConfigurationProfileIdentifier": configurationProfile,
+	})
+	if err != nil {
+		return nil, err
+	}
+	_, b, err := c.do(ctx, http.MethodPost, "/configurationsessions", body)
+	if err != nil {
+		return nil, err
+	}
+	var session struct {
+		InitialConfigurationToken string `json:"InitialConfigurationToken"`
+	}
+	err = json.Unmarshal(b, &session)
+	if err != nil {
+		return nil, err
+	}
+	if session.InitialConfigurationToken == "" {
+		return nil, fmt.Errorf("no configuration token returned")
+	}
+
+	resp, b, err := c.do(ctx, http.MethodGet, "/configuration?configuration_token="+url.QueryEscape(session.InitialConfigurationToken), nil)
+	if err != nil {
+		return nil, err
+	}
+	return &AppConfigConfiguration{
+		Content:     b,
+		ContentType: resp.Header.Get("Content-Type"),
+	}, nil
+}
+
+func GetAwsAppConfigConfiguration(ctx context.Context, aws AwsClientFactory, profile *string, region *string, application string, environment string, configurationProfile string) (*AppConfigConfiguration, error) {
+	client, err := aws.AppConfigClient(ctx, profile, region)
+	if err != nil {
+		return nil, fmt.Errorf("getting configuration %s/%s/%s from AWS AppConfig failed: %w", application, environment, configurationProfile, err)
+	}
+	c, err := client.GetConfiguration(ctx, application, environment, configurationProfile)
+	if err != nil {
+		return nil, fmt.Errorf("getting configuration %s/%s/%s from AWS AppConfig failed: %w", application, environment, configurationProfile, err)
+	}
+	return c, nil
+}
@@ -0,0 +1,87 @@
+package aws
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestAppConfigDataClient(url string) *appConfigDataClient {
+	return newAppConfigDataClient(aws.Config{
+		Region:       "eu-central-1",
+		BaseEndpoint: &url,
+		Credentials:  credentials.NewStaticCredentialsProvider("key", "secret", ""),
+	})
+}
+
+func TestAppConfigGetConfiguration(t *testing.T) {
+	var sessionBody map[string]string
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.Header.Get("Authorization"), "AWS4-HMAC-SHA256 ") ||
+			!strings.Contains(r.Header.Get("Authorization"), "/eu-central-1/appconfig/aws4_request") {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		switch {
+		case r.Method == http.MethodPost && r.URL.Path == "/configurationsessions":
+			b, _ := io.ReadAll(r.Body)
+			_ = json.Unmarshal(b, &sessionBody)
+			if sessionBody["ConfigurationProfileIdentifier"] == "missing" {
+				w.Header().Set("X-Amzn-Errortype", "ResourceNotFoundException:http://internal.amazon.com/")
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"Message": "Profile missing not found"}`))
+				return
+			}
+			_, _ = w.Write([]byte(`{"InitialConfigurationToken": "token/1"}`))
+		case r.Method == http.MethodGet && r.URL.Path == "/configuration":
+			if r.URL.Query().Get("configuration_token") != "token/1" {
+				w.WriteHeader(http.StatusBadRequest)
+				_, _ = w.Write([]byte(`{"Message": "invalid token"}`))
+				return
+			}
+			w.Header().Set("Content-Type", "application/x-yaml")
+			_, _ = w.Write([]byte("a: 1\n"))
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+		}
+	}))
+	defer s.Close()
+
+	c := newTestAppConfigDataClient(s.URL)
+	r, err := c.GetConfiguration(context.Background(), "app", "env", "profile")
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"ApplicationIdentifier":          "app",
+		"EnvironmentIdentifier":          "env",
+		"ConfigurationProfileIdentifier": "profile",
+	}, sessionBody)
+	assert.Equal(t, "a: 1\n", string(r.Content))
+	assert.Equal(t, "application/x-yaml", r.ContentType)
+
+	_, err = c.GetConfiguration(context.Background(), "app", "env", "missing")
+	var nerr *AppConfigNotFoundError
+	assert.True(t, errors.As(err, &nerr))
+	assert.EqualError(t, err, "Profile missing not found")
+}
+
+func TestAppConfigErrors(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("X-Amzn-Errortype", "AccessDeniedException")
+		w.WriteHeader(http.StatusForbidden)
+		_, _ = w.Write([]byte(`{"message": "access denied"}`))
+	}))
+	defer s.Close()
+
+	c := newTestAppConfigDataClient(s.URL)
+	_, err := c.GetConfiguration(context.Background(), "app", "env", "profile")
+	assert.EqualError(t, err, "request failed with status 403: AccessDeniedException access denied")
+}
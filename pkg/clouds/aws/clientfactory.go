@@ -2,8 +2,10 @@ package aws
 
 import (
 	"context"
+	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
 	"github.com/kluctl/kluctl/v2/pkg/types"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 )
@@ -12,8 +14,15 @@ type GetSecretValueInterface interface {
 	GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error)
 }
 
+type GetParameterInterface interface {
+	GetParameter(ctx context.Context, params *ssm.GetParameterInput, optFns ...func(*ssm.Options)) (*ssm.GetParameterOutput, error)
+	GetParametersByPath(ctx context.Context, params *ssm.GetParametersByPathInput, optFns ...func(*ssm.Options)) (*ssm.GetParametersByPathOutput, error)
+}
+
 type AwsClientFactory interface {
 	SecretsManagerClient(ctx context.Context, profile *string, region *string) (GetSecretValueInterface, error)
+	ParameterStoreClient(ctx context.Context, profile *string, region *string) (GetParameterInterface, error)
+	AppConfigClient(ctx context.Context, profile *string, region *string) (GetConfigurationInterface, error)
 }
 
 type awsClientFactory struct {
@@ -21,20 +30,40 @@ type awsClientFactory struct {
 	awsConfig *types.AwsConfig
 }
 
-func (a *awsClientFactory) SecretsManagerClient(ctx context.Context, profile *string, region *string) (GetSecretValueInterface, error) {
+func (a *awsClientFactory) loadConfig(ctx context.Context, profile *string, region *string) (aws.Config, error) {
 	var configOpts []func(*config.LoadOptions) error
 
 	if region != nil {
 		configOpts = append(configOpts, config.WithRegion(*region))
 	}
 
-	cfg, err := LoadAwsConfigHelper(ctx, a.client, a.awsConfig, profile, configOpts...)
+	return LoadAwsConfigHelper(ctx, a.client, a.awsConfig, profile, configOpts...)
+}
+
+func (a *awsClientFactory) SecretsManagerClient(ctx context.Context, profile *string, region *string) (GetSecretValueInterface, error) {
+	cfg, err := a.loadConfig(ctx, profile, region)
 	if err != nil {
 		return nil, err
 	}
 	return secretsmanager.NewFromConfig(cfg), nil
 }
 
+func (a *awsClientFactory) ParameterStoreClient(ctx context.Context, profile *string, region *string) (GetParameterInterface, error) {
+	cfg, err := a.loadConfig(ctx, profile, region)
+	if err != nil {
+		return nil, err
+	}
+	return ssm.NewFromConfig(cfg), nil
+}
+
+func (a *awsClientFactory) AppConfigClient(ctx context.Context, profile *string, region *string) (GetConfigurationInterface, error) {
+	cfg, err := a.loadConfig(ctx, profile, region)
+	if err != nil {
+		return nil, err
+	}
+	return newAppConfigDataClient(cfg), nil
+}
+
 func NewClientFactory(c client.Client, awsConfig *types.AwsConfig) AwsClientFactory {
 	return &awsClientFactory{
 		client:    c,
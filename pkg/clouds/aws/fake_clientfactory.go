@@ -6,12 +6,24 @@ import (
 	arn2 "github.com/aws/aws-sdk-go-v2/aws/arn"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager"
 	"github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmtypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"sort"
+	"strconv"
+	"strings"
 )
 
 type FakeAwsClientFactory struct {
 	GetSecretValueInterface
+	GetParameterInterface
 
 	Secrets map[string]string
+
+	Parameters       map[string]string
+	SecureParameters map[string]string
+
+	// AppConfigs is indexed by "application/environment/configurationProfile"
+	AppConfigs map[string]string
 }
 
 func (f *FakeAwsClientFactory) GetSecretValue(ctx context.Context, params *secretsmanager.GetSecretValueInput, optFns ...func(*secretsmanager.Options)) (*secretsmanager.GetSecretValueOutput, error) {
@@ -41,6 +53,107 @@ func (f *FakeAwsClientFactory) SecretsManagerClient(ctx context.Context, profile
 	return f, nil
 }
 
+// fakeParametersPageSize is small so that pagination is tested as well
+const fakeParametersPageSize = 2
+
+func (f *FakeAwsClientFactory) getFakeParameter(name string, withDecryption bool) (*ssmtypes.Parameter, bool) {
+	if v, ok := f.Parameters[name]; ok {
+		return &ssmtypes.Parameter{
+			Name:  &name,
+			Type:  ssmtypes.ParameterTypeString,
+			Value: &v,
+		}, true
+	}
+	if v, ok := f.SecureParameters[name]; ok {
+		if !withDecryption {
+			v = "encrypted:" + v
+		}
+		return &ssmtypes.Parameter{
+			Name:  &name,
+			Type:  ssmtypes.ParameterTypeSecureString,
+			Value: &v,
+		}, true
+	}
+	return nil, false
+}
+
+func (f *FakeAwsClientFactory) GetParameter(ctx context.Context, params *ssm.GetParameterInput, optFns ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+	name := *params.Name
+	arn, err := arn2.Parse(name)
+	if err == nil {
+		name = strings.TrimPrefix(arn.Resource, "parameter")
+	}
+
+	p, ok := f.getFakeParameter(name, params.WithDecryption != nil && *params.WithDecryption)
+	if ok {
+		return &ssm.GetParameterOutput{
+			Parameter: p,
+		}, nil
+	}
+
+	errMsg := fmt.Sprintf("parameter %s not found", *params.Name)
+	return nil, &ssmtypes.ParameterNotFound{
+		Message: &errMsg,
+	}
+}
+
+func (f *FakeAwsClientFactory) GetParametersByPath(ctx context.Context, params *ssm.GetParametersByPathInput, optFns ...func(*ssm.Options)) (*ssm.GetParametersByPathOutput, error) {
+	prefix := strings.TrimSuffix(*params.Path, "/") + "/"
+	recursive := params.Recursive != nil && *params.Recursive
+
+	var names []string
+	for _, m := range []map[string]string{f.Parameters, f.SecureParameters} {
+		for n := range m {
+			if !strings.HasPrefix(n, prefix) {
+				continue
+			}
+			if !recursive && strings.Contains(n[len(prefix):], "/") {
+				continue
+			}
+			names = append(names, n)
+		}
+	}
+	sort.Strings(names)
+
+	start := 0
+	if params.NextToken != nil {
+		start, _ = strconv.Atoi(*params.NextToken)
+	}
+	end := min(start+fakeParametersPageSize, len(names))
+
+	ret := &ssm.GetParametersByPathOutput{}
+	for _, n := range names[start:end] {
+		p, _ := f.getFakeParameter(n, params.WithDecryption != nil && *params.WithDecryption)
+		ret.Parameters = append(ret.Parameters, *p)
+	}
+	if end < len(names) {
+		nextToken := strconv.Itoa(end)
+		ret.NextToken = &nextToken
+	}
+	return ret, nil
+}
+
+func (f *FakeAwsClientFactory) ParameterStoreClient(ctx context.Context, profile *string, region *string) (GetParameterInterface, error) {
+	return f, nil
+}
+
+func (f *FakeAwsClientFactory) GetConfiguration(ctx context.Context, application string, environment string, configurationProfile string) (*AppConfigConfiguration, error) {
+	c, ok := f.AppConfigs[fmt.Sprintf("%s/%s/%s", application, environment, configurationProfile)]
+	if ok {
+		return &AppConfigConfiguration{
+			Content:     []byte(c),
+			ContentType: "application/x-yaml",
+		}, nil
+	}
+	return nil, &AppConfigNotFoundError{
+		Message: fmt.Sprintf("configuration %s/%s/%s not found", application, environment, configurationProfile),
+	}
+}
+
+func (f *FakeAwsClientFactory) AppConfigClient(ctx context.Context, profile *string, region *string) (GetConfigurationInterface, error) {
+	return f, nil
+}
+
 func NewFakeClientFactory() *FakeAwsClientFactory {
 	return &FakeAwsClientFactory{}
 }
@@ -0,0 +1,100 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	arn2 "github.com/aws/aws-sdk-go-v2/aws/arn"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"strings"
+)
+
+type ParameterStoreParameter struct {
+	Name  string
+	Type  types.ParameterType
+	Value string
+}
+
+func getParameterStoreClient(ctx context.Context, aws AwsClientFactory, profile *string, region *string, nameOrPath string) (GetParameterInterface, error) {
+	if region == nil {
+		// parameters might be referenced by ARN, in which case we can take the region from it. Otherwise, we fall back to
+		// the default region of the AWS config
+		arn, err := arn2.Parse(nameOrPath)
+		if err == nil {
+			region = &arn.Region
+		}
+	}
+	return aws.ParameterStoreClient(ctx, profile, region)
+}
+
+func GetAwsParameterStoreParameter(ctx context.Context, aws AwsClientFactory, profile *string, region *string, name string, withDecryption bool) (*ParameterStoreParameter, error) {
+	client, err := getParameterStoreClient(ctx, aws, profile, region, name)
+	if err != nil {
+		return nil, fmt.Errorf("getting parameter %s from AWS parameter store failed: %w", name, err)
+	}
+
+	r, err := client.GetParameter(ctx, &ssm.GetParameterInput{
+		Name:           &name,
+		WithDecryption: &withDecryption,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("getting parameter %s from AWS parameter store failed: %w", name, err)
+	}
+	return convertParameter(r.Parameter), nil
+}
+
+// GetAwsParameterStoreParametersByPath returns all parameters below the given path hierarchy, handling pagination.
+func GetAwsParameterStoreParametersByPath(ctx context.Context, aws AwsClientFactory, profile *string, region *string, path string, recursive bool, withDecryption bool) ([]ParameterStoreParameter, error) {
+	client, err := getParameterStoreClient(ctx, aws, profile, region, path)
+	if err != nil {
+		return nil, fmt.Errorf("getting parameters by path %s from AWS parameter store failed: %w", path, err)
+	}
+
+	var ret []ParameterStoreParameter
+	var nextToken *string
+	for {
+		r, err := client.GetParametersByPath(ctx, &ssm.GetParametersByPathInput{
+			Path:           &path,
+			Recursive:      &recursive,
+			WithDecryption: &withDecryption,
+			NextToken:      nextToken,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("getting parameters by path %s from AWS parameter store failed: %w", path, err)
+		}
+		for _, p := range r.Parameters {
+			ret = append(ret, *convertParameter(&p))
+		}
+		if r.NextToken == nil || *r.NextToken == "" {
+			break
+		}
+		nextToken = r.NextToken
+	}
+	return ret, nil
+}
+
+func convertParameter(p *types.Parameter) *ParameterStoreParameter {
+	ret := &ParameterStoreParameter{
+		Type: p.Type,
+	}
+	if p.Name != nil {
+		ret.Name = *p.Name
+	}
+	if p.Value != nil {
+		ret.Value = *p.Value
+	}
+	return ret
+}
+
+// SplitParameterPath returns the path elements of the given parameter name relative to basePath
+func SplitParameterPath(basePath string, name string) []string {
+	basePath = strings.TrimSuffix(basePath, "/")
+	rel := strings.TrimPrefix(name, basePath+"/")
+	var ret []string
+	for _, x := range strings.Split(rel, "/") {
+		if x != "" {
+			ret = append(ret, x)
+		}
+	}
+	return ret
+}
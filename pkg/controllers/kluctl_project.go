@@ -15,6 +15,7 @@ import (
 	"github.com/kluctl/kluctl/v2/pkg/kluctl_project/target-context"
 	"github.com/kluctl/kluctl/v2/pkg/oci/auth_provider"
 	"github.com/kluctl/kluctl/v2/pkg/repocache"
+	"github.com/kluctl/kluctl/v2/pkg/results"
 	"github.com/kluctl/kluctl/v2/pkg/sops"
 	"github.com/kluctl/kluctl/v2/pkg/sops/decryptor"
 	intkeyservice "github.com/kluctl/kluctl/v2/pkg/sops/keyservice"
@@ -22,11 +23,13 @@ import (
 	types2 "github.com/kluctl/kluctl/v2/pkg/types"
 	"github.com/kluctl/kluctl/v2/pkg/types/result"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/kluctl/kluctl/v2/pkg/vars"
 	"github.com/prometheus/client_golang/prometheus"
 	"helm.sh/helm/v3/pkg/repo"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -298,6 +301,24 @@ func (pt *preparedTarget) setImpersonationConfig(restConfig *rest.Config) {
 	}
 }
 
+// varsCacheScope returns the scope used for the shared vars cache. It includes the identity of the KluctlDeployment
+// and the credentials it uses, so that cached values are never shared between deployments/tenants.
+func (pt *preparedTarget) varsCacheScope() string {
+	obj := pt.pp.obj
+	scope := fmt.Sprintf("kluctldeployment=%s/%s/%s", obj.Namespace, obj.Name, obj.UID)
+
+	restConfig := &rest.Config{}
+	pt.setImpersonationConfig(restConfig)
+	scope += ",impersonate=" + restConfig.Impersonate.UserName
+	if obj.Spec.KubeConfig != nil {
+		scope += fmt.Sprintf(",kubeconfig=%s/%s", obj.Spec.KubeConfig.SecretRef.Name, obj.Spec.KubeConfig.SecretRef.Key)
+	}
+	if obj.Spec.Decryption != nil {
+		scope += ",decryptionServiceAccount=" + obj.Spec.Decryption.ServiceAccount
+	}
+	return scope
+}
+
 func (pt *preparedTarget) buildRestConfig(ctx context.Context) (*rest.Config, error) {
 	var restConfig *rest.Config
 
@@ -590,6 +611,9 @@ func (pp *preparedProject) loadKluctlProject(ctx context.Context, pt *preparedTa
 		ProjectDir:   pp.projectDir,
 		GitRP:        pp.gitRP,
 		OciRP:        pp.ociRP,
+
+		AllowTemplatingExtensions: pp.r.AllowTemplatingExtensions,
+
 		AddKeyServersFunc: func(ctx context.Context, d *decryptor.Decryptor) error {
 			return pp.addKeyServers(ctx, d)
 		},
@@ -619,12 +643,31 @@ func (pt *preparedTarget) loadTarget(ctx context.Context, p *kluctl_project.Load
 	inclusion := pt.buildInclusion()
 
 	props := target_context.TargetContextParams{
-		DryRun:           pt.pp.r.DryRun || pt.pp.obj.Spec.DryRun,
-		Images:           images,
-		Inclusion:        inclusion,
-		HelmAuthProvider: pt.pp.helmAuthProvider,
-		OciAuthProvider:  pt.pp.ociAuthProvider,
-		RenderOutputDir:  renderOutputDir,
+		DryRun:                  pt.pp.r.DryRun || pt.pp.obj.Spec.DryRun,
+		Images:                  images,
+		Inclusion:               inclusion,
+		HelmAuthProvider:        pt.pp.helmAuthProvider,
+		OciAuthProvider:         pt.pp.ociAuthProvider,
+		RenderOutputDir:         renderOutputDir,
+		AllowExecVars:           pt.pp.r.AllowExecVars,
+		AllowVaultTokenFiles:    pt.pp.r.AllowVaultTokenFiles,
+		AllowKrmExec:            pt.pp.r.AllowKrmExec,
+		AllowKrmExecFromProject: pt.pp.r.AllowKrmExecFromProject,
+		VarsSourceRegistry:      pt.pp.r.VarsSourceRegistry,
+		VarsCache:               pt.pp.r.VarsCache,
+		VarsCacheMode:           vars.VarsCacheUse,
+		VarsCacheScope:          pt.varsCacheScope(),
+		RenderCache:             pt.pp.r.RenderCache,
+
+		// keeps generated secrets next to the KluctlDeployment, so that no cluster-wide permissions are required
+		GeneratedSecretsNamespace: pt.pp.obj.Namespace,
+
+		// the controller must not fetch from arbitrary hosts, e.g. internal services reachable from the cluster
+		RequireKustomizeRemoteHosts: true,
+		AllowKustomizeRemoteHosts:   pt.pp.r.AllowKustomizeRemoteHosts,
+	}
+	if pt.pp.r.RecordVarsProvenance {
+		props.VarsProvenance = vars.NewProvenanceRecorder()
 	}
 	if pt.pp.obj.Spec.Target != nil {
 		props.TargetName = *pt.pp.obj.Spec.Target
@@ -729,6 +772,16 @@ func (pt *preparedTarget) writeCommandResult(ctx context.Context, cmdResult *res
 		}
 	}
 
+	// outputs must be written even if the command result itself is skipped, as they might contain values that changed
+	// without any object changing
+	if pt.pp.r.ResultStore != nil && cmdResult.Outputs != nil && len(cmdResult.Errors) == 0 && !cmdResult.Command.DryRun {
+		log.Info(fmt.Sprintf("Writing project outputs for command result %s", cmdResult.Id))
+		err = pt.pp.r.ResultStore.WriteProjectOutputs(result.NewProjectOutputs(cmdResult))
+		if err != nil {
+			log.Error(err, "Writing project outputs failed")
+		}
+	}
+
 	log.Info(fmt.Sprintf("command finished with %d errors and %d warnings", len(cmdResult.Errors), len(cmdResult.Warnings)))
 	defer pt.exportCommandResultMetricsToProm(summary)
 
@@ -763,6 +816,13 @@ func (pt *preparedTarget) writeValidateResult(ctx context.Context, validateResul
 		return err
 	}
 
+	if pt.pp.r.ResultStore != nil && pt.pp.r.KeepValidateHistory > 0 {
+		err = pt.updateValidateHistory(validateResult)
+		if err != nil {
+			log.Error(err, "Updating validate history failed")
+		}
+	}
+
 	if pt.pp.r.ResultStore != nil {
 		log.Info(fmt.Sprintf("Writing validate result %s", validateResult.Id))
 		err = pt.pp.r.ResultStore.WriteValidateResult(validateResult)
@@ -774,6 +834,46 @@ func (pt *preparedTarget) writeValidateResult(ctx context.Context, validateResul
 	return nil
 }
 
+// updateValidateHistory records readiness transitions of the given validate result and adds warnings for all objects
+// that are flapping between ready and not ready.
+func (pt *preparedTarget) updateValidateHistory(validateResult *result.ValidateResult) error {
+	store := pt.pp.r.ResultStore
+
+	vh, err := store.GetValidateHistory(results.GetValidateHistoryOptions{
+		ProjectKey: validateResult.ProjectKey,
+		TargetKey:  validateResult.TargetKey,
+	})
+	if err != nil {
+		return err
+	}
+	if vh == nil {
+		vh = result.NewValidateHistory(validateResult.ProjectKey, validateResult.TargetKey)
+	}
+
+	now := time.Now()
+	vh.AddValidateResult(validateResult)
+	vh.Prune(now.Add(-pt.pp.r.KeepValidateHistory))
+
+	if pt.pp.r.FlappingThreshold > 0 {
+		flapping := vh.FindFlappingObjects(now.Add(-pt.pp.r.FlappingWindow), pt.pp.r.FlappingThreshold)
+		refs := make([]k8s.ObjectRef, 0, len(flapping))
+		for ref := range flapping {
+			refs = append(refs, ref)
+		}
+		sort.Slice(refs, func(i, j int) bool {
+			return refs[i].Less(refs[j])
+		})
+		for _, ref := range refs {
+			validateResult.Warnings = append(validateResult.Warnings, result.DeploymentError{
+				Ref:     ref,
+				Message: fmt.Sprintf("Object is flapping, readiness changed %d times within the last %s", flapping[ref], pt.pp.r.FlappingWindow.String()),
+			})
+		}
+	}
+
+	return store.WriteValidateHistory(vh)
+}
+
 func (pt *preparedTarget) kluctlDeployOrPokeImages(deployMode string, targetContext *target_context.TargetContext) (*result.CommandResult, error) {
 	if deployMode == kluctlv1.KluctlDeployModeFull {
 		return pt.kluctlDeploy(targetContext), nil
@@ -796,6 +896,7 @@ func (pt *preparedTarget) kluctlDeploy(targetContext *target_context.TargetConte
 	cmd.NoWait = pt.pp.obj.Spec.NoWait
 	cmd.Prune = pt.pp.obj.Spec.Prune
 	cmd.WaitPrune = false
+	cmd.PreflightCheck = pt.pp.obj.Spec.PreflightCheck
 
 	cmdResult := cmd.Run(nil)
 	return cmdResult
@@ -814,6 +915,7 @@ func (pt *preparedTarget) kluctlPrune(targetContext *target_context.TargetContex
 	timer := prometheus.NewTimer(internal_metrics.NewKluctlDeploymentDuration(pt.pp.obj.ObjectMeta.Namespace, pt.pp.obj.ObjectMeta.Name, pt.pp.obj.Spec.DeployMode))
 	defer timer.ObserveDuration()
 	cmd := commands.NewPruneCommand("", targetContext, false)
+	cmd.PreflightCheck = pt.pp.obj.Spec.PreflightCheck
 
 	cmdResult := cmd.Run(func(refs []k8s.ObjectRef) error {
 		pt.printDeletedRefs(targetContext.SharedContext.Ctx, refs)
@@ -7,12 +7,14 @@ import (
 	"github.com/hashicorp/go-multierror"
 	kluctlv1 "github.com/kluctl/kluctl/v2/api/v1beta1"
 	internal_metrics "github.com/kluctl/kluctl/v2/pkg/controllers/metrics"
+	"github.com/kluctl/kluctl/v2/pkg/deployment"
 	ssh_pool "github.com/kluctl/kluctl/v2/pkg/git/ssh-pool"
 	"github.com/kluctl/kluctl/v2/pkg/results"
 	"github.com/kluctl/kluctl/v2/pkg/status"
 	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/types/result"
 	"github.com/kluctl/kluctl/v2/pkg/utils/flux_utils/metrics"
+	"github.com/kluctl/kluctl/v2/pkg/vars"
 	"github.com/kluctl/kluctl/v2/pkg/yaml"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
@@ -28,21 +30,35 @@ import (
 )
 
 type KluctlDeploymentReconciler struct {
-	RestConfig            *rest.Config
-	Client                client.Client
-	ApiReader             client.Reader
-	Scheme                *runtime.Scheme
-	EventRecorder         kuberecorder.EventRecorder
-	MetricsRecorder       *metrics.Recorder
-	ControllerName        string
-	ControllerNamespace   string
-	DefaultServiceAccount string
-	DryRun                bool
+	RestConfig                *rest.Config
+	Client                    client.Client
+	ApiReader                 client.Reader
+	Scheme                    *runtime.Scheme
+	EventRecorder             kuberecorder.EventRecorder
+	MetricsRecorder           *metrics.Recorder
+	ControllerName            string
+	ControllerNamespace       string
+	DefaultServiceAccount     string
+	DryRun                    bool
+	AllowExecVars             bool
+	AllowVaultTokenFiles      bool
+	AllowTemplatingExtensions bool
+	AllowKrmExec              []string
+	AllowKrmExecFromProject   bool
+	AllowKustomizeRemoteHosts []string
+	VarsSourceRegistry        *vars.VarsSourceRegistry
+	VarsCache                 vars.VarsCache
+	RecordVarsProvenance      bool
+	RenderCache               *deployment.RenderCache
 
 	SshPool *ssh_pool.SshPool
 
 	ResultStore results.ResultStore
 
+	KeepValidateHistory time.Duration
+	FlappingThreshold   int
+	FlappingWindow      time.Duration
+
 	mutex               sync.Mutex
 	resourceVersionsMap map[client.ObjectKey]map[k8s.ObjectRef]string
 }
@@ -21,6 +21,7 @@ type DeployCommand struct {
 	NoWait              bool
 	Prune               bool
 	WaitPrune           bool
+	PreflightCheck      bool
 }
 
 func NewDeployCommand(targetCtx *target_context.TargetContext) *DeployCommand {
@@ -66,6 +67,22 @@ func (cmd *DeployCommand) Run(diffResultCb func(diffResult *result.CommandResult
 		NoWait:              cmd.NoWait,
 	}
 
+	if cmd.PreflightCheck {
+		pu := utils2.NewPreflightUtil(cmd.targetCtx.SharedContext.Ctx, dew, cmd.targetCtx.SharedContext.K)
+		pu.AddDeploymentChecks(cmd.targetCtx.DeploymentCollection.Deployments, ru, o)
+		if cmd.Prune && cmd.targetCtx.Target.Discriminator != "" {
+			orphanObjects, err := FindOrphanObjects(cmd.targetCtx.SharedContext.K, ru, cmd.targetCtx.DeploymentCollection)
+			if err != nil {
+				dew.AddError(k8s2.ObjectRef{}, err)
+				return r
+			}
+			pu.AddDeleteChecks(orphanObjects)
+		}
+		if !pu.Run() {
+			return r
+		}
+	}
+
 	if diffResultCb != nil {
 		diffDew := dew.Clone()
 		au := utils2.NewApplyDeploymentsUtil(cmd.targetCtx.SharedContext.Ctx, diffDew, ru, cmd.targetCtx.SharedContext.K, o)
@@ -73,6 +90,7 @@ func (cmd *DeployCommand) Run(diffResultCb func(diffResult *result.CommandResult
 
 		du := utils2.NewDiffUtil(diffDew, ru, au.GetAppliedObjectsMap())
 		du.DiffDeploymentItems(cmd.targetCtx.DeploymentCollection.Deployments)
+		addPendingGeneratedSecretsWarnings(cmd.targetCtx.SharedContext.GeneratedSecrets, diffDew)
 
 		orphanObjects, err := FindOrphanObjects(cmd.targetCtx.SharedContext.K, ru, cmd.targetCtx.DeploymentCollection)
 		diffResult := &result.CommandResult{
@@ -93,6 +111,17 @@ func (cmd *DeployCommand) Run(diffResultCb func(diffResult *result.CommandResult
 	o.DryRun = cmd.targetCtx.SharedContext.K.DryRun
 	o.AbortOnError = cmd.AbortOnError
 
+	if o.DryRun {
+		addPendingGeneratedSecretsWarnings(cmd.targetCtx.SharedContext.GeneratedSecrets, dew)
+	} else if cmd.targetCtx.SharedContext.GeneratedSecrets != nil {
+		// generated secrets are only persisted when we really deploy
+		err = cmd.targetCtx.SharedContext.GeneratedSecrets.PersistPending()
+		if err != nil {
+			dew.AddError(k8s2.ObjectRef{}, err)
+			return r
+		}
+	}
+
 	au := utils2.NewApplyDeploymentsUtil(cmd.targetCtx.SharedContext.Ctx, dew, ru, cmd.targetCtx.SharedContext.K, o)
 	au.ApplyDeployments(cmd.targetCtx.DeploymentCollection.Deployments)
 
@@ -118,5 +147,9 @@ func (cmd *DeployCommand) Run(diffResultCb func(diffResult *result.CommandResult
 
 	r.Objects = collectObjects(cmd.targetCtx.DeploymentCollection, ru, au, du, orphanObjects, deleted)
 
+	if len(dew.GetErrorsList()) == 0 {
+		r.Outputs = computeOutputs(cmd.targetCtx.SharedContext.K, &cmd.targetCtx.DeploymentProject.Config, dew)
+	}
+
 	return r
 }
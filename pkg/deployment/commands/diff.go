@@ -71,6 +71,7 @@ func (cmd *DiffCommand) Run() *result.CommandResult {
 	du.IgnoreAnnotations = cmd.IgnoreAnnotations
 	du.IgnoreKluctlMetadata = cmd.IgnoreKluctlMetadata
 	du.DiffDeploymentItems(cmd.targetCtx.DeploymentCollection.Deployments)
+	addPendingGeneratedSecretsWarnings(cmd.targetCtx.SharedContext.GeneratedSecrets, dew)
 
 	orphanObjects, err := FindOrphanObjects(cmd.targetCtx.SharedContext.K, ru, cmd.targetCtx.DeploymentCollection)
 	if err != nil {
@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/deployment/utils"
+	k8s3 "github.com/kluctl/kluctl/v2/pkg/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/kluctl/kluctl/v2/pkg/yaml"
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+// collectOutputsConfig returns all outputs of the given deployment project and all its included projects
+func collectOutputsConfig(c *types.DeploymentProjectConfig) []types.DeploymentOutput {
+	if c == nil {
+		return nil
+	}
+	ret := append([]types.DeploymentOutput{}, c.Outputs...)
+	for _, di := range c.Deployments {
+		ret = append(ret, collectOutputsConfig(di.RenderedInclude)...)
+	}
+	return ret
+}
+
+// computeOutputs evaluates all declared outputs after a deployment. It returns nil if no outputs are declared.
+func computeOutputs(k *k8s3.K8sCluster, c *types.DeploymentProjectConfig, dew *utils.DeploymentErrorsAndWarnings) *uo.UnstructuredObject {
+	outputs := collectOutputsConfig(c)
+	if len(outputs) == 0 {
+		return nil
+	}
+
+	ret := uo.New()
+	for _, o := range outputs {
+		if _, ok := ret.Object[o.Name]; ok {
+			dew.AddError(k8s2.ObjectRef{}, fmt.Errorf("duplicate output %s", o.Name))
+			continue
+		}
+
+		var v any
+		var err error
+		if o.Value != nil {
+			err = yaml.ReadYamlBytes(o.Value.Raw, &v)
+		} else if o.Object != nil {
+			v, err = computeObjectOutput(k, o.Object, dew)
+		}
+		if err != nil {
+			dew.AddError(k8s2.ObjectRef{}, fmt.Errorf("failed to compute output %s: %w", o.Name, err))
+			continue
+		}
+		ret.Object[o.Name] = v
+	}
+	return ret
+}
+
+func computeObjectOutput(k *k8s3.K8sCluster, x *types.DeploymentOutputObject, dew *utils.DeploymentErrorsAndWarnings) (any, error) {
+	jp, err := uo.NewMyJsonPath(x.JsonPath)
+	if err != nil {
+		return nil, err
+	}
+
+	ars, err := k.GetFilteredPreferredAPIResources(k8s3.BuildGVKFilter(x.Group, nil, x.Kind))
+	if err != nil {
+		return nil, err
+	}
+	if len(ars) == 0 {
+		return nil, fmt.Errorf("resource for %s not found", x.Name)
+	}
+	ar := ars[0]
+	ref := k8s2.NewObjectRef(ar.Group, ar.Version, ar.Kind, x.Name, x.Namespace)
+
+	o, _, err := k.GetSingleObject(ref)
+	if err != nil {
+		if errors.IsNotFound(err) && k.DryRun {
+			// the object is most likely created by this deployment, which did not happen due to the dry-run
+			dew.AddWarning(ref, fmt.Errorf("object for output not found, which is expected in dry-run mode"))
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	v, ok := jp.GetFirst(o)
+	if !ok {
+		return nil, fmt.Errorf("jsonPath %s did not match any field in %s", x.JsonPath, ref.String())
+	}
+	return v, nil
+}
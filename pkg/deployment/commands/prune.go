@@ -14,6 +14,8 @@ type PruneCommand struct {
 	discriminator string
 	targetCtx     *target_context.TargetContext
 	wait          bool
+
+	PreflightCheck bool
 }
 
 func NewPruneCommand(discriminator string, targetCtx *target_context.TargetContext, wait bool) *PruneCommand {
@@ -55,6 +57,14 @@ func (cmd *PruneCommand) Run(confirmCb func(refs []k8s2.ObjectRef) error) *resul
 		return r
 	}
 
+	if cmd.PreflightCheck {
+		pu := utils2.NewPreflightUtil(cmd.targetCtx.SharedContext.Ctx, dew, cmd.targetCtx.SharedContext.K)
+		pu.AddDeleteChecks(orphanObjects)
+		if !pu.Run() {
+			return r
+		}
+	}
+
 	if confirmCb != nil {
 		err = confirmCb(orphanObjects)
 		if err != nil {
@@ -31,6 +31,11 @@ func newCommandResult(targetCtx *target_context.TargetContext, startTime time.Ti
 	r.Command.DryRun = targetCtx.Params.DryRun
 
 	r.Deployment = &targetCtx.DeploymentProject.Config
+	r.ProjectArgs = targetCtx.KluctlProject.Config.Args
+
+	if targetCtx.Params.VarsProvenance != nil {
+		r.VarsProvenance = targetCtx.Params.VarsProvenance.Result().ToCompacted()
+	}
 
 	var err error
 	r.GitInfo, r.ProjectKey, err = git.BuildGitInfo(targetCtx.SharedContext.Ctx,
@@ -1,8 +1,10 @@
 package commands
 
 import (
+	"fmt"
 	"github.com/kluctl/kluctl/v2/pkg/deployment"
 	"github.com/kluctl/kluctl/v2/pkg/deployment/utils"
+	"github.com/kluctl/kluctl/v2/pkg/generated_secrets"
 	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/types/result"
 	"sort"
@@ -141,3 +143,14 @@ func filterDeletedOrphans(orphans []k8s.ObjectRef, deleted []k8s.ObjectRef) []k8
 	}
 	return tmp
 }
+
+// addPendingGeneratedSecretsWarnings marks all objects that use generated secrets which were generated in-memory and
+// are not persisted yet. These values will change until the target is actually deployed.
+func addPendingGeneratedSecretsWarnings(gs *generated_secrets.Store, dew *utils.DeploymentErrorsAndWarnings) {
+	if gs == nil {
+		return
+	}
+	for ref, names := range gs.PendingObjects() {
+		dew.AddWarning(ref, fmt.Errorf("generated secrets %v do not exist yet, the shown values are pending and will be persisted when the target is deployed", names))
+	}
+}
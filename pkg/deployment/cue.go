@@ -0,0 +1,163 @@
+package deployment
+
+import (
+	"cuelang.org/go/cue"
+	"cuelang.org/go/cue/build"
+	"cuelang.org/go/cue/cuecontext"
+	cueerrors "cuelang.org/go/cue/errors"
+	"cuelang.org/go/cue/load"
+	"encoding/json"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	cueDefaultObjectsPath = "objects"
+	cueDefaultVarsPath    = "vars"
+)
+
+func (p *DeploymentProject) checkCuePaths(c *types.CueItemConfig) error {
+	dir := filepath.Join(p.absDir, c.Dir)
+	if !isInDir(p.source.dir, dir) {
+		return fmt.Errorf("cue directory is not part of the deployment project: %s", c.Dir)
+	}
+	if !utils.IsDirectory(dir) {
+		return fmt.Errorf("cue directory does not exist or is not a directory: %s", c.Dir)
+	}
+	return nil
+}
+
+// formatCueError returns an error that contains all CUE errors including their positions, relative to the project
+func (di *DeploymentItem) formatCueError(err error) error {
+	details := cueerrors.Details(err, &cueerrors.Config{
+		Cwd: di.Project.source.dir,
+	})
+	return fmt.Errorf("%s", strings.TrimSpace(details))
+}
+
+// checkCueFiles ensures that the instance and all its imports were loaded from inside the project
+func (di *DeploymentItem) checkCueFiles(inst *build.Instance, visited map[*build.Instance]bool) error {
+	if visited[inst] {
+		return nil
+	}
+	visited[inst] = true
+
+	root, err := filepath.EvalSymlinks(di.Project.source.dir)
+	if err != nil {
+		return err
+	}
+	for _, f := range inst.BuildFiles {
+		if f.Filename == "" || f.Filename == "-" {
+			continue
+		}
+		p, err := filepath.EvalSymlinks(f.Filename)
+		if err != nil {
+			return err
+		}
+		if !isInDir(root, p) {
+			return fmt.Errorf("cue file %s is not part of the project", f.Filename)
+		}
+	}
+	for _, imp := range inst.Imports {
+		err = di.checkCueFiles(imp, visited)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (di *DeploymentItem) loadCueValue() (cue.Value, error) {
+	c := di.Config.Cue
+
+	insts := load.Instances([]string{"."}, &load.Config{
+		Dir:     *di.dir,
+		Package: c.Package,
+		// never fetch modules from remote registries, dependencies must be part of the project
+		Env: []string{"CUE_REGISTRY=none"},
+	})
+	if len(insts) != 1 {
+		return cue.Value{}, fmt.Errorf("expected exactly one CUE instance, got %d", len(insts))
+	}
+	inst := insts[0]
+	if inst.Err != nil {
+		return cue.Value{}, di.formatCueError(inst.Err)
+	}
+	err := di.checkCueFiles(inst, map[*build.Instance]bool{})
+	if err != nil {
+		return cue.Value{}, err
+	}
+
+	ctx := cuecontext.New()
+	v := ctx.BuildInstance(inst)
+	if v.Err() != nil {
+		return cue.Value{}, di.formatCueError(v.Err())
+	}
+
+	varsPath := c.VarsPath
+	if varsPath == "" {
+		varsPath = cueDefaultVarsPath
+	}
+	vp := cue.ParsePath(varsPath)
+	if vp.Err() != nil {
+		return cue.Value{}, fmt.Errorf("invalid varsPath %s: %w", varsPath, vp.Err())
+	}
+	v = v.FillPath(vp, ctx.Encode(di.VarsCtx.Vars.Object))
+
+	// reports conflicts between the vars and the schema defined in the package
+	err = v.Validate()
+	if err != nil {
+		return cue.Value{}, di.formatCueError(err)
+	}
+	return v, nil
+}
+
+func (di *DeploymentItem) buildCue() error {
+	if di.dir == nil {
+		return nil
+	}
+	if di.Config.OnlyRender {
+		return nil
+	}
+
+	v, err := di.loadCueValue()
+	if err != nil {
+		return err
+	}
+
+	objectsPath := di.Config.Cue.ObjectsPath
+	if objectsPath == "" {
+		objectsPath = cueDefaultObjectsPath
+	}
+	op := cue.ParsePath(objectsPath)
+	if op.Err() != nil {
+		return fmt.Errorf("invalid objectsPath %s: %w", objectsPath, op.Err())
+	}
+	objects := v.LookupPath(op)
+	if !objects.Exists() {
+		return fmt.Errorf("%s not found in CUE package", objectsPath)
+	}
+	err = objects.Validate(cue.Concrete(true))
+	if err != nil {
+		return di.formatCueError(err)
+	}
+
+	b, err := objects.MarshalJSON()
+	if err != nil {
+		return di.formatCueError(err)
+	}
+	var x any
+	err = json.Unmarshal(b, &x)
+	if err != nil {
+		return err
+	}
+
+	di.Objects = nil
+	return collectNestedObjects(x, objectsPath, func(o map[string]any) {
+		di.Objects = append(di.Objects, uo.FromMap(o))
+	})
+}
@@ -0,0 +1,107 @@
+package deployment
+
+import (
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/kluctl/kluctl/v2/pkg/vars"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func newTestCueItem(t *testing.T, files map[string]string, c types.CueItemConfig, v map[string]any) *DeploymentItem {
+	root := t.TempDir()
+	for n, s := range files {
+		p := filepath.Join(root, n)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(p), 0o700))
+		assert.NoError(t, os.WriteFile(p, []byte(s), 0o600))
+	}
+	dir := filepath.Join(root, c.Dir)
+	return &DeploymentItem{
+		Project: &DeploymentProject{
+			source: NewSource(root),
+			absDir: root,
+		},
+		Config:  &types.DeploymentItemConfig{Cue: &c},
+		VarsCtx: &vars.VarsCtx{Vars: uo.FromMap(v)},
+		dir:     &dir,
+	}
+}
+
+const testCuePackage = `package app
+
+vars: {
+	args: env: "prod" | "dev"
+	...
+}
+
+objects: [{
+	apiVersion: "v1"
+	kind:       "ConfigMap"
+	metadata: name: "cm-\(vars.args.env)"
+}]
+`
+
+func TestBuildCue(t *testing.T) {
+	di := newTestCueItem(t, map[string]string{
+		"app/main.cue": testCuePackage,
+	}, types.CueItemConfig{Dir: "app"}, map[string]any{
+		"args": map[string]any{"env": "prod"},
+	})
+
+	assert.NoError(t, di.buildCue())
+	if assert.Len(t, di.Objects, 1) {
+		assert.Equal(t, "cm-prod", di.Objects[0].GetK8sName())
+	}
+}
+
+func TestBuildCueCustomPaths(t *testing.T) {
+	di := newTestCueItem(t, map[string]string{
+		"app/main.cue": `package app
+
+kluctl: _
+out: a: {apiVersion: "v1", kind: "ConfigMap", metadata: name: kluctl.name}
+`,
+	}, types.CueItemConfig{Dir: "app", ObjectsPath: "out", VarsPath: "kluctl"}, map[string]any{
+		"name": "x",
+	})
+
+	assert.NoError(t, di.buildCue())
+	if assert.Len(t, di.Objects, 1) {
+		assert.Equal(t, "x", di.Objects[0].GetK8sName())
+	}
+}
+
+func TestBuildCueSchemaError(t *testing.T) {
+	di := newTestCueItem(t, map[string]string{
+		"app/main.cue": testCuePackage,
+	}, types.CueItemConfig{Dir: "app"}, map[string]any{
+		"args": map[string]any{"env": "qa"},
+	})
+
+	err := di.buildCue()
+	assert.ErrorContains(t, err, `vars.args.env: conflicting values "prod" and "qa"`)
+	assert.ErrorContains(t, err, filepath.FromSlash("app/main.cue:4:"))
+}
+
+func TestBuildCueNotConcrete(t *testing.T) {
+	di := newTestCueItem(t, map[string]string{
+		"app/main.cue": `package app
+
+objects: [{apiVersion: "v1", kind: "ConfigMap", metadata: name: string}]
+`,
+	}, types.CueItemConfig{Dir: "app"}, map[string]any{})
+
+	err := di.buildCue()
+	assert.ErrorContains(t, err, "incomplete value string")
+	assert.ErrorContains(t, err, filepath.FromSlash("app/main.cue:3:"))
+}
+
+func TestBuildCueMissingObjects(t *testing.T) {
+	di := newTestCueItem(t, map[string]string{
+		"app/main.cue": "package app\n",
+	}, types.CueItemConfig{Dir: "app"}, map[string]any{})
+
+	assert.EqualError(t, di.buildCue(), "objects not found in CUE package")
+}
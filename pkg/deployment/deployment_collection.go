@@ -15,6 +15,8 @@ import (
 	"github.com/kluctl/kluctl/v2/pkg/yaml"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	"path/filepath"
+	"sort"
+	"strings"
 	"sync"
 )
 
@@ -28,6 +30,10 @@ type DeploymentCollection struct {
 
 	Deployments []*DeploymentItem
 	mutex       sync.Mutex
+
+	clusterInfoOnce sync.Once
+	clusterInfo     string
+	clusterInfoErr  error
 }
 
 func NewDeploymentCollection(ctx SharedContext, project *DeploymentProject, images *Images, inclusion *utils.Inclusion, forSeal bool) (*DeploymentCollection, error) {
@@ -118,7 +124,16 @@ func (c *DeploymentCollection) collectAllDeployments(project *DeploymentProject,
 				ret = append(ret, c.createBarrierDummy(project))
 			}
 		} else {
-			index, dir2 := findDeploymentItemIndex(project, diConfig.Path, indexes)
+			pth := diConfig.Path
+			if diConfig.Jsonnet != nil {
+				pth = utils.Ptr(filepath.Dir(diConfig.Jsonnet.Main))
+			} else if diConfig.Cue != nil {
+				pth = &diConfig.Cue.Dir
+			} else if diConfig.Manifest != nil {
+				// manifests have no source directory, so they are treated as items of the project itself
+				pth = utils.Ptr(".")
+			}
+			index, dir2 := findDeploymentItemIndex(project, pth, indexes)
 			di, err := NewDeploymentItem(c.ctx, project, c, diConfig, dir2, index)
 			if err != nil {
 				return nil, err
@@ -149,6 +164,60 @@ func (c *DeploymentCollection) RenderDeployments() error {
 	return g.ErrorOrNil()
 }
 
+// getClusterInfo returns the server version and all API versions of the cluster, as these are passed to Helm
+func (c *DeploymentCollection) getClusterInfo() (string, error) {
+	c.clusterInfoOnce.Do(func() {
+		if c.ctx.K == nil {
+			return
+		}
+		ars, err := c.ctx.K.GetAllAPIResources()
+		if err != nil {
+			c.clusterInfoErr = err
+			return
+		}
+		l := make([]string, 0, len(ars))
+		for _, ar := range ars {
+			l = append(l, fmt.Sprintf("%s/%s/%s", ar.Group, ar.Version, ar.Kind))
+		}
+		sort.Strings(l)
+		c.clusterInfo = c.ctx.K.ServerVersion.String() + "\n" + strings.Join(l, "\n")
+	})
+	return c.clusterInfo, c.clusterInfoErr
+}
+
+func (c *DeploymentCollection) lookupRenderCache() error {
+	if c.ctx.RenderCache == nil || c.forSeal {
+		return nil
+	}
+
+	g := utils.NewGoHelper(c.ctx.Ctx, 16)
+	for _, d := range c.Deployments {
+		d := d
+		g.RunE(func() error {
+			return d.lookupRenderCache(c.getClusterInfo)
+		})
+	}
+	g.Wait()
+	return g.ErrorOrNil()
+}
+
+func (c *DeploymentCollection) storeRenderCache() {
+	if c.ctx.RenderCache == nil || c.forSeal {
+		return
+	}
+
+	for _, d := range c.Deployments {
+		err := d.storeRenderCache()
+		if err != nil {
+			// the cache is only an optimization
+			status.Warningf(c.ctx.Ctx, "Failed to store %s in render cache: %s", d.RelRenderedDir, err.Error())
+		}
+	}
+
+	stats := c.RenderCacheStats()
+	status.Tracef(c.ctx.Ctx, "Render cache: %d hits, %d misses, %d uncacheable", stats.Hits, stats.Misses, stats.Uncacheable)
+}
+
 func (c *DeploymentCollection) renderHelmCharts() error {
 	s := status.Start(c.ctx.Ctx, "Rendering Helm Charts")
 	defer s.Failed()
@@ -182,6 +251,18 @@ func (c *DeploymentCollection) resolveSealedSecrets() error {
 	return nil
 }
 
+// resolveKustomizeRemotes runs sequentially, as kustomizations outside the items' dirs might be shared between items
+func (c *DeploymentCollection) resolveKustomizeRemotes() error {
+	r := newKustomizeRemoteResolver(&c.ctx)
+	for _, d := range c.Deployments {
+		err := d.resolveKustomizeRemotes(r)
+		if err != nil {
+			return fmt.Errorf("resolving remote kustomize resources for %s failed. %w", *d.dir, err)
+		}
+	}
+	return nil
+}
+
 func (c *DeploymentCollection) buildKustomizeObjects() error {
 	g := utils.NewGoHelper(c.ctx.Ctx, 0)
 
@@ -189,9 +270,30 @@ func (c *DeploymentCollection) buildKustomizeObjects() error {
 	for _, d_ := range c.Deployments {
 		d := d_
 		g.RunE(func() error {
-			err := d.buildKustomize()
+			if d.Config.Jsonnet != nil {
+				err := d.buildJsonnet()
+				if err != nil {
+					return fmt.Errorf("evaluating jsonnet for %s failed. %w", *d.dir, err)
+				}
+			} else if d.Config.Cue != nil {
+				err := d.buildCue()
+				if err != nil {
+					return fmt.Errorf("evaluating cue for %s failed. %w", *d.dir, err)
+				}
+			} else if d.Config.Manifest != nil {
+				err := d.buildManifest()
+				if err != nil {
+					return fmt.Errorf("loading manifest for %s failed. %w", *d.dir, err)
+				}
+			} else {
+				err := d.buildKustomize()
+				if err != nil {
+					return fmt.Errorf("building kustomize objects for %s failed. %w", *d.dir, err)
+				}
+			}
+			err := d.runConfiguredKrmFunctions()
 			if err != nil {
-				return fmt.Errorf("building kustomize objects for %s failed. %w", *d.dir, err)
+				return fmt.Errorf("running KRM functions for %s failed. %w", *d.dir, err)
 			}
 			return nil
 		})
@@ -349,6 +451,10 @@ func (c *DeploymentCollection) Prepare() error {
 	if err != nil {
 		return err
 	}
+	err = c.lookupRenderCache()
+	if err != nil {
+		return err
+	}
 	err = c.renderHelmCharts()
 	if err != nil {
 		return err
@@ -357,10 +463,16 @@ func (c *DeploymentCollection) Prepare() error {
 	if err != nil {
 		return err
 	}
+	err = c.resolveKustomizeRemotes()
+	if err != nil {
+		return err
+	}
 	err = c.buildKustomizeObjects()
 	if err != nil {
 		return err
 	}
+	// must happen before postprocessing, as it modifies the objects in-place
+	c.storeRenderCache()
 	err = c.postprocessObjects()
 	if err != nil {
 		return err
@@ -380,6 +492,29 @@ func (c *DeploymentCollection) Prepare() error {
 	return nil
 }
 
+// RenderCacheStats returns the hits and misses of the deployment items rendered by this collection
+func (c *DeploymentCollection) RenderCacheStats() RenderCacheStats {
+	var ret RenderCacheStats
+	for _, d := range c.Deployments {
+		if d.renderCacheStats != nil {
+			ret.add(*d.renderCacheStats)
+		}
+	}
+	sort.SliceStable(ret.Items, func(i, j int) bool {
+		return ret.Items[i].Dir < ret.Items[j].Dir
+	})
+	return ret
+}
+
+// TransformationTraces returns the objects that transformations were applied to, in the order of the deployment items
+func (c *DeploymentCollection) TransformationTraces() []TransformationTrace {
+	var ret []TransformationTrace
+	for _, d := range c.Deployments {
+		ret = append(ret, d.transformationTraces...)
+	}
+	return ret
+}
+
 func (c *DeploymentCollection) FindRenderedImages() map[k8s2.ObjectRef][]string {
 	ret := make(map[k8s2.ObjectRef][]string)
 	for _, d := range c.Deployments {
@@ -434,6 +569,9 @@ func (c *DeploymentCollection) CalcObjectsHash() (string, error) {
 	for _, x := range hashes {
 		h.Write(x[:])
 	}
+	if c.ctx.TemplatingHash != "" {
+		h.Write([]byte(c.ctx.TemplatingHash))
+	}
 
 	return hex.EncodeToString(h.Sum(nil)), nil
 }
@@ -1,10 +1,12 @@
 package deployment
 
 import (
+	"errors"
 	"fmt"
 	"github.com/hashicorp/go-multierror"
 	"github.com/kluctl/kluctl/v2/pkg/helm"
 	"github.com/kluctl/kluctl/v2/pkg/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_jinja2"
 	"github.com/kluctl/kluctl/v2/pkg/sops"
 	"github.com/kluctl/kluctl/v2/pkg/types"
 	"github.com/kluctl/kluctl/v2/pkg/utils"
@@ -17,6 +19,8 @@ import (
 	"os"
 	"path"
 	"path/filepath"
+	"slices"
+	"sort"
 	"strings"
 )
 
@@ -45,6 +49,20 @@ type DeploymentItem struct {
 	RelRenderedDir        string
 	RenderedDir           string
 	renderedYamlPath      string
+
+	// traceChain is the include chain of the parent project, followed by the item dir
+	traceChain []string
+
+	// renderCacheKey is set when the item was not found in the render cache and must be stored after building it
+	renderCacheKey string
+	renderCacheHit bool
+	// renderCacheStats is nil if the render cache was not consulted for this item
+	renderCacheStats *RenderCacheItemStats
+
+	transformationTraces []TransformationTrace
+
+	// manifestFiles are the rendered files of manifest items
+	manifestFiles []string
 }
 
 func NewDeploymentItem(ctx SharedContext, project *DeploymentProject, collection *DeploymentCollection, config *types.DeploymentItemConfig, dir *string, index int) (*DeploymentItem, error) {
@@ -83,9 +101,22 @@ func NewDeploymentItem(ctx SharedContext, project *DeploymentProject, collection
 		di.RenderedSourceRootDir = filepath.Join(collection.ctx.RenderDir, di.Project.source.id)
 		di.RenderedDir = filepath.Join(di.RenderedSourceRootDir, di.RelRenderedDir)
 		di.renderedYamlPath = filepath.Join(di.RenderedDir, ".rendered.yml")
+
+		di.traceChain = append(slices.Clone(di.Project.traceChain), filepath.ToSlash(di.RelToSourceItemDir))
+		di.VarsCtx.J2Opts = append(slices.Clip(di.VarsCtx.J2Opts), kluctl_jinja2.WithTraceChain(di.traceChain))
 	}
 
-	err = di.Project.loadVarsList(di.VarsCtx, di.Config.Vars)
+	origin := di.Project.provenanceOrigin() + " deployments"
+	if di.Config.Path != nil {
+		origin = fmt.Sprintf("%s[path=%s]", origin, *di.Config.Path)
+	} else if di.Config.Jsonnet != nil {
+		origin = fmt.Sprintf("%s[jsonnet=%s]", origin, di.Config.Jsonnet.Main)
+	} else if di.Config.Cue != nil {
+		origin = fmt.Sprintf("%s[cue=%s]", origin, di.Config.Cue.Dir)
+	} else if di.Config.Manifest != nil {
+		origin = fmt.Sprintf("%s[manifest=%s]", origin, manifestDefaultTag(di.Config.Manifest))
+	}
+	err = di.Project.loadVarsList(di.VarsCtx, di.Config.Vars, origin+".vars")
 	if err != nil {
 		return nil, err
 	}
@@ -93,6 +124,17 @@ func NewDeploymentItem(ctx SharedContext, project *DeploymentProject, collection
 	return di, nil
 }
 
+// usesKustomize returns false for items that are rendered by Jsonnet, CUE or from downloaded manifests instead of
+// Jinja2, Helm and kustomize
+func (di *DeploymentItem) usesKustomize() bool {
+	return di.Config.Jsonnet == nil && di.Config.Cue == nil && di.Config.Manifest == nil
+}
+
+// GetDir returns the absolute source directory of the item, or nil for items without a path, e.g. barriers
+func (di *DeploymentItem) GetDir() *string {
+	return di.dir
+}
+
 func (di *DeploymentItem) getCommonLabels() map[string]string {
 	l := di.Project.GetCommonLabels()
 	if di.ctx.Discriminator != "" {
@@ -125,6 +167,14 @@ func (di *DeploymentItem) render(forSeal bool) error {
 		return err
 	}
 
+	if di.Config.Manifest != nil {
+		return di.renderManifest()
+	}
+	if !di.usesKustomize() {
+		// Jsonnet and CUE are not rendered with Jinja2, vars are passed to the evaluation instead
+		return nil
+	}
+
 	var excludePatterns []string
 	excludePatterns = append(excludePatterns, "**/.git")
 
@@ -160,13 +210,28 @@ func (di *DeploymentItem) render(forSeal bool) error {
 	// also add deployment item dir to search dirs
 	searchDirs = append([]string{*di.dir}, searchDirs...)
 
-	return di.VarsCtx.RenderDirectory(
+	err = di.VarsCtx.RenderDirectory(
 		filepath.Join(di.Project.source.dir, di.RelToSourceItemDir),
 		di.RenderedDir,
 		excludePatterns,
 		searchDirs,
 		di.Project.source.dir,
 	)
+	return kluctl_jinja2.MapTemplateErrors(err, di.Project.source.dir, di.traceChain)
+}
+
+// mapRenderedPaths replaces paths inside the render dir with the corresponding source paths, so that errors point
+// to the files that must actually be fixed
+func (di *DeploymentItem) mapRenderedPaths(err error) error {
+	if err == nil || di.dir == nil {
+		return err
+	}
+	s := err.Error()
+	mapped := strings.ReplaceAll(s, di.RenderedDir, *di.dir)
+	if mapped == s {
+		return err
+	}
+	return errors.New(mapped)
 }
 
 func (di *DeploymentItem) isHelmChartYaml(p string) bool {
@@ -206,7 +271,7 @@ func (di *DeploymentItem) newHelmRelease(subDir string) (*helm.Release, error) {
 }
 
 func (di *DeploymentItem) renderHelmCharts() error {
-	if di.dir == nil {
+	if di.dir == nil || di.renderCacheHit || !di.usesKustomize() {
 		return nil
 	}
 
@@ -334,7 +399,7 @@ func (di *DeploymentItem) BuildSealedSecretPath(relPath string) (string, error)
 }
 
 func (di *DeploymentItem) resolveSealedSecrets() error {
-	if di.dir == nil {
+	if di.dir == nil || di.renderCacheHit || !di.usesKustomize() {
 		return nil
 	}
 
@@ -513,7 +578,7 @@ func (di *DeploymentItem) prepareKustomizationYaml() (*uo.UnstructuredObject, er
 }
 
 func (di *DeploymentItem) buildKustomize() error {
-	if di.dir == nil {
+	if di.dir == nil || di.renderCacheHit {
 		return nil
 	}
 	if di.Config.OnlyRender {
@@ -522,7 +587,13 @@ func (di *DeploymentItem) buildKustomize() error {
 
 	ky, err := di.prepareKustomizationYaml()
 	if err != nil {
-		return err
+		return di.mapRenderedPaths(err)
+	}
+
+	// KRM functions are run by us after the build, kustomize only sees the builtin generators and transformers
+	fns, err := di.extractKustomizeFunctions(ky)
+	if err != nil {
+		return di.mapRenderedPaths(err)
 	}
 
 	// Save modified kustomization.yml
@@ -539,7 +610,7 @@ func (di *DeploymentItem) buildKustomize() error {
 	fs = sops.NewDecryptingFs(fs, di.ctx.SopsDecrypter)
 	rm, err := kustomize.Build(fs, di.RenderedDir)
 	if err != nil {
-		return err
+		return di.mapRenderedPaths(err)
 	}
 
 	di.Objects = nil
@@ -552,7 +623,7 @@ func (di *DeploymentItem) buildKustomize() error {
 		di.Objects = append(di.Objects, o)
 	}
 
-	return nil
+	return di.runKrmFunctions(fns)
 }
 
 func (di *DeploymentItem) postprocessObjects(images *Images) error {
@@ -560,6 +631,8 @@ func (di *DeploymentItem) postprocessObjects(images *Images) error {
 		return nil
 	}
 
+	transformations := di.Project.getTransformations()
+
 	var errs *multierror.Error
 	for _, o := range di.Objects {
 		commonLabels := di.getCommonLabels()
@@ -579,6 +652,24 @@ func (di *DeploymentItem) postprocessObjects(images *Images) error {
 			if err != nil {
 				errs = multierror.Append(errs, err)
 			}
+
+			// Resolve generated_secret(...) placeholders
+			if di.ctx.GeneratedSecrets != nil {
+				err = di.ctx.GeneratedSecrets.ResolvePlaceholders(o)
+				if err != nil {
+					errs = multierror.Append(errs, err)
+				}
+			}
+			return nil
+		})
+
+		// Transformations are applied last, so that they can select objects by their common labels and see resolved
+		// images
+		_ = k8s.UnwrapListItems(o, false, func(o *uo.UnstructuredObject) error {
+			err := di.applyTransformations(transformations, o)
+			if err != nil {
+				errs = multierror.Append(errs, err)
+			}
 			return nil
 		})
 	}
@@ -610,3 +701,42 @@ func (di *DeploymentItem) writeRenderedYaml() error {
 	}
 	return nil
 }
+
+// collectNestedObjects walks the output of a jsonnet or CUE evaluation and calls cb for every Kubernetes object found.
+// Objects can be nested inside lists and dictionaries (e.g. as produced by kube-prometheus), which are traversed in a
+// stable order. null values are ignored, so that conditionally generated objects can be omitted easily.
+func collectNestedObjects(v any, path string, cb func(o map[string]any)) error {
+	switch x := v.(type) {
+	case nil:
+		return nil
+	case map[string]any:
+		_, hasApiVersion := x["apiVersion"]
+		_, hasKind := x["kind"]
+		if hasApiVersion && hasKind {
+			cb(x)
+			return nil
+		}
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			err := collectNestedObjects(x[k], fmt.Sprintf("%s.%s", path, k), cb)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	case []any:
+		for i, e := range x {
+			err := collectNestedObjects(e, fmt.Sprintf("%s[%d]", path, i), cb)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unexpected value of type %T at %s, expected Kubernetes objects", v, path)
+	}
+}
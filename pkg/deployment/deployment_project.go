@@ -3,6 +3,7 @@ package deployment
 import (
 	"fmt"
 	securejoin "github.com/cyphar/filepath-securejoin"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_jinja2"
 	"github.com/kluctl/kluctl/v2/pkg/kluctl_project"
 	"github.com/kluctl/kluctl/v2/pkg/status"
 	"github.com/kluctl/kluctl/v2/pkg/types"
@@ -11,6 +12,8 @@ import (
 	"github.com/kluctl/kluctl/v2/pkg/vars"
 	"github.com/kluctl/kluctl/v2/pkg/yaml"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 )
 
@@ -29,6 +32,12 @@ type DeploymentProject struct {
 
 	parentProject        *DeploymentProject
 	parentProjectInclude *types.DeploymentItemConfig
+
+	// traceChain describes the include chain that lead to this project, starting with the root project
+	traceChain []string
+
+	// transformations are compiled from Config.Transformations
+	transformations []*transformation
 }
 
 func NewDeploymentProject(ctx SharedContext, varsCtx *vars.VarsCtx, source Source, relDir string, parentProject *DeploymentProject) (*DeploymentProject, error) {
@@ -52,6 +61,9 @@ func NewDeploymentProject(ctx SharedContext, varsCtx *vars.VarsCtx, source Sourc
 
 	dp.absDir = dir
 
+	dp.traceChain = dp.buildTraceChain()
+	dp.VarsCtx.J2Opts = append(slices.Clip(dp.VarsCtx.J2Opts), kluctl_jinja2.WithTraceChain(dp.traceChain))
+
 	err = dp.loadConfig()
 	if err != nil {
 		return nil, fmt.Errorf("failed to load deployment config for %s: %w", dir, err)
@@ -73,8 +85,24 @@ func NewDeploymentProject(ctx SharedContext, varsCtx *vars.VarsCtx, source Sourc
 	return dp, nil
 }
 
-func (p *DeploymentProject) loadVarsList(varsCtx *vars.VarsCtx, varsList []types.VarsSource) error {
-	return p.ctx.VarsLoader.LoadVarsList(p.ctx.Ctx, varsCtx, varsList, p.getRenderSearchDirs(), "")
+func (p *DeploymentProject) loadVarsList(varsCtx *vars.VarsCtx, varsList []types.VarsSource, origin string) error {
+	return p.ctx.VarsLoader.LoadVarsListWithOrigin(p.ctx.Ctx, varsCtx, varsList, p.getRenderSearchDirs(), "", origin)
+}
+
+// provenanceOrigin returns the path of the deployment.yml, used to describe where vars are declared
+func (p *DeploymentProject) provenanceOrigin() string {
+	return filepath.ToSlash(filepath.Join(p.relDir, "deployment.yml"))
+}
+
+func (p *DeploymentProject) buildTraceChain() []string {
+	desc := p.provenanceOrigin()
+	if p.source.url != "" {
+		desc = fmt.Sprintf("%s (%s)", desc, p.source.url)
+	}
+	if p.parentProject == nil {
+		return []string{desc}
+	}
+	return append(slices.Clone(p.parentProject.traceChain), desc)
 }
 
 func (p *DeploymentProject) loadConfig() error {
@@ -92,14 +120,31 @@ func (p *DeploymentProject) loadConfig() error {
 	}
 	configPath = yaml.FixPathExt(configPath)
 
-	err := p.VarsCtx.RenderYamlFile(configPath, p.getRenderSearchDirs(), &p.Config)
+	rendered, err := p.VarsCtx.RenderYamlFile(configPath, p.getRenderSearchDirs(), &p.Config)
 	if err != nil {
-		return fmt.Errorf("failed to load deployment.yml: %w", err)
+		return fmt.Errorf("failed to load deployment.yml: %w", kluctl_jinja2.MapTemplateErrors(err, p.source.dir, p.traceChain))
+	}
+	err = p.checkVarsSources(configPath, []byte(rendered))
+	if err != nil {
+		return fmt.Errorf("failed to load deployment.yml: %w", kluctl_jinja2.MapTemplateErrors(err, p.source.dir, p.traceChain))
 	}
 
 	return p.processConfig()
 }
 
+// checkVarsSources reports unknown vars source types before any vars are loaded, so that typos are reported with their
+// position instead of failing when the vars source is loaded
+func (p *DeploymentProject) checkVarsSources(configPath string, rendered []byte) error {
+	perrs := p.ctx.VarsLoader.CheckVarsSources(configPath, rendered, []string{"vars"}, p.Config.Vars)
+	for i, item := range p.Config.Deployments {
+		perrs = append(perrs, p.ctx.VarsLoader.CheckVarsSources(configPath, rendered, []string{"deployments", strconv.Itoa(i), "vars"}, item.Vars)...)
+	}
+	if len(perrs) != 0 {
+		return perrs
+	}
+	return nil
+}
+
 func (p *DeploymentProject) generateSingleKustomizeProject() error {
 	p.Config.Deployments = append(p.Config.Deployments, types.DeploymentItemConfig{
 		Path: utils.Ptr("."),
@@ -118,9 +163,12 @@ func (p *DeploymentProject) processConfig() error {
 		if item.RenderedInclude != nil {
 			return fmt.Errorf("renderedInclude is not allowed here")
 		}
+		if len(item.RenderedRemoteResources) != 0 {
+			return fmt.Errorf("renderedRemoteResources is not allowed here")
+		}
 	}
 
-	err := p.loadVarsList(p.VarsCtx, p.Config.Vars)
+	err := p.loadVarsList(p.VarsCtx, p.Config.Vars, p.provenanceOrigin()+" vars")
 	if err != nil {
 		return fmt.Errorf("failed to load deployment.yml vars: %w", err)
 	}
@@ -136,6 +184,12 @@ func (p *DeploymentProject) processConfig() error {
 			item.Tags = []string{filepath.Base(*item.Path)}
 		} else if item.Include != nil {
 			item.Tags = []string{filepath.Base(*item.Include)}
+		} else if item.Jsonnet != nil {
+			item.Tags = []string{jsonnetDefaultTag(item.Jsonnet.Main)}
+		} else if item.Cue != nil {
+			item.Tags = []string{filepath.Base(item.Cue.Dir)}
+		} else if item.Manifest != nil {
+			item.Tags = []string{manifestDefaultTag(item.Manifest)}
 		}
 	}
 
@@ -144,11 +198,30 @@ func (p *DeploymentProject) processConfig() error {
 		return err
 	}
 
+	err = p.compileTransformations()
+	if err != nil {
+		return err
+	}
+
 	return nil
 }
 
 func (p *DeploymentProject) checkDeploymentDirs() error {
 	for _, di := range p.Config.Deployments {
+		if di.Jsonnet != nil {
+			err := p.checkJsonnetPaths(di.Jsonnet)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+		if di.Cue != nil {
+			err := p.checkCuePaths(di.Cue)
+			if err != nil {
+				return err
+			}
+			continue
+		}
 		if di.Path == nil {
 			continue
 		}
@@ -195,7 +268,7 @@ func (p *DeploymentProject) loadIncludes() error {
 		}
 
 		if inc.Include != nil {
-			newProject, err = p.loadLocalInclude(p.source, filepath.Join(p.relDir, *inc.Include), inc)
+			newProject, err = p.loadLocalInclude(p.source, filepath.Join(p.relDir, *inc.Include), inc, i)
 			if err != nil {
 				return err
 			}
@@ -213,7 +286,9 @@ func (p *DeploymentProject) loadIncludes() error {
 			if err != nil {
 				return err
 			}
-			newProject, err = p.loadLocalInclude(NewSource(cloneDir), inc.Git.SubDir, inc)
+			source := NewSource(cloneDir)
+			source.url = inc.Git.Url.String()
+			newProject, err = p.loadLocalInclude(source, inc.Git.SubDir, inc, i)
 			if err != nil {
 				return err
 			}
@@ -226,7 +301,9 @@ func (p *DeploymentProject) loadIncludes() error {
 			if err != nil {
 				return err
 			}
-			newProject, err = p.loadLocalInclude(NewSource(extractedDir), inc.Oci.SubDir, inc)
+			source := NewSource(extractedDir)
+			source.url = inc.Oci.Url
+			newProject, err = p.loadLocalInclude(source, inc.Oci.SubDir, inc, i)
 			if err != nil {
 				return err
 			}
@@ -240,8 +317,11 @@ func (p *DeploymentProject) loadIncludes() error {
 	return nil
 }
 
-func (p *DeploymentProject) loadLocalInclude(source Source, incDir string, inc *types.DeploymentItemConfig) (*DeploymentProject, error) {
+func (p *DeploymentProject) loadLocalInclude(source Source, incDir string, inc *types.DeploymentItemConfig, incIndex int) (*DeploymentProject, error) {
 	varsCtx := vars.NewVarsCtx(p.VarsCtx.J2)
+	varsCtx.J2Opts = p.VarsCtx.J2Opts
+	varsCtx.Sensitive = p.VarsCtx.Sensitive
+	origin := fmt.Sprintf("%s deployments[%d]", p.provenanceOrigin(), incIndex)
 
 	libraryFile := yaml.FixPathExt(filepath.Join(source.dir, incDir, ".kluctl-library.yaml"))
 	if yaml.Exists(libraryFile) {
@@ -265,12 +345,13 @@ func (p *DeploymentProject) loadLocalInclude(source Source, incDir string, inc *
 		if err != nil {
 			return nil, err
 		}
+		p.ctx.VarsLoader.RecordProvenance(origin+".args", "library args", varsCtx.Vars, args, "args")
 		varsCtx.UpdateChild("args", args)
 	} else {
 		varsCtx = p.VarsCtx.Copy()
 	}
 
-	err := p.loadVarsList(varsCtx, inc.Vars)
+	err := p.loadVarsList(varsCtx, inc.Vars, origin+".vars")
 	if err != nil {
 		return nil, err
 	}
@@ -283,6 +364,22 @@ func (p *DeploymentProject) loadLocalInclude(source Source, incDir string, inc *
 	return newProject, nil
 }
 
+// GetDir returns the absolute directory of the project
+func (p *DeploymentProject) GetDir() string {
+	return p.absDir
+}
+
+// GetDescription returns the path of the deployment.yml, followed by the git or oci url if it was included from
+// another source
+func (p *DeploymentProject) GetDescription() string {
+	return p.traceChain[len(p.traceChain)-1]
+}
+
+// GetIncludes returns the included projects that were loaded, keyed by the index inside 'deployments'
+func (p *DeploymentProject) GetIncludes() map[int]*DeploymentProject {
+	return p.includes
+}
+
 func (p *DeploymentProject) getRenderedOutputPattern() string {
 	for _, x := range p.getParents() {
 		if x.p.Config.SealedSecrets != nil && x.p.Config.SealedSecrets.OutputPattern != nil {
@@ -0,0 +1,192 @@
+package deployment
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/google/go-jsonnet"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	securefs "github.com/kluctl/kluctl/v2/pkg/utils/flux_utils/kustomize/filesys"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"io/fs"
+	"path/filepath"
+	"sigs.k8s.io/kustomize/kyaml/filesys"
+	"strings"
+)
+
+// jsonnetDefaultTag returns the tag used for jsonnet items without explicit tags, which is the directory of the main
+// file or the main file itself if it is located in the deployment project's root
+func jsonnetDefaultTag(main string) string {
+	dir := filepath.Dir(main)
+	if dir == "." {
+		return strings.TrimSuffix(filepath.Base(main), filepath.Ext(main))
+	}
+	return filepath.Base(dir)
+}
+
+func (p *DeploymentProject) checkJsonnetPaths(c *types.JsonnetItemConfig) error {
+	main := filepath.Join(p.absDir, c.Main)
+	if !isInDir(p.source.dir, main) {
+		return fmt.Errorf("jsonnet main file is not part of the deployment project: %s", c.Main)
+	}
+	if !utils.IsFile(main) {
+		return fmt.Errorf("jsonnet main file does not exist: %s", c.Main)
+	}
+	for _, l := range c.Libs {
+		if !isInDir(p.source.dir, filepath.Join(p.absDir, l)) {
+			return fmt.Errorf("jsonnet library path is not part of the deployment project: %s", l)
+		}
+	}
+	return nil
+}
+
+// jsonnetImporter resolves imports relative to the importing file first and then inside the library paths. All paths
+// are relative to root and files are read through a secure fs, so that imports can not leave the project, not even
+// via symlinks.
+type jsonnetImporter struct {
+	root  string
+	fs    filesys.FileSystem
+	libs  []string
+	cache map[string]*jsonnetImporterEntry
+}
+
+type jsonnetImporterEntry struct {
+	contents jsonnet.Contents
+	exists   bool
+}
+
+func newJsonnetImporter(root string, libs []string) (*jsonnetImporter, error) {
+	fs, err := securefs.MakeFsOnDiskSecureBuild(root)
+	if err != nil {
+		return nil, err
+	}
+	return &jsonnetImporter{
+		root:  root,
+		fs:    fs,
+		libs:  libs,
+		cache: map[string]*jsonnetImporterEntry{},
+	}, nil
+}
+
+func (i *jsonnetImporter) tryPath(dir string, importedPath string) (bool, jsonnet.Contents, string, error) {
+	rel := filepath.Join(dir, importedPath)
+	if !filepath.IsLocal(rel) {
+		return false, jsonnet.Contents{}, "", fmt.Errorf("import %s is not part of the project", importedPath)
+	}
+
+	e, ok := i.cache[rel]
+	if !ok {
+		b, err := i.fs.ReadFile(filepath.Join(i.root, rel))
+		if err != nil {
+			if !errors.Is(err, fs.ErrNotExist) {
+				return false, jsonnet.Contents{}, "", err
+			}
+			e = &jsonnetImporterEntry{}
+		} else {
+			e = &jsonnetImporterEntry{
+				contents: jsonnet.MakeContentsRaw(b),
+				exists:   true,
+			}
+		}
+		i.cache[rel] = e
+	}
+	return e.exists, e.contents, rel, nil
+}
+
+func (i *jsonnetImporter) Import(importedFrom, importedPath string) (jsonnet.Contents, string, error) {
+	if filepath.IsAbs(importedPath) {
+		return jsonnet.Contents{}, "", fmt.Errorf("absolute import %s is not allowed", importedPath)
+	}
+
+	found, contents, foundAt, err := i.tryPath(filepath.Dir(importedFrom), importedPath)
+	if err != nil {
+		return jsonnet.Contents{}, "", err
+	}
+	for _, l := range i.libs {
+		if found {
+			break
+		}
+		found, contents, foundAt, err = i.tryPath(l, importedPath)
+		if err != nil {
+			return jsonnet.Contents{}, "", err
+		}
+	}
+	if !found {
+		return jsonnet.Contents{}, "", fmt.Errorf("couldn't open import %q: no match locally or in the jsonnet library paths", importedPath)
+	}
+	return contents, foundAt, nil
+}
+
+func (di *DeploymentItem) buildJsonnetVM() (*jsonnet.VM, error) {
+	c := di.Config.Jsonnet
+
+	var libs []string
+	for _, l := range c.Libs {
+		rel, err := filepath.Rel(di.Project.source.dir, filepath.Join(di.Project.absDir, l))
+		if err != nil {
+			return nil, err
+		}
+		libs = append(libs, rel)
+	}
+	importer, err := newJsonnetImporter(di.Project.source.dir, libs)
+	if err != nil {
+		return nil, err
+	}
+
+	vm := jsonnet.MakeVM()
+	vm.Importer(importer)
+
+	// every top-level variable (e.g. args, target, or custom vars) becomes an external variable
+	for k, v := range di.VarsCtx.Vars.Object {
+		b, err := json.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		vm.ExtCode(k, string(b))
+	}
+	if c.Tlas != nil {
+		for k, v := range c.Tlas.Object {
+			b, err := json.Marshal(v)
+			if err != nil {
+				return nil, err
+			}
+			vm.TLACode(k, string(b))
+		}
+	}
+	return vm, nil
+}
+
+func (di *DeploymentItem) buildJsonnet() error {
+	if di.dir == nil {
+		return nil
+	}
+	if di.Config.OnlyRender {
+		return nil
+	}
+
+	vm, err := di.buildJsonnetVM()
+	if err != nil {
+		return err
+	}
+
+	main, err := filepath.Rel(di.Project.source.dir, filepath.Join(di.Project.absDir, di.Config.Jsonnet.Main))
+	if err != nil {
+		return err
+	}
+	out, err := vm.EvaluateFile(main)
+	if err != nil {
+		return err
+	}
+
+	var v any
+	err = json.Unmarshal([]byte(out), &v)
+	if err != nil {
+		return err
+	}
+
+	di.Objects = nil
+	return collectNestedObjects(v, "$", func(o map[string]any) {
+		di.Objects = append(di.Objects, uo.FromMap(o))
+	})
+}
@@ -0,0 +1,84 @@
+package deployment
+
+import (
+	"github.com/google/go-jsonnet"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestJsonnetDefaultTag(t *testing.T) {
+	assert.Equal(t, "mon", jsonnetDefaultTag("apps/mon/main.jsonnet"))
+	assert.Equal(t, "main", jsonnetDefaultTag("main.jsonnet"))
+}
+
+func TestJsonnetImporter(t *testing.T) {
+	base := t.TempDir()
+	root := filepath.Join(base, "root")
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, "app"), 0o700))
+	assert.NoError(t, os.MkdirAll(filepath.Join(root, "lib"), 0o700))
+	assert.NoError(t, os.WriteFile(filepath.Join(base, "outside.libsonnet"), []byte("{}"), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "app", "local.libsonnet"), []byte("{a: 1}"), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "lib", "lib.libsonnet"), []byte("{b: 2}"), 0o600))
+	assert.NoError(t, os.Symlink(filepath.Join(base, "outside.libsonnet"), filepath.Join(root, "app", "link.libsonnet")))
+
+	i, err := newJsonnetImporter(root, []string{"lib"})
+	assert.NoError(t, err)
+
+	c, foundAt, err := i.Import("app/main.jsonnet", "local.libsonnet")
+	assert.NoError(t, err)
+	assert.Equal(t, "{a: 1}", c.String())
+	assert.Equal(t, filepath.FromSlash("app/local.libsonnet"), foundAt)
+
+	c, foundAt, err = i.Import("app/main.jsonnet", "lib.libsonnet")
+	assert.NoError(t, err)
+	assert.Equal(t, "{b: 2}", c.String())
+	assert.Equal(t, filepath.FromSlash("lib/lib.libsonnet"), foundAt)
+
+	_, _, err = i.Import("app/main.jsonnet", "missing.libsonnet")
+	assert.ErrorContains(t, err, "no match locally or in the jsonnet library paths")
+
+	_, _, err = i.Import("app/main.jsonnet", "../../outside.libsonnet")
+	assert.ErrorContains(t, err, "is not part of the project")
+
+	_, _, err = i.Import("app/main.jsonnet", filepath.Join(base, "outside.libsonnet"))
+	assert.ErrorContains(t, err, "absolute import")
+
+	_, _, err = i.Import("app/main.jsonnet", "link.libsonnet")
+	assert.ErrorContains(t, err, "fs-security-constraint")
+}
+
+func TestJsonnetImporterCachesContents(t *testing.T) {
+	root := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(root, "a.libsonnet"), []byte("1"), 0o600))
+
+	i, err := newJsonnetImporter(root, nil)
+	assert.NoError(t, err)
+
+	vm := jsonnet.MakeVM()
+	vm.Importer(i)
+	out, err := vm.EvaluateAnonymousSnippet("main.jsonnet", "(import 'a.libsonnet') + (import 'a.libsonnet')")
+	assert.NoError(t, err)
+	assert.Equal(t, "2\n", out)
+}
+
+func TestCollectNestedObjects(t *testing.T) {
+	cm := func(name string) map[string]any {
+		return map[string]any{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]any{"name": name}}
+	}
+
+	var names []string
+	err := collectNestedObjects(map[string]any{
+		"b": []any{cm("b1"), nil, cm("b2")},
+		"a": map[string]any{"x": cm("a1")},
+		"c": nil,
+	}, "$", func(o map[string]any) {
+		names = append(names, o["metadata"].(map[string]any)["name"].(string))
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"a1", "b1", "b2"}, names)
+
+	err = collectNestedObjects(map[string]any{"a": []any{"x"}}, "$", func(o map[string]any) {})
+	assert.EqualError(t, err, "unexpected value of type string at $.a[0], expected Kubernetes objects")
+}
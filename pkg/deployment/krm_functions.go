@@ -0,0 +1,392 @@
+package deployment
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	securefs "github.com/kluctl/kluctl/v2/pkg/utils/flux_utils/kustomize/filesys"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/runtimeutil"
+	"sigs.k8s.io/kustomize/kyaml/fn/runtime/starlark"
+	"sigs.k8s.io/kustomize/kyaml/kio"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+	"strings"
+	"time"
+)
+
+// krmExecTimeout limits the runtime of a single exec function
+const krmExecTimeout = 5 * time.Minute
+
+// krmFunction is a KRM function declared in a kustomization's generators/transformers or in deployment.yml
+type krmFunction struct {
+	// origin is used in error messages
+	origin    string
+	generator bool
+	config    *kyaml.RNode
+
+	execPath string
+	execArgs []string
+	// baseDir is the source directory that relative exec paths are resolved against and that exec functions run in
+	baseDir string
+
+	starlarkName    string
+	starlarkProgram string
+}
+
+func isKrmExecAllowed(allowList []string, p string) bool {
+	for _, x := range allowList {
+		if m, err := path.Match(x, p); err == nil && m {
+			return true
+		}
+	}
+	return false
+}
+
+// readProjectFile reads a file through a secure fs, so that symlinks can not be used to leave root
+func readProjectFile(root string, p string) ([]byte, error) {
+	if !isInDir(root, p) {
+		return nil, fmt.Errorf("%s is not part of the project", p)
+	}
+	fs, err := securefs.MakeFsOnDiskSecureBuild(root)
+	if err != nil {
+		return nil, err
+	}
+	return fs.ReadFile(p)
+}
+
+func readKrmFunctionConfigs(b []byte) ([]*kyaml.RNode, error) {
+	return (&kio.ByteReader{
+		Reader:                bytes.NewReader(b),
+		OmitReaderAnnotations: true,
+	}).Read()
+}
+
+// parseKustomizeFunction converts a function config with a config.kubernetes.io/function annotation into a krmFunction.
+// renderedDir is the directory of the file that contains the function config.
+func (di *DeploymentItem) parseKustomizeFunction(origin string, n *kyaml.RNode, spec *runtimeutil.FunctionSpec, renderedDir string, generator bool) (*krmFunction, error) {
+	fn := &krmFunction{
+		origin:    origin,
+		generator: generator,
+		config:    n,
+	}
+
+	rel, err := filepath.Rel(di.RenderedSourceRootDir, renderedDir)
+	if err != nil {
+		return nil, err
+	}
+	fn.baseDir = filepath.Join(di.Project.source.dir, rel)
+
+	switch {
+	case spec.Container.Image != "":
+		return nil, fmt.Errorf("%s: container based KRM functions are not supported", origin)
+	case spec.Starlark.URL != "":
+		return nil, fmt.Errorf("%s: Starlark functions must refer to a local script", origin)
+	case spec.Starlark.Path != "":
+		b, err := readProjectFile(di.RenderedSourceRootDir, filepath.Join(renderedDir, spec.Starlark.Path))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", origin, err)
+		}
+		fn.starlarkName = spec.Starlark.Name
+		fn.starlarkProgram = string(b)
+	case spec.Exec.Path != "":
+		fn.execPath = spec.Exec.Path
+	default:
+		return nil, fmt.Errorf("%s: unsupported KRM function", origin)
+	}
+	return fn, nil
+}
+
+// extractKustomizeFunctions removes all generators and transformers that are KRM functions from the kustomization
+// and returns them, so that they can be run after kustomize has built the objects
+func (di *DeploymentItem) extractKustomizeFunctions(ky *uo.UnstructuredObject) ([]*krmFunction, error) {
+	var fns []*krmFunction
+	for _, field := range []string{"generators", "transformers"} {
+		l, found, err := ky.GetNestedStringList(field)
+		if err != nil || !found {
+			// reported by kustomize
+			continue
+		}
+
+		var keep []any
+		for i, e := range l {
+			origin := fmt.Sprintf("%s[%d]", field, i)
+			var b []byte
+			renderedDir := di.RenderedDir
+			if strings.Contains(e, "\n") {
+				b = []byte(e)
+			} else {
+				p := filepath.Join(di.RenderedDir, e)
+				if filepath.IsAbs(e) || !utils.IsFile(p) {
+					keep = append(keep, e)
+					continue
+				}
+				b, err = readProjectFile(di.RenderedSourceRootDir, p)
+				if err != nil {
+					return nil, err
+				}
+				origin = e
+				renderedDir = filepath.Dir(p)
+			}
+
+			nodes, err := readKrmFunctionConfigs(b)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", origin, err)
+			}
+			var entryFns []*krmFunction
+			for _, n := range nodes {
+				spec, err := runtimeutil.GetFunctionSpec(n)
+				if err != nil {
+					return nil, fmt.Errorf("%s: %w", origin, err)
+				}
+				if spec == nil {
+					continue
+				}
+				fn, err := di.parseKustomizeFunction(origin, n, spec, renderedDir, field == "generators")
+				if err != nil {
+					return nil, err
+				}
+				entryFns = append(entryFns, fn)
+			}
+			if len(entryFns) == 0 {
+				keep = append(keep, e)
+				continue
+			}
+			if len(entryFns) != len(nodes) {
+				return nil, fmt.Errorf("%s: mixing KRM functions and builtin %s in the same entry is not supported", origin, field)
+			}
+			fns = append(fns, entryFns...)
+		}
+
+		if len(keep) == 0 {
+			_ = ky.RemoveNestedField(field)
+		} else if len(keep) != len(l) {
+			err = ky.SetNestedField(keep, field)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+	return fns, nil
+}
+
+// configuredKrmFunctions returns the functions declared in deployment.yml
+func (di *DeploymentItem) configuredKrmFunctions() ([]*krmFunction, error) {
+	var fns []*krmFunction
+	for i, c := range di.Config.Functions {
+		fn := &krmFunction{
+			origin:    fmt.Sprintf("functions[%d]", i),
+			generator: c.Generator,
+			baseDir:   di.Project.absDir,
+		}
+		if c.Config != nil {
+			n, err := kyaml.FromMap(c.Config.Object)
+			if err != nil {
+				return nil, err
+			}
+			fn.config = n
+		}
+		if c.Starlark != nil {
+			b, err := readProjectFile(di.Project.source.dir, filepath.Join(di.Project.absDir, c.Starlark.Path))
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", fn.origin, err)
+			}
+			fn.starlarkName = c.Starlark.Path
+			fn.starlarkProgram = string(b)
+		} else if c.Exec != nil {
+			fn.execPath = c.Exec.Path
+			fn.execArgs = c.Exec.Args
+		}
+		fns = append(fns, fn)
+	}
+	return fns, nil
+}
+
+// resolveExecPath returns the path of the executable and the path that is matched against the allow-lists. The
+// latter is relative to the project root for executables shipped with the project and absolute for executables found
+// in PATH.
+func (di *DeploymentItem) resolveExecPath(fn *krmFunction) (string, string, error) {
+	p := fn.execPath
+	if !strings.Contains(p, "/") {
+		p, err := exec.LookPath(p)
+		if err != nil {
+			return "", "", err
+		}
+		p, err = filepath.Abs(p)
+		if err != nil {
+			return "", "", err
+		}
+		p, err = filepath.EvalSymlinks(p)
+		if err != nil {
+			return "", "", err
+		}
+		return p, filepath.ToSlash(p), nil
+	}
+
+	if filepath.IsAbs(p) {
+		return "", "", fmt.Errorf("absolute exec path %s is not allowed", p)
+	}
+	if !di.ctx.AllowKrmExecFromProject {
+		return "", "", fmt.Errorf("exec function %s is not allowed, executables from the project must be explicitly enabled via --allow-krm-exec-from-project", fn.execPath)
+	}
+	root, err := filepath.EvalSymlinks(di.Project.source.dir)
+	if err != nil {
+		return "", "", err
+	}
+	// symlinks must not point outside the project, e.g. to a shell
+	p, err = filepath.EvalSymlinks(filepath.Join(fn.baseDir, p))
+	if err != nil {
+		return "", "", err
+	}
+	if !isInDir(root, p) {
+		return "", "", fmt.Errorf("exec path %s is not part of the project", fn.execPath)
+	}
+	rel, err := filepath.Rel(root, p)
+	if err != nil {
+		return "", "", err
+	}
+	return p, filepath.ToSlash(rel), nil
+}
+
+func (di *DeploymentItem) buildExecRunner(fn *krmFunction) (func(reader io.Reader, writer io.Writer) error, error) {
+	p, matchPath, err := di.resolveExecPath(fn)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", fn.origin, err)
+	}
+	if !isKrmExecAllowed(di.ctx.KrmExecAllowList, matchPath) || !isKrmExecAllowed(di.ctx.AllowKrmExec, matchPath) {
+		name := fn.execPath
+		if matchPath != fn.execPath {
+			name = fmt.Sprintf("%s (resolved to %s)", fn.execPath, matchPath)
+		}
+		return nil, fmt.Errorf("%s: exec function %s is not allowed, it must be listed in krmFunctions.execAllowList and allowed via --allow-krm-exec", fn.origin, name)
+	}
+
+	return func(reader io.Reader, writer io.Writer) error {
+		ctx, cancel := context.WithTimeout(di.ctx.Ctx, krmExecTimeout)
+		defer cancel()
+
+		stderr := bytes.NewBuffer(nil)
+		cmd := exec.CommandContext(ctx, p, fn.execArgs...)
+		cmd.Dir = fn.baseDir
+		// the environment of kluctl (or the controller) might contain credentials, so only PATH is passed
+		cmd.Env = []string{"PATH=" + os.Getenv("PATH")}
+		cmd.Stdin = reader
+		cmd.Stdout = writer
+		cmd.Stderr = stderr
+		err := cmd.Run()
+		if err != nil {
+			return fmt.Errorf("%w: %s", err, strings.TrimSpace(stderr.String()))
+		}
+		return nil
+	}, nil
+}
+
+func (di *DeploymentItem) buildKrmFunctionFilter(fn *krmFunction) (kio.Filter, error) {
+	ff := runtimeutil.FunctionFilter{
+		FunctionConfig: fn.config,
+		GlobalScope:    true,
+	}
+	if fn.starlarkProgram != "" {
+		return &starlark.Filter{
+			Name:           fn.starlarkName,
+			Program:        fn.starlarkProgram,
+			FunctionFilter: ff,
+		}, nil
+	}
+	run, err := di.buildExecRunner(fn)
+	if err != nil {
+		return nil, err
+	}
+	ff.Run = run
+	return &ff, nil
+}
+
+// cleanupKrmFunctionOutput removes annotations that are added by the function runtime and drops local configs
+func cleanupKrmFunctionOutput(nodes []*kyaml.RNode) ([]*kyaml.RNode, error) {
+	var ret []*kyaml.RNode
+	for _, n := range nodes {
+		a := n.GetAnnotations()
+		if a["config.kubernetes.io/local-config"] == "true" {
+			continue
+		}
+		for k := range a {
+			if strings.HasPrefix(k, "internal.config.kubernetes.io/") || k == "config.kubernetes.io/path" ||
+				k == "config.kubernetes.io/index" || k == "config.k8s.io/id" {
+				delete(a, k)
+			}
+		}
+		err := n.SetAnnotations(a)
+		if err != nil {
+			return nil, err
+		}
+		ret = append(ret, n)
+	}
+	return ret, nil
+}
+
+// runKrmFunctions runs the functions in order. Generators receive no input and their output is appended to the
+// objects, transformers receive all objects and their output replaces the objects.
+func (di *DeploymentItem) runKrmFunctions(fns []*krmFunction) error {
+	if len(fns) == 0 {
+		return nil
+	}
+
+	var nodes []*kyaml.RNode
+	for _, o := range di.Objects {
+		n, err := kyaml.FromMap(o.Object)
+		if err != nil {
+			return err
+		}
+		nodes = append(nodes, n)
+	}
+
+	for _, fn := range fns {
+		f, err := di.buildKrmFunctionFilter(fn)
+		if err != nil {
+			return err
+		}
+		var input []*kyaml.RNode
+		if !fn.generator {
+			input = nodes
+		}
+		out, err := f.Filter(input)
+		if err != nil {
+			return fmt.Errorf("%s: %w", fn.origin, err)
+		}
+		out, err = cleanupKrmFunctionOutput(out)
+		if err != nil {
+			return err
+		}
+		if fn.generator {
+			nodes = append(nodes, out...)
+		} else {
+			nodes = out
+		}
+	}
+
+	di.Objects = nil
+	for _, n := range nodes {
+		m, err := n.Map()
+		if err != nil {
+			return err
+		}
+		di.Objects = append(di.Objects, uo.FromMap(m))
+	}
+	return nil
+}
+
+func (di *DeploymentItem) runConfiguredKrmFunctions() error {
+	if di.dir == nil || di.renderCacheHit || di.Config.OnlyRender {
+		return nil
+	}
+	fns, err := di.configuredKrmFunctions()
+	if err != nil {
+		return err
+	}
+	return di.runKrmFunctions(fns)
+}
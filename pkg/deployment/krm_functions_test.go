@@ -0,0 +1,263 @@
+package deployment
+
+import (
+	"context"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+func newTestKrmItem(t *testing.T, files map[string]string, fns []types.KrmFunctionConfig) *DeploymentItem {
+	root := t.TempDir()
+	for n, s := range files {
+		p := filepath.Join(root, n)
+		assert.NoError(t, os.MkdirAll(filepath.Dir(p), 0o700))
+		assert.NoError(t, os.WriteFile(p, []byte(s), 0o700))
+	}
+	dir := filepath.Join(root, "app")
+	return &DeploymentItem{
+		ctx: SharedContext{Ctx: context.Background()},
+		Project: &DeploymentProject{
+			source: NewSource(root),
+			absDir: root,
+		},
+		Config:                &types.DeploymentItemConfig{Functions: fns},
+		dir:                   &dir,
+		RenderedSourceRootDir: root,
+		RenderedDir:           dir,
+		Objects: []*uo.UnstructuredObject{
+			uo.FromMap(map[string]any{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   map[string]any{"name": "cm"},
+			}),
+		},
+	}
+}
+
+const testStarlarkFunction = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: set-team
+  annotations:
+    config.kubernetes.io/function: |
+      starlark:
+        path: set-team.star
+data:
+  team: platform
+`
+
+const testStarlarkScript = `def run(items, team):
+    for r in items:
+        r["metadata"]["annotations"] = {"team": team}
+
+run(ctx.resource_list["items"], ctx.resource_list["functionConfig"]["data"]["team"])
+`
+
+func TestExtractKustomizeFunctions(t *testing.T) {
+	di := newTestKrmItem(t, map[string]string{
+		"app/fn.yaml":       testStarlarkFunction,
+		"app/set-team.star": testStarlarkScript,
+		"app/labels.yaml":   "apiVersion: builtin\nkind: LabelTransformer\nmetadata:\n  name: labels\n",
+	}, nil)
+
+	ky := uo.FromMap(map[string]any{
+		"resources":    []any{"cm.yaml"},
+		"transformers": []any{"labels.yaml", "fn.yaml"},
+		"generators":   []any{testStarlarkFunction},
+	})
+	fns, err := di.extractKustomizeFunctions(ky)
+	assert.NoError(t, err)
+	if assert.Len(t, fns, 2) {
+		assert.Equal(t, "generators[0]", fns[0].origin)
+		assert.True(t, fns[0].generator)
+		assert.Equal(t, testStarlarkScript, fns[0].starlarkProgram)
+		assert.Equal(t, "fn.yaml", fns[1].origin)
+		assert.False(t, fns[1].generator)
+	}
+
+	l, _, _ := ky.GetNestedStringList("transformers")
+	assert.Equal(t, []string{"labels.yaml"}, l)
+	_, found, _ := ky.GetNestedField("generators")
+	assert.False(t, found)
+}
+
+func TestExtractKustomizeFunctionsErrors(t *testing.T) {
+	type testCase struct {
+		name string
+		fn   string
+		err  string
+	}
+	tests := []testCase{
+		{name: "container", fn: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: fn
+  annotations:
+    config.kubernetes.io/function: |
+      container:
+        image: example.com/fn:v1
+`, err: "container based KRM functions are not supported"},
+		{name: "starlark-url", fn: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: fn
+  annotations:
+    config.kubernetes.io/function: |
+      starlark:
+        url: https://example.com/fn.star
+`, err: "Starlark functions must refer to a local script"},
+		{name: "mixed", fn: testStarlarkFunction + "---\napiVersion: builtin\nkind: LabelTransformer\nmetadata:\n  name: labels\n",
+			err: "mixing KRM functions and builtin transformers in the same entry is not supported"},
+		{name: "outside", fn: `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: fn
+  annotations:
+    config.kubernetes.io/function: |
+      starlark:
+        path: ../../fn.star
+`, err: "is not part of the project"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			di := newTestKrmItem(t, map[string]string{
+				"app/fn.yaml":       tc.fn,
+				"app/set-team.star": testStarlarkScript,
+			}, nil)
+			_, err := di.extractKustomizeFunctions(uo.FromMap(map[string]any{
+				"transformers": []any{"fn.yaml"},
+			}))
+			assert.ErrorContains(t, err, tc.err)
+		})
+	}
+}
+
+func TestRunKustomizeFunctions(t *testing.T) {
+	di := newTestKrmItem(t, map[string]string{
+		"app/fn.yaml":       testStarlarkFunction,
+		"app/set-team.star": testStarlarkScript,
+	}, nil)
+
+	fns, err := di.extractKustomizeFunctions(uo.FromMap(map[string]any{
+		"transformers": []any{"fn.yaml"},
+	}))
+	assert.NoError(t, err)
+	assert.NoError(t, di.runKrmFunctions(fns))
+	if assert.Len(t, di.Objects, 1) {
+		assert.Equal(t, map[string]string{"team": "platform"}, di.Objects[0].GetK8sAnnotations())
+	}
+}
+
+func TestRunConfiguredKrmFunctions(t *testing.T) {
+	di := newTestKrmItem(t, map[string]string{
+		"gen.star": `ctx.resource_list["items"].append({"apiVersion": "v1", "kind": "ConfigMap", "metadata": {"name": ctx.resource_list["functionConfig"]["data"]["name"]}})
+`,
+		"fns/annotate.sh": "#!/bin/sh\nsed \"s/^  metadata:\\$/  metadata:\\n    labels: {team: $1}/\"\n",
+	}, []types.KrmFunctionConfig{
+		{
+			Starlark:  &types.KrmStarlarkFunction{Path: "gen.star"},
+			Generator: true,
+			Config: uo.FromMap(map[string]any{
+				"apiVersion": "v1",
+				"kind":       "ConfigMap",
+				"metadata":   map[string]any{"name": "gen-config"},
+				"data":       map[string]any{"name": "generated"},
+			}),
+		},
+		{
+			Exec: &types.KrmExecFunction{Path: "fns/annotate.sh", Args: []string{"a"}},
+		},
+	})
+
+	err := di.runConfiguredKrmFunctions()
+	assert.ErrorContains(t, err, "exec function fns/annotate.sh is not allowed, executables from the project must be explicitly enabled via --allow-krm-exec-from-project")
+
+	di.ctx.AllowKrmExecFromProject = true
+	err = di.runConfiguredKrmFunctions()
+	assert.ErrorContains(t, err, "exec function fns/annotate.sh is not allowed, it must be listed")
+
+	di.ctx.KrmExecAllowList = []string{"fns/*"}
+	err = di.runConfiguredKrmFunctions()
+	assert.ErrorContains(t, err, "exec function fns/annotate.sh is not allowed")
+
+	di.ctx.AllowKrmExec = []string{"fns/annotate.sh"}
+	assert.NoError(t, di.runConfiguredKrmFunctions())
+	if assert.Len(t, di.Objects, 2) {
+		assert.Equal(t, "cm", di.Objects[0].GetK8sName())
+		assert.Equal(t, "generated", di.Objects[1].GetK8sName())
+		for _, o := range di.Objects {
+			assert.Equal(t, map[string]string{"team": "a"}, o.GetK8sLabels())
+			assert.Empty(t, o.GetK8sAnnotations())
+		}
+	}
+}
+
+func TestKrmExecResolvedPath(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not found")
+	}
+	sh, err = filepath.EvalSymlinks(sh)
+	assert.NoError(t, err)
+
+	di := newTestKrmItem(t, nil, nil)
+	fn := &krmFunction{origin: "test", execPath: "sh", baseDir: di.Project.absDir}
+
+	// names are not matched, only the resolved path
+	di.ctx.KrmExecAllowList = []string{"sh"}
+	di.ctx.AllowKrmExec = []string{"sh"}
+	_, err = di.buildExecRunner(fn)
+	assert.ErrorContains(t, err, "is not allowed")
+
+	di.ctx.KrmExecAllowList = []string{filepath.ToSlash(sh)}
+	di.ctx.AllowKrmExec = []string{filepath.ToSlash(sh)}
+	_, err = di.buildExecRunner(fn)
+	assert.NoError(t, err)
+}
+
+func TestKrmExecSymlinkOutsideProject(t *testing.T) {
+	sh, err := exec.LookPath("sh")
+	if err != nil {
+		t.Skip("sh not found")
+	}
+
+	di := newTestKrmItem(t, nil, nil)
+	di.ctx.AllowKrmExecFromProject = true
+	di.ctx.KrmExecAllowList = []string{"*"}
+	di.ctx.AllowKrmExec = []string{"*"}
+	assert.NoError(t, os.Symlink(sh, filepath.Join(di.Project.absDir, "fn")))
+
+	_, err = di.buildExecRunner(&krmFunction{origin: "test", execPath: "./fn", baseDir: di.Project.absDir})
+	assert.ErrorContains(t, err, "exec path ./fn is not part of the project")
+}
+
+func TestKrmExecEnv(t *testing.T) {
+	t.Setenv("KLUCTL_TEST_SECRET", "secret")
+
+	di := newTestKrmItem(t, map[string]string{
+		"fns/env.sh": "#!/bin/sh\nsed \"s/^  metadata:\\$/  metadata:\\n    labels: {secret: \\\"$KLUCTL_TEST_SECRET\\\"}/\"\n",
+	}, []types.KrmFunctionConfig{
+		{Exec: &types.KrmExecFunction{Path: "fns/env.sh"}},
+	})
+	di.ctx.AllowKrmExecFromProject = true
+	di.ctx.KrmExecAllowList = []string{"fns/*"}
+	di.ctx.AllowKrmExec = []string{"fns/*"}
+
+	assert.NoError(t, di.runConfiguredKrmFunctions())
+	if assert.Len(t, di.Objects, 1) {
+		assert.Equal(t, map[string]string{"secret": ""}, di.Objects[0].GetK8sLabels())
+	}
+}
+
+func TestIsKrmExecAllowed(t *testing.T) {
+	assert.False(t, isKrmExecAllowed(nil, "fns/a.sh"))
+	assert.True(t, isKrmExecAllowed([]string{"fns/*"}, "fns/a.sh"))
+	assert.False(t, isKrmExecAllowed([]string{"fns/*"}, "fns/sub/a.sh"))
+	assert.True(t, isKrmExecAllowed([]string{"kpt-fn"}, "kpt-fn"))
+}
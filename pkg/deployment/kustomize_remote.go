@@ -0,0 +1,399 @@
+package deployment
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/kluctl/kluctl/v2/pkg/yaml"
+	cp "github.com/otiai10/copy"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// remotes are copied into this directory inside the rendered source root, so that kustomize can access them through
+// the secure fs
+const kustomizeRemotesDir = ".kluctl-remote"
+
+var (
+	scpUrlRegex    = regexp.MustCompile(`^([\w.-]+)@([\w.-]+):(.+)$`)
+	gitCommitRegex = regexp.MustCompile(`^[0-9a-f]{40}$`)
+)
+
+// kustomizeRemote is a parsed remote reference from a kustomization's resources, components or bases
+type kustomizeRemote struct {
+	// repoUrl is the git or OCI url passed to the repo cache
+	repoUrl string
+	host    string
+	subDir  string
+	ref     url.Values
+
+	oci bool
+	// file is true for plain http(s) urls that point to a single file, these are loaded by kustomize itself
+	file bool
+}
+
+// splitRepoPath splits the path of a remote url into the repository and the sub directory, following the same rules
+// as kustomize
+func splitRepoPath(host string, p string) (string, string, bool) {
+	p = strings.TrimPrefix(p, "/")
+	if i := strings.Index(p, "//"); i != -1 {
+		return p[:i], p[i+2:], true
+	}
+	if i := strings.Index(p, ".git/"); i != -1 {
+		return p[:i+4], p[i+5:], true
+	}
+	if strings.HasSuffix(p, ".git") {
+		return p, "", true
+	}
+	if i := strings.Index(p, "_git/"); i != -1 {
+		// Azure DevOps
+		s := strings.SplitN(p[i+5:], "/", 2)
+		if len(s) == 2 {
+			return p[:i+5] + s[0], s[1], true
+		}
+		return p, "", true
+	}
+	switch host {
+	case "github.com", "gitlab.com", "bitbucket.org":
+		s := strings.SplitN(p, "/", 3)
+		if len(s) < 2 {
+			return "", "", false
+		}
+		if len(s) == 3 {
+			return s[0] + "/" + s[1], s[2], true
+		}
+		return p, "", true
+	}
+	return "", "", false
+}
+
+// parseKustomizeRemote parses remote references as supported by kustomize, e.g.
+// https://github.com/org/repo//dir?ref=v1.0.0, github.com/org/repo/dir or git@github.com:org/repo.git//dir.
+// Additionally, OCI artifacts can be referenced via oci://registry/repo//dir?tag=v1.0.0.
+// It returns nil if s is not a remote reference.
+func parseKustomizeRemote(s string) (*kustomizeRemote, error) {
+	var q url.Values
+	if i := strings.Index(s, "?"); i != -1 {
+		var err error
+		q, err = url.ParseQuery(s[i+1:])
+		if err != nil {
+			return nil, fmt.Errorf("invalid remote %s: %w", s, err)
+		}
+		s = s[:i]
+	}
+
+	if strings.HasPrefix(s, "oci://") {
+		repo, subDir, _ := strings.Cut(strings.TrimPrefix(s, "oci://"), "//")
+		host, _, _ := strings.Cut(repo, "/")
+		return &kustomizeRemote{
+			repoUrl: "oci://" + repo,
+			host:    host,
+			subDir:  subDir,
+			ref:     q,
+			oci:     true,
+		}, nil
+	}
+
+	s = strings.TrimPrefix(s, "git::")
+	if strings.HasPrefix(s, "file://") {
+		return nil, fmt.Errorf("file:// remotes are not supported: %s", s)
+	}
+
+	if m := scpUrlRegex.FindStringSubmatch(s); m != nil && !strings.Contains(s, "://") {
+		repo, subDir, ok := splitRepoPath(m[2], m[3])
+		if !ok {
+			repo = m[3]
+		}
+		return &kustomizeRemote{
+			repoUrl: fmt.Sprintf("%s@%s:%s", m[1], m[2], repo),
+			host:    m[2],
+			subDir:  subDir,
+			ref:     q,
+		}, nil
+	}
+
+	shorthand := false
+	if !strings.Contains(s, "://") {
+		// kustomize treats github.com/org/repo and similar as https urls
+		first, _, found := strings.Cut(s, "/")
+		if !found || !strings.Contains(first, ".") || strings.HasPrefix(first, ".") {
+			return nil, nil
+		}
+		s = "https://" + s
+		shorthand = true
+	}
+
+	u, err := url.Parse(s)
+	if err != nil {
+		return nil, fmt.Errorf("invalid remote %s: %w", s, err)
+	}
+	switch u.Scheme {
+	case "https", "http", "ssh":
+	default:
+		return nil, fmt.Errorf("unsupported scheme in remote %s", s)
+	}
+
+	r := &kustomizeRemote{
+		host: u.Hostname(),
+		ref:  q,
+	}
+	repo, subDir, ok := splitRepoPath(r.host, u.Path)
+	if !ok {
+		if shorthand {
+			// probably a local path that does not exist, let kustomize report it
+			return nil, nil
+		}
+		if u.Scheme != "ssh" {
+			r.file = true
+			return r, nil
+		}
+		repo = strings.TrimPrefix(u.Path, "/")
+	}
+	u.Path = "/" + repo
+	u.RawPath = ""
+	r.repoUrl = u.String()
+	r.subDir = subDir
+	return r, nil
+}
+
+func isKustomizeRemoteHostAllowed(allowedHosts []string, host string) bool {
+	if allowedHosts == nil {
+		return true
+	}
+	for _, p := range allowedHosts {
+		if m, err := path.Match(p, host); err == nil && m {
+			return true
+		}
+	}
+	return false
+}
+
+// checkRemoteHostAllowed is used for remote kustomize resources and manifest urls
+func (di *DeploymentItem) checkRemoteHostAllowed(host string) error {
+	if !isKustomizeRemoteHostAllowed(di.ctx.KustomizeRemoteHosts, host) {
+		if len(di.ctx.KustomizeRemoteHosts) == 0 && di.ctx.AllowKustomizeRemoteHosts != nil {
+			return fmt.Errorf("host %s is not listed in kustomize.remoteHosts. Please note that the controller requires kustomize.remoteHosts to be set explicitly", host)
+		}
+		return fmt.Errorf("host %s is not listed in kustomize.remoteHosts", host)
+	}
+	if !isKustomizeRemoteHostAllowed(di.ctx.AllowKustomizeRemoteHosts, host) {
+		return fmt.Errorf("host %s is not allowed via --allow-kustomize-remote-hosts", host)
+	}
+	return nil
+}
+
+type fetchedKustomizeRemote struct {
+	dir    string
+	ref    string
+	commit string
+}
+
+// kustomizeRemoteResolver fetches remotes through the git and oci repo caches, so that authentication, local
+// overrides and source overrides are respected. Each remote is only fetched and copied once per rendered source.
+type kustomizeRemoteResolver struct {
+	ctx     *SharedContext
+	fetched map[string]*fetchedKustomizeRemote
+}
+
+func newKustomizeRemoteResolver(ctx *SharedContext) *kustomizeRemoteResolver {
+	return &kustomizeRemoteResolver{
+		ctx:     ctx,
+		fetched: map[string]*fetchedKustomizeRemote{},
+	}
+}
+
+func (r *kustomizeRemoteResolver) fetchDir(rr *kustomizeRemote) (string, string, string, error) {
+	if rr.oci {
+		if r.ctx.OciRP == nil {
+			return "", "", "", fmt.Errorf("oci remotes are not supported here")
+		}
+		ref := &types.OciRef{
+			Tag:    rr.ref.Get("tag"),
+			Digest: rr.ref.Get("digest"),
+			SemVer: rr.ref.Get("semver"),
+		}
+		oe, err := r.ctx.OciRP.GetEntry(rr.repoUrl)
+		if err != nil {
+			return "", "", "", err
+		}
+		dir, info, err := oe.GetExtractedDir(ref)
+		if err != nil {
+			return "", "", "", err
+		}
+		return dir, ref.String(), info.CheckedOutCommit, nil
+	}
+
+	if r.ctx.GitRP == nil {
+		return "", "", "", fmt.Errorf("git remotes are not supported here")
+	}
+	var ref *types.GitRef
+	refStr := rr.ref.Get("ref")
+	if refStr == "" {
+		refStr = rr.ref.Get("version")
+	}
+	if gitCommitRegex.MatchString(refStr) {
+		ref = &types.GitRef{Commit: refStr}
+	} else if refStr != "" {
+		ref = &types.GitRef{Ref: refStr}
+	}
+	ge, err := r.ctx.GitRP.GetEntry(rr.repoUrl)
+	if err != nil {
+		return "", "", "", err
+	}
+	dir, info, err := ge.GetClonedDir(ref)
+	if err != nil {
+		return "", "", "", err
+	}
+	return dir, info.CheckedOutRef.String(), info.CheckedOutCommit, nil
+}
+
+func (r *kustomizeRemoteResolver) fetch(renderedSourceRootDir string, rr *kustomizeRemote) (*fetchedKustomizeRemote, error) {
+	key := fmt.Sprintf("%s\n%s\n%s\n%t", renderedSourceRootDir, rr.repoUrl, rr.ref.Encode(), rr.oci)
+	if f, ok := r.fetched[key]; ok {
+		return f, nil
+	}
+
+	dir, ref, commit, err := r.fetchDir(rr)
+	if err != nil {
+		return nil, err
+	}
+
+	h := sha256.Sum256([]byte(key))
+	targetDir := filepath.Join(renderedSourceRootDir, kustomizeRemotesDir, hex.EncodeToString(h[:])[:16])
+	err = cp.Copy(dir, targetDir, cp.Options{
+		Skip: func(srcinfo os.FileInfo, src, dest string) (bool, error) {
+			return srcinfo.IsDir() && srcinfo.Name() == ".git", nil
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	f := &fetchedKustomizeRemote{
+		dir:    targetDir,
+		ref:    ref,
+		commit: commit,
+	}
+	r.fetched[key] = f
+	return f, nil
+}
+
+func (di *DeploymentItem) resolveKustomizeRemotes(r *kustomizeRemoteResolver) error {
+	if di.dir == nil || di.renderCacheHit || di.Config.OnlyRender || !di.usesKustomize() {
+		return nil
+	}
+	di.Config.RenderedRemoteResources = nil
+	return di.resolveKustomizeRemotesInDir(r, di.RenderedDir, map[string]bool{})
+}
+
+// resolveKustomizeRemotesInDir replaces all remote references of the kustomization found in dir with relative paths
+// to the fetched copies of the remotes. Local and fetched kustomizations are processed recursively.
+func (di *DeploymentItem) resolveKustomizeRemotesInDir(r *kustomizeRemoteResolver, dir string, visited map[string]bool) error {
+	if visited[dir] {
+		return nil
+	}
+	visited[dir] = true
+
+	p := yaml.FixPathExt(filepath.Join(dir, "kustomization.yml"))
+	if !utils.IsFile(p) {
+		return nil
+	}
+	ky, err := uo.FromFile(p)
+	if err != nil {
+		// reported by buildKustomize
+		return nil
+	}
+
+	changed := false
+	for _, field := range []string{"resources", "components", "bases"} {
+		l, _, err := ky.GetNestedStringList(field)
+		if err != nil {
+			continue
+		}
+		fieldChanged := false
+		for i, e := range l {
+			if !filepath.IsAbs(e) && utils.Exists(filepath.Join(dir, e)) {
+				local := filepath.Clean(filepath.Join(dir, e))
+				if utils.IsDirectory(local) && isInDir(di.RenderedSourceRootDir, local) {
+					err = di.resolveKustomizeRemotesInDir(r, local, visited)
+					if err != nil {
+						return err
+					}
+				}
+				continue
+			}
+
+			rr, err := parseKustomizeRemote(e)
+			if err != nil {
+				return err
+			}
+			if rr == nil {
+				continue
+			}
+			if err := di.checkRemoteHostAllowed(rr.host); err != nil {
+				return fmt.Errorf("remote %s is not allowed, %w", e, err)
+			}
+			if rr.file {
+				continue
+			}
+
+			f, err := r.fetch(di.RenderedSourceRootDir, rr)
+			if err != nil {
+				return fmt.Errorf("failed to fetch remote %s: %w", e, err)
+			}
+			target := filepath.Join(f.dir, rr.subDir)
+			if !isInDir(f.dir, target) {
+				return fmt.Errorf("remote %s points outside of its repository", e)
+			}
+			if !utils.Exists(target) {
+				return fmt.Errorf("%s not found in remote %s", rr.subDir, e)
+			}
+			rel, err := filepath.Rel(dir, target)
+			if err != nil {
+				return err
+			}
+			l[i] = filepath.ToSlash(rel)
+			fieldChanged = true
+			di.addRenderedRemoteResource(types.RemoteResourceInfo{
+				Url:    e,
+				Ref:    f.ref,
+				Commit: f.commit,
+			})
+
+			if utils.IsDirectory(target) {
+				err = di.resolveKustomizeRemotesInDir(r, target, visited)
+				if err != nil {
+					return err
+				}
+			}
+		}
+		if fieldChanged {
+			err = ky.SetNestedField(l, field)
+			if err != nil {
+				return err
+			}
+			changed = true
+		}
+	}
+
+	if !changed {
+		return nil
+	}
+	return yaml.WriteYamlFile(p, ky)
+}
+
+func (di *DeploymentItem) addRenderedRemoteResource(info types.RemoteResourceInfo) {
+	for _, x := range di.Config.RenderedRemoteResources {
+		if x == info {
+			return
+		}
+	}
+	di.Config.RenderedRemoteResources = append(di.Config.RenderedRemoteResources, info)
+}
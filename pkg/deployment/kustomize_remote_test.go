@@ -0,0 +1,89 @@
+package deployment
+
+import (
+	"github.com/stretchr/testify/assert"
+	"net/url"
+	"testing"
+)
+
+func TestParseKustomizeRemote(t *testing.T) {
+	type testCase struct {
+		s        string
+		expected *kustomizeRemote
+		err      string
+	}
+	tests := []testCase{
+		{s: "base"},
+		{s: "../base"},
+		{s: "configmap.yaml"},
+		{s: "missing.d/x.yaml"},
+		{s: "https://github.com/org/repo//dir/sub?ref=v1", expected: &kustomizeRemote{
+			repoUrl: "https://github.com/org/repo", host: "github.com", subDir: "dir/sub", ref: url.Values{"ref": {"v1"}},
+		}},
+		{s: "github.com/org/repo/dir?version=v1", expected: &kustomizeRemote{
+			repoUrl: "https://github.com/org/repo", host: "github.com", subDir: "dir", ref: url.Values{"version": {"v1"}},
+		}},
+		{s: "git::https://git.example.com/org/repo.git/dir", expected: &kustomizeRemote{
+			repoUrl: "https://git.example.com/org/repo.git", host: "git.example.com", subDir: "dir",
+		}},
+		{s: "https://dev.azure.com/org/project/_git/repo/dir", expected: &kustomizeRemote{
+			repoUrl: "https://dev.azure.com/org/project/_git/repo", host: "dev.azure.com", subDir: "dir",
+		}},
+		{s: "git@github.com:org/repo.git//dir", expected: &kustomizeRemote{
+			repoUrl: "git@github.com:org/repo.git", host: "github.com", subDir: "dir",
+		}},
+		{s: "ssh://git@git.example.com:2222/org/repo", expected: &kustomizeRemote{
+			repoUrl: "ssh://git@git.example.com:2222/org/repo", host: "git.example.com",
+		}},
+		{s: "oci://ghcr.io/org/repo//dir?tag=v1", expected: &kustomizeRemote{
+			repoUrl: "oci://ghcr.io/org/repo", host: "ghcr.io", subDir: "dir", ref: url.Values{"tag": {"v1"}}, oci: true,
+		}},
+		{s: "https://raw.example.com/manifests/cm.yaml", expected: &kustomizeRemote{
+			host: "raw.example.com", file: true,
+		}},
+		{s: "file:///tmp/repo//dir", err: "file:// remotes are not supported"},
+		{s: "ftp://example.com/org/repo", err: "unsupported scheme"},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.s, func(t *testing.T) {
+			r, err := parseKustomizeRemote(tc.s)
+			if tc.err != "" {
+				assert.ErrorContains(t, err, tc.err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, tc.expected, r)
+		})
+	}
+}
+
+func TestIsKustomizeRemoteHostAllowed(t *testing.T) {
+	assert.True(t, isKustomizeRemoteHostAllowed(nil, "github.com"))
+	assert.False(t, isKustomizeRemoteHostAllowed([]string{}, "github.com"))
+	assert.True(t, isKustomizeRemoteHostAllowed([]string{"gitlab.com", "github.com"}, "github.com"))
+	assert.True(t, isKustomizeRemoteHostAllowed([]string{"*.example.com"}, "git.example.com"))
+	assert.False(t, isKustomizeRemoteHostAllowed([]string{"*.example.com"}, "example.com"))
+}
+
+func TestCheckRemoteHostAllowed(t *testing.T) {
+	di := &DeploymentItem{}
+	assert.NoError(t, di.checkRemoteHostAllowed("github.com"))
+
+	di.ctx.KustomizeRemoteHosts = []string{}
+	err := di.checkRemoteHostAllowed("github.com")
+	assert.EqualError(t, err, "host github.com is not listed in kustomize.remoteHosts")
+
+	// the controller sets both lists
+	di.ctx.AllowKustomizeRemoteHosts = []string{}
+	err = di.checkRemoteHostAllowed("github.com")
+	assert.EqualError(t, err, "host github.com is not listed in kustomize.remoteHosts. Please note that the controller requires kustomize.remoteHosts to be set explicitly")
+
+	di.ctx.KustomizeRemoteHosts = []string{"*"}
+	err = di.checkRemoteHostAllowed("github.com")
+	assert.EqualError(t, err, "host github.com is not allowed via --allow-kustomize-remote-hosts")
+
+	di.ctx.AllowKustomizeRemoteHosts = []string{"github.com"}
+	assert.NoError(t, di.checkRemoteHostAllowed("github.com"))
+	assert.Error(t, di.checkRemoteHostAllowed("internal.example.com"))
+}
@@ -0,0 +1,238 @@
+package deployment
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	securejoin "github.com/cyphar/filepath-securejoin"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/kluctl/kluctl/v2/pkg/yaml"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// manifestFetchTimeout limits the total time of a single manifest download, including reading the body
+const manifestFetchTimeout = 5 * time.Minute
+
+// manifestMaxSize limits the size of downloaded manifests, so that misbehaving servers can't exhaust memory. It is a
+// variable so that tests can lower it.
+var manifestMaxSize int64 = 64 * 1024 * 1024
+
+var manifestHttpClient = &http.Client{
+	Timeout: manifestFetchTimeout,
+}
+
+type manifestFile struct {
+	name    string
+	content []byte
+}
+
+func manifestDefaultTag(c *types.ManifestItemConfig) string {
+	if c.Oci != nil {
+		return path.Base(strings.TrimPrefix(c.Oci.Url, "oci://"))
+	}
+	name := c.Url
+	if u, err := url.Parse(c.Url); err == nil {
+		name = u.Path
+	}
+	name = path.Base(name)
+	return strings.TrimSuffix(name, path.Ext(name))
+}
+
+func manifestCachePath(ctx context.Context, sha256Sum string) string {
+	return filepath.Join(utils.GetCacheDir(ctx), "manifests", sha256Sum)
+}
+
+func sha256Hex(b []byte) string {
+	h := sha256.Sum256(b)
+	return hex.EncodeToString(h[:])
+}
+
+// FetchManifest downloads the manifest found at u and returns its content and SHA256 checksum. If expectedSha256 is
+// set, the checksum is verified and the manifest is cached in the cache dir, so that it is only downloaded once.
+func FetchManifest(ctx context.Context, u string, expectedSha256 string) ([]byte, string, error) {
+	var cachePath string
+	if expectedSha256 != "" {
+		cachePath = manifestCachePath(ctx, expectedSha256)
+		b, err := os.ReadFile(cachePath)
+		if err == nil && sha256Hex(b) == expectedSha256 {
+			return b, expectedSha256, nil
+		}
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, "", err
+	}
+	resp, err := manifestHttpClient.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("downloading manifest %s failed: %w", u, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("downloading manifest %s failed: %s", u, resp.Status)
+	}
+	b, err := io.ReadAll(io.LimitReader(resp.Body, manifestMaxSize+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("downloading manifest %s failed: %w", u, err)
+	}
+	if int64(len(b)) > manifestMaxSize {
+		return nil, "", fmt.Errorf("downloading manifest %s failed: manifest is larger than %d bytes", u, manifestMaxSize)
+	}
+
+	sum := sha256Hex(b)
+	if expectedSha256 == "" {
+		return b, sum, nil
+	}
+	if sum != expectedSha256 {
+		return nil, "", fmt.Errorf("checksum mismatch for manifest %s: expected sha256 %s, got %s", u, expectedSha256, sum)
+	}
+
+	err = os.MkdirAll(filepath.Dir(cachePath), 0o700)
+	if err != nil {
+		return nil, "", err
+	}
+	// write to a temporary file first, so that concurrent readers never see partial files
+	tmp, err := os.CreateTemp(filepath.Dir(cachePath), sum+".tmp-*")
+	if err != nil {
+		return nil, "", err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(b)
+	_ = tmp.Close()
+	if err != nil {
+		return nil, "", err
+	}
+	err = os.Rename(tmp.Name(), cachePath)
+	if err != nil {
+		return nil, "", err
+	}
+	return b, sum, nil
+}
+
+func (di *DeploymentItem) fetchManifestFiles() ([]manifestFile, error) {
+	c := di.Config.Manifest
+	if c.Url != "" {
+		u, err := url.Parse(c.Url)
+		if err != nil {
+			return nil, err
+		}
+		// manifests are fetched like remote kustomize resources, so the same hosts are allowed
+		if err := di.checkRemoteHostAllowed(u.Hostname()); err != nil {
+			return nil, fmt.Errorf("manifest %s is not allowed, %w", c.Url, err)
+		}
+		b, _, err := FetchManifest(di.ctx.Ctx, c.Url, c.Sha256)
+		if err != nil {
+			return nil, err
+		}
+		di.addRenderedRemoteResource(types.RemoteResourceInfo{
+			Url:    c.Url,
+			Commit: "sha256:" + c.Sha256,
+		})
+		name := "manifest.yaml"
+		if path.Base(u.Path) != "/" && path.Base(u.Path) != "." {
+			name = path.Base(u.Path)
+		}
+		return []manifestFile{{name: name, content: b}}, nil
+	}
+
+	oe, err := di.ctx.OciRP.GetEntry(c.Oci.Url)
+	if err != nil {
+		return nil, err
+	}
+	extractedDir, info, err := oe.GetExtractedDir(c.Oci.Ref)
+	if err != nil {
+		return nil, err
+	}
+	dir, err := securejoin.SecureJoin(extractedDir, c.Oci.SubDir)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var files []manifestFile
+	for _, e := range entries {
+		ext := filepath.Ext(e.Name())
+		if e.IsDir() || (ext != ".yaml" && ext != ".yml") {
+			continue
+		}
+		b, err := os.ReadFile(filepath.Join(dir, e.Name()))
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, manifestFile{name: e.Name(), content: b})
+	}
+	if len(files) == 0 {
+		return nil, fmt.Errorf("no .yaml or .yml files found in %s", c.Oci.Url)
+	}
+	di.addRenderedRemoteResource(types.RemoteResourceInfo{
+		Url:    c.Oci.Url,
+		Ref:    info.CheckedOutRef.String(),
+		Commit: info.CheckedOutCommit,
+	})
+	return files, nil
+}
+
+// renderManifest fetches the manifests of the item and writes them into the rendered dir. Unless skipTemplating is
+// set, the manifests are rendered with Jinja2 on the way.
+func (di *DeploymentItem) renderManifest() error {
+	files, err := di.fetchManifestFiles()
+	if err != nil {
+		return err
+	}
+
+	di.manifestFiles = nil
+	for _, f := range files {
+		content := string(f.content)
+		if !di.Config.Manifest.SkipTemplating {
+			content, err = di.VarsCtx.RenderString(content, di.Project.getRenderSearchDirs())
+			if err != nil {
+				return fmt.Errorf("rendering manifest %s failed: %w", f.name, err)
+			}
+		}
+		p := filepath.Join(di.RenderedDir, f.name)
+		err = os.WriteFile(p, []byte(content), 0o600)
+		if err != nil {
+			return err
+		}
+		di.manifestFiles = append(di.manifestFiles, p)
+	}
+	return nil
+}
+
+func (di *DeploymentItem) buildManifest() error {
+	if di.dir == nil {
+		return nil
+	}
+	if di.Config.OnlyRender {
+		return nil
+	}
+
+	di.Objects = nil
+	for _, p := range di.manifestFiles {
+		docs, err := yaml.ReadYamlAllFile(p)
+		if err != nil {
+			return fmt.Errorf("parsing manifest %s failed: %w", filepath.Base(p), err)
+		}
+		for _, d := range docs {
+			m, ok := d.(map[string]any)
+			if !ok {
+				return fmt.Errorf("manifest %s contains a document that is not an object", filepath.Base(p))
+			}
+			di.Objects = append(di.Objects, uo.FromMap(m))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package deployment
+
+import (
+	"context"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+const testManifest = `apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm1
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: cm2
+`
+
+func TestManifestDefaultTag(t *testing.T) {
+	assert.Equal(t, "install", manifestDefaultTag(&types.ManifestItemConfig{Url: "https://github.com/org/repo/releases/download/v1.0.0/install.yaml?x=y"}))
+	assert.Equal(t, "operator", manifestDefaultTag(&types.ManifestItemConfig{Oci: &types.OciProject{Url: "oci://ghcr.io/org/operator"}}))
+}
+
+func TestFetchManifest(t *testing.T) {
+	var requests atomic.Int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path != "/install.yaml" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		_, _ = w.Write([]byte(testManifest))
+	}))
+	defer s.Close()
+
+	ctx := utils.WithCacheDir(context.Background(), t.TempDir())
+	sum := sha256Hex([]byte(testManifest))
+
+	b, actual, err := FetchManifest(ctx, s.URL+"/install.yaml", "")
+	assert.NoError(t, err)
+	assert.Equal(t, testManifest, string(b))
+	assert.Equal(t, sum, actual)
+	assert.NoFileExists(t, manifestCachePath(ctx, sum))
+
+	_, _, err = FetchManifest(ctx, s.URL+"/install.yaml", sha256Hex([]byte("x")))
+	assert.ErrorContains(t, err, "checksum mismatch for manifest")
+	assert.ErrorContains(t, err, "got "+sum)
+
+	_, _, err = FetchManifest(ctx, s.URL+"/missing.yaml", sum)
+	assert.ErrorContains(t, err, "404 Not Found")
+
+	requests.Store(0)
+	for i := 0; i < 2; i++ {
+		b, _, err = FetchManifest(ctx, s.URL+"/install.yaml", sum)
+		assert.NoError(t, err)
+		assert.Equal(t, testManifest, string(b))
+	}
+	assert.Equal(t, int32(1), requests.Load())
+	assert.FileExists(t, manifestCachePath(ctx, sum))
+
+	// corrupted cache entries are downloaded again
+	assert.NoError(t, os.WriteFile(manifestCachePath(ctx, sum), []byte("x"), 0o600))
+	b, _, err = FetchManifest(ctx, s.URL+"/install.yaml", sum)
+	assert.NoError(t, err)
+	assert.Equal(t, testManifest, string(b))
+	assert.Equal(t, int32(2), requests.Load())
+}
+
+func TestFetchManifestMaxSize(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(testManifest))
+	}))
+	defer s.Close()
+
+	oldMaxSize := manifestMaxSize
+	defer func() { manifestMaxSize = oldMaxSize }()
+
+	ctx := utils.WithCacheDir(context.Background(), t.TempDir())
+	manifestMaxSize = int64(len(testManifest))
+	_, _, err := FetchManifest(ctx, s.URL+"/install.yaml", "")
+	assert.NoError(t, err)
+
+	manifestMaxSize = int64(len(testManifest)) - 1
+	_, _, err = FetchManifest(ctx, s.URL+"/install.yaml", "")
+	assert.ErrorContains(t, err, "manifest is larger than")
+}
+
+func TestFetchManifestRemoteHosts(t *testing.T) {
+	var requests atomic.Int32
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		_, _ = w.Write([]byte(testManifest))
+	}))
+	defer s.Close()
+
+	di := &DeploymentItem{
+		ctx: SharedContext{
+			Ctx:                  utils.WithCacheDir(context.Background(), t.TempDir()),
+			KustomizeRemoteHosts: []string{"example.com"},
+		},
+		Config: &types.DeploymentItemConfig{
+			Manifest: &types.ManifestItemConfig{
+				Url:    s.URL + "/install.yaml",
+				Sha256: sha256Hex([]byte(testManifest)),
+			},
+		},
+	}
+	_, err := di.fetchManifestFiles()
+	assert.ErrorContains(t, err, "host 127.0.0.1 is not listed in kustomize.remoteHosts")
+	assert.Equal(t, int32(0), requests.Load())
+
+	di.ctx.KustomizeRemoteHosts = []string{"127.0.0.*"}
+	di.ctx.AllowKustomizeRemoteHosts = []string{"example.com"}
+	_, err = di.fetchManifestFiles()
+	assert.ErrorContains(t, err, "host 127.0.0.1 is not allowed via --allow-kustomize-remote-hosts")
+	assert.Equal(t, int32(0), requests.Load())
+
+	di.ctx.AllowKustomizeRemoteHosts = nil
+	files, err := di.fetchManifestFiles()
+	assert.NoError(t, err)
+	assert.Equal(t, []manifestFile{{name: "install.yaml", content: []byte(testManifest)}}, files)
+}
+
+func TestBuildManifest(t *testing.T) {
+	dir := t.TempDir()
+	p1 := filepath.Join(dir, "a.yaml")
+	p2 := filepath.Join(dir, "b.yaml")
+	assert.NoError(t, os.WriteFile(p1, []byte(testManifest), 0o600))
+	assert.NoError(t, os.WriteFile(p2, []byte("---\napiVersion: v1\nkind: Namespace\nmetadata:\n  name: ns\n"), 0o600))
+
+	di := &DeploymentItem{
+		Config:        &types.DeploymentItemConfig{Manifest: &types.ManifestItemConfig{}},
+		dir:           &dir,
+		manifestFiles: []string{p1, p2},
+	}
+	assert.NoError(t, di.buildManifest())
+	if assert.Len(t, di.Objects, 3) {
+		assert.Equal(t, "cm1", di.Objects[0].GetK8sName())
+		assert.Equal(t, "cm2", di.Objects[1].GetK8sName())
+		assert.Equal(t, "ns", di.Objects[2].GetK8sName())
+	}
+
+	assert.NoError(t, os.WriteFile(p2, []byte("- a\n- b\n"), 0o600))
+	assert.ErrorContains(t, di.buildManifest(), "manifest b.yaml contains a document that is not an object")
+}
@@ -0,0 +1,381 @@
+package deployment
+
+import (
+	"context"
+	"fmt"
+	"github.com/Masterminds/semver/v3"
+	"github.com/google/go-containerregistry/pkg/crane"
+	git2 "github.com/kluctl/kluctl/v2/pkg/git"
+	"github.com/kluctl/kluctl/v2/pkg/git/auth"
+	ssh_pool "github.com/kluctl/kluctl/v2/pkg/git/ssh-pool"
+	"github.com/kluctl/kluctl/v2/pkg/oci/auth_provider"
+	"github.com/kluctl/kluctl/v2/pkg/status"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/yaml"
+	yamlv3 "gopkg.in/yaml.v3"
+	"io/fs"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ManifestUpdate is a manifest item found in a deployment.yml that can be checked for new versions. Only items with a
+// versioned url or an OCI tag can be updated.
+type ManifestUpdate struct {
+	ConfigFile string
+	Config     types.ManifestItemConfig
+
+	// Version is the version that is currently referenced by the item
+	Version string
+
+	gitUrl     *types.GitUrl
+	urlSegment int
+	versions   []string
+
+	urlNode    *yamlv3.Node
+	sha256Node *yamlv3.Node
+	tagNode    *yamlv3.Node
+}
+
+// LoadProjectManifests finds all updatable manifest items in all deployment.yml files of the project. Files that can't
+// be parsed without rendering them first are skipped.
+func LoadProjectManifests(ctx context.Context, projectDir string) ([]*ManifestUpdate, error) {
+	var ret []*ManifestUpdate
+	err := filepath.WalkDir(projectDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != projectDir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() != "deployment.yml" && d.Name() != "deployment.yaml" {
+			return nil
+		}
+
+		relPath, err := filepath.Rel(projectDir, p)
+		if err != nil {
+			return err
+		}
+
+		b, err := os.ReadFile(p)
+		if err != nil {
+			return err
+		}
+		var root yamlv3.Node
+		err = yamlv3.Unmarshal(b, &root)
+		if err != nil {
+			status.Warningf(ctx, "%s: Skipping file as it can't be parsed: %s", relPath, err.Error())
+			return nil
+		}
+		if len(root.Content) == 0 {
+			return nil
+		}
+
+		deployments := findMappingValue(root.Content[0], "deployments")
+		if deployments == nil || deployments.Kind != yamlv3.SequenceNode {
+			return nil
+		}
+		for i, item := range deployments.Content {
+			n := findMappingValue(item, "manifest")
+			if n == nil {
+				continue
+			}
+			mu, err := newManifestUpdate(p, n)
+			if err != nil {
+				status.Warningf(ctx, "%s: Skipping deployments[%d]: %s", relPath, i, err.Error())
+				continue
+			}
+			if mu != nil {
+				ret = append(ret, mu)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+func findMappingValue(n *yamlv3.Node, key string) *yamlv3.Node {
+	if n == nil || n.Kind != yamlv3.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if n.Content[i].Value == key {
+			return n.Content[i+1]
+		}
+	}
+	return nil
+}
+
+func isTemplated(s string) bool {
+	return strings.Contains(s, "{{") || strings.Contains(s, "{%")
+}
+
+func newManifestUpdate(configFile string, n *yamlv3.Node) (*ManifestUpdate, error) {
+	b, err := yamlv3.Marshal(n)
+	if err != nil {
+		return nil, err
+	}
+	mu := &ManifestUpdate{
+		ConfigFile: configFile,
+	}
+	err = yaml.ReadYamlBytes(b, &mu.Config)
+	if err != nil {
+		return nil, err
+	}
+
+	if mu.Config.Oci != nil {
+		ref := mu.Config.Oci.Ref
+		if ref == nil || ref.Tag == "" || ref.Digest != "" || ref.SemVer != "" {
+			// only fixed tags can be updated, semver refs are resolved on every deployment
+			return nil, nil
+		}
+		if isTemplated(ref.Tag) {
+			return nil, fmt.Errorf("tag %s is templated", ref.Tag)
+		}
+		mu.Version = ref.Tag
+		mu.tagNode = findMappingValue(findMappingValue(findMappingValue(n, "oci"), "ref"), "tag")
+		return mu, nil
+	}
+
+	if isTemplated(mu.Config.Url) {
+		return nil, fmt.Errorf("url %s is templated", mu.Config.Url)
+	}
+	u, err := url.Parse(mu.Config.Url)
+	if err != nil {
+		return nil, err
+	}
+	mu.urlSegment, mu.Version = findUrlVersion(u)
+	if mu.urlSegment == -1 {
+		return nil, fmt.Errorf("no version found in url %s", mu.Config.Url)
+	}
+	mu.gitUrl = mu.Config.UpdateGitUrl
+	if mu.gitUrl == nil {
+		mu.gitUrl = githubRepoFromUrl(u)
+	}
+	if mu.gitUrl == nil {
+		return nil, fmt.Errorf("updateGitUrl must be set for urls that are not GitHub release urls")
+	}
+	mu.urlNode = findMappingValue(n, "url")
+	mu.sha256Node = findMappingValue(n, "sha256")
+	return mu, nil
+}
+
+// findUrlVersion returns the index and value of the last path segment that is a semantic version, e.g. v1.2.3. The
+// file name itself is never treated as version.
+func findUrlVersion(u *url.URL) (int, string) {
+	segments := strings.Split(u.Path, "/")
+	for i := len(segments) - 2; i >= 0; i-- {
+		_, err := semver.StrictNewVersion(strings.TrimPrefix(segments[i], "v"))
+		if err == nil {
+			return i, segments[i]
+		}
+	}
+	return -1, ""
+}
+
+// githubRepoFromUrl returns the repository of GitHub release download urls, e.g.
+// https://github.com/org/repo/releases/download/v1.2.3/install.yaml
+func githubRepoFromUrl(u *url.URL) *types.GitUrl {
+	if u.Host != "github.com" {
+		return nil
+	}
+	s := strings.Split(strings.TrimPrefix(u.Path, "/"), "/")
+	if len(s) < 5 || s[2] != "releases" || s[3] != "download" {
+		return nil
+	}
+	gu, err := types.ParseGitUrl(fmt.Sprintf("https://github.com/%s/%s.git", s[0], s[1]))
+	if err != nil {
+		return nil
+	}
+	return gu
+}
+
+func (mu *ManifestUpdate) GetName() string {
+	if mu.Config.Oci != nil {
+		return mu.Config.Oci.Url
+	}
+	return mu.Config.Url
+}
+
+// QueryVersions lists the tags of the OCI repository or of the git repository that the url belongs to
+func (mu *ManifestUpdate) QueryVersions(ctx context.Context, ociAuthProvider auth_provider.OciAuthProvider, gitAuthProvider auth.GitAuthProvider, sshPool *ssh_pool.SshPool) error {
+	if mu.Config.Oci != nil {
+		var clientOpts []crane.Option
+		clientOpts = append(clientOpts, crane.WithContext(ctx))
+		if ociAuthProvider != nil {
+			auth, err := ociAuthProvider.FindAuthEntry(ctx, mu.Config.Oci.Url)
+			if err != nil {
+				return err
+			}
+			authOpts, err := auth.BuildCraneOptions()
+			if err != nil {
+				return err
+			}
+			clientOpts = append(clientOpts, authOpts...)
+		}
+		tags, err := crane.ListTags(strings.TrimPrefix(mu.Config.Oci.Url, "oci://"), clientOpts...)
+		if err != nil {
+			return err
+		}
+		mu.versions = tags
+		return nil
+	}
+
+	gitAuth, err := gitAuthProvider.BuildAuth(ctx, *mu.gitUrl)
+	if err != nil {
+		return err
+	}
+	refs, err := git2.ListRemoteRefs(ctx, *mu.gitUrl, sshPool, gitAuth)
+	if err != nil {
+		return err
+	}
+	mu.versions = nil
+	for _, r := range refs {
+		if r.Name().IsTag() {
+			mu.versions = append(mu.versions, r.Name().Short())
+		}
+	}
+	return nil
+}
+
+// GetLatestVersion returns the latest queried version that satisfies updateConstraints. Pre-releases are only
+// considered when constraints are set. The current version is returned if no newer version exists.
+func (mu *ManifestUpdate) GetLatestVersion() (string, error) {
+	current, err := semver.NewVersion(mu.Version)
+	if err != nil {
+		return "", fmt.Errorf("current version %s is not a semantic version: %w", mu.Version, err)
+	}
+
+	var updateConstraints *semver.Constraints
+	if mu.Config.UpdateConstraints != nil {
+		updateConstraints, err = semver.NewConstraint(*mu.Config.UpdateConstraints)
+		if err != nil {
+			return "", fmt.Errorf("invalid constraints '%s': %w", *mu.Config.UpdateConstraints, err)
+		}
+	}
+
+	hasVPrefix := strings.HasPrefix(mu.Version, "v")
+	versions := semver.Collection{current}
+	for _, x := range mu.versions {
+		if strings.HasPrefix(x, "v") != hasVPrefix {
+			// new versions must fit into the url or tag the same way as the current version
+			continue
+		}
+		v, err := semver.NewVersion(x)
+		if err != nil {
+			continue
+		}
+		if updateConstraints == nil {
+			if v.Prerelease() != "" {
+				continue
+			}
+		} else if !updateConstraints.Check(v) {
+			continue
+		}
+		versions = append(versions, v)
+	}
+
+	sort.Stable(versions)
+	return versions[len(versions)-1].Original(), nil
+}
+
+// BuildUrl returns the url with the version replaced by the given version
+func (mu *ManifestUpdate) BuildUrl(version string) (string, error) {
+	u, err := url.Parse(mu.Config.Url)
+	if err != nil {
+		return "", err
+	}
+	segments := strings.Split(u.Path, "/")
+	segments[mu.urlSegment] = version
+	u.Path = strings.Join(segments, "/")
+	u.RawPath = ""
+	return u.String(), nil
+}
+
+// Upgrade updates the deployment.yml to reference the given version. For urls, the new manifest is downloaded to
+// compute the new checksum.
+func (mu *ManifestUpdate) Upgrade(ctx context.Context, version string) error {
+	var edits []manifestEdit
+	if mu.Config.Oci != nil {
+		edits = append(edits, manifestEdit{n: mu.tagNode, value: version})
+		mu.Config.Oci.Ref.Tag = version
+	} else {
+		newUrl, err := mu.BuildUrl(version)
+		if err != nil {
+			return err
+		}
+		_, sum, err := FetchManifest(ctx, newUrl, "")
+		if err != nil {
+			return err
+		}
+		edits = append(edits, manifestEdit{n: mu.urlNode, value: newUrl}, manifestEdit{n: mu.sha256Node, value: sum})
+		mu.Config.Url = newUrl
+		mu.Config.Sha256 = sum
+	}
+
+	b, err := os.ReadFile(mu.ConfigFile)
+	if err != nil {
+		return err
+	}
+	s, err := applyManifestEdits(string(b), edits)
+	if err != nil {
+		return fmt.Errorf("failed to update %s: %w", mu.ConfigFile, err)
+	}
+	err = os.WriteFile(mu.ConfigFile, []byte(s), 0o600)
+	if err != nil {
+		return err
+	}
+	mu.Version = version
+	return nil
+}
+
+type manifestEdit struct {
+	n     *yamlv3.Node
+	value string
+}
+
+// applyManifestEdits replaces the values of scalar nodes in-place, so that comments and formatting of the file are
+// preserved
+func applyManifestEdits(s string, edits []manifestEdit) (string, error) {
+	sort.Slice(edits, func(i, j int) bool {
+		if edits[i].n.Line != edits[j].n.Line {
+			return edits[i].n.Line > edits[j].n.Line
+		}
+		return edits[i].n.Column > edits[j].n.Column
+	})
+
+	lines := strings.Split(s, "\n")
+	for _, e := range edits {
+		quote := ""
+		switch e.n.Style {
+		case 0:
+		case yamlv3.DoubleQuotedStyle:
+			quote = `"`
+		case yamlv3.SingleQuotedStyle:
+			quote = `'`
+		default:
+			return "", fmt.Errorf("unsupported style for value %s in line %d", e.n.Value, e.n.Line)
+		}
+
+		if e.n.Line < 1 || e.n.Line > len(lines) {
+			return "", fmt.Errorf("invalid line %d", e.n.Line)
+		}
+		line := []rune(lines[e.n.Line-1])
+		start := e.n.Column - 1
+		old := []rune(quote + e.n.Value + quote)
+		if start < 0 || start+len(old) > len(line) || string(line[start:start+len(old)]) != string(old) {
+			return "", fmt.Errorf("value %s not found in line %d", e.n.Value, e.n.Line)
+		}
+		newLine := string(line[:start]) + quote + e.value + quote + string(line[start+len(old):])
+		lines[e.n.Line-1] = newLine
+	}
+	return strings.Join(lines, "\n"), nil
+}
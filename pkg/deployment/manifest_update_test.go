@@ -0,0 +1,131 @@
+package deployment
+
+import (
+	"context"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/stretchr/testify/assert"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFindUrlVersion(t *testing.T) {
+	type testCase struct {
+		url     string
+		index   int
+		version string
+	}
+	tests := []testCase{
+		{url: "https://github.com/org/repo/releases/download/v1.2.3/install.yaml", index: 5, version: "v1.2.3"},
+		{url: "https://example.com/1.2.3/manifests/operator.yaml", index: 1, version: "1.2.3"},
+		{url: "https://example.com/latest/1.2.3.yaml", index: -1},
+		{url: "https://example.com/2024/install.yaml", index: -1},
+	}
+	for _, tc := range tests {
+		t.Run(tc.url, func(t *testing.T) {
+			u, err := url.Parse(tc.url)
+			assert.NoError(t, err)
+			index, version := findUrlVersion(u)
+			assert.Equal(t, tc.index, index)
+			assert.Equal(t, tc.version, version)
+		})
+	}
+}
+
+func TestGithubRepoFromUrl(t *testing.T) {
+	u, _ := url.Parse("https://github.com/org/repo/releases/download/v1.2.3/install.yaml")
+	gu := githubRepoFromUrl(u)
+	if assert.NotNil(t, gu) {
+		assert.Equal(t, "https://github.com/org/repo.git", gu.String())
+	}
+
+	u, _ = url.Parse("https://raw.githubusercontent.com/org/repo/v1.2.3/install.yaml")
+	assert.Nil(t, githubRepoFromUrl(u))
+}
+
+func TestManifestGetLatestVersion(t *testing.T) {
+	mu := &ManifestUpdate{
+		Version:  "v1.2.3",
+		versions: []string{"v1.2.3", "v1.3.0", "v1.10.0", "v2.0.0-rc.1", "2.1.0", "helm-chart-3.0.0"},
+	}
+	v, err := mu.GetLatestVersion()
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.10.0", v)
+
+	mu.Config.UpdateConstraints = utils.Ptr(">=1.0.0-0")
+	v, err = mu.GetLatestVersion()
+	assert.NoError(t, err)
+	assert.Equal(t, "v2.0.0-rc.1", v)
+
+	mu.Config.UpdateConstraints = utils.Ptr("~1.2.0")
+	v, err = mu.GetLatestVersion()
+	assert.NoError(t, err)
+	assert.Equal(t, "v1.2.3", v)
+}
+
+func TestManifestUpdate(t *testing.T) {
+	s := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(r.URL.Path))
+	}))
+	defer s.Close()
+
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "sub"), 0o700))
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, ".hidden"), 0o700))
+
+	deploymentYml := `deployments:
+  # the operator
+  - manifest:
+      url: ` + s.URL + `/v1.0.0/install.yaml # comment
+      sha256: ` + sha256Hex([]byte("/v1.0.0/install.yaml")) + `
+      updateGitUrl: https://example.com/repo.git
+  - manifest: {url: "` + s.URL + `/1.0.0/crds.yaml", sha256: '` + sha256Hex([]byte("/1.0.0/crds.yaml")) + `', updateGitUrl: https://example.com/repo.git}
+  - manifest:
+      oci:
+        url: oci://ghcr.io/org/operator
+        ref:
+          tag: 1.0.0
+  - manifest:
+      oci:
+        url: oci://ghcr.io/org/operator
+        ref:
+          semver: ~1.0.0
+  - path: app
+`
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "deployment.yml"), []byte(deploymentYml), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "deployment.yaml"), []byte(`deployments:
+  - manifest:
+      url: "{{ args.url }}"
+      sha256: `+sha256Hex(nil)+`
+`), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, ".hidden", "deployment.yml"), []byte(deploymentYml), 0o600))
+
+	ctx := utils.WithCacheDir(context.Background(), t.TempDir())
+	manifests, err := LoadProjectManifests(ctx, dir)
+	assert.NoError(t, err)
+	if !assert.Len(t, manifests, 3) {
+		return
+	}
+	assert.Equal(t, "v1.0.0", manifests[0].Version)
+	assert.Equal(t, "1.0.0", manifests[1].Version)
+	assert.Equal(t, "1.0.0", manifests[2].Version)
+
+	for i, v := range []string{"v1.1.0", "1.1.0", "1.2.0"} {
+		assert.NoError(t, manifests[i].Upgrade(ctx, v))
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, "deployment.yml"))
+	assert.NoError(t, err)
+	expected := strings.NewReplacer(
+		"/v1.0.0/install.yaml", "/v1.1.0/install.yaml",
+		sha256Hex([]byte("/v1.0.0/install.yaml")), sha256Hex([]byte("/v1.1.0/install.yaml")),
+		"/1.0.0/crds.yaml", "/1.1.0/crds.yaml",
+		sha256Hex([]byte("/1.0.0/crds.yaml")), sha256Hex([]byte("/1.1.0/crds.yaml")),
+		"tag: 1.0.0", "tag: 1.2.0",
+	).Replace(deploymentYml)
+	assert.Equal(t, expected, string(b))
+}
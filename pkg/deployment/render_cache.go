@@ -0,0 +1,538 @@
+package deployment
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/sops"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/kluctl/kluctl/v2/pkg/version"
+	"github.com/kluctl/kluctl/v2/pkg/yaml"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// renderCacheFormat must be increased whenever the cache key or the entry format changes
+const renderCacheFormat = "2"
+
+// renderCacheMaxAge is the time after which entries that were not used anymore are removed
+const renderCacheMaxAge = 7 * 24 * time.Hour
+
+// renderCachePruneInterval is the minimum time between two prunes, which are performed while storing new entries
+const renderCachePruneInterval = time.Hour
+
+// renderCacheMaxSize is the total size of all entries. Least recently used entries are removed when it is exceeded
+var renderCacheMaxSize int64 = 1024 * 1024 * 1024
+
+type RenderCacheResult string
+
+const (
+	RenderCacheHit         RenderCacheResult = "hit"
+	RenderCacheMiss        RenderCacheResult = "miss"
+	RenderCacheUncacheable RenderCacheResult = "uncacheable"
+)
+
+type RenderCacheItemStats struct {
+	Dir    string            `json:"dir"`
+	Result RenderCacheResult `json:"result"`
+	Reason string            `json:"reason,omitempty"`
+}
+
+type RenderCacheStats struct {
+	Hits        int                    `json:"hits"`
+	Misses      int                    `json:"misses"`
+	Uncacheable int                    `json:"uncacheable"`
+	Items       []RenderCacheItemStats `json:"items,omitempty"`
+}
+
+// RenderCache stores the objects that were built for deployment items, after Helm Charts were rendered and
+// kustomize was invoked. Entries are addressed by the hash of all inputs of the item, so that unchanged items can
+// skip the expensive steps. The cache is safe to be shared between multiple renders, hits and misses are recorded per
+// render by the DeploymentCollection.
+type RenderCache struct {
+	dir string
+
+	pruneMutex sync.Mutex
+	lastPrune  time.Time
+}
+
+type renderCacheEntry struct {
+	Barrier         bool                   `json:"barrier,omitempty"`
+	WaitReadiness   bool                   `json:"waitReadiness,omitempty"`
+	HelmChartConfig *types.HelmChartConfig `json:"helmChartConfig,omitempty"`
+	Objects         []map[string]any       `json:"objects"`
+}
+
+func NewRenderCache(dir string) (*RenderCache, error) {
+	err := os.MkdirAll(dir, 0o700)
+	if err != nil {
+		return nil, err
+	}
+	c := &RenderCache{
+		dir: dir,
+	}
+	c.prune()
+	return c, nil
+}
+
+// prune removes all entries that were not used for renderCacheMaxAge and then the least recently used entries until
+// the total size is below renderCacheMaxSize. Entries are touched on every hit.
+func (c *RenderCache) prune() {
+	c.pruneMutex.Lock()
+	defer c.pruneMutex.Unlock()
+	c.lastPrune = time.Now()
+
+	des, err := os.ReadDir(c.dir)
+	if err != nil {
+		return
+	}
+	var entries []fs.FileInfo
+	var totalSize int64
+	for _, de := range des {
+		info, err := de.Info()
+		if err != nil || info.IsDir() {
+			continue
+		}
+		if time.Since(info.ModTime()) >= renderCacheMaxAge {
+			_ = os.Remove(filepath.Join(c.dir, de.Name()))
+			continue
+		}
+		if strings.HasPrefix(de.Name(), ".tmp-") {
+			// still being written
+			continue
+		}
+		entries = append(entries, info)
+		totalSize += info.Size()
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].ModTime().Before(entries[j].ModTime())
+	})
+	for _, info := range entries {
+		if totalSize <= renderCacheMaxSize {
+			break
+		}
+		_ = os.Remove(filepath.Join(c.dir, info.Name()))
+		totalSize -= info.Size()
+	}
+}
+
+func (c *RenderCache) pruneIfNeeded() {
+	c.pruneMutex.Lock()
+	needed := time.Since(c.lastPrune) >= renderCachePruneInterval
+	c.pruneMutex.Unlock()
+	if needed {
+		c.prune()
+	}
+}
+
+func (c *RenderCache) entryPath(key string) string {
+	return filepath.Join(c.dir, key+".yaml")
+}
+
+// get returns nil if no entry exists. Unreadable entries are treated as non-existent, as they are overwritten later.
+func (c *RenderCache) get(key string) *renderCacheEntry {
+	p := c.entryPath(key)
+	var e renderCacheEntry
+	err := yaml.ReadYamlFile(p, &e)
+	if err != nil {
+		return nil
+	}
+	now := time.Now()
+	_ = os.Chtimes(p, now, now)
+	return &e
+}
+
+func (c *RenderCache) put(key string, e *renderCacheEntry) error {
+	b, err := yaml.WriteYamlBytes(e)
+	if err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(c.dir, ".tmp-")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	_, err = tmp.Write(b)
+	_ = tmp.Close()
+	if err != nil {
+		return err
+	}
+	// rename is atomic, so that parallel renders never see partial entries
+	err = os.Rename(tmp.Name(), c.entryPath(key))
+	if err != nil {
+		return err
+	}
+	c.pruneIfNeeded()
+	return nil
+}
+
+func (s *RenderCacheStats) add(is RenderCacheItemStats) {
+	switch is.Result {
+	case RenderCacheHit:
+		s.Hits++
+	case RenderCacheMiss:
+		s.Misses++
+	case RenderCacheUncacheable:
+		s.Uncacheable++
+	}
+	s.Items = append(s.Items, is)
+}
+
+func newRenderCacheEntry(di *DeploymentItem) *renderCacheEntry {
+	e := &renderCacheEntry{
+		Barrier:         di.Barrier,
+		WaitReadiness:   di.WaitReadiness,
+		HelmChartConfig: di.Config.RenderedHelmChartConfig,
+		Objects:         make([]map[string]any, 0, len(di.Objects)),
+	}
+	for _, o := range di.Objects {
+		e.Objects = append(e.Objects, o.Object)
+	}
+	return e
+}
+
+func (e *renderCacheEntry) apply(di *DeploymentItem) {
+	di.Barrier = e.Barrier
+	di.WaitReadiness = e.WaitReadiness
+	di.Config.RenderedHelmChartConfig = e.HelmChartConfig
+	di.Objects = make([]*uo.UnstructuredObject, 0, len(e.Objects))
+	for _, o := range e.Objects {
+		di.Objects = append(di.Objects, uo.FromMap(o))
+	}
+}
+
+// renderCacheHasher builds the cache key of a single deployment item. The first reason that prevents caching is
+// remembered, in which case the key must not be used.
+type renderCacheHasher struct {
+	values      []string
+	uncacheable string
+}
+
+func (h *renderCacheHasher) add(name string, value string) {
+	h.values = append(h.values, fmt.Sprintf("%s:%d:%s", name, len(value), value))
+}
+
+func (h *renderCacheHasher) setUncacheable(reason string) {
+	if h.uncacheable == "" {
+		h.uncacheable = reason
+	}
+}
+
+func (h *renderCacheHasher) key() string {
+	x := sha256.New()
+	for _, v := range h.values {
+		_, _ = x.Write([]byte(v))
+		_, _ = x.Write([]byte{0})
+	}
+	return hex.EncodeToString(x.Sum(nil))
+}
+
+// addPath adds the file or all files inside the directory p. If check is set, it is called for every file and can
+// return a reason that prevents caching.
+func (h *renderCacheHasher) addPath(name string, p string, check func(rel string, content []byte) string) error {
+	return filepath.WalkDir(p, func(p2 string, d os.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(p, p2)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if d.IsDir() {
+			if d.Name() == ".git" {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Type()&os.ModeSymlink != 0 {
+			l, err := os.Readlink(p2)
+			if err != nil {
+				return err
+			}
+			h.add(name+"/"+rel+"@", l)
+			return nil
+		}
+		b, err := os.ReadFile(p2)
+		if err != nil {
+			return err
+		}
+		h.add(name+"/"+rel, string(b))
+		if check != nil {
+			if reason := check(rel, b); reason != "" {
+				h.setUncacheable(reason)
+			}
+		}
+		return nil
+	})
+}
+
+func isInDir(dir string, p string) bool {
+	rel, err := filepath.Rel(dir, p)
+	if err != nil {
+		return false
+	}
+	return rel != ".." && !strings.HasPrefix(rel, ".."+string(filepath.Separator))
+}
+
+// collectStrings calls cb for all strings found in o, including map keys
+func collectStrings(o any, cb func(s string)) {
+	switch x := o.(type) {
+	case string:
+		cb(x)
+	case map[string]any:
+		// sorted, as the order influences the cache key
+		keys := make([]string, 0, len(x))
+		for k := range x {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			cb(k)
+			collectStrings(x[k], cb)
+		}
+	case []any:
+		for _, v := range x {
+			collectStrings(v, cb)
+		}
+	}
+}
+
+// helmLookupRegex matches usages of the Helm 'lookup' function, which makes the rendered chart depend on the cluster
+var helmLookupRegex = regexp.MustCompile(`\blookup\s+"`)
+
+func sopsCheck(rel string, content []byte) string {
+	if sops.IsMaybeSopsFile(content) {
+		// we don't want decrypted secrets to end up in the cache
+		return fmt.Sprintf("%s might be SOPS encrypted", rel)
+	}
+	return ""
+}
+
+// buildRenderCacheKey hashes everything that influences the objects built by renderHelmCharts, resolveSealedSecrets
+// and buildKustomize. The rendered directory already reflects the source files and all used vars. The returned reason
+// is non-empty if the item can not be cached.
+func (di *DeploymentItem) buildRenderCacheKey(clusterInfo func() (string, error)) (string, string, error) {
+	h := &renderCacheHasher{}
+	h.add("format", renderCacheFormat)
+	h.add("version", version.GetVersion())
+	if ns := di.Project.getOverrideNamespace(); ns != nil {
+		h.add("overrideNamespace", *ns)
+	}
+
+	err := h.addPath("rendered", di.RenderedDir, sopsCheck)
+	if err != nil {
+		return "", "", err
+	}
+
+	di.addKustomizeRefsToRenderCacheKey(h, di.RenderedDir, map[string]bool{})
+
+	if ky, err := di.readKustomizationYaml(""); err == nil && ky != nil {
+		fns, err := di.extractKustomizeFunctions(ky)
+		if err != nil || len(fns) != 0 {
+			h.setUncacheable("kustomization uses KRM functions")
+		}
+	}
+
+	hasHelmCharts := false
+	err = filepath.WalkDir(di.RenderedDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !di.isHelmChartYaml(p) {
+			return nil
+		}
+		hasHelmCharts = true
+
+		subDir, err := filepath.Rel(di.RenderedDir, filepath.Dir(p))
+		if err != nil {
+			return err
+		}
+		hr, err := di.newHelmRelease(subDir)
+		if err != nil {
+			// reported by renderHelmCharts
+			h.setUncacheable(fmt.Sprintf("invalid Helm chart config in %s", subDir))
+			return nil
+		}
+		chartDir, err := hr.GetLocalChartDir()
+		if err != nil || chartDir == "" {
+			h.setUncacheable(fmt.Sprintf("Helm chart %s is not pre-pulled", hr.Chart.GetChartName()))
+			return nil
+		}
+		return h.addPath("chart:"+filepath.ToSlash(subDir), chartDir, func(rel string, content []byte) string {
+			if di.ctx.K != nil && helmLookupRegex.Match(content) {
+				return fmt.Sprintf("Helm chart %s uses the lookup function", hr.Chart.GetChartName())
+			}
+			return ""
+		})
+	})
+	if err != nil {
+		return "", "", err
+	}
+	if hasHelmCharts {
+		ci, err := clusterInfo()
+		if err != nil {
+			return "", "", err
+		}
+		h.add("k8sVersion", di.ctx.K8sVersion)
+		h.add("cluster", ci)
+	}
+
+	sealedSecrets, err := di.ListSealedSecrets("")
+	if err != nil {
+		// reported by resolveSealedSecrets
+		h.setUncacheable("failed to list sealed secrets")
+	}
+	for _, relPath := range sealedSecrets {
+		sourcePath, err := di.BuildSealedSecretPath(relPath)
+		if err != nil || !utils.IsFile(sourcePath) {
+			h.setUncacheable(fmt.Sprintf("sealed secret for %s not found", relPath))
+			continue
+		}
+		err = h.addPath("sealed:"+filepath.ToSlash(relPath), sourcePath, nil)
+		if err != nil {
+			return "", "", err
+		}
+	}
+
+	return h.key(), h.uncacheable, nil
+}
+
+// addKustomizeRefsToRenderCacheKey adds all files and directories outside the item's rendered dir that are referenced
+// by kustomizations. As kustomize knows many fields that can refer to files, all strings that resolve to existing
+// paths are treated as references.
+func (di *DeploymentItem) addKustomizeRefsToRenderCacheKey(h *renderCacheHasher, dir string, visited map[string]bool) {
+	if visited[dir] {
+		return
+	}
+	visited[dir] = true
+
+	p := yaml.FixPathExt(filepath.Join(dir, "kustomization.yml"))
+	if !utils.IsFile(p) {
+		return
+	}
+	ky, err := uo.FromFile(p)
+	if err != nil {
+		// reported by buildKustomize
+		h.setUncacheable("invalid kustomization.yaml")
+		return
+	}
+
+	for _, f := range []string{"resources", "components", "bases"} {
+		l, _, _ := ky.GetNestedStringList(f)
+		for _, r := range l {
+			if filepath.IsAbs(r) || !utils.Exists(filepath.Join(dir, r)) {
+				h.setUncacheable(fmt.Sprintf("kustomization refers to remote resource %s", r))
+			}
+		}
+	}
+
+	collectStrings(ky.Object, func(s string) {
+		if s == "" || filepath.IsAbs(s) || strings.ContainsAny(s, "\n:") {
+			return
+		}
+		p := filepath.Clean(filepath.Join(dir, s))
+		if !utils.Exists(p) {
+			return
+		}
+		if !isInDir(di.RenderedDir, p) {
+			if !isInDir(di.RenderedSourceRootDir, p) {
+				// kustomize will fail later
+				h.setUncacheable(fmt.Sprintf("kustomization refers to %s, which is outside of the project", s))
+				return
+			}
+			rel, err := filepath.Rel(di.RenderedSourceRootDir, p)
+			if err != nil {
+				h.setUncacheable(err.Error())
+				return
+			}
+			err = h.addPath("ref:"+filepath.ToSlash(rel), p, sopsCheck)
+			if err != nil {
+				h.setUncacheable(err.Error())
+				return
+			}
+		}
+		if utils.IsDirectory(p) {
+			di.addKustomizeRefsToRenderCacheKey(h, p, visited)
+		}
+	})
+}
+
+func (di *DeploymentItem) recordRenderCache(result RenderCacheResult, reason string) {
+	di.renderCacheStats = &RenderCacheItemStats{
+		Dir:    filepath.ToSlash(di.RelRenderedDir),
+		Result: result,
+		Reason: reason,
+	}
+}
+
+func (di *DeploymentItem) lookupRenderCache(clusterInfo func() (string, error)) error {
+	rc := di.ctx.RenderCache
+	if di.dir == nil || di.Config.OnlyRender {
+		return nil
+	}
+
+	if di.Config.Jsonnet != nil || di.Config.Cue != nil {
+		// imports can reach anywhere in the project, which is not covered by the key
+		di.recordRenderCache(RenderCacheUncacheable, "jsonnet and cue items are not cached")
+		return nil
+	}
+	if di.Config.Manifest != nil {
+		// manifests are fetched while rendering and only need to be parsed
+		di.recordRenderCache(RenderCacheUncacheable, "manifest items are not cached")
+		return nil
+	}
+	if len(di.Config.Functions) != 0 {
+		// exec functions might return different results for the same input
+		di.recordRenderCache(RenderCacheUncacheable, "items with KRM functions are not cached")
+		return nil
+	}
+	if di.VarsCtx.Sensitive {
+		// the rendered objects might contain the sensitive values, which must not end up in the unencrypted cache
+		di.recordRenderCache(RenderCacheUncacheable, "items using sensitive vars are not cached")
+		return nil
+	}
+
+	key, reason, err := di.buildRenderCacheKey(clusterInfo)
+	if err != nil {
+		return err
+	}
+	if reason != "" {
+		di.recordRenderCache(RenderCacheUncacheable, reason)
+		return nil
+	}
+
+	e := rc.get(key)
+	if e == nil {
+		di.renderCacheKey = key
+		di.recordRenderCache(RenderCacheMiss, "")
+		return nil
+	}
+	e.apply(di)
+	di.renderCacheHit = true
+	di.recordRenderCache(RenderCacheHit, "")
+	return nil
+}
+
+func (di *DeploymentItem) storeRenderCache() error {
+	if di.renderCacheKey == "" || di.renderCacheHit {
+		return nil
+	}
+	for _, o := range di.Objects {
+		if o.GetK8sGVK().GroupKind().String() == "Secret" {
+			// Secrets might contain values from any source, e.g. Helm Charts generating passwords
+			di.recordRenderCache(RenderCacheUncacheable, fmt.Sprintf("items with Secrets are not cached, found %s", o.GetK8sRef().String()))
+			return nil
+		}
+	}
+	return di.ctx.RenderCache.put(di.renderCacheKey, newRenderCacheEntry(di))
+}
@@ -0,0 +1,241 @@
+package deployment
+
+import (
+	"context"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/kluctl/kluctl/v2/pkg/vars"
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRenderCachePutGet(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewRenderCache(dir)
+	assert.NoError(t, err)
+
+	assert.Nil(t, c.get("k1"))
+
+	e := &renderCacheEntry{
+		Barrier: true,
+		Objects: []map[string]any{
+			{"apiVersion": "v1", "kind": "ConfigMap", "metadata": map[string]any{"name": "cm"}},
+		},
+	}
+	assert.NoError(t, c.put("k1", e))
+	assert.Equal(t, e, c.get("k1"))
+
+	// unreadable entries are treated as misses
+	assert.NoError(t, os.WriteFile(c.entryPath("k2"), []byte("{"), 0o600))
+	assert.Nil(t, c.get("k2"))
+}
+
+func TestRenderCachePrune(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewRenderCache(dir)
+	assert.NoError(t, err)
+
+	assert.NoError(t, c.put("old", &renderCacheEntry{}))
+	assert.NoError(t, c.put("new", &renderCacheEntry{}))
+	old := time.Now().Add(-renderCacheMaxAge - time.Hour)
+	assert.NoError(t, os.Chtimes(c.entryPath("old"), old, old))
+
+	_, err = NewRenderCache(dir)
+	assert.NoError(t, err)
+	assert.NoFileExists(t, c.entryPath("old"))
+	assert.FileExists(t, c.entryPath("new"))
+}
+
+func TestRenderCachePruneMaxSize(t *testing.T) {
+	oldMaxSize := renderCacheMaxSize
+	defer func() { renderCacheMaxSize = oldMaxSize }()
+
+	dir := t.TempDir()
+	c, err := NewRenderCache(dir)
+	assert.NoError(t, err)
+
+	e := &renderCacheEntry{Objects: []map[string]any{{"kind": "ConfigMap"}}}
+	for i, k := range []string{"k1", "k2", "k3"} {
+		assert.NoError(t, c.put(k, e))
+		tm := time.Now().Add(time.Duration(i-3) * time.Minute)
+		assert.NoError(t, os.Chtimes(c.entryPath(k), tm, tm))
+	}
+	st, err := os.Stat(c.entryPath("k1"))
+	assert.NoError(t, err)
+	renderCacheMaxSize = st.Size() * 2
+
+	// k1 is the least recently used entry
+	c.prune()
+	assert.NoFileExists(t, c.entryPath("k1"))
+	assert.FileExists(t, c.entryPath("k2"))
+	assert.FileExists(t, c.entryPath("k3"))
+}
+
+func TestRenderCachePruneOnPut(t *testing.T) {
+	dir := t.TempDir()
+	c, err := NewRenderCache(dir)
+	assert.NoError(t, err)
+
+	assert.NoError(t, c.put("old", &renderCacheEntry{}))
+	old := time.Now().Add(-renderCacheMaxAge - time.Hour)
+	assert.NoError(t, os.Chtimes(c.entryPath("old"), old, old))
+
+	// pruned recently, so the entry is kept
+	assert.NoError(t, c.put("new", &renderCacheEntry{}))
+	assert.FileExists(t, c.entryPath("old"))
+
+	c.lastPrune = time.Now().Add(-renderCachePruneInterval)
+	assert.NoError(t, c.put("new", &renderCacheEntry{}))
+	assert.NoFileExists(t, c.entryPath("old"))
+	assert.FileExists(t, c.entryPath("new"))
+}
+
+func TestRenderCacheStats(t *testing.T) {
+	newItem := func(dir string, result RenderCacheResult, reason string) *DeploymentItem {
+		di := &DeploymentItem{RelRenderedDir: dir}
+		di.recordRenderCache(result, reason)
+		return di
+	}
+	c := &DeploymentCollection{
+		Deployments: []*DeploymentItem{
+			newItem("b", RenderCacheMiss, ""),
+			newItem("a", RenderCacheHit, ""),
+			{RelRenderedDir: "not-consulted"},
+			newItem("c", RenderCacheUncacheable, "reason"),
+		},
+	}
+
+	s := c.RenderCacheStats()
+	assert.Equal(t, 1, s.Hits)
+	assert.Equal(t, 1, s.Misses)
+	assert.Equal(t, 1, s.Uncacheable)
+	assert.Equal(t, []RenderCacheItemStats{
+		{Dir: "a", Result: RenderCacheHit},
+		{Dir: "b", Result: RenderCacheMiss},
+		{Dir: "c", Result: RenderCacheUncacheable, Reason: "reason"},
+	}, s.Items)
+}
+
+func TestRenderCacheSensitiveVars(t *testing.T) {
+	c, err := NewRenderCache(t.TempDir())
+	assert.NoError(t, err)
+
+	dir := "app"
+	varsCtx := vars.NewVarsCtx(nil)
+	varsCtx.Sensitive = true
+	di := &DeploymentItem{
+		ctx:            SharedContext{Ctx: context.Background(), RenderCache: c},
+		Config:         &types.DeploymentItemConfig{},
+		VarsCtx:        varsCtx,
+		dir:            &dir,
+		RelRenderedDir: dir,
+	}
+	assert.NoError(t, di.lookupRenderCache(nil))
+	assert.Empty(t, di.renderCacheKey)
+	assert.Equal(t, &RenderCacheItemStats{
+		Dir: "app", Result: RenderCacheUncacheable, Reason: "items using sensitive vars are not cached",
+	}, di.renderCacheStats)
+}
+
+func TestRenderCacheSecrets(t *testing.T) {
+	c, err := NewRenderCache(t.TempDir())
+	assert.NoError(t, err)
+
+	newItem := func(dir string, kind string) *DeploymentItem {
+		di := &DeploymentItem{
+			ctx:            SharedContext{Ctx: context.Background(), RenderCache: c},
+			Config:         &types.DeploymentItemConfig{},
+			RelRenderedDir: dir,
+			renderCacheKey: dir,
+			Objects: []*uo.UnstructuredObject{
+				uo.FromMap(map[string]any{
+					"apiVersion": "v1",
+					"kind":       kind,
+					"metadata":   map[string]any{"name": "x", "namespace": "ns"},
+					"data":       map[string]any{"password": "c2VjcmV0"},
+				}),
+			},
+		}
+		di.recordRenderCache(RenderCacheMiss, "")
+		return di
+	}
+
+	cm := newItem("cm", "ConfigMap")
+	secret := newItem("secret", "Secret")
+	assert.NoError(t, cm.storeRenderCache())
+	assert.NoError(t, secret.storeRenderCache())
+
+	assert.NotNil(t, c.get("cm"))
+	assert.Nil(t, c.get("secret"))
+	assert.NoFileExists(t, c.entryPath("secret"))
+
+	s := (&DeploymentCollection{Deployments: []*DeploymentItem{cm, secret}}).RenderCacheStats()
+	assert.Equal(t, 1, s.Misses)
+	assert.Equal(t, 1, s.Uncacheable)
+	assert.Equal(t, []RenderCacheItemStats{
+		{Dir: "cm", Result: RenderCacheMiss},
+		{Dir: "secret", Result: RenderCacheUncacheable, Reason: "items with Secrets are not cached, found ns/Secret/x"},
+	}, s.Items)
+}
+
+func TestRenderCacheHasher(t *testing.T) {
+	dir := t.TempDir()
+	assert.NoError(t, os.MkdirAll(filepath.Join(dir, "sub"), 0o700))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "a.yaml"), []byte("a: 1"), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "b.yaml"), []byte("b: 1"), 0o600))
+
+	buildKey := func() (string, string) {
+		var h renderCacheHasher
+		assert.NoError(t, h.addPath("dir", dir, sopsCheck))
+		return h.key(), h.uncacheable
+	}
+
+	k1, reason := buildKey()
+	assert.Empty(t, reason)
+	k2, _ := buildKey()
+	assert.Equal(t, k1, k2)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "sub", "b.yaml"), []byte("b: 2"), 0o600))
+	k3, _ := buildKey()
+	assert.NotEqual(t, k1, k3)
+
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "secret.yaml"), []byte("a: ENC[AES256_GCM,data:x]\nsops:\n  version: 3.7.3\n"), 0o600))
+	_, reason = buildKey()
+	assert.Equal(t, "secret.yaml might be SOPS encrypted", reason)
+
+	// the first reason wins
+	var h renderCacheHasher
+	h.setUncacheable("r1")
+	h.setUncacheable("r2")
+	assert.Equal(t, "r1", h.uncacheable)
+}
+
+func TestRenderCacheHasherSeparatesValues(t *testing.T) {
+	var h1, h2 renderCacheHasher
+	h1.add("a", "bc")
+	h2.add("a", "b")
+	h2.add("c", "")
+	assert.NotEqual(t, h1.key(), h2.key())
+}
+
+func TestCollectStrings(t *testing.T) {
+	var ret []string
+	collectStrings(map[string]any{
+		"b": []any{"x", 1, map[string]any{"y": "z"}},
+		"a": "v",
+	}, func(s string) {
+		ret = append(ret, s)
+	})
+	assert.Equal(t, []string{"a", "v", "b", "x", "y", "z"}, ret)
+}
+
+func TestIsInDir(t *testing.T) {
+	assert.True(t, isInDir("/a/b", "/a/b"))
+	assert.True(t, isInDir("/a/b", "/a/b/c"))
+	assert.True(t, isInDir("/a/b", "/a/b/..c"))
+	assert.False(t, isInDir("/a/b", "/a/bc"))
+	assert.False(t, isInDir("/a/b", "/a"))
+}
@@ -2,6 +2,7 @@ package deployment
 
 import (
 	"context"
+	"github.com/kluctl/kluctl/v2/pkg/generated_secrets"
 	helm_auth "github.com/kluctl/kluctl/v2/pkg/helm/auth"
 	"github.com/kluctl/kluctl/v2/pkg/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/oci/auth_provider"
@@ -20,9 +21,28 @@ type SharedContext struct {
 	VarsLoader       *vars.VarsLoader
 	HelmAuthProvider helm_auth.HelmAuthProvider
 	OciAuthProvider  auth_provider.OciAuthProvider
+	GeneratedSecrets *generated_secrets.Store
+	// RenderCache is optional and allows to skip Helm rendering and kustomize builds for unchanged items
+	RenderCache *RenderCache
 
 	Discriminator                     string
 	RenderDir                         string
 	SealedSecretsDir                  string
 	DefaultSealedSecretsOutputPattern string
+
+	// KustomizeRemoteHosts restricts the hosts that remote kustomize resources can be fetched from, nil allows all hosts
+	KustomizeRemoteHosts []string
+	// AllowKustomizeRemoteHosts is the list of hosts that the environment (the controller) allows to fetch from, remote
+	// hosts must match both lists. nil allows all hosts
+	AllowKustomizeRemoteHosts []string
+	// KrmExecAllowList is the project's list of executables that exec KRM functions may run
+	KrmExecAllowList []string
+	// AllowKrmExec is the list of executables that the environment (CLI or controller) allows to run, exec KRM
+	// functions must match both lists
+	AllowKrmExec []string
+	// AllowKrmExecFromProject allows exec KRM functions to run executables that are part of the project
+	AllowKrmExecFromProject bool
+
+	// TemplatingHash is included in the objects hash, so that changes to templating libraries are detected
+	TemplatingHash string
 }
@@ -8,6 +8,9 @@ import (
 type Source struct {
 	id  string
 	dir string
+
+	// url is the git or oci url of included sources, used to describe include chains
+	url string
 }
 
 func NewSource(dir string) Source {
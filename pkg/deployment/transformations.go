@@ -0,0 +1,293 @@
+package deployment
+
+import (
+	"encoding/json"
+	"fmt"
+	json_patch "github.com/evanphx/json-patch/v5"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"k8s.io/apimachinery/pkg/labels"
+	"path/filepath"
+	"regexp"
+	kyaml "sigs.k8s.io/kustomize/kyaml/yaml"
+	"sigs.k8s.io/kustomize/kyaml/yaml/merge2"
+	"slices"
+)
+
+// TransformationTrace records the objects of a deployment item that a transformation was applied to
+type TransformationTrace struct {
+	Transformation string `json:"transformation"`
+	// Chain is the include chain of the deployment project that declared the transformation
+	Chain          []string         `json:"chain"`
+	DeploymentItem string           `json:"deploymentItem"`
+	Objects        []k8s2.ObjectRef `json:"objects"`
+}
+
+type transformationSetter struct {
+	fieldPath   *uo.MyJsonPath
+	value       []byte
+	regex       *regexp.Regexp
+	replacement string
+}
+
+// transformation is a compiled TransformationConfig
+type transformation struct {
+	name   string
+	chain  []string
+	target *types.TransformationTarget
+
+	labelSelector labels.Selector
+	patch         *kyaml.RNode
+	jsonPatch     json_patch.Patch
+	setters       []transformationSetter
+	remove        []*uo.MyJsonPath
+}
+
+func (p *DeploymentProject) compileTransformations() error {
+	for i, c := range p.Config.Transformations {
+		t, err := compileTransformation(c, i, p.traceChain)
+		if err != nil {
+			return err
+		}
+		p.transformations = append(p.transformations, t)
+	}
+	return nil
+}
+
+func compileTransformation(c types.TransformationConfig, index int, chain []string) (*transformation, error) {
+	t := &transformation{
+		name:   c.Name,
+		chain:  chain,
+		target: c.Target,
+	}
+	if t.name == "" {
+		t.name = fmt.Sprintf("transformations[%d]", index)
+	}
+
+	var err error
+	if c.Target != nil && c.Target.LabelSelector != "" {
+		t.labelSelector, err = labels.Parse(c.Target.LabelSelector)
+		if err != nil {
+			return nil, fmt.Errorf("invalid labelSelector in transformation %s: %w", t.name, err)
+		}
+	}
+	if c.Patch != nil {
+		t.patch, err = kyaml.FromMap(c.Patch.Object)
+		if err != nil {
+			return nil, fmt.Errorf("invalid patch in transformation %s: %w", t.name, err)
+		}
+	}
+	if len(c.JsonPatch) != 0 {
+		b, err := json.Marshal(c.JsonPatch)
+		if err != nil {
+			return nil, err
+		}
+		t.jsonPatch, err = json_patch.DecodePatch(b)
+		if err != nil {
+			return nil, fmt.Errorf("invalid jsonPatch in transformation %s: %w", t.name, err)
+		}
+	}
+	for _, s := range c.Set {
+		jp, err := uo.NewMyJsonPath(s.FieldPath)
+		if err != nil {
+			return nil, fmt.Errorf("invalid fieldPath %s in transformation %s: %w", s.FieldPath, t.name, err)
+		}
+		ts := transformationSetter{
+			fieldPath:   jp,
+			replacement: s.Replacement,
+		}
+		if s.Value != nil {
+			ts.value = s.Value.Raw
+		} else {
+			ts.regex, err = regexp.Compile(s.Regex)
+			if err != nil {
+				return nil, fmt.Errorf("invalid regex in transformation %s: %w", t.name, err)
+			}
+		}
+		t.setters = append(t.setters, ts)
+	}
+	for _, fp := range c.Remove {
+		jp, err := uo.NewMyJsonPath(fp)
+		if err != nil {
+			return nil, fmt.Errorf("invalid remove path %s in transformation %s: %w", fp, t.name, err)
+		}
+		t.remove = append(t.remove, jp)
+	}
+	return t, nil
+}
+
+// getTransformations returns the transformations of this project and all its parents, starting with the root project
+func (p *DeploymentProject) getTransformations() []*transformation {
+	var ret []*transformation
+	parents := p.getParents()
+	for i := range parents {
+		d := parents[len(parents)-i-1]
+		ret = append(ret, d.p.transformations...)
+	}
+	return ret
+}
+
+func (t *transformation) matches(o *uo.UnstructuredObject) bool {
+	if t.target == nil {
+		return true
+	}
+	checkMatch := func(v string, m *string) bool {
+		return m == nil || v == *m
+	}
+
+	gvk := o.GetK8sGVK()
+	if !checkMatch(gvk.Group, t.target.Group) || !checkMatch(gvk.Version, t.target.Version) || !checkMatch(gvk.Kind, t.target.Kind) {
+		return false
+	}
+	if !checkMatch(o.GetK8sName(), t.target.Name) || !checkMatch(o.GetK8sNamespace(), t.target.Namespace) {
+		return false
+	}
+	if t.labelSelector != nil && !t.labelSelector.Matches(labels.Set(o.GetK8sLabels())) {
+		return false
+	}
+	return true
+}
+
+func (t *transformation) apply(o *uo.UnstructuredObject) error {
+	m := o.Object
+	if t.patch != nil {
+		n, err := kyaml.FromMap(m)
+		if err != nil {
+			return err
+		}
+		n, err = merge2.Merge(t.patch.Copy(), n, kyaml.MergeOptions{
+			ListIncreaseDirection: kyaml.MergeOptionsListAppend,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to apply patch: %w", err)
+		}
+		m, err = n.Map()
+		if err != nil {
+			return err
+		}
+	}
+	if t.jsonPatch != nil {
+		b, err := json.Marshal(m)
+		if err != nil {
+			return err
+		}
+		b, err = t.jsonPatch.Apply(b)
+		if err != nil {
+			return fmt.Errorf("failed to apply jsonPatch: %w", err)
+		}
+		m = nil
+		err = json.Unmarshal(b, &m)
+		if err != nil {
+			return err
+		}
+	}
+	if t.patch != nil || t.jsonPatch != nil {
+		// modify the object in-place, as it might be an item of a List
+		for k := range o.Object {
+			delete(o.Object, k)
+		}
+		for k, v := range m {
+			o.Object[k] = v
+		}
+	}
+
+	for _, s := range t.setters {
+		err := s.apply(o)
+		if err != nil {
+			return err
+		}
+	}
+	for _, jp := range t.remove {
+		err := jp.Del(o)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *transformationSetter) apply(o *uo.UnstructuredObject) error {
+	if s.regex != nil {
+		fields, err := s.fieldPath.ListMatchingFields(o)
+		if err != nil {
+			return err
+		}
+		for _, kp := range fields {
+			v, ok, _ := o.GetNestedString(kp...)
+			if !ok {
+				continue
+			}
+			err = o.SetNestedField(s.regex.ReplaceAllString(v, s.replacement), kp...)
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	newValue := func() (any, error) {
+		var v any
+		err := json.Unmarshal(s.value, &v)
+		return v, err
+	}
+
+	// Set also creates missing fields, but assigns the same value to all of them
+	v, err := newValue()
+	if err != nil {
+		return err
+	}
+	err = s.fieldPath.Set(o, v)
+	if err != nil {
+		return err
+	}
+
+	// each field gets its own copy of the value, so that later modifications don't leak into other fields
+	fields, err := s.fieldPath.ListMatchingFields(o)
+	if err != nil {
+		return err
+	}
+	for _, kp := range fields[min(1, len(fields)):] {
+		v, err := newValue()
+		if err != nil {
+			return err
+		}
+		err = o.SetNestedField(v, kp...)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyTransformations applies all transformations of the project and its parents to o
+func (di *DeploymentItem) applyTransformations(transformations []*transformation, o *uo.UnstructuredObject) error {
+	for _, t := range transformations {
+		if !t.matches(o) {
+			continue
+		}
+		ref := o.GetK8sRef()
+		err := t.apply(o)
+		if err != nil {
+			return fmt.Errorf("transformation %s failed on %s: %w", t.name, ref.String(), err)
+		}
+		di.addTransformationTrace(t, ref)
+	}
+	return nil
+}
+
+func (di *DeploymentItem) addTransformationTrace(t *transformation, ref k8s2.ObjectRef) {
+	for i := range di.transformationTraces {
+		tt := &di.transformationTraces[i]
+		if tt.Transformation == t.name && slices.Equal(tt.Chain, t.chain) {
+			tt.Objects = append(tt.Objects, ref)
+			return
+		}
+	}
+	di.transformationTraces = append(di.transformationTraces, TransformationTrace{
+		Transformation: t.name,
+		Chain:          t.chain,
+		DeploymentItem: filepath.ToSlash(di.RelToSourceItemDir),
+		Objects:        []k8s2.ObjectRef{ref},
+	})
+}
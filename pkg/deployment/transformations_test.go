@@ -0,0 +1,170 @@
+package deployment
+
+import (
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/kluctl/kluctl/v2/pkg/yaml"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+const testTransformationDeployment = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+  namespace: ns
+  labels:
+    app: web
+  annotations:
+    unwanted: x
+spec:
+  replicas: 1
+  template:
+    spec:
+      containers:
+        - name: app
+          image: docker.io/library/nginx:1
+        - name: sidecar
+          image: quay.io/x/y:1
+`
+
+func compileTestTransformation(t *testing.T, s string) *transformation {
+	var c types.TransformationConfig
+	err := yaml.ReadYamlString(s, &c)
+	assert.NoError(t, err)
+	tr, err := compileTransformation(c, 0, []string{"deployment.yml"})
+	assert.NoError(t, err)
+	return tr
+}
+
+func applyTestTransformation(t *testing.T, s string) *uo.UnstructuredObject {
+	o, err := uo.FromString(testTransformationDeployment)
+	assert.NoError(t, err)
+	tr := compileTestTransformation(t, s)
+	if tr.matches(o) {
+		assert.NoError(t, tr.apply(o))
+	}
+	return o
+}
+
+func TestTransformationMatches(t *testing.T) {
+	o, err := uo.FromString(testTransformationDeployment)
+	assert.NoError(t, err)
+
+	type testCase struct {
+		target string
+		match  bool
+	}
+	tests := []testCase{
+		{target: "{}", match: true},
+		{target: "{group: apps, kind: Deployment}", match: true},
+		{target: "{group: apps, version: v1beta1}", match: false},
+		{target: "{kind: StatefulSet}", match: false},
+		{target: "{name: web, namespace: ns}", match: true},
+		{target: "{namespace: other}", match: false},
+		{target: "{labelSelector: 'app=web'}", match: true},
+		{target: "{labelSelector: 'app in (api, worker)'}", match: false},
+	}
+	for _, tc := range tests {
+		t.Run(tc.target, func(t *testing.T) {
+			tr := compileTestTransformation(t, "target: "+tc.target+"\nremove: [x]")
+			assert.Equal(t, tc.match, tr.matches(o))
+		})
+	}
+}
+
+func TestTransformationPatch(t *testing.T) {
+	o := applyTestTransformation(t, `
+patch:
+  spec:
+    template:
+      spec:
+        tolerations:
+          - key: dedicated
+            operator: Exists
+        containers:
+          - name: app
+            imagePullPolicy: Always
+`)
+	containers, _, _ := o.GetNestedObjectList("spec", "template", "spec", "containers")
+	if assert.Len(t, containers, 2) {
+		assert.Equal(t, map[string]any{"name": "app", "image": "docker.io/library/nginx:1", "imagePullPolicy": "Always"}, containers[0].Object)
+	}
+	tolerations, _, _ := o.GetNestedObjectList("spec", "template", "spec", "tolerations")
+	assert.Len(t, tolerations, 1)
+}
+
+func TestTransformationJsonPatch(t *testing.T) {
+	o := applyTestTransformation(t, `
+jsonPatch:
+  - op: replace
+    path: /spec/replicas
+    value: 3
+  - op: remove
+    path: /spec/template/spec/containers/1
+`)
+	replicas, _, _ := o.GetNestedInt("spec", "replicas")
+	assert.Equal(t, int64(3), replicas)
+	containers, _, _ := o.GetNestedObjectList("spec", "template", "spec", "containers")
+	assert.Len(t, containers, 1)
+}
+
+func TestTransformationSetAndRemove(t *testing.T) {
+	o := applyTestTransformation(t, `
+set:
+  - fieldPath: metadata.labels['network-policy']
+    value: restricted
+  - fieldPath: spec.template.spec.containers[*].resources
+    value: {limits: {memory: 128Mi}}
+  - fieldPath: spec.template.spec.containers[*].image
+    regex: ^docker\.io/
+    replacement: registry.example.com/
+remove:
+  - metadata.annotations.unwanted
+`)
+	assert.Equal(t, utils.Ptr("restricted"), o.GetK8sLabel("network-policy"))
+	assert.Empty(t, o.GetK8sAnnotations())
+
+	containers, _, _ := o.GetNestedObjectList("spec", "template", "spec", "containers")
+	if assert.Len(t, containers, 2) {
+		assert.Equal(t, "registry.example.com/library/nginx:1", containers[0].Object["image"])
+		assert.Equal(t, "quay.io/x/y:1", containers[1].Object["image"])
+
+		// each container must get its own copy of the value
+		_ = containers[0].SetNestedField("256Mi", "resources", "limits", "memory")
+		assert.Equal(t, map[string]any{"limits": map[string]any{"memory": "128Mi"}}, containers[1].Object["resources"])
+	}
+}
+
+func TestTransformationPatchListItem(t *testing.T) {
+	d, err := uo.FromString(testTransformationDeployment)
+	assert.NoError(t, err)
+	list := uo.FromMap(map[string]any{
+		"apiVersion": "v1",
+		"kind":       "List",
+		"items":      []any{d.Object},
+	})
+
+	tr := compileTestTransformation(t, "jsonPatch: [{op: replace, path: /spec/replicas, value: 2}]")
+	items, _, _ := list.GetNestedObjectList("items")
+	assert.NoError(t, tr.apply(items[0]))
+
+	items, _, _ = list.GetNestedObjectList("items")
+	replicas, _, _ := items[0].GetNestedInt("spec", "replicas")
+	assert.Equal(t, int64(2), replicas)
+}
+
+func TestCompileTransformationErrors(t *testing.T) {
+	_, err := compileTransformation(types.TransformationConfig{
+		Name:   "x",
+		Target: &types.TransformationTarget{LabelSelector: "app in ("},
+	}, 0, nil)
+	assert.ErrorContains(t, err, "invalid labelSelector in transformation x")
+
+	_, err = compileTransformation(types.TransformationConfig{
+		Set: []types.TransformationSetter{{FieldPath: "a", Regex: "("}},
+	}, 2, nil)
+	assert.ErrorContains(t, err, "invalid regex in transformation transformations[2]")
+}
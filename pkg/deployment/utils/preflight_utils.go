@@ -0,0 +1,147 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	"github.com/kluctl/kluctl/v2/pkg/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/status"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"sort"
+)
+
+// PreflightUtil collects all permissions required by a command and verifies them via SelfSubjectAccessReviews
+// before anything is applied or deleted. This avoids failing in the middle of a deployment due to missing RBAC rights,
+// e.g. when impersonating a restricted ServiceAccount.
+type PreflightUtil struct {
+	ctx context.Context
+	dew *DeploymentErrorsAndWarnings
+	k   *k8s.K8sCluster
+
+	checks map[k8s.AccessCheck]k8s2.ObjectRef
+}
+
+func NewPreflightUtil(ctx context.Context, dew *DeploymentErrorsAndWarnings, k *k8s.K8sCluster) *PreflightUtil {
+	return &PreflightUtil{
+		ctx:    ctx,
+		dew:    dew,
+		k:      k,
+		checks: map[k8s.AccessCheck]k8s2.ObjectRef{},
+	}
+}
+
+func (u *PreflightUtil) addCheck(ref k8s2.ObjectRef, verb string) {
+	c := k8s.AccessCheck{
+		GVK:       ref.GroupVersionKind(),
+		Namespace: ref.Namespace,
+		Verb:      verb,
+	}
+	if _, ok := u.checks[c]; !ok {
+		// remember the first object that requires this permission, so that we can report it later
+		u.checks[c] = ref
+	}
+}
+
+func isHookObject(o *uo.UnstructuredObject) bool {
+	return o.GetK8sAnnotation("kluctl.io/hook") != nil || o.GetK8sAnnotation("helm.sh/hook") != nil
+}
+
+// AddDeploymentChecks adds all checks required to apply the given deployment items with the given options.
+func (u *PreflightUtil) AddDeploymentChecks(deployments []*deployment.DeploymentItem, ru *RemoteObjectUtils, o *ApplyUtilOptions) {
+	for _, d := range deployments {
+		for _, x := range d.Config.DeleteObjects {
+			ars, err := u.k.GetFilteredPreferredAPIResources(k8s.BuildGVKFilter(x.Group, nil, x.Kind))
+			if err != nil {
+				u.dew.AddError(k8s2.ObjectRef{}, err)
+				continue
+			}
+			for _, ar := range ars {
+				u.addCheck(k8s2.NewObjectRef(ar.Group, ar.Version, ar.Kind, x.Name, x.Namespace), "delete")
+			}
+		}
+
+		for _, x := range d.Objects {
+			ref := x.GetK8sRef()
+			if x.GetK8sAnnotationBoolNoError("kluctl.io/delete", false) {
+				u.addCheck(ref, "delete")
+				continue
+			}
+
+			// server-side apply is a patch, but it requires create permissions if the object does not exist yet
+			u.addCheck(ref, "patch")
+			if ru.GetRemoteObject(ref) == nil {
+				u.addCheck(ref, "create")
+			}
+			if isHookObject(x) {
+				// hooks are deleted and re-created depending on their delete policies
+				u.addCheck(ref, "delete")
+				u.addCheck(ref, "create")
+			}
+			if o.ReplaceOnError || o.ForceReplaceOnError {
+				u.addCheck(ref, "update")
+			}
+			if o.ForceReplaceOnError {
+				u.addCheck(ref, "delete")
+				u.addCheck(ref, "create")
+			}
+		}
+	}
+}
+
+// AddDeleteChecks adds checks for the deletion of the given objects, e.g. orphan objects that are about to be pruned.
+func (u *PreflightUtil) AddDeleteChecks(refs []k8s2.ObjectRef) {
+	for _, ref := range refs {
+		u.addCheck(ref, "delete")
+	}
+}
+
+// Run performs all collected checks and reports missing permissions as errors. It returns false if at least one
+// permission is missing, in which case the caller must not touch the cluster.
+func (u *PreflightUtil) Run() bool {
+	if len(u.checks) == 0 {
+		return true
+	}
+
+	s := status.Start(u.ctx, "Checking permissions")
+	defer s.Failed()
+
+	checks := make([]k8s.AccessCheck, 0, len(u.checks))
+	for c := range u.checks {
+		checks = append(checks, c)
+	}
+	sort.Slice(checks, func(i, j int) bool {
+		return checks[i].String() < checks[j].String()
+	})
+
+	results, err := u.k.CheckAccess(checks)
+	if err != nil {
+		u.dew.AddError(k8s2.ObjectRef{}, err)
+		return false
+	}
+
+	missing := 0
+	for _, r := range results {
+		if r.Unknown {
+			status.Tracef(u.ctx, "skipping access check for %s: %s", r.AccessCheck.String(), r.Reason)
+			continue
+		}
+		if r.Allowed {
+			continue
+		}
+		msg := fmt.Sprintf("missing permission to %s", r.AccessCheck.String())
+		if r.Reason != "" {
+			msg += fmt.Sprintf(" (%s)", r.Reason)
+		}
+		u.dew.AddError(u.checks[r.AccessCheck], fmt.Errorf("%s", msg))
+		missing++
+	}
+
+	if missing != 0 {
+		s.FailedWithMessagef("%d permissions are missing", missing)
+		return false
+	}
+
+	s.Success()
+	return true
+}
@@ -0,0 +1,99 @@
+package generated_secrets
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/kluctl/kluctl/v2/pkg/status"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+)
+
+// these must match the markers in ext/generated_secrets_ext.py
+const beginPlaceholder = "XXXXXbegin_generated_secret_"
+const endPlaceholder = "_end_generated_secretXXXXX"
+
+// replacePlaceholders replaces all generated_secret(...) placeholders found in s by calling cb
+func replacePlaceholders(s string, cb func(spec Spec) (string, error)) (string, bool, error) {
+	var b strings.Builder
+	found := false
+	for {
+		start := strings.Index(s, beginPlaceholder)
+		if start == -1 {
+			break
+		}
+		end := strings.Index(s[start:], endPlaceholder)
+		if end == -1 {
+			return "", false, fmt.Errorf("generated secret begin marker without end marker")
+		}
+		end += start
+
+		j, err := base64.StdEncoding.DecodeString(s[start+len(beginPlaceholder) : end])
+		if err != nil {
+			return "", false, fmt.Errorf("failed to decode generated secret placeholder: %w", err)
+		}
+		var spec Spec
+		err = json.Unmarshal(j, &spec)
+		if err != nil {
+			return "", false, fmt.Errorf("failed to decode generated secret placeholder: %w", err)
+		}
+		v, err := cb(spec)
+		if err != nil {
+			return "", false, err
+		}
+
+		b.WriteString(s[:start])
+		b.WriteString(v)
+		s = s[end+len(endPlaceholder):]
+		found = true
+	}
+	if !found {
+		return s, false, nil
+	}
+	b.WriteString(s)
+	return b.String(), true, nil
+}
+
+// ResolvePlaceholders replaces all placeholders created by the generated_secret(...) Jinja2 function with the
+// values from the store. Objects that use values which are not persisted yet are remembered, so that they can be
+// reported via PendingObjects.
+func (s *Store) ResolvePlaceholders(o *uo.UnstructuredObject) error {
+	warned := false
+	var pendingNames []string
+	err := uo.NewObjectIterator(o.Object).IterateLeafs(func(it *uo.ObjectIterator) error {
+		str, ok := it.Value().(string)
+		if !ok {
+			return nil
+		}
+		newStr, found, err := replacePlaceholders(str, func(spec Spec) (string, error) {
+			v, pending, err := s.get(spec)
+			if pending && !slices.Contains(pendingNames, spec.Name) {
+				pendingNames = append(pendingNames, spec.Name)
+			}
+			return v, err
+		})
+		if err != nil {
+			return err
+		}
+		if !found {
+			return nil
+		}
+		if !warned && o.GetK8sGVK().GroupKind().String() != "Secret" {
+			warned = true
+			status.Warningf(s.ctx, "generated_secret(...) is used in %s, which is not a Secret. The generated value won't be hidden in diffs.", o.GetK8sRef().String())
+		}
+		return it.SetValue(newStr)
+	})
+	if err != nil {
+		return err
+	}
+	if len(pendingNames) != 0 {
+		slices.Sort(pendingNames)
+		s.mutex.Lock()
+		s.pendingObjects[o.GetK8sRef()] = pendingNames
+		s.mutex.Unlock()
+	}
+	return nil
+}
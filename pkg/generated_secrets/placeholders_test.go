@@ -0,0 +1,54 @@
+package generated_secrets
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func buildPlaceholder(t *testing.T, spec Spec) string {
+	j, err := json.Marshal(spec)
+	assert.NoError(t, err)
+	return beginPlaceholder + base64.StdEncoding.EncodeToString(j) + endPlaceholder
+}
+
+func TestReplacePlaceholders(t *testing.T) {
+	cb := func(spec Spec) (string, error) {
+		return "<" + spec.Name + ">", nil
+	}
+
+	s, found, err := replacePlaceholders("no placeholders", cb)
+	assert.NoError(t, err)
+	assert.False(t, found)
+	assert.Equal(t, "no placeholders", s)
+
+	s, found, err = replacePlaceholders("a="+buildPlaceholder(t, Spec{Name: "a"})+", b="+buildPlaceholder(t, Spec{Name: "b", Length: 5})+"!", cb)
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, "a=<a>, b=<b>!", s)
+
+	_, _, err = replacePlaceholders("x"+beginPlaceholder+"abc", cb)
+	assert.ErrorContains(t, err, "without end marker")
+}
+
+func TestGenerateValue(t *testing.T) {
+	spec := Spec{Name: "x", Length: 50, Charset: "ab"}
+	v, err := generateValue(spec)
+	assert.NoError(t, err)
+	assert.Len(t, v, 50)
+	assert.Empty(t, strings.Trim(v, "ab"))
+
+	spec = Spec{Name: "x"}.withDefaults()
+	v, err = generateValue(spec)
+	assert.NoError(t, err)
+	assert.Len(t, v, DefaultLength)
+}
+
+func TestSpecValidate(t *testing.T) {
+	assert.NoError(t, Spec{Name: "db-password.v1"}.withDefaults().validate())
+	assert.Error(t, Spec{Name: "db/password"}.withDefaults().validate())
+	assert.Error(t, Spec{Name: "x", Length: -1}.validate())
+}
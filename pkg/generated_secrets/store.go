@@ -0,0 +1,392 @@
+package generated_secrets
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"regexp"
+	"sync"
+
+	"github.com/kluctl/kluctl/v2/pkg/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/status"
+	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+const (
+	DefaultLength  = 32
+	DefaultCharset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+
+	discriminatorAnnotation = "kluctl.io/discriminator"
+	specsAnnotation         = "kluctl.io/generated-secret-specs"
+	managedLabel            = "kluctl.io/generated-secrets"
+
+	maxConflictRetries = 5
+)
+
+var validName = regexp.MustCompile(`^[-._a-zA-Z0-9]+$`)
+
+// Spec describes how a secret value is generated
+type Spec struct {
+	Name    string `json:"name"`
+	Length  int    `json:"length,omitempty"`
+	Charset string `json:"charset,omitempty"`
+}
+
+func (s Spec) withDefaults() Spec {
+	if s.Length == 0 {
+		s.Length = DefaultLength
+	}
+	if s.Charset == "" {
+		s.Charset = DefaultCharset
+	}
+	return s
+}
+
+func (s Spec) validate() error {
+	if !validName.MatchString(s.Name) {
+		return fmt.Errorf("invalid generated secret name '%s', only alphanumeric characters, '-', '_' and '.' are allowed", s.Name)
+	}
+	if s.Length <= 0 {
+		return fmt.Errorf("invalid length %d for generated secret %s", s.Length, s.Name)
+	}
+	return nil
+}
+
+// Store reads and writes generated secrets of a single target. All values are stored in one Secret per
+// discriminator inside the configured namespace, so that they are shared between all machines and the controller that
+// deploy the same target with the same namespace. The namespace is never created implicitly.
+// New values are only generated in-memory while rendering and are persisted via PersistPending right before a
+// deployment is actually applied, so that diffs, renders and dry-runs never write to the cluster.
+type Store struct {
+	ctx           context.Context
+	k             *k8s.K8sCluster
+	discriminator string
+	namespace     string
+
+	mutex sync.Mutex
+	// values that were already read or generated in this run
+	cache map[string]string
+	// values that were generated in this run but are not persisted yet
+	pending map[string]Spec
+	// objects that use pending values
+	pendingObjects map[k8s2.ObjectRef][]string
+}
+
+func NewStore(ctx context.Context, k *k8s.K8sCluster, discriminator string, namespace string) *Store {
+	return &Store{
+		ctx:            ctx,
+		k:              k,
+		discriminator:  discriminator,
+		namespace:      namespace,
+		cache:          map[string]string{},
+		pending:        map[string]Spec{},
+		pendingObjects: map[k8s2.ObjectRef][]string{},
+	}
+}
+
+func (s *Store) Namespace() string {
+	return s.namespace
+}
+
+func (s *Store) secretRef() k8s2.ObjectRef {
+	return k8s2.NewObjectRef("", "v1", "Secret", "generated-"+utils.Sha256String(s.discriminator)[:32], s.Namespace())
+}
+
+func (s *Store) checkDiscriminator() error {
+	if s.discriminator == "" {
+		return fmt.Errorf("generated secrets require a discriminator to be set for the target")
+	}
+	return nil
+}
+
+func (s *Store) checkNamespace() error {
+	if s.namespace == "" {
+		return fmt.Errorf("generated secrets require a namespace, set generatedSecrets.namespace for the target")
+	}
+	return nil
+}
+
+func (s *Store) checkUsable() error {
+	if s.k == nil {
+		return fmt.Errorf("generated secrets require access to the target cluster")
+	}
+	if err := s.checkDiscriminator(); err != nil {
+		return err
+	}
+	return s.checkNamespace()
+}
+
+// Get returns the value of the given generated secret. If it does not exist yet, a new value is generated and
+// remembered as pending, which is only persisted when PersistPending is called.
+func (s *Store) Get(spec Spec) (string, error) {
+	v, _, err := s.get(spec)
+	return v, err
+}
+
+func (s *Store) get(spec Spec) (string, bool, error) {
+	spec = spec.withDefaults()
+	if err := spec.validate(); err != nil {
+		return "", false, err
+	}
+	if err := s.checkDiscriminator(); err != nil {
+		return "", false, err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if v, ok := s.cache[spec.Name]; ok {
+		_, pending := s.pending[spec.Name]
+		return v, pending, nil
+	}
+
+	if s.k != nil {
+		if err := s.checkNamespace(); err != nil {
+			return "", false, err
+		}
+		o, err := s.getSecret()
+		if err != nil {
+			return "", false, fmt.Errorf("failed to get generated secret %s: %w", spec.Name, err)
+		}
+		if o != nil {
+			values, specs, err := parseSecret(o)
+			if err != nil {
+				return "", false, fmt.Errorf("failed to get generated secret %s: %w", spec.Name, err)
+			}
+			if v, ok := values[spec.Name]; ok {
+				if old, ok := specs[spec.Name]; ok && old != spec {
+					status.Warningf(s.ctx, "Parameters of generated secret %s have changed. Run 'kluctl secrets rotate %s' to generate a new value.", spec.Name, spec.Name)
+				}
+				s.cache[spec.Name] = v
+				return v, false, nil
+			}
+		}
+	}
+
+	v, err := generateValue(spec)
+	if err != nil {
+		return "", false, err
+	}
+	status.Infof(s.ctx, "Generated new secret value for %s, it will be persisted when the target is deployed", spec.Name)
+	s.cache[spec.Name] = v
+	s.pending[spec.Name] = spec
+	return v, true, nil
+}
+
+// PendingObjects returns the objects that use generated secrets which are not persisted yet, together with the names
+// of these secrets.
+func (s *Store) PendingObjects() map[k8s2.ObjectRef][]string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	ret := make(map[k8s2.ObjectRef][]string, len(s.pendingObjects))
+	for ref, names := range s.pendingObjects {
+		ret[ref] = append([]string{}, names...)
+	}
+	return ret
+}
+
+// PersistPending writes all values generated in this run to the cluster. It fails if another kluctl instance
+// persisted a value with the same name in the meantime, as the rendered objects would then use a different value.
+func (s *Store) PersistPending() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if len(s.pending) == 0 {
+		return nil
+	}
+	if err := s.checkUsable(); err != nil {
+		return err
+	}
+
+	err := s.modify(func(values map[string]string, specs map[string]Spec) (bool, error) {
+		for name, spec := range s.pending {
+			if _, ok := values[name]; ok {
+				return false, fmt.Errorf("generated secret %s was created concurrently, please retry", name)
+			}
+			values[name] = s.cache[name]
+			specs[name] = spec
+		}
+		return true, nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to persist generated secrets: %w", err)
+	}
+	for name := range s.pending {
+		status.Infof(s.ctx, "Persisted generated secret %s", name)
+	}
+	s.pending = map[string]Spec{}
+	s.pendingObjects = map[k8s2.ObjectRef][]string{}
+	return nil
+}
+
+// Rotate generates a new value for an existing generated secret, re-using the parameters it was created with.
+func (s *Store) Rotate(name string) error {
+	if err := s.checkUsable(); err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	err := s.modify(func(values map[string]string, specs map[string]Spec) (bool, error) {
+		if _, ok := values[name]; !ok {
+			return false, fmt.Errorf("generated secret %s not found", name)
+		}
+		spec, ok := specs[name]
+		if !ok {
+			spec = Spec{Name: name}.withDefaults()
+		}
+		v, err := generateValue(spec)
+		if err != nil {
+			return false, err
+		}
+		values[name] = v
+		return true, nil
+	})
+	if err != nil {
+		return err
+	}
+	delete(s.cache, name)
+	return nil
+}
+
+// List returns the specs of all generated secrets of the target
+func (s *Store) List() ([]Spec, error) {
+	if err := s.checkUsable(); err != nil {
+		return nil, err
+	}
+	o, err := s.getSecret()
+	if err != nil || o == nil {
+		return nil, err
+	}
+	values, specs, err := parseSecret(o)
+	if err != nil {
+		return nil, err
+	}
+	var ret []Spec
+	for n := range values {
+		spec, ok := specs[n]
+		if !ok {
+			spec = Spec{Name: n}.withDefaults()
+		}
+		ret = append(ret, spec)
+	}
+	return ret, nil
+}
+
+func (s *Store) getSecret() (*uo.UnstructuredObject, error) {
+	o, _, err := s.k.GetSingleObject(s.secretRef())
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return o, nil
+}
+
+// modify reads the Secret, calls cb to modify the values and writes it back if cb returned true. It retries on
+// conflicts, which happen when multiple kluctl instances generate values at the same time.
+func (s *Store) modify(cb func(values map[string]string, specs map[string]Spec) (bool, error)) error {
+	for i := 0; ; i++ {
+		o, err := s.getSecret()
+		if err != nil {
+			return err
+		}
+
+		values := map[string]string{}
+		specs := map[string]Spec{}
+		if o != nil {
+			values, specs, err = parseSecret(o)
+			if err != nil {
+				return err
+			}
+		}
+
+		changed, err := cb(values, specs)
+		if err != nil || !changed {
+			return err
+		}
+
+		err = s.writeSecret(o, values, specs)
+		if err == nil {
+			return nil
+		}
+		if !errors.IsConflict(err) && !errors.IsAlreadyExists(err) || i >= maxConflictRetries {
+			return err
+		}
+	}
+}
+
+func (s *Store) writeSecret(old *uo.UnstructuredObject, values map[string]string, specs map[string]Spec) error {
+	specsJson, err := json.Marshal(specs)
+	if err != nil {
+		return err
+	}
+
+	ref := s.secretRef()
+	secret := uo.New()
+	secret.SetK8sGVKs("", "v1", "Secret")
+	secret.SetK8sName(ref.Name)
+	secret.SetK8sNamespace(ref.Namespace)
+	secret.SetK8sLabel(managedLabel, "true")
+	secret.SetK8sAnnotation(discriminatorAnnotation, s.discriminator)
+	secret.SetK8sAnnotation(specsAnnotation, string(specsJson))
+	if old != nil {
+		// this ensures that we get a conflict if someone else modified the secret in the meantime
+		secret.SetK8sResourceVersion(old.GetK8sResourceVersion())
+	}
+	data := map[string]any{}
+	for k, v := range values {
+		data[k] = base64.StdEncoding.EncodeToString([]byte(v))
+	}
+	secret.Object["data"] = data
+
+	_, _, err = s.k.ReadWrite().ApplyObject(secret, k8s.PatchOptions{ForceApply: true})
+	return err
+}
+
+func parseSecret(o *uo.UnstructuredObject) (map[string]string, map[string]Spec, error) {
+	values := map[string]string{}
+	specs := map[string]Spec{}
+
+	data, _, err := o.GetNestedStringMapCopy("data")
+	if err != nil {
+		return nil, nil, err
+	}
+	for k, v := range data {
+		b, err := base64.StdEncoding.DecodeString(v)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to decode generated secret %s: %w", k, err)
+		}
+		values[k] = string(b)
+	}
+
+	if a := o.GetK8sAnnotation(specsAnnotation); a != nil {
+		err = json.Unmarshal([]byte(*a), &specs)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to parse %s annotation: %w", specsAnnotation, err)
+		}
+	}
+	return values, specs, nil
+}
+
+func generateValue(spec Spec) (string, error) {
+	charset := []rune(spec.Charset)
+	max := big.NewInt(int64(len(charset)))
+	ret := make([]rune, spec.Length)
+	for i := range ret {
+		n, err := rand.Int(rand.Reader, max)
+		if err != nil {
+			return "", err
+		}
+		ret[i] = charset[n.Int64()]
+	}
+	return string(ret), nil
+}
@@ -0,0 +1,55 @@
+package generated_secrets
+
+import (
+	"context"
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStoreNamespace(t *testing.T) {
+	s := NewStore(context.Background(), nil, "d", "")
+	assert.ErrorContains(t, s.checkNamespace(), "require a namespace")
+
+	// rendering without a cluster does not need a namespace
+	_, err := s.Get(Spec{Name: "a"})
+	assert.NoError(t, err)
+
+	s = NewStore(context.Background(), nil, "d", "ns")
+	assert.NoError(t, s.checkNamespace())
+	assert.Equal(t, "ns", s.secretRef().Namespace)
+}
+
+func TestStorePending(t *testing.T) {
+	s := NewStore(context.Background(), nil, "d", "ns")
+
+	v1, err := s.Get(Spec{Name: "a"})
+	assert.NoError(t, err)
+	v2, err := s.Get(Spec{Name: "a"})
+	assert.NoError(t, err)
+	assert.Equal(t, v1, v2)
+
+	o := uo.FromMap(map[string]any{
+		"apiVersion": "v1",
+		"kind":       "Secret",
+		"metadata": map[string]any{
+			"name":      "s",
+			"namespace": "ns",
+		},
+		"stringData": map[string]any{
+			"a": buildPlaceholder(t, Spec{Name: "a"}),
+			"b": buildPlaceholder(t, Spec{Name: "b"}),
+		},
+	})
+	assert.NoError(t, s.ResolvePlaceholders(o))
+	a, _, _ := o.GetNestedString("stringData", "a")
+	assert.Equal(t, v1, a)
+	assert.Equal(t, []string{"a", "b"}, s.PendingObjects()[o.GetK8sRef()])
+
+	// persisting requires a cluster
+	assert.ErrorContains(t, s.PersistPending(), "require access to the target cluster")
+
+	_, err = NewStore(context.Background(), nil, "", "ns").Get(Spec{Name: "a"})
+	assert.ErrorContains(t, err, "require a discriminator")
+}
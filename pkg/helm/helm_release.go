@@ -92,6 +92,26 @@ func NewRelease(ctx context.Context, projectRoot string, relDirInProject string,
 	return hr, nil
 }
 
+// GetLocalChartDir returns the directory of the local or pre-pulled chart. It returns an empty string if the chart
+// has to be pulled while rendering, either because pre-pulling is disabled or because it was not pre-pulled yet.
+func (hr *Release) GetLocalChartDir() (string, error) {
+	if hr.Chart.IsLocalChart() {
+		return hr.Chart.GetLocalPath(), nil
+	}
+	if hr.Config.SkipPrePull {
+		return "", nil
+	}
+	pc, err := hr.Chart.GetPrePulledChart(hr.baseChartsDir, hr.Config.ChartVersion)
+	if err != nil {
+		return "", err
+	}
+	needsPull, _, _, err := pc.CheckNeedsPull()
+	if err != nil || needsPull {
+		return "", err
+	}
+	return pc.dir, nil
+}
+
 func (hr *Release) GetOutputPath() string {
 	output := "helm-rendered.yaml"
 	if hr.Config.Output != nil {
@@ -0,0 +1,55 @@
+package helm
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/helm/auth"
+	"github.com/kluctl/kluctl/v2/pkg/oci/auth_provider"
+	"io/fs"
+	"path/filepath"
+)
+
+// LoadProjectReleases loads all helm-chart.yaml files found in projectDir. Local charts are skipped. Releases that use
+// the same chart share the same Chart instance, which is also returned in the list of charts.
+func LoadProjectReleases(ctx context.Context, projectDir string, baseChartsDir string, helmAuthProvider auth.HelmAuthProvider, ociAuthProvider auth_provider.OciAuthProvider) ([]*Release, []*Chart, error) {
+	var releases []*Release
+	chartsMap := make(map[string]*Chart)
+	err := filepath.WalkDir(projectDir, func(p string, d fs.DirEntry, err error) error {
+		fname := filepath.Base(p)
+		if fname != "helm-chart.yml" && fname != "helm-chart.yaml" {
+			return nil
+		}
+
+		relDir, err := filepath.Rel(projectDir, filepath.Dir(p))
+		if err != nil {
+			return err
+		}
+
+		hr, err := NewRelease(ctx, projectDir, relDir, p, baseChartsDir, helmAuthProvider, ociAuthProvider)
+		if err != nil {
+			return err
+		}
+
+		if hr.Chart.IsLocalChart() {
+			return nil
+		}
+
+		releases = append(releases, hr)
+		chart := hr.Chart
+		key := fmt.Sprintf("%s / %s", chart.GetRepo(), chart.GetChartName())
+		if x, ok := chartsMap[key]; !ok {
+			chartsMap[key] = chart
+		} else {
+			hr.Chart = x
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	charts := make([]*Chart, 0, len(chartsMap))
+	for _, chart := range chartsMap {
+		charts = append(charts, chart)
+	}
+	return releases, charts, nil
+}
@@ -0,0 +1,119 @@
+package k8s
+
+import (
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/status"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sync"
+)
+
+// AccessCheck describes a single permission that is required to perform an operation on the cluster.
+type AccessCheck struct {
+	GVK       schema.GroupVersionKind
+	Namespace string
+	Verb      string
+}
+
+func (c AccessCheck) String() string {
+	s := fmt.Sprintf("%s %s", c.Verb, c.GVK.GroupKind().String())
+	if c.Namespace != "" {
+		s += fmt.Sprintf(" in namespace %s", c.Namespace)
+	}
+	return s
+}
+
+type accessReviewKey struct {
+	resource  schema.GroupVersionResource
+	namespace string
+	verb      string
+}
+
+type AccessCheckResult struct {
+	AccessCheck
+
+	Allowed bool
+	Reason  string
+
+	// Unknown is true when the check could not be performed, e.g. because the resource is not known to the cluster yet
+	Unknown bool
+}
+
+// CheckAccess performs SelfSubjectAccessReviews for all given checks. Duplicate checks are only performed once and
+// results are cached for the lifetime of the K8sCluster object, so that multiple commands running against the same
+// cluster do not repeat identical reviews.
+func (k *K8sCluster) CheckAccess(checks []AccessCheck) ([]AccessCheckResult, error) {
+	ret := make([]AccessCheckResult, len(checks))
+
+	var mutex sync.Mutex
+	var firstErr error
+
+	g := utils.NewGoHelper(k.ctx, 0)
+	for i, c := range checks {
+		i := i
+		c := c
+		ret[i].AccessCheck = c
+
+		rm, err := k.mapper.RESTMapping(c.GVK.GroupKind(), c.GVK.Version)
+		if err != nil {
+			// the resource is probably defined by a CRD that is part of the same deployment
+			ret[i].Unknown = true
+			ret[i].Reason = err.Error()
+			continue
+		}
+
+		key := accessReviewKey{
+			resource:  rm.Resource,
+			namespace: c.Namespace,
+			verb:      c.Verb,
+		}
+		g.Run(func() {
+			r, err := k.accessReviewCache.Get(key, func() (*authorizationv1.SubjectAccessReviewStatus, error) {
+				return k.doAccessReview(key)
+			})
+			if err != nil {
+				mutex.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mutex.Unlock()
+				return
+			}
+			ret[i].Allowed = r.Allowed
+			ret[i].Reason = r.Reason
+		})
+	}
+	g.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+	return ret, nil
+}
+
+func (k *K8sCluster) doAccessReview(key accessReviewKey) (*authorizationv1.SubjectAccessReviewStatus, error) {
+	status.Tracef(k.ctx, "performing access review for verb=%s, resource=%s, namespace=%s", key.verb, key.resource.String(), key.namespace)
+
+	review := &authorizationv1.SelfSubjectAccessReview{
+		Spec: authorizationv1.SelfSubjectAccessReviewSpec{
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace: key.namespace,
+				Verb:      key.verb,
+				Group:     key.resource.Group,
+				Version:   key.resource.Version,
+				Resource:  key.resource.Resource,
+			},
+		},
+	}
+
+	// access reviews are never persisted, so we can always use a non-dry-run client
+	_, err := k.clients.withCClientFromPool(k.ctx, false, func(c client.Client) error {
+		return c.Create(k.ctx, review)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to perform access review for %s %s: %w", key.verb, key.resource.String(), err)
+	}
+	return &review.Status, nil
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	authorizationv1 "k8s.io/api/authorization/v1"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
@@ -45,6 +46,8 @@ type K8sCluster struct {
 
 	crdCache      map[k8s.ObjectRef]any
 	crdCacheMutex *sync.Mutex
+
+	accessReviewCache *utils.ThreadSafeCache[accessReviewKey, *authorizationv1.SubjectAccessReviewStatus]
 }
 
 func NewK8sCluster(ctx context.Context,
@@ -63,6 +66,8 @@ func NewK8sCluster(ctx context.Context,
 		discoveryMutex: &sync.Mutex{},
 		crdCache:       map[k8s.ObjectRef]any{},
 		crdCacheMutex:  &sync.Mutex{},
+
+		accessReviewCache: &utils.ThreadSafeCache[accessReviewKey, *authorizationv1.SubjectAccessReviewStatus]{},
 	}
 
 	k.clients, err = newK8sClients(k, 16)
@@ -0,0 +1,32 @@
+package k8s
+
+import (
+	"fmt"
+	authenticationv1 "k8s.io/api/authentication/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+// CreateServiceAccountToken requests a short-lived token for the given ServiceAccount via the TokenRequest API.
+// TokenRequests are never persisted, so this is also performed when running in dry-run mode.
+func (k *K8sCluster) CreateServiceAccountToken(namespace string, name string, audiences []string) (string, error) {
+	tr := &authenticationv1.TokenRequest{
+		Spec: authenticationv1.TokenRequestSpec{
+			Audiences: audiences,
+		},
+	}
+
+	var ret *authenticationv1.TokenRequest
+	_, err := k.clients.withClientFromPool(k.ctx, func(p *parallelClientEntry) error {
+		c, err := corev1.NewForConfigAndClient(p.config, p.httpClient)
+		if err != nil {
+			return err
+		}
+		ret, err = c.ServiceAccounts(namespace).CreateToken(k.ctx, name, tr, metav1.CreateOptions{})
+		return err
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to request token for ServiceAccount %s/%s: %w", namespace, name, err)
+	}
+	return ret.Status.Token, nil
+}
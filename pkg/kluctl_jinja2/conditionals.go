@@ -7,7 +7,7 @@ import (
 	"strings"
 )
 
-func RenderConditionals(j *jinja2.Jinja2, vars map[string]any, conditionals []string) ([]string, error) {
+func RenderConditionals(j *jinja2.Jinja2, vars map[string]any, conditionals []string, opts ...jinja2.Jinja2Opt) ([]string, error) {
 	ret := make([]string, len(conditionals))
 	jobs := make([]*jinja2.RenderJob, 0, len(conditionals))
 
@@ -17,7 +17,7 @@ func RenderConditionals(j *jinja2.Jinja2, vars map[string]any, conditionals []st
 		}
 		jobs = append(jobs, job)
 	}
-	err := j.RenderStrings(jobs, jinja2.WithGlobals(vars))
+	err := j.RenderStrings(jobs, append([]jinja2.Jinja2Opt{jinja2.WithGlobals(vars)}, opts...)...)
 	if err != nil {
 		return nil, err
 	}
@@ -38,8 +38,8 @@ func RenderConditionals(j *jinja2.Jinja2, vars map[string]any, conditionals []st
 	return ret, err
 }
 
-func RenderConditional(j *jinja2.Jinja2, vars map[string]any, conditional string) (string, error) {
-	rendered, err := RenderConditionals(j, vars, []string{conditional})
+func RenderConditional(j *jinja2.Jinja2, vars map[string]any, conditional string, opts ...jinja2.Jinja2Opt) (string, error) {
+	rendered, err := RenderConditionals(j, vars, []string{conditional}, opts...)
 	if err != nil {
 		return "", err
 	}
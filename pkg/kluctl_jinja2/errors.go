@@ -0,0 +1,115 @@
+package kluctl_jinja2
+
+import (
+	"errors"
+	"fmt"
+	"github.com/hashicorp/go-multierror"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	renderDirectoryErrorRegex = regexp.MustCompile(`^(?s)failed rendering template '([^']+)': (.*)$`)
+	tracebackLocationRegex    = regexp.MustCompile(`File "([^"]+)", line (\d+), in [^\n]*\n`)
+)
+
+// TemplateError is a render error that points to the original template file and line
+type TemplateError struct {
+	// Root is the template that was rendered
+	Root string
+	// File and Line point to the template that caused the error, which might be an included template
+	File    string
+	Line    int
+	Message string
+	// Chain is the include chain of deployment projects and the deployment item
+	Chain []string
+}
+
+func (e *TemplateError) Error() string {
+	s := e.Message
+	if e.File != "" {
+		loc := e.File
+		if e.Line != 0 {
+			loc = fmt.Sprintf("%s:%d", loc, e.Line)
+		}
+		s = fmt.Sprintf("%s: %s", loc, s)
+	}
+	if e.Root != "" && e.Root != e.File {
+		s += fmt.Sprintf(", included from %s", e.Root)
+	}
+	if len(e.Chain) != 0 {
+		s += fmt.Sprintf(" (%s)", strings.Join(e.Chain, " -> "))
+	}
+	return s
+}
+
+// MapTemplateErrors converts errors returned by go-jinja2 into TemplateErrors. Paths inside baseDir are made
+// relative to it. Multiple errors returned from RenderDirectory are converted one by one, other errors are returned
+// as is.
+func MapTemplateErrors(err error, baseDir string, chain []string) error {
+	if err == nil {
+		return nil
+	}
+	var merr *multierror.Error
+	if errors.As(err, &merr) {
+		var ret *multierror.Error
+		for _, e := range merr.Errors {
+			ret = multierror.Append(ret, mapTemplateError(e, baseDir, chain))
+		}
+		return ret.ErrorOrNil()
+	}
+	return mapTemplateError(err, baseDir, chain)
+}
+
+func mapTemplateError(err error, baseDir string, chain []string) error {
+	msg := err.Error()
+	te := &TemplateError{
+		Chain: chain,
+	}
+	if m := renderDirectoryErrorRegex.FindStringSubmatch(msg); m != nil {
+		te.Root = relToBaseDir(m[1], baseDir)
+		msg = m[2]
+	}
+
+	// go-jinja2 returns the last traceback entry that points into a template, followed by the code line and the
+	// exception
+	if loc := tracebackLocationRegex.FindStringSubmatchIndex(msg); loc != nil {
+		te.File = relToBaseDir(msg[loc[2]:loc[3]], baseDir)
+		te.Line, _ = strconv.Atoi(msg[loc[4]:loc[5]])
+		rest := msg[loc[1]:]
+		lines := strings.Split(strings.TrimRight(rest, "\n"), "\n")
+		// skip the code line
+		if len(lines) > 1 && strings.HasPrefix(lines[0], "    ") {
+			lines = lines[1:]
+		}
+		msg = strings.Join(lines, "\n")
+	} else if te.Root == "" {
+		// nothing to map
+		if len(chain) == 0 {
+			return err
+		}
+		return fmt.Errorf("%w (%s)", err, strings.Join(chain, " -> "))
+	}
+
+	if te.File == "<template>" {
+		te.File = ""
+	}
+	if te.File == "" {
+		te.File = te.Root
+	}
+	te.Message = strings.TrimSpace(msg)
+	return te
+}
+
+func relToBaseDir(p string, baseDir string) string {
+	if baseDir == "" || !filepath.IsAbs(p) {
+		return p
+	}
+	r, err := filepath.Rel(baseDir, p)
+	if err != nil || strings.HasPrefix(r, "..") {
+		return p
+	}
+	return filepath.ToSlash(r)
+}
@@ -0,0 +1,25 @@
+import base64
+import json
+
+from jinja2.ext import Extension
+
+begin_placeholder = "XXXXXbegin_generated_secret_"
+end_placeholder = "_end_generated_secretXXXXX"
+
+class GeneratedSecretsExtension(Extension):
+    def __init__(self, environment):
+        super().__init__(environment)
+        environment.globals["generated_secret"] = self.generated_secret
+
+    def generated_secret(self, name, length=None, charset=None):
+        placeholder = {
+            "name": name,
+        }
+        if length is not None:
+            placeholder["length"] = length
+        if charset is not None:
+            placeholder["charset"] = charset
+        j = json.dumps(placeholder)
+        j = base64.b64encode(j.encode("utf8")).decode("utf8")
+        j = begin_placeholder + j + end_placeholder
+        return j
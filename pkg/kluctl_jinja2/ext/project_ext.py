@@ -0,0 +1,37 @@
+import importlib
+import sys
+from types import ModuleType
+
+from jinja2.ext import Extension
+from jinja2.utils import import_string
+
+templating_global = "__kluctl_templating"
+
+
+class ProjectTemplatingExtension(Extension):
+    """Loads the extensions configured via 'templating' in .kluctl.yaml.
+
+    The configuration is passed via a hidden global, as the Python path and extensions might differ between renders.
+    Extensions can either be Jinja2 extension classes or modules. Modules can provide the dictionaries 'filters',
+    'globals' and 'tests', which are then added to the environment.
+    """
+
+    def __init__(self, environment):
+        super().__init__(environment)
+        cfg = environment.globals.pop(templating_global, None)
+        if not cfg:
+            return
+
+        for p in reversed(cfg.get("pythonPath", [])):
+            if p not in sys.path:
+                sys.path.insert(0, p)
+                importlib.invalidate_caches()
+
+        for e in cfg.get("extensions", []):
+            x = import_string(e)
+            if isinstance(x, ModuleType):
+                environment.filters.update(getattr(x, "filters", {}))
+                environment.globals.update(getattr(x, "globals", {}))
+                environment.tests.update(getattr(x, "tests", {}))
+            else:
+                environment.add_extension(x)
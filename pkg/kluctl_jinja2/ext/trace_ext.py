@@ -0,0 +1,172 @@
+import json
+import os
+import sys
+
+from jinja2 import StrictUndefined, nodes, meta
+from jinja2.ext import Extension
+
+trace_global = "__kluctl_trace"
+trace_chain_global = "__kluctl_trace_chain"
+
+
+class TraceExtension(Extension):
+    """Records template accesses and undefined variable lookups.
+
+    The configuration is passed via hidden globals. Records are appended as JSON lines to the configured file, as the
+    render results can not carry any additional information. Without configuration, this extension does nothing.
+    """
+
+    def __init__(self, environment):
+        super().__init__(environment)
+        self.cfg = environment.globals.pop(trace_global, None)
+        self.chain = environment.globals.pop(trace_chain_global, None)
+        self.in_parse = False
+        self.seen = set()
+        if not self.cfg:
+            return
+
+        if self.cfg.get("full"):
+            # included templates must be parsed for every root template, so that accesses are not only attributed to
+            # the first root template that includes them
+            environment.cache = None
+
+        base = environment.undefined
+        if self.cfg.get("full") or not issubclass(base, StrictUndefined):
+            environment.undefined = self.build_undefined(base)
+
+    def build_undefined(self, base):
+        ext = self
+
+        class TracingUndefined(base):
+            __slots__ = ()
+
+            def __init__(self, *args, **kwargs):
+                super().__init__(*args, **kwargs)
+                ext.record_undefined(self, False)
+
+            def __str__(self):
+                ext.record_undefined(self, True)
+                return super().__str__()
+
+            def __iter__(self):
+                ext.record_undefined(self, True)
+                return super().__iter__()
+
+        return TracingUndefined
+
+    def record_undefined(self, u, used):
+        if not used and not self.cfg.get("full"):
+            return
+        template, line = find_template_location()
+        rec = {
+            "kind": "undefined",
+            "template": template,
+            "line": line,
+            "message": u._undefined_message,
+            "used": used,
+        }
+        self.write(rec, True)
+
+    def preprocess(self, source, name, filename=None):
+        if not self.cfg or not self.cfg.get("full") or self.in_parse:
+            return source
+
+        self.in_parse = True
+        try:
+            ast = self.environment.parse(source, name, filename)
+        except Exception:
+            # syntax errors are reported by the actual parsing step
+            return source
+        finally:
+            self.in_parse = False
+
+        self.write({
+            "kind": "template",
+            "template": name or filename,
+            "vars": find_var_paths(self.environment, ast),
+        }, False)
+        return source
+
+    def current_root(self):
+        for l in getattr(self.environment.loader, "loaders", []):
+            if hasattr(l, "root_template"):
+                return l.root_template
+        return None
+
+    def write(self, rec, dedup):
+        rec["root"] = self.current_root()
+        rec["chain"] = self.chain
+        line = json.dumps(rec, sort_keys=True)
+        if dedup:
+            if line in self.seen:
+                return
+            self.seen.add(line)
+
+        # O_APPEND guarantees that lines from parallel renderers do not get mixed up
+        fd = os.open(self.cfg["file"], os.O_WRONLY | os.O_APPEND | os.O_CREAT, 0o600)
+        try:
+            os.write(fd, (line + "\n").encode("utf8"))
+        finally:
+            os.close(fd)
+
+
+def find_template_location():
+    f = sys._getframe(1)
+    while f is not None:
+        t = f.f_globals.get("__jinja_template__")
+        if t is not None:
+            return t.name or t.filename, t.get_corresponding_lineno(f.f_lineno)
+        f = f.f_back
+    return None, None
+
+
+def node_path(node):
+    parts = []
+    while True:
+        if isinstance(node, nodes.Getattr):
+            parts.append(node.attr)
+            node = node.node
+        elif isinstance(node, nodes.Getitem) and isinstance(node.arg, nodes.Const) and \
+                isinstance(node.arg.value, (str, int)):
+            parts.append(str(node.arg.value))
+            node = node.node
+        elif isinstance(node, nodes.Name):
+            parts.append(node.name)
+            return list(reversed(parts))
+        else:
+            return None
+
+
+def is_function_global(v):
+    if callable(v):
+        return True
+    # e.g. 'images' and 'version', which only contain functions
+    return isinstance(v, dict) and len(v) != 0 and all(callable(x) for x in v.values())
+
+
+def find_var_paths(environment, ast):
+    # vars are passed as globals, which find_undeclared_variables would ignore
+    env_globals = environment.globals
+    environment.globals = {}
+    try:
+        undeclared = meta.find_undeclared_variables(ast)
+    finally:
+        environment.globals = env_globals
+
+    paths = set()
+    for n in ast.find_all((nodes.Getattr, nodes.Getitem, nodes.Name)):
+        p = node_path(n)
+        if not p or p[0] not in undeclared:
+            continue
+        if is_function_global(env_globals.get(p[0])):
+            continue
+        paths.add(".".join(p))
+
+    # also handle get_var("a.b.c", default)
+    for n in ast.find_all(nodes.Call):
+        if isinstance(n.node, nodes.Name) and n.node.name == "get_var" and n.args and \
+                isinstance(n.args[0], nodes.Const) and isinstance(n.args[0].value, str):
+            paths.add(n.args[0].value)
+
+    # only keep the longest paths
+    return sorted(p for p in paths if not any(x.startswith(p + ".") for x in paths))
@@ -12,7 +12,7 @@ import (
 
 const parallelism = 4
 
-func NewKluctlJinja2(ctx context.Context, strict bool) (*x.Jinja2, error) {
+func NewKluctlJinja2(ctx context.Context, strict bool, opts ...x.Jinja2Opt) (*x.Jinja2, error) {
 	tmpDir := filepath.Join(utils.GetCacheDir(ctx), "go-embed-jinja2")
 
 	if testing.Testing() {
@@ -26,14 +26,17 @@ func NewKluctlJinja2(ctx context.Context, strict bool) (*x.Jinja2, error) {
 		return nil, err
 	}
 
-	return x.NewJinja2("kluctl",
-		parallelism,
+	opts = append([]x.Jinja2Opt{
 		x.WithStrict(strict),
 		x.WithExtension("jinja2.ext.loopcontrols"),
 		x.WithExtension("go_jinja2.ext.kluctl"),
 		x.WithExtension("go_jinja2.ext.time"),
 		x.WithExtension("ext.images_ext.ImagesExtension"),
+		x.WithExtension("ext.generated_secrets_ext.GeneratedSecretsExtension"),
+		x.WithExtension("ext.trace_ext.TraceExtension"),
 		x.WithPythonPath(extSrc.GetExtractedPath()),
 		x.WithEmbeddedExtractDir(tmpDir),
-	)
+	}, opts...)
+
+	return x.NewJinja2("kluctl", parallelism, opts...)
 }
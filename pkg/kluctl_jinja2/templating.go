@@ -0,0 +1,38 @@
+package kluctl_jinja2
+
+import (
+	"github.com/kluctl/go-jinja2"
+)
+
+// ProjectTemplating holds the resolved 'templating' configuration of a Kluctl project
+type ProjectTemplating struct {
+	// PythonPath contains absolute directories which are added to the Python path
+	PythonPath []string
+	// Extensions contains the import names of Jinja2 extensions or Python modules
+	Extensions []string
+	// SearchDirs contains absolute directories which are searched for includes and imports, e.g. macro libraries
+	SearchDirs []string
+}
+
+// RenderOpts returns the options that must be passed to every render call that should use the project specific
+// extensions and search dirs. As the Python processes are started before the project is loaded, extensions are
+// loaded per render via ext.project_ext.ProjectTemplatingExtension.
+func (t *ProjectTemplating) RenderOpts() []jinja2.Jinja2Opt {
+	if t == nil {
+		return nil
+	}
+	var ret []jinja2.Jinja2Opt
+	if len(t.PythonPath) != 0 || len(t.Extensions) != 0 {
+		ret = append(ret,
+			jinja2.WithExtension("ext.project_ext.ProjectTemplatingExtension"),
+			jinja2.WithGlobal("__kluctl_templating", map[string]any{
+				"pythonPath": t.PythonPath,
+				"extensions": t.Extensions,
+			}),
+		)
+	}
+	if len(t.SearchDirs) != 0 {
+		ret = append(ret, jinja2.WithSearchDirs(t.SearchDirs))
+	}
+	return ret
+}
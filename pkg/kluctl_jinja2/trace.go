@@ -0,0 +1,232 @@
+package kluctl_jinja2
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/kluctl/go-jinja2"
+	"github.com/kluctl/kluctl/v2/pkg/status"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	traceGlobal      = "__kluctl_trace"
+	traceChainGlobal = "__kluctl_trace_chain"
+)
+
+// TemplateTracer collects undefined variable lookups and, if full tracing is enabled, the variables and includes
+// accessed by all rendered templates. ext.trace_ext.TraceExtension appends records to a file, as the render results
+// can not carry additional information.
+type TemplateTracer struct {
+	path string
+	full bool
+}
+
+// TemplateTrace describes a single root template, e.g. a file of a deployment item
+type TemplateTrace struct {
+	// File is the root template. It is empty for inline templates, e.g. when: conditions or strings inside vars sources
+	File string `json:"file,omitempty"`
+	// Chain is the include chain of deployment projects and the deployment item that rendered the template
+	Chain []string `json:"chain,omitempty"`
+	// Includes contains all templates that were included or imported by the root template
+	Includes []string `json:"includes,omitempty"`
+	// Vars contains the variables accessed by the root template and all its includes
+	Vars      []string          `json:"vars,omitempty"`
+	Undefined []UndefinedLookup `json:"undefined,omitempty"`
+}
+
+type UndefinedLookup struct {
+	Template string `json:"template,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Message  string `json:"message"`
+	// Used is true if the undefined value was rendered or iterated, in contrast to checks like 'is defined' or the
+	// 'default' filter
+	Used bool `json:"used"`
+}
+
+type traceRecord struct {
+	Kind     string   `json:"kind"`
+	Root     *string  `json:"root"`
+	Chain    []string `json:"chain"`
+	Template *string  `json:"template"`
+	Line     int      `json:"line"`
+	Message  string   `json:"message"`
+	Used     bool     `json:"used"`
+	Vars     []string `json:"vars"`
+}
+
+// NewTemplateTracer creates a tracer that stores its records inside tmpDir. Undefined variables that are rendered
+// are always recorded, while full tracing also records all variable accesses and includes.
+func NewTemplateTracer(tmpDir string, full bool) (*TemplateTracer, error) {
+	f, err := os.CreateTemp(tmpDir, "template-trace-")
+	if err != nil {
+		return nil, err
+	}
+	_ = f.Close()
+	return &TemplateTracer{
+		path: f.Name(),
+		full: full,
+	}, nil
+}
+
+func (t *TemplateTracer) Close() {
+	_ = os.Remove(t.path)
+}
+
+// Opts returns the options that must be passed to NewKluctlJinja2
+func (t *TemplateTracer) Opts() []jinja2.Jinja2Opt {
+	return []jinja2.Jinja2Opt{
+		jinja2.WithGlobal(traceGlobal, map[string]any{
+			"file": t.path,
+			"full": t.full,
+		}),
+	}
+}
+
+// Reset removes all records collected so far
+func (t *TemplateTracer) Reset() error {
+	return os.Truncate(t.path, 0)
+}
+
+// WithTraceChain sets the include chain that is attached to all records of the render call
+func WithTraceChain(chain []string) jinja2.Jinja2Opt {
+	return jinja2.WithGlobal(traceChainGlobal, chain)
+}
+
+func (t *TemplateTracer) readRecords() ([]traceRecord, error) {
+	f, err := os.Open(t.path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var ret []traceRecord
+	s := bufio.NewScanner(f)
+	s.Buffer(nil, 16*1024*1024)
+	for s.Scan() {
+		var r traceRecord
+		err = json.Unmarshal(s.Bytes(), &r)
+		if err != nil {
+			return nil, fmt.Errorf("invalid template trace record: %w", err)
+		}
+		ret = append(ret, r)
+	}
+	return ret, s.Err()
+}
+
+// Report aggregates all records per root template and include chain. Paths inside baseDir are made relative to it.
+func (t *TemplateTracer) Report(baseDir string) ([]*TemplateTrace, error) {
+	records, err := t.readRecords()
+	if err != nil {
+		return nil, err
+	}
+
+	relPath := func(p *string) string {
+		if p == nil {
+			return ""
+		}
+		return relToBaseDir(*p, baseDir)
+	}
+
+	byKey := map[string]*TemplateTrace{}
+	seenVars := map[*TemplateTrace]map[string]bool{}
+	seenIncludes := map[*TemplateTrace]map[string]bool{}
+	seenUndefined := map[*TemplateTrace]map[UndefinedLookup]bool{}
+	for _, r := range records {
+		root := relPath(r.Root)
+		key := root + "\x00" + strings.Join(r.Chain, "\x00")
+		tt, ok := byKey[key]
+		if !ok {
+			tt = &TemplateTrace{
+				File:  root,
+				Chain: r.Chain,
+			}
+			byKey[key] = tt
+			seenVars[tt] = map[string]bool{}
+			seenIncludes[tt] = map[string]bool{}
+			seenUndefined[tt] = map[UndefinedLookup]bool{}
+		}
+
+		template := relPath(r.Template)
+		switch r.Kind {
+		case "template":
+			if template != "" && template != root && !seenIncludes[tt][template] {
+				seenIncludes[tt][template] = true
+				tt.Includes = append(tt.Includes, template)
+			}
+			for _, v := range r.Vars {
+				if !seenVars[tt][v] {
+					seenVars[tt][v] = true
+					tt.Vars = append(tt.Vars, v)
+				}
+			}
+		case "undefined":
+			u := UndefinedLookup{
+				Template: template,
+				Line:     r.Line,
+				Message:  r.Message,
+				Used:     r.Used,
+			}
+			if !seenUndefined[tt][u] {
+				seenUndefined[tt][u] = true
+				tt.Undefined = append(tt.Undefined, u)
+			}
+		}
+	}
+
+	ret := make([]*TemplateTrace, 0, len(byKey))
+	for _, tt := range byKey {
+		sort.Strings(tt.Includes)
+		sort.Strings(tt.Vars)
+		ret = append(ret, tt)
+	}
+	sort.SliceStable(ret, func(i, j int) bool {
+		if ret[i].File != ret[j].File {
+			return ret[i].File < ret[j].File
+		}
+		return strings.Join(ret[i].Chain, "\x00") < strings.Join(ret[j].Chain, "\x00")
+	})
+	return ret, nil
+}
+
+// WarnUndefined emits a warning for every undefined variable that was rendered or iterated. This only happens in
+// non-strict mode, as strict mode fails with an error instead.
+func (t *TemplateTracer) WarnUndefined(ctx context.Context, baseDir string) {
+	report, err := t.Report(baseDir)
+	if err != nil {
+		status.Warningf(ctx, "Failed to read template trace: %s", err.Error())
+		return
+	}
+	for _, tt := range report {
+		for _, u := range tt.Undefined {
+			if !u.Used {
+				continue
+			}
+			status.Warningf(ctx, "Undefined variable rendered as empty value: %s", u.describe(tt))
+		}
+	}
+}
+
+func (u *UndefinedLookup) describe(tt *TemplateTrace) string {
+	s := u.Message
+	loc := u.Template
+	if loc == "" {
+		loc = tt.File
+	}
+	if loc != "" {
+		if u.Line != 0 {
+			loc = fmt.Sprintf("%s:%d", loc, u.Line)
+		}
+		s = fmt.Sprintf("%s: %s", loc, s)
+	}
+	if u.Template != "" && u.Template != tt.File && tt.File != "" {
+		s += fmt.Sprintf(", included from %s", tt.File)
+	}
+	if len(tt.Chain) != 0 {
+		s += fmt.Sprintf(" (%s)", strings.Join(tt.Chain, " -> "))
+	}
+	return s
+}
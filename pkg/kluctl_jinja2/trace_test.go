@@ -0,0 +1,78 @@
+package kluctl_jinja2
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kluctl/go-jinja2"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestJinja2(t *testing.T, strict bool, full bool) (*jinja2.Jinja2, *TemplateTracer) {
+	tracer, err := NewTemplateTracer(t.TempDir(), full)
+	assert.NoError(t, err)
+	t.Cleanup(tracer.Close)
+
+	j2, err := NewKluctlJinja2(context.Background(), strict, tracer.Opts()...)
+	assert.NoError(t, err)
+	t.Cleanup(j2.Close)
+	return j2, tracer
+}
+
+func writeTestTemplates(t *testing.T) string {
+	dir := t.TempDir()
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "root.yaml"), []byte("a: {{ a.b }}\n{% include './inc.j2' %}\n"), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "inc.j2"), []byte("c: {{ get_var('c.d', 'x') }}\ne: {{ e }}\nf: {{ f | default('y') }}\n"), 0o600))
+	return dir
+}
+
+func TestTraceUndefinedNonStrict(t *testing.T) {
+	j2, tracer := newTestJinja2(t, false, false)
+	dir := writeTestTemplates(t)
+
+	r, err := j2.RenderFile(filepath.Join(dir, "root.yaml"), jinja2.WithSearchDir(dir), jinja2.WithGlobal("a", map[string]any{"b": "v"}), WithTraceChain([]string{"deployment.yml", "item"}))
+	assert.NoError(t, err)
+	assert.Equal(t, "a: v\nc: x\ne: \nf: y", r)
+
+	report, err := tracer.Report(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []*TemplateTrace{{
+		File:  "root.yaml",
+		Chain: []string{"deployment.yml", "item"},
+		Undefined: []UndefinedLookup{
+			{Template: "inc.j2", Line: 2, Message: "'e' is undefined", Used: true},
+		},
+	}}, report)
+}
+
+func TestTraceFull(t *testing.T) {
+	j2, tracer := newTestJinja2(t, true, true)
+	dir := writeTestTemplates(t)
+
+	_, err := j2.RenderFile(filepath.Join(dir, "root.yaml"), jinja2.WithSearchDir(dir), jinja2.WithGlobal("a", map[string]any{"b": "v"}), jinja2.WithGlobal("e", 1))
+	assert.NoError(t, err)
+
+	report, err := tracer.Report(dir)
+	assert.NoError(t, err)
+	assert.Equal(t, []*TemplateTrace{{
+		File:     "root.yaml",
+		Includes: []string{"inc.j2"},
+		Vars:     []string{"a.b", "c.d", "e", "f"},
+		Undefined: []UndefinedLookup{
+			{Template: "inc.j2", Line: 3, Message: "'f' is undefined", Used: false},
+		},
+	}}, report)
+}
+
+func TestMapTemplateErrors(t *testing.T) {
+	j2, _ := newTestJinja2(t, true, false)
+	dir := writeTestTemplates(t)
+	target := t.TempDir()
+
+	err := j2.RenderDirectory(dir, target, []string{"inc.j2"}, jinja2.WithGlobal("a", map[string]any{"b": "v"}), jinja2.WithSearchDir(dir))
+	assert.Error(t, err)
+	err = MapTemplateErrors(err, dir, []string{"deployment.yml", "item"})
+	assert.EqualError(t, err, "1 error occurred:\n\t* inc.j2:2: UndefinedError: 'e' is undefined, included from root.yaml (deployment.yml -> item)\n\n")
+}
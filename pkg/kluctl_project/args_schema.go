@@ -0,0 +1,57 @@
+package kluctl_project
+
+import (
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/xeipuuv/gojsonschema"
+	"strings"
+)
+
+// ParseArgSchema parses the JSON schema of the given arg. It returns nil if the arg has no schema.
+func ParseArgSchema(a types.DeploymentArg) (*gojsonschema.Schema, error) {
+	if a.Schema == nil {
+		return nil, nil
+	}
+	schema, err := gojsonschema.NewSchema(gojsonschema.NewBytesLoader(a.Schema.Raw))
+	if err != nil {
+		return nil, fmt.Errorf("invalid schema for argument %s: %w", a.Name, err)
+	}
+	return schema, nil
+}
+
+// checkArgsSchema validates all args that have a schema. All errors are collected so that users can fix them in one go.
+func checkArgsSchema(argsDef []types.DeploymentArg, args *uo.UnstructuredObject) error {
+	var errs []string
+	for _, a := range argsDef {
+		schema, err := ParseArgSchema(a)
+		if err != nil {
+			return err
+		}
+		if schema == nil {
+			continue
+		}
+		v, found, _ := args.GetNestedField(argKeyPath(a.Name)...)
+		if !found {
+			continue
+		}
+
+		result, err := schema.Validate(gojsonschema.NewGoLoader(v))
+		if err != nil {
+			return fmt.Errorf("failed to validate argument %s: %w", a.Name, err)
+		}
+		for _, e := range result.Errors() {
+			field := a.Name
+			if e.Field() != gojsonschema.STRING_ROOT_SCHEMA_PROPERTY {
+				field += "." + e.Field()
+			}
+			// some descriptions are prefixed with the field name, which is redundant here
+			desc := strings.TrimPrefix(e.Description(), e.Field()+" ")
+			errs = append(errs, fmt.Sprintf("%s: %s", field, desc))
+		}
+	}
+	if len(errs) != 0 {
+		return fmt.Errorf("invalid arguments:\n  %s", strings.Join(errs, "\n  "))
+	}
+	return nil
+}
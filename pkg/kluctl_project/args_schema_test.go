@@ -0,0 +1,54 @@
+package kluctl_project
+
+import (
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"testing"
+)
+
+func buildSchemaArg(name string, schema string) types.DeploymentArg {
+	return types.DeploymentArg{
+		Name:   name,
+		Schema: &apiextensionsv1.JSON{Raw: []byte(schema)},
+	}
+}
+
+func TestCheckArgsSchema(t *testing.T) {
+	argsDef := []types.DeploymentArg{
+		buildSchemaArg("env", `{"type": "string", "enum": ["dev", "prod"]}`),
+		buildSchemaArg("replicas", `{"type": "integer", "minimum": 1, "maximum": 10}`),
+		buildSchemaArg("ingress", `{"type": "object", "properties": {"host": {"type": "string", "pattern": "^[a-z.]+$"}}}`),
+		{Name: "noSchema"},
+	}
+
+	args := uo.FromMap(map[string]any{
+		"env":      "dev",
+		"replicas": 3,
+		"ingress":  map[string]any{"host": "example.com"},
+		"noSchema": 42,
+	})
+	assert.NoError(t, checkArgsSchema(argsDef, args))
+
+	args = uo.FromMap(map[string]any{
+		"env":      "test",
+		"replicas": 20,
+		"ingress":  map[string]any{"host": "Example_Com"},
+	})
+	err := checkArgsSchema(argsDef, args)
+	assert.ErrorContains(t, err, "env: must be one of the following: \"dev\", \"prod\"")
+	assert.ErrorContains(t, err, "replicas: Must be less than or equal to 10")
+	assert.ErrorContains(t, err, "ingress.host: Does not match pattern")
+
+	// missing args are handled by checkRequiredArgs
+	assert.NoError(t, checkArgsSchema(argsDef, uo.New()))
+}
+
+func TestCheckArgsSchemaInvalidSchema(t *testing.T) {
+	argsDef := []types.DeploymentArg{
+		buildSchemaArg("a1", `{"type": "invalid"}`),
+	}
+	err := checkArgsSchema(argsDef, uo.FromMap(map[string]any{"a1": "x"}))
+	assert.ErrorContains(t, err, "invalid schema for argument a1")
+}
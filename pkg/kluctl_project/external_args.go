@@ -77,16 +77,24 @@ func LoadDefaultArgs(args []types.DeploymentArg, deployArgs *uo.UnstructuredObje
 	if err != nil {
 		return err
 	}
+	err = checkArgsSchema(args, deployArgs)
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
+func argKeyPath(name string) []interface{} {
+	var p []interface{}
+	for _, x := range strings.Split(name, ".") {
+		p = append(p, x)
+	}
+	return p
+}
+
 func checkRequiredArgs(argsDef []types.DeploymentArg, args *uo.UnstructuredObject) error {
 	for _, a := range argsDef {
-		var p []interface{}
-		for _, x := range strings.Split(a.Name, ".") {
-			p = append(p, x)
-		}
-		_, found, _ := args.GetNestedField(p...)
+		_, found, _ := args.GetNestedField(argKeyPath(a.Name)...)
 		if !found {
 			if a.Default == nil {
 				return fmt.Errorf("required argument %s not set", a.Name)
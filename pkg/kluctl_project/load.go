@@ -23,6 +23,10 @@ func LoadKluctlProject(ctx context.Context, args LoadKluctlProjectArgs, j2 *jinj
 	if err != nil {
 		return nil, err
 	}
+	err = p.loadTemplating()
+	if err != nil {
+		return nil, err
+	}
 	err = p.loadTargets(ctx)
 	if err != nil {
 		return nil, err
@@ -3,6 +3,7 @@ package kluctl_project
 import (
 	"fmt"
 	"github.com/kluctl/go-jinja2"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_jinja2"
 	"github.com/kluctl/kluctl/v2/pkg/repocache"
 	types2 "github.com/kluctl/kluctl/v2/pkg/types"
 	"time"
@@ -20,6 +21,11 @@ type LoadedKluctlProject struct {
 	J2    *jinja2.Jinja2
 	GitRP *repocache.GitRepoCache
 	OciRP *repocache.OciRepoCache
+
+	// Templating is the resolved 'templating' config, nil if not configured
+	Templating *kluctl_jinja2.ProjectTemplating
+	// TemplatingHash is a hash over all files of the templating libraries
+	TemplatingHash string
 }
 
 func (c *LoadedKluctlProject) FindTarget(name string) (*types2.Target, error) {
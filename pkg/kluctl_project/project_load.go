@@ -3,6 +3,7 @@ package kluctl_project
 import (
 	"context"
 	helm_auth "github.com/kluctl/kluctl/v2/pkg/helm/auth"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_jinja2"
 	"github.com/kluctl/kluctl/v2/pkg/oci/auth_provider"
 	"github.com/kluctl/kluctl/v2/pkg/repocache"
 	"github.com/kluctl/kluctl/v2/pkg/sops/decryptor"
@@ -27,8 +28,14 @@ type LoadKluctlProjectArgs struct {
 	OciAuthProvider  auth_provider.OciAuthProvider
 	HelmAuthProvider helm_auth.HelmAuthProvider
 
+	// AllowTemplatingExtensions allows templating.extensions and templating.pythonPath, which run arbitrary Python code
+	AllowTemplatingExtensions bool
+
 	AddKeyServersFunc  func(ctx context.Context, d *decryptor.Decryptor) error
 	ClientConfigGetter func(context *string) (*rest.Config, *api.Config, error)
+
+	// TemplateTracer is optional and receives the records of the Jinja2 instance passed to LoadKluctlProject
+	TemplateTracer *kluctl_jinja2.TemplateTracer
 }
 
 func (c *LoadedKluctlProject) getConfigPath() string {
@@ -6,6 +6,7 @@ import (
 	"github.com/kluctl/kluctl/v2/pkg/clouds/aws"
 	"github.com/kluctl/kluctl/v2/pkg/clouds/gcp"
 	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	"github.com/kluctl/kluctl/v2/pkg/generated_secrets"
 	"github.com/kluctl/kluctl/v2/pkg/helm/auth"
 	"github.com/kluctl/kluctl/v2/pkg/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/kluctl_project"
@@ -13,6 +14,7 @@ import (
 	"github.com/kluctl/kluctl/v2/pkg/status"
 	"github.com/kluctl/kluctl/v2/pkg/types"
 	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
 	"github.com/kluctl/kluctl/v2/pkg/vars"
 	"path/filepath"
 	"sigs.k8s.io/controller-runtime/pkg/client"
@@ -31,19 +33,37 @@ type TargetContext struct {
 }
 
 type TargetContextParams struct {
-	TargetName         string
-	TargetNameOverride string
-	ContextOverride    string
-	Discriminator      string
-	OfflineK8s         bool
-	K8sVersion         string
-	DryRun             bool
-	ForSeal            bool
-	Images             *deployment.Images
-	Inclusion          *utils.Inclusion
-	HelmAuthProvider   auth.HelmAuthProvider
-	OciAuthProvider    auth_provider.OciAuthProvider
-	RenderOutputDir    string
+	TargetName              string
+	TargetNameOverride      string
+	ContextOverride         string
+	Discriminator           string
+	OfflineK8s              bool
+	K8sVersion              string
+	DryRun                  bool
+	ForSeal                 bool
+	Images                  *deployment.Images
+	Inclusion               *utils.Inclusion
+	HelmAuthProvider        auth.HelmAuthProvider
+	OciAuthProvider         auth_provider.OciAuthProvider
+	RenderOutputDir         string
+	AllowExecVars           bool
+	AllowVaultTokenFiles    bool
+	AllowKrmExec            []string
+	AllowKrmExecFromProject bool
+	VarsSourceRegistry      *vars.VarsSourceRegistry
+	VarsProvenance          *vars.ProvenanceRecorder
+	VarsCache               vars.VarsCache
+	VarsCacheMode           vars.VarsCacheMode
+	VarsCacheScope          string
+	RenderCache             *deployment.RenderCache
+
+	// GeneratedSecretsNamespace is used for generated secrets if the target does not specify generatedSecrets.namespace
+	GeneratedSecretsNamespace string
+
+	// RequireKustomizeRemoteHosts forbids all kustomize remotes and manifest urls unless kustomize.remoteHosts is
+	// explicitly set and the host is also listed in AllowKustomizeRemoteHosts
+	RequireKustomizeRemoteHosts bool
+	AllowKustomizeRemoteHosts   []string
 }
 
 func NewTargetContext(ctx context.Context, p *kluctl_project.LoadedKluctlProject, contextName string, k *k8s.K8sCluster, params TargetContextParams) (*TargetContext, error) {
@@ -111,7 +131,51 @@ func NewTargetContext(ctx context.Context, p *kluctl_project.LoadedKluctlProject
 	if err != nil {
 		return nil, err
 	}
-	varsLoader := vars.NewVarsLoader(ctx, k, sopsDecryptor, p.GitRP, aws.NewClientFactory(client, target.Aws), gcp.NewClientFactory())
+
+	generatedSecretsNamespace := params.GeneratedSecretsNamespace
+	if target.GeneratedSecrets != nil && target.GeneratedSecrets.Namespace != "" {
+		generatedSecretsNamespace = target.GeneratedSecrets.Namespace
+	}
+	generatedSecrets := generated_secrets.NewStore(ctx, k, target.Discriminator, generatedSecretsNamespace)
+
+	varsLoader := vars.NewVarsLoader(ctx, k, sopsDecryptor, p.GitRP, p.OciRP, aws.NewClientFactory(client, target.Aws), gcp.NewClientFactory())
+	varsLoader.SetAllowExec(params.AllowExecVars)
+	varsLoader.SetAllowVaultTokenFiles(params.AllowVaultTokenFiles)
+	varsLoader.SetGeneratedSecretsStore(generatedSecrets)
+	if params.VarsSourceRegistry != nil {
+		varsLoader.SetRegistry(params.VarsSourceRegistry)
+	}
+	if params.VarsCache != nil {
+		varsLoader.SetCache(params.VarsCache, params.VarsCacheMode, params.VarsCacheScope)
+	}
+	if params.VarsProvenance != nil {
+		varsLoader.SetProvenanceRecorder(params.VarsProvenance)
+		for _, k := range []string{"target", "args"} {
+			o, _, _ := varsCtx.Vars.GetNestedObject(k)
+			if o != nil {
+				varsLoader.RecordProvenance(".kluctl.yaml", k, uo.New(), o, k)
+			}
+		}
+	}
+
+	var kustomizeRemoteHosts []string
+	if p.Config.Kustomize != nil {
+		kustomizeRemoteHosts = p.Config.Kustomize.RemoteHosts
+	}
+	var allowKustomizeRemoteHosts []string
+	if params.RequireKustomizeRemoteHosts {
+		if kustomizeRemoteHosts == nil {
+			kustomizeRemoteHosts = []string{}
+		}
+		allowKustomizeRemoteHosts = params.AllowKustomizeRemoteHosts
+		if allowKustomizeRemoteHosts == nil {
+			allowKustomizeRemoteHosts = []string{}
+		}
+	}
+	var krmExecAllowList []string
+	if p.Config.KrmFunctions != nil {
+		krmExecAllowList = p.Config.KrmFunctions.ExecAllowList
+	}
 
 	dctx := deployment.SharedContext{
 		Ctx:                               ctx,
@@ -123,10 +187,18 @@ func NewTargetContext(ctx context.Context, p *kluctl_project.LoadedKluctlProject
 		VarsLoader:                        varsLoader,
 		HelmAuthProvider:                  params.HelmAuthProvider,
 		OciAuthProvider:                   params.OciAuthProvider,
+		GeneratedSecrets:                  generatedSecrets,
+		RenderCache:                       params.RenderCache,
 		Discriminator:                     target.Discriminator,
 		RenderDir:                         params.RenderOutputDir,
 		SealedSecretsDir:                  p.SealedSecretsDir,
 		DefaultSealedSecretsOutputPattern: target.Name,
+		KustomizeRemoteHosts:              kustomizeRemoteHosts,
+		AllowKustomizeRemoteHosts:         allowKustomizeRemoteHosts,
+		KrmExecAllowList:                  krmExecAllowList,
+		AllowKrmExec:                      params.AllowKrmExec,
+		AllowKrmExecFromProject:           params.AllowKrmExecFromProject,
+		TemplatingHash:                    p.TemplatingHash,
 	}
 
 	targetCtx := &TargetContext{
@@ -176,7 +248,7 @@ func (tc *TargetContext) loadSecrets(ctx context.Context, target *types.Target,
 		if err != nil {
 			return err
 		}
-		err = varsLoader.LoadVarsList(ctx, varsCtx, secretEntry.Vars, searchDirs, "secrets")
+		err = varsLoader.LoadVarsListWithOrigin(ctx, varsCtx, secretEntry.Vars, searchDirs, "secrets", fmt.Sprintf(".kluctl.yaml secretSets[%s].vars", secretSetName))
 		if err != nil {
 			return err
 		}
@@ -0,0 +1,171 @@
+package kluctl_project
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	securejoin "github.com/cyphar/filepath-securejoin"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_jinja2"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+)
+
+func (c *LoadedKluctlProject) loadTemplating() error {
+	cfg := c.Config.Templating
+	if cfg == nil {
+		return nil
+	}
+
+	if (len(cfg.Extensions) != 0 || len(cfg.PythonPath) != 0) && !c.LoadArgs.AllowTemplatingExtensions {
+		return fmt.Errorf("templating.extensions and templating.pythonPath are disabled, as they execute arbitrary Python code. They must be explicitly enabled via --allow-templating-extensions")
+	}
+
+	t := &kluctl_jinja2.ProjectTemplating{
+		Extensions: cfg.Extensions,
+	}
+	for i, l := range cfg.PythonPath {
+		dir, err := c.resolveTemplatingLibrary(l)
+		if err != nil {
+			return fmt.Errorf("failed to resolve templating.pythonPath[%d]: %w", i, err)
+		}
+		t.PythonPath = append(t.PythonPath, dir)
+	}
+	for i, l := range cfg.SearchDirs {
+		dir, err := c.resolveTemplatingLibrary(l)
+		if err != nil {
+			return fmt.Errorf("failed to resolve templating.searchDirs[%d]: %w", i, err)
+		}
+		t.SearchDirs = append(t.SearchDirs, dir)
+	}
+
+	h, err := hashTemplating(t)
+	if err != nil {
+		return fmt.Errorf("failed to hash templating libraries: %w", err)
+	}
+
+	c.Templating = t
+	c.TemplatingHash = h
+	return nil
+}
+
+func (c *LoadedKluctlProject) resolveTemplatingLibrary(l types.TemplatingLibrary) (string, error) {
+	var dir string
+	var err error
+	if l.Path != nil {
+		dir = filepath.Join(c.LoadArgs.ProjectDir, *l.Path)
+		root := c.LoadArgs.RepoRoot
+		if root == "" {
+			root = c.LoadArgs.ProjectDir
+		}
+		err = utils.CheckInDir(root, dir)
+		if err != nil {
+			return "", err
+		}
+	} else if l.Git != nil {
+		if c.GitRP == nil {
+			return "", fmt.Errorf("no git repository cache available")
+		}
+		ge, err := c.GitRP.GetEntry(l.Git.Url.String())
+		if err != nil {
+			return "", err
+		}
+		cloneDir, _, err := ge.GetClonedDir(l.Git.Ref)
+		if err != nil {
+			return "", err
+		}
+		dir, err = securejoin.SecureJoin(cloneDir, l.Git.SubDir)
+		if err != nil {
+			return "", err
+		}
+	} else if l.Oci != nil {
+		if c.OciRP == nil {
+			return "", fmt.Errorf("no OCI repository cache available")
+		}
+		oe, err := c.OciRP.GetEntry(l.Oci.Url)
+		if err != nil {
+			return "", err
+		}
+		extractedDir, _, err := oe.GetExtractedDir(l.Oci.Ref)
+		if err != nil {
+			return "", err
+		}
+		dir, err = securejoin.SecureJoin(extractedDir, l.Oci.SubDir)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		return "", fmt.Errorf("invalid templating library")
+	}
+
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return "", err
+	}
+	if !utils.IsDirectory(dir) {
+		return "", fmt.Errorf("%s does not exist or is not a directory", dir)
+	}
+	return dir, nil
+}
+
+// hashTemplating calculates a hash over the configured extensions and all files inside the resolved directories. It
+// is part of the objects hash, so that the controller re-deploys when libraries change.
+func hashTemplating(t *kluctl_jinja2.ProjectTemplating) (string, error) {
+	h := sha256.New()
+	for _, e := range t.Extensions {
+		_, _ = fmt.Fprintf(h, "extension:%s\n", e)
+	}
+
+	hashDir := func(kind string, dir string) error {
+		var files []string
+		err := filepath.WalkDir(dir, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if d.Name() == ".git" || d.Name() == "__pycache__" {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			if d.Type().IsRegular() {
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		sort.Strings(files)
+
+		_, _ = fmt.Fprintf(h, "%s:%d\n", kind, len(files))
+		for _, p := range files {
+			b, err := os.ReadFile(p)
+			if err != nil {
+				return err
+			}
+			rel, err := filepath.Rel(dir, p)
+			if err != nil {
+				return err
+			}
+			fh := sha256.Sum256(b)
+			_, _ = fmt.Fprintf(h, "%s:%s\n", filepath.ToSlash(rel), hex.EncodeToString(fh[:]))
+		}
+		return nil
+	}
+
+	for _, d := range t.PythonPath {
+		if err := hashDir("pythonPath", d); err != nil {
+			return "", err
+		}
+	}
+	for _, d := range t.SearchDirs {
+		if err := hashDir("searchDir", d); err != nil {
+			return "", err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
@@ -8,6 +8,7 @@ import (
 
 func (p *LoadedKluctlProject) BuildVars(target *types.Target, forSeal bool) (*vars.VarsCtx, error) {
 	varsCtx := vars.NewVarsCtx(p.J2)
+	varsCtx.J2Opts = p.Templating.RenderOpts()
 
 	targetVars, err := uo.FromStruct(target)
 	if err != nil {
@@ -0,0 +1,339 @@
+package lint
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/helm"
+	helm_auth "github.com/kluctl/kluctl/v2/pkg/helm/auth"
+	"github.com/kluctl/kluctl/v2/pkg/oci/auth_provider"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/kluctl/kluctl/v2/pkg/yaml"
+	"io/fs"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+func (l *Linter) checkHelmCharts(ctx context.Context, helmAuthProvider helm_auth.HelmAuthProvider, ociAuthProvider auth_provider.OciAuthProvider) error {
+	baseChartsDir := filepath.Join(l.projectDir, ".helm-charts")
+	releases, _, err := helm.LoadProjectReleases(ctx, l.projectDir, baseChartsDir, helmAuthProvider, ociAuthProvider)
+	if err != nil {
+		return err
+	}
+	for _, hr := range releases {
+		if hr.Config.SkipPrePull {
+			continue
+		}
+		pc, err := hr.Chart.GetPrePulledChart(baseChartsDir, hr.Config.ChartVersion)
+		if err != nil {
+			return err
+		}
+		needsPull, versionChanged, prePulledVersion, err := pc.CheckNeedsPull()
+		if err != nil {
+			return err
+		}
+		if !needsPull {
+			continue
+		}
+		var msg string
+		if versionChanged {
+			msg = fmt.Sprintf("Helm Chart %s needs to be pulled, desired version is %s while pre-pulled version is %s", hr.Chart.GetChartName(), hr.Config.ChartVersion, prePulledVersion)
+		} else {
+			msg = fmt.Sprintf("Helm Chart %s with version %s has not been pre-pulled", hr.Chart.GetChartName(), hr.Config.ChartVersion)
+		}
+		l.addFinding(Finding{
+			Check:    CheckHelmChartNotPulled,
+			Severity: SeverityError,
+			Message:  msg + ", run 'kluctl helm-pull'",
+			File:     l.relPath(hr.ConfigFile),
+		}, "")
+	}
+	return nil
+}
+
+// splitVarPath converts a path as returned by uo.KeyPath.ToJsonPath into the dotted form used by the template trace.
+// It returns nil for paths that can not be compared, e.g. list indexes.
+func splitVarPath(p string) []string {
+	var ret []string
+	p = strings.TrimPrefix(p, "$")
+	for len(p) != 0 {
+		switch {
+		case p[0] == '.':
+			p = p[1:]
+		case strings.HasPrefix(p, `["`), strings.HasPrefix(p, `['`):
+			q := p[1]
+			end := strings.IndexByte(p[2:], q)
+			if end == -1 || !strings.HasPrefix(p[2+end+1:], "]") {
+				return nil
+			}
+			ret = append(ret, p[2:2+end])
+			p = p[2+end+2:]
+		case p[0] == '[':
+			return nil
+		default:
+			end := strings.IndexAny(p, ".[")
+			if end == -1 {
+				end = len(p)
+			}
+			ret = append(ret, p[:end])
+			p = p[end:]
+		}
+	}
+	return ret
+}
+
+func isPathPrefix(prefix string, p string) bool {
+	return p == prefix || strings.HasPrefix(p, prefix+".")
+}
+
+func (l *Linter) isVarUsed(p string) bool {
+	for a := range l.accessedVars {
+		// accessing a parent (e.g. iterating over a dict) uses all children, while accessing a child of a leaf
+		// (e.g. a method call on a string) uses the leaf
+		if isPathPrefix(a, p) || isPathPrefix(p, a) {
+			return true
+		}
+	}
+	return false
+}
+
+func (l *Linter) checkUnusedVars() {
+	leafs := map[string]*definedVar{}
+	for p, dv := range l.definedVars {
+		s := splitVarPath(p)
+		if s == nil {
+			continue
+		}
+		leafs[strings.Join(s, ".")] = dv
+	}
+
+	unused := map[string]bool{}
+	for p := range leafs {
+		if !l.isVarUsed(p) {
+			unused[p] = true
+		}
+	}
+
+	allUnused := func(prefix string) bool {
+		for p := range leafs {
+			if isPathPrefix(prefix, p) && !unused[p] {
+				return false
+			}
+		}
+		return true
+	}
+
+	// report the highest ancestor whose leafs are all unused instead of each leaf
+	reported := map[string][]string{}
+	for p := range unused {
+		s := strings.Split(p, ".")
+		for i := 1; i <= len(s); i++ {
+			a := strings.Join(s[:i], ".")
+			if allUnused(a) {
+				reported[a] = append(reported[a], p)
+				break
+			}
+		}
+	}
+
+	for _, a := range sortedKeys(reported) {
+		var origins []string
+		targets := map[string]bool{}
+		for _, p := range reported[a] {
+			dv := leafs[p]
+			for _, o := range dv.origins {
+				if utils.FindStrInSlice(origins, o) == -1 {
+					origins = append(origins, o)
+				}
+			}
+			for t := range dv.targets {
+				targets[t] = true
+			}
+		}
+		sort.Strings(origins)
+		f := Finding{
+			Check:    CheckUnusedVar,
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("variable %s is defined but never used, defined by %s", a, strings.Join(origins, ", ")),
+		}
+		for _, t := range sortedKeys(targets) {
+			l.addFinding(f, t)
+		}
+	}
+}
+
+func (l *Linter) checkUndefinedVars() {
+	for k, u := range l.undefined {
+		rendered := l.renderedTemplates[k.template]
+		undefinedInAll := true
+		for t := range rendered {
+			if !u.targets[t] {
+				undefinedInAll = false
+				break
+			}
+		}
+		if !undefinedInAll {
+			continue
+		}
+
+		severity := SeverityInfo
+		msg := fmt.Sprintf("%s in all targets, but only checked for existence", k.message)
+		if u.used {
+			severity = SeverityWarning
+			msg = fmt.Sprintf("%s in all targets", k.message)
+		}
+		l.addFinding(Finding{
+			Check:    CheckUndefinedVar,
+			Severity: severity,
+			Message:  msg,
+			File:     k.template,
+			Line:     k.line,
+		}, "")
+	}
+}
+
+func (l *Linter) checkConstantConditions() {
+	for k, c := range l.conditions {
+		if len(c.results) < 2 {
+			// a single target does not tell anything
+			continue
+		}
+		var value string
+		constant := true
+		for _, r := range c.results {
+			if value == "" {
+				value = r
+			} else if r != value {
+				constant = false
+				break
+			}
+		}
+		if !constant {
+			continue
+		}
+
+		where := k.project
+		if k.index == -1 {
+			where += " when"
+		} else {
+			where += fmt.Sprintf(" deployments[%d].when", k.index)
+		}
+		l.addFinding(Finding{
+			Check:    CheckConstantCondition,
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("condition '%s' in %s is %s for all targets", c.when, where, value),
+		}, "")
+	}
+}
+
+func (l *Linter) checkUnselectedItems() {
+	if len(l.kluctlDeployments) == 0 {
+		return
+	}
+	selected := map[string]bool{}
+	for _, kd := range l.kluctlDeployments {
+		if len(kd.includeTags) == 0 {
+			// this one deploys everything
+			return
+		}
+		for _, t := range kd.includeTags {
+			selected[t] = true
+		}
+	}
+
+	for _, dir := range sortedKeys(l.items) {
+		it := l.items[dir]
+		found := false
+		for t := range it.tags {
+			if selected[t] {
+				found = true
+				break
+			}
+		}
+		if found {
+			continue
+		}
+		l.addFinding(Finding{
+			Check:    CheckUnselectedItem,
+			Severity: SeverityWarning,
+			Message:  fmt.Sprintf("deployment item %s with tags [%s] is not selected by the includeTags of any KluctlDeployment", dir, strings.Join(sortedKeys(it.tags), ", ")),
+			File:     dir,
+		}, "")
+	}
+}
+
+// addKustomizeReferences marks local resources, components and bases of a kustomization as owned. The rendered
+// kustomization is used, as the source might still contain templates.
+func (l *Linter) addKustomizeReferences(dir string, renderedDir string, visited map[string]bool) {
+	if visited[renderedDir] {
+		return
+	}
+	visited[renderedDir] = true
+
+	p := yaml.FixPathExt(filepath.Join(renderedDir, "kustomization.yml"))
+	if !utils.IsFile(p) {
+		return
+	}
+	ky, err := uo.FromFile(p)
+	if err != nil {
+		// reported by the actual rendering
+		return
+	}
+	for _, field := range []string{"resources", "components", "bases"} {
+		refs, _, _ := ky.GetNestedStringList(field)
+		for _, r := range refs {
+			if strings.Contains(r, "://") || filepath.IsAbs(r) {
+				continue
+			}
+			subRendered := filepath.Join(renderedDir, r)
+			if !utils.IsDirectory(subRendered) {
+				continue
+			}
+			subDir := filepath.Join(dir, r)
+			l.ownedDirs[subDir] = true
+			l.addKustomizeReferences(subDir, subRendered, visited)
+		}
+	}
+}
+
+func (l *Linter) checkUnreachableDirs() error {
+	isDeploymentDir := func(dir string) bool {
+		for _, n := range []string{"deployment.yml", "kustomization.yml", "helm-chart.yml"} {
+			if utils.IsFile(yaml.FixPathExt(filepath.Join(dir, n))) {
+				return true
+			}
+		}
+		return false
+	}
+
+	return filepath.WalkDir(l.projectDir, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() {
+			return nil
+		}
+		if p != l.projectDir && strings.HasPrefix(d.Name(), ".") {
+			return filepath.SkipDir
+		}
+		if l.projectDirs[p] {
+			// included deployment projects can contain further includes and items
+			return nil
+		}
+		if l.ownedDirs[p] || utils.IsFile(filepath.Join(p, "Chart.yaml")) {
+			// everything inside items and local Helm charts belongs to them
+			return filepath.SkipDir
+		}
+		if isDeploymentDir(p) {
+			l.addFinding(Finding{
+				Check:    CheckUnreachableDir,
+				Severity: SeverityWarning,
+				Message:  fmt.Sprintf("directory %s is not referenced by any target", l.relPath(p)),
+				File:     l.relPath(p),
+			}, "")
+			return filepath.SkipDir
+		}
+		return nil
+	})
+}
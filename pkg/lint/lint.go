@@ -0,0 +1,459 @@
+package lint
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/deployment"
+	helm_auth "github.com/kluctl/kluctl/v2/pkg/helm/auth"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_jinja2"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_project/target-context"
+	"github.com/kluctl/kluctl/v2/pkg/oci/auth_provider"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/vars"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+)
+
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+	SeverityInfo    Severity = "info"
+)
+
+var severityOrder = map[Severity]int{
+	SeverityError:   0,
+	SeverityWarning: 1,
+	SeverityInfo:    2,
+}
+
+const (
+	CheckRenderError        = "render-error"
+	CheckUnusedVar          = "unused-var"
+	CheckUndefinedVar       = "undefined-var"
+	CheckConstantCondition  = "constant-condition"
+	CheckUnselectedItem     = "unselected-item"
+	CheckUnreachableDir     = "unreachable-dir"
+	CheckHelmChartNotPulled = "helm-chart-not-pulled"
+	CheckDuplicateObject    = "duplicate-object"
+)
+
+type Finding struct {
+	Check    string   `json:"check"`
+	Severity Severity `json:"severity"`
+	Message  string   `json:"message"`
+	File     string   `json:"file,omitempty"`
+	Line     int      `json:"line,omitempty"`
+	// Targets contains the targets the finding applies to. It is empty if the finding applies to the project as a whole.
+	Targets []string `json:"targets,omitempty"`
+}
+
+type Result struct {
+	Targets  []string  `json:"targets"`
+	Findings []Finding `json:"findings"`
+}
+
+func (r *Result) Count(severity Severity) int {
+	n := 0
+	for _, f := range r.Findings {
+		if f.Severity == severity {
+			n++
+		}
+	}
+	return n
+}
+
+type definedVar struct {
+	origins []string
+	targets map[string]bool
+}
+
+type undefinedKey struct {
+	template string
+	line     int
+	message  string
+}
+
+type undefinedLookup struct {
+	used    bool
+	targets map[string]bool
+}
+
+type conditionKey struct {
+	project string
+	index   int
+}
+
+type condition struct {
+	when    string
+	results map[string]string
+}
+
+type item struct {
+	tags    map[string]bool
+	targets map[string]bool
+}
+
+type kluctlDeployment struct {
+	includeTags []string
+}
+
+// Linter collects information about all targets of a project and then statically analyses it. Targets are rendered
+// by the caller in offline mode and passed in one by one via AddTarget.
+type Linter struct {
+	repoRoot   string
+	projectDir string
+
+	targets       []string
+	failedTargets []string
+	findings      map[string]*Finding
+
+	accessedVars      map[string]bool
+	definedVars       map[string]*definedVar
+	undefined         map[undefinedKey]*undefinedLookup
+	renderedTemplates map[string]map[string]bool
+	conditions        map[conditionKey]*condition
+	projectDirs       map[string]bool
+	ownedDirs         map[string]bool
+	items             map[string]*item
+	kluctlDeployments []kluctlDeployment
+}
+
+func NewLinter(repoRoot string, projectDir string) *Linter {
+	return &Linter{
+		repoRoot:          repoRoot,
+		projectDir:        projectDir,
+		findings:          map[string]*Finding{},
+		accessedVars:      map[string]bool{},
+		definedVars:       map[string]*definedVar{},
+		undefined:         map[undefinedKey]*undefinedLookup{},
+		renderedTemplates: map[string]map[string]bool{},
+		conditions:        map[conditionKey]*condition{},
+		projectDirs:       map[string]bool{},
+		ownedDirs:         map[string]bool{},
+		items:             map[string]*item{},
+	}
+}
+
+func (l *Linter) relPath(p string) string {
+	r, err := filepath.Rel(l.repoRoot, p)
+	if err != nil || strings.HasPrefix(r, "..") {
+		return p
+	}
+	return filepath.ToSlash(r)
+}
+
+// addFinding merges findings that only differ in the affected targets
+func (l *Linter) addFinding(f Finding, target string) {
+	key := fmt.Sprintf("%s\x00%s\x00%d\x00%s", f.Check, f.File, f.Line, f.Message)
+	existing, ok := l.findings[key]
+	if !ok {
+		existing = &f
+		l.findings[key] = existing
+	}
+	if target != "" {
+		existing.Targets = append(existing.Targets, target)
+	}
+}
+
+// AddTemplateTrace adds template accesses that happened outside of targets, e.g. while loading the project.
+func (l *Linter) AddTemplateTrace(trace []*kluctl_jinja2.TemplateTrace) {
+	for _, tt := range trace {
+		for _, v := range tt.Vars {
+			l.accessedVars[v] = true
+		}
+	}
+}
+
+// AddTargetError records a target that failed to render. Checks that require all targets to be rendered are skipped
+// in that case.
+func (l *Linter) AddTargetError(target string, err error) {
+	l.targets = append(l.targets, target)
+	l.failedTargets = append(l.failedTargets, target)
+	l.addFinding(Finding{
+		Check:    CheckRenderError,
+		Severity: SeverityError,
+		Message:  err.Error(),
+	}, target)
+}
+
+// AddTarget collects everything required for the checks from a rendered target. trace must only contain the
+// records of this target.
+func (l *Linter) AddTarget(target string, tc *target_context.TargetContext, provenance *result.VarsProvenance, trace []*kluctl_jinja2.TemplateTrace) error {
+	l.targets = append(l.targets, target)
+
+	l.addProvenance(target, provenance)
+	l.addTrace(target, trace)
+
+	err := l.addConditions(target, tc.DeploymentProject)
+	if err != nil {
+		return err
+	}
+	l.addProjectDirs(tc.DeploymentProject)
+	l.addItems(target, tc.DeploymentCollection)
+	return nil
+}
+
+func (l *Linter) addProvenance(target string, provenance *result.VarsProvenance) {
+	for _, k := range provenance.Keys {
+		if k.Path == "target" || strings.HasPrefix(k.Path, "target.") {
+			// predefined
+			continue
+		}
+		dv, ok := l.definedVars[k.Path]
+		if !ok {
+			dv = &definedVar{
+				targets: map[string]bool{},
+			}
+			l.definedVars[k.Path] = dv
+		}
+		dv.targets[target] = true
+		for _, e := range k.Entries {
+			if e.Action == vars.ProvenanceActionIgnored {
+				continue
+			}
+			o := fmt.Sprintf("%s (%s)", e.Origin, e.Source)
+			found := false
+			for _, x := range dv.origins {
+				if x == o {
+					found = true
+					break
+				}
+			}
+			if !found {
+				dv.origins = append(dv.origins, o)
+			}
+		}
+	}
+}
+
+func (l *Linter) addTrace(target string, trace []*kluctl_jinja2.TemplateTrace) {
+	for _, tt := range trace {
+		for _, v := range tt.Vars {
+			l.accessedVars[v] = true
+		}
+		for _, t := range append([]string{tt.File}, tt.Includes...) {
+			if t == "" {
+				continue
+			}
+			m, ok := l.renderedTemplates[t]
+			if !ok {
+				m = map[string]bool{}
+				l.renderedTemplates[t] = m
+			}
+			m[target] = true
+		}
+		for _, u := range tt.Undefined {
+			if u.Template == "" {
+				// inline templates can not be reported with a location
+				continue
+			}
+			k := undefinedKey{template: u.Template, line: u.Line, message: u.Message}
+			x, ok := l.undefined[k]
+			if !ok {
+				x = &undefinedLookup{
+					targets: map[string]bool{},
+				}
+				l.undefined[k] = x
+			}
+			x.used = x.used || u.Used
+			x.targets[target] = true
+		}
+	}
+}
+
+func (l *Linter) addCondition(target string, key conditionKey, when string, vc interface {
+	CheckConditional(string) (bool, error)
+}) error {
+	r, err := vc.CheckConditional(when)
+	if err != nil {
+		return err
+	}
+	c, ok := l.conditions[key]
+	if !ok {
+		c = &condition{
+			when:    when,
+			results: map[string]string{},
+		}
+		l.conditions[key] = c
+	}
+	c.results[target] = fmt.Sprintf("%v", r)
+	return nil
+}
+
+func (l *Linter) addConditions(target string, p *deployment.DeploymentProject) error {
+	if p == nil {
+		return nil
+	}
+	if p.Config.When != "" {
+		err := l.addCondition(target, conditionKey{project: p.GetDescription(), index: -1}, p.Config.When, p.VarsCtx)
+		if err != nil {
+			return err
+		}
+	}
+	for i, d := range p.Config.Deployments {
+		if d.When == "" {
+			continue
+		}
+		err := l.addCondition(target, conditionKey{project: p.GetDescription(), index: i}, d.When, p.VarsCtx)
+		if err != nil {
+			return err
+		}
+	}
+	for _, inc := range p.GetIncludes() {
+		err := l.addConditions(target, inc)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (l *Linter) addProjectDirs(p *deployment.DeploymentProject) {
+	if p == nil {
+		return
+	}
+	l.projectDirs[p.GetDir()] = true
+	for _, inc := range p.GetIncludes() {
+		l.addProjectDirs(inc)
+	}
+}
+
+func (l *Linter) addItems(target string, c *deployment.DeploymentCollection) {
+	if c == nil {
+		return
+	}
+
+	objectItems := map[string][]string{}
+	for _, di := range c.Deployments {
+		dir := di.GetDir()
+		if dir == nil {
+			continue
+		}
+		relDir := l.relPath(*dir)
+
+		l.ownedDirs[*dir] = true
+		l.addKustomizeReferences(*dir, di.RenderedDir, map[string]bool{})
+
+		it, ok := l.items[relDir]
+		if !ok {
+			it = &item{
+				tags:    map[string]bool{},
+				targets: map[string]bool{},
+			}
+			l.items[relDir] = it
+		}
+		it.targets[target] = true
+		for _, t := range di.Tags.ListKeys() {
+			it.tags[t] = true
+		}
+
+		for _, o := range di.Objects {
+			ref := o.GetK8sRef().String()
+			objectItems[ref] = append(objectItems[ref], relDir)
+
+			gvk := o.GetK8sGVK()
+			if gvk.Group == "gitops.kluctl.io" && gvk.Kind == "KluctlDeployment" {
+				includeTags, _, _ := o.GetNestedStringList("spec", "includeTags")
+				l.kluctlDeployments = append(l.kluctlDeployments, kluctlDeployment{
+					includeTags: includeTags,
+				})
+			}
+		}
+	}
+
+	for ref, items := range objectItems {
+		if len(items) < 2 {
+			continue
+		}
+		// the same item might be included multiple times
+		sort.Strings(items)
+		items = slices.Compact(items)
+		l.addFinding(Finding{
+			Check:    CheckDuplicateObject,
+			Severity: SeverityError,
+			Message:  fmt.Sprintf("object %s is rendered by multiple deployment items: %s", ref, strings.Join(items, ", ")),
+		}, target)
+	}
+}
+
+// Finish runs all checks and returns the findings
+func (l *Linter) Finish(ctx context.Context, helmAuthProvider helm_auth.HelmAuthProvider, ociAuthProvider auth_provider.OciAuthProvider) (*Result, error) {
+	err := l.checkHelmCharts(ctx, helmAuthProvider, ociAuthProvider)
+	if err != nil {
+		return nil, err
+	}
+
+	// all other checks require complete information from all targets
+	if len(l.failedTargets) == 0 {
+		l.checkUnusedVars()
+		l.checkUndefinedVars()
+		l.checkConstantConditions()
+		l.checkUnselectedItems()
+		err = l.checkUnreachableDirs()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	ret := &Result{
+		Targets:  l.targets,
+		Findings: []Finding{},
+	}
+	for _, f := range l.findings {
+		sort.Strings(f.Targets)
+		ret.Findings = append(ret.Findings, *f)
+	}
+	sort.SliceStable(ret.Findings, func(i, j int) bool {
+		a, b := ret.Findings[i], ret.Findings[j]
+		if a.Severity != b.Severity {
+			return severityOrder[a.Severity] < severityOrder[b.Severity]
+		}
+		if a.Check != b.Check {
+			return a.Check < b.Check
+		}
+		if a.File != b.File {
+			return a.File < b.File
+		}
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Message < b.Message
+	})
+	return ret, nil
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	ret := make([]string, 0, len(m))
+	for k := range m {
+		ret = append(ret, k)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// FormatText returns a human-readable representation, one line per finding
+func (r *Result) FormatText() string {
+	var sb strings.Builder
+	for _, f := range r.Findings {
+		sb.WriteString(fmt.Sprintf("%s: [%s] ", f.Severity, f.Check))
+		if f.File != "" {
+			sb.WriteString(f.File)
+			if f.Line != 0 {
+				sb.WriteString(fmt.Sprintf(":%d", f.Line))
+			}
+			sb.WriteString(": ")
+		}
+		sb.WriteString(f.Message)
+		if len(f.Targets) != 0 {
+			sb.WriteString(fmt.Sprintf(" (targets: %s)", strings.Join(f.Targets, ", ")))
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(fmt.Sprintf("%d errors, %d warnings, %d infos in %d targets\n",
+		r.Count(SeverityError), r.Count(SeverityWarning), r.Count(SeverityInfo), len(r.Targets)))
+	return sb.String()
+}
@@ -0,0 +1,125 @@
+package lint
+
+import (
+	"context"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_jinja2"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestSplitVarPath(t *testing.T) {
+	assert.Equal(t, []string{"a", "b"}, splitVarPath("a.b"))
+	assert.Equal(t, []string{"a", "x-y", "c"}, splitVarPath(`a["x-y"].c`))
+	assert.Equal(t, []string{"x-y", "c"}, splitVarPath(`$["x-y"].c`))
+	assert.Equal(t, []string{"a", `x"y`}, splitVarPath(`a['x"y']`))
+	assert.Nil(t, splitVarPath("a[0]"))
+}
+
+func findChecks(r *Result, check string) []Finding {
+	var ret []Finding
+	for _, f := range r.Findings {
+		if f.Check == check {
+			ret = append(ret, f)
+		}
+	}
+	return ret
+}
+
+func TestUnusedVars(t *testing.T) {
+	dir := t.TempDir()
+	l := NewLinter(dir, dir)
+
+	provenance := &result.VarsProvenance{}
+	for _, p := range []string{"target.name", "args.env", "app.replicas", "app.unused.a", "app.unused.b", "app.partial.a", "app.partial.b", "list"} {
+		provenance.Keys = append(provenance.Keys, result.VarsProvenanceKey{
+			Path:    p,
+			Entries: []result.VarsProvenanceEntry{{Origin: "deployment.yml vars[0]", Source: "values", Action: "set"}},
+		})
+	}
+	trace := []*kluctl_jinja2.TemplateTrace{
+		{File: "app/cm.yaml", Vars: []string{"args.env", "app.replicas", "app.partial.a", "list.0"}},
+	}
+
+	for _, target := range []string{"t1", "t2"} {
+		l.addProvenance(target, provenance)
+		l.addTrace(target, trace)
+		l.targets = append(l.targets, target)
+	}
+
+	r, err := l.Finish(context.Background(), nil, nil)
+	assert.NoError(t, err)
+
+	unused := findChecks(r, CheckUnusedVar)
+	assert.Len(t, unused, 2)
+	assert.Equal(t, "variable app.partial.b is defined but never used, defined by deployment.yml vars[0] (values)", unused[0].Message)
+	assert.Equal(t, "variable app.unused is defined but never used, defined by deployment.yml vars[0] (values)", unused[1].Message)
+	assert.Equal(t, []string{"t1", "t2"}, unused[1].Targets)
+}
+
+func TestUndefinedVars(t *testing.T) {
+	dir := t.TempDir()
+	l := NewLinter(dir, dir)
+
+	l.addTrace("t1", []*kluctl_jinja2.TemplateTrace{
+		{File: "a.yaml", Undefined: []kluctl_jinja2.UndefinedLookup{
+			{Template: "a.yaml", Line: 1, Message: "'x' is undefined", Used: true},
+			{Template: "a.yaml", Line: 2, Message: "'y' is undefined", Used: true},
+			{Template: "a.yaml", Line: 3, Message: "'z' is undefined", Used: false},
+		}},
+	})
+	l.addTrace("t2", []*kluctl_jinja2.TemplateTrace{
+		{File: "a.yaml", Undefined: []kluctl_jinja2.UndefinedLookup{
+			{Template: "a.yaml", Line: 1, Message: "'x' is undefined", Used: true},
+			{Template: "a.yaml", Line: 3, Message: "'z' is undefined", Used: false},
+		}},
+	})
+	l.targets = []string{"t1", "t2"}
+
+	r, err := l.Finish(context.Background(), nil, nil)
+	assert.NoError(t, err)
+
+	undefined := findChecks(r, CheckUndefinedVar)
+	assert.Len(t, undefined, 2)
+	assert.Equal(t, SeverityWarning, undefined[0].Severity)
+	assert.Equal(t, 1, undefined[0].Line)
+	assert.Equal(t, SeverityInfo, undefined[1].Severity)
+	assert.Equal(t, 3, undefined[1].Line)
+}
+
+func TestConstantConditions(t *testing.T) {
+	dir := t.TempDir()
+	l := NewLinter(dir, dir)
+
+	l.conditions[conditionKey{project: "deployment.yml", index: 0}] = &condition{
+		when:    "args.a",
+		results: map[string]string{"t1": "true", "t2": "true"},
+	}
+	l.conditions[conditionKey{project: "deployment.yml", index: 1}] = &condition{
+		when:    "args.b",
+		results: map[string]string{"t1": "true", "t2": "false"},
+	}
+	l.targets = []string{"t1", "t2"}
+
+	r, err := l.Finish(context.Background(), nil, nil)
+	assert.NoError(t, err)
+
+	c := findChecks(r, CheckConstantCondition)
+	assert.Len(t, c, 1)
+	assert.Equal(t, "condition 'args.a' in deployment.yml deployments[0].when is true for all targets", c[0].Message)
+}
+
+func TestTargetErrorSkipsChecks(t *testing.T) {
+	dir := t.TempDir()
+	l := NewLinter(dir, dir)
+
+	l.addProvenance("t1", &result.VarsProvenance{Keys: []result.VarsProvenanceKey{{Path: "a"}}})
+	l.AddTargetError("t2", assert.AnError)
+
+	r, err := l.Finish(context.Background(), nil, nil)
+	assert.NoError(t, err)
+	assert.Len(t, r.Findings, 1)
+	assert.Equal(t, CheckRenderError, r.Findings[0].Check)
+	assert.Equal(t, []string{"t2"}, r.Findings[0].Targets)
+	assert.Equal(t, 1, r.Count(SeverityError))
+}
@@ -0,0 +1,77 @@
+package lsp
+
+type annotationInfo struct {
+	Name   string
+	Doc    string
+	Values []string
+}
+
+var boolValues = []string{"true", "false"}
+
+// knownAnnotations lists the annotations documented in docs/kluctl/deployments/annotations
+var knownAnnotations = []annotationInfo{
+	{Name: "kluctl.io/delete", Values: boolValues,
+		Doc: "If set to \"true\", the resource will be deleted at deployment time."},
+	{Name: "kluctl.io/force-apply", Values: boolValues,
+		Doc: "If set to \"true\", the whole resource will be force-applied, meaning that all fields will be overwritten in case of field manager conflicts."},
+	{Name: "kluctl.io/force-apply-field",
+		Doc: "JSON Path for fields that should be force-applied. Add `-xxx` to the key to specify multiple fields."},
+	{Name: "kluctl.io/force-apply-manager",
+		Doc: "Regex for managers that should be force-applied. Add `-xxx` to the key to specify multiple managers."},
+	{Name: "kluctl.io/ignore-conflicts", Values: boolValues,
+		Doc: "If set to \"true\", all fields of the object are ignored when conflicts arise."},
+	{Name: "kluctl.io/ignore-conflicts-field",
+		Doc: "JSON Path for fields that should be ignored when conflicts arise. Add `-xxx` to the key to specify multiple fields."},
+	{Name: "kluctl.io/ignore-conflicts-manager",
+		Doc: "Regex for field managers that should be ignored when conflicts arise. Add `-xxx` to the key to specify multiple managers."},
+	{Name: "kluctl.io/wait-readiness", Values: boolValues,
+		Doc: "If set to \"true\", kluctl will wait for readiness of this object (or of all objects when set on a kustomization.yaml)."},
+	{Name: "kluctl.io/is-ready", Values: boolValues,
+		Doc: "Overrides readiness checks. \"true\" always considers the object as ready, \"false\" as not ready."},
+	{Name: "kluctl.io/skip-delete", Values: boolValues,
+		Doc: "If set to \"true\", the resource will not be deleted by `kluctl delete` or `kluctl prune`."},
+	{Name: "kluctl.io/skip-delete-if-tags", Values: boolValues,
+		Doc: "If set to \"true\", the resource will not be deleted by `kluctl delete` or `kluctl prune` when inclusion/exclusion tags are used."},
+	{Name: "kluctl.io/force-managed", Values: boolValues,
+		Doc: "If set to \"true\", the resource is always treated as being managed by kluctl."},
+	{Name: "kluctl.io/diff-name",
+		Doc: "Overrides the name used to find the in-cluster version of the object for diffs."},
+	{Name: "kluctl.io/ignore-diff", Values: boolValues,
+		Doc: "If set to \"true\", the whole resource is ignored while calculating diffs."},
+	{Name: "kluctl.io/ignore-diff-field",
+		Doc: "JSON Path for fields that should be ignored while calculating diffs. Add `-xxx` to the key to specify multiple fields."},
+	{Name: "kluctl.io/ignore-diff-field-regex",
+		Doc: "Regex for fields that should be ignored while calculating diffs. Add `-xxx` to the key to specify multiple fields."},
+	{Name: "kluctl.io/hook",
+		Values: []string{"pre-deploy", "post-deploy", "pre-deploy-initial", "post-deploy-initial", "pre-deploy-upgrade", "post-deploy-upgrade"},
+		Doc:    "Declares the resource to be a hook. The value determines when the hook is deployed/executed. Multiple values can be separated by commas."},
+	{Name: "kluctl.io/hook-weight",
+		Doc: "Weight of the hook, used to determine deployment/execution order."},
+	{Name: "kluctl.io/hook-delete-policy",
+		Values: []string{"before-hook-creation", "hook-succeeded", "hook-failed"},
+		Doc:    "Defines when to delete the hook resource."},
+	{Name: "kluctl.io/hook-wait", Values: boolValues,
+		Doc: "Defines whether kluctl should wait for hook completion. Defaults to \"true\"."},
+	{Name: "kluctl.io/barrier", Values: boolValues,
+		Doc: "If set to \"true\" on a kustomization.yaml, kluctl waits for all previous objects to be applied."},
+	{Name: "kluctl.io/validate-ignore", Values: boolValues,
+		Doc: "If set to \"true\", the object is ignored by `kluctl validate`."},
+}
+
+func findAnnotation(name string) *annotationInfo {
+	for i, a := range knownAnnotations {
+		if a.Name == name {
+			return &knownAnnotations[i]
+		}
+	}
+	// support the -xxx suffix for annotations that can be specified multiple times
+	var best *annotationInfo
+	for i, a := range knownAnnotations {
+		if len(name) > len(a.Name)+1 && name[:len(a.Name)+1] == a.Name+"-" {
+			if best == nil || len(a.Name) > len(best.Name) {
+				best = &knownAnnotations[i]
+			}
+		}
+	}
+	return best
+}
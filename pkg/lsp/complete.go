@@ -0,0 +1,293 @@
+package lsp
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/yaml"
+)
+
+func (s *Server) completion(d *document, pos Position) *CompletionList {
+	ret := &CompletionList{Items: []CompletionItem{}}
+	lines := d.lines()
+	if pos.Line >= len(lines) {
+		return ret
+	}
+
+	if expr, ok := templateExpressionAt(lines[pos.Line], pos.Character); ok {
+		ret.Items = s.completeTemplate(d, expr)
+		return ret
+	}
+
+	ctx := getCursorContext(lines, pos)
+	if d.kind == kindResource {
+		if isAnnotationsPath(ctx.path) {
+			ret.Items = completeAnnotations(ctx)
+		}
+		return ret
+	}
+
+	typ := types.ConfigSchemaTypes[d.kind.schemaName()]
+	if ctx.isValue {
+		ret.Items = completeValues(yaml.SchemaAtPath(typ, nil, append(ctx.path, ctx.key)))
+		return ret
+	}
+
+	props := yaml.SchemaPropertiesAtPath(typ, nil, ctx.path)
+	for _, k := range sortedKeys(props) {
+		ps := props[k]
+		item := CompletionItem{
+			Label:      k,
+			Kind:       CompletionItemKindProperty,
+			Detail:     schemaTypeString(ps),
+			InsertText: k + ": ",
+		}
+		if desc, ok := ps["description"].(string); ok {
+			m := markdown(desc)
+			item.Documentation = &m
+		}
+		ret.Items = append(ret.Items, item)
+	}
+	return ret
+}
+
+func isAnnotationsPath(path []string) bool {
+	return len(path) >= 2 && path[len(path)-2] == "metadata" && path[len(path)-1] == "annotations"
+}
+
+func completeAnnotations(ctx cursorContext) []CompletionItem {
+	var ret []CompletionItem
+	if ctx.isValue {
+		a := findAnnotation(ctx.key)
+		if a == nil {
+			return nil
+		}
+		for _, v := range a.Values {
+			ret = append(ret, CompletionItem{
+				Label:      v,
+				Kind:       CompletionItemKindValue,
+				InsertText: `"` + v + `"`,
+			})
+		}
+		return ret
+	}
+	for _, a := range knownAnnotations {
+		m := markdown(a.Doc)
+		ret = append(ret, CompletionItem{
+			Label:         a.Name,
+			Kind:          CompletionItemKindProperty,
+			Documentation: &m,
+			InsertText:    a.Name + ": ",
+		})
+	}
+	return ret
+}
+
+func completeValues(s yaml.JSONSchema) []CompletionItem {
+	if s == nil {
+		return nil
+	}
+	var ret []CompletionItem
+	if enum, ok := s["enum"].([]any); ok {
+		for _, e := range enum {
+			ret = append(ret, CompletionItem{
+				Label: fmt.Sprint(e),
+				Kind:  CompletionItemKindEnum,
+			})
+		}
+	} else if s["type"] == "boolean" {
+		for _, v := range boolValues {
+			ret = append(ret, CompletionItem{
+				Label: v,
+				Kind:  CompletionItemKindValue,
+			})
+		}
+	}
+	return ret
+}
+
+func (s *Server) completeTemplate(d *document, expr string) []CompletionItem {
+	ident := trailingIdentifier(expr)
+	parts := strings.Split(ident, ".")
+	parent := parts[:len(parts)-1]
+
+	p := s.loadProjectInfo(d)
+	v, ok := lookupPath(p.globals(), parent)
+	if !ok {
+		return nil
+	}
+	m, ok := v.(map[string]any)
+	if !ok {
+		return nil
+	}
+
+	var ret []CompletionItem
+	for _, k := range sortedKeys(m) {
+		item := CompletionItem{
+			Label:  k,
+			Kind:   CompletionItemKindVariable,
+			Detail: valueDetail(m[k]),
+		}
+		if len(parent) == 0 && (k == "args" || k == "target") {
+			item.Kind = CompletionItemKindField
+		}
+		ret = append(ret, item)
+	}
+	return ret
+}
+
+func lookupPath(o any, path []string) (any, bool) {
+	for _, k := range path {
+		m, ok := o.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		o, ok = m[k]
+		if !ok {
+			return nil, false
+		}
+	}
+	return o, true
+}
+
+func valueDetail(v any) string {
+	switch x := v.(type) {
+	case map[string]any:
+		return "object"
+	case []any:
+		return "list"
+	case nil:
+		return "null"
+	case string:
+		return fmt.Sprintf("%q", x)
+	default:
+		return fmt.Sprint(x)
+	}
+}
+
+func schemaTypeString(s yaml.JSONSchema) string {
+	if t, ok := s["type"].(string); ok {
+		if t == "array" {
+			if items, ok := s["items"].(yaml.JSONSchema); ok {
+				if it, ok := items["type"].(string); ok {
+					return "array of " + it
+				}
+			}
+		}
+		return t
+	}
+	if _, ok := s["if"]; ok {
+		return "string or object"
+	}
+	return ""
+}
+
+func sortedKeys[T any](m map[string]T) []string {
+	var ret []string
+	for k := range m {
+		ret = append(ret, k)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+func (s *Server) hover(d *document, pos Position) *Hover {
+	lines := d.lines()
+	if pos.Line >= len(lines) {
+		return nil
+	}
+	line := lines[pos.Line]
+
+	if _, ok := templateExpressionAt(line, pos.Character); ok {
+		ident := identifierAt(line, pos.Character)
+		if ident == "" {
+			return nil
+		}
+		p := s.loadProjectInfo(d)
+		v, ok := lookupPath(p.globals(), strings.Split(ident, "."))
+		if !ok {
+			return nil
+		}
+		y, err := yaml.WriteYamlString(v)
+		if err != nil {
+			return nil
+		}
+		return &Hover{Contents: markdown(fmt.Sprintf("**%s**\n\n```yaml\n%s```", ident, y))}
+	}
+
+	l := parseYamlLine(line)
+	if !l.hasColon || pos.Character < l.col || pos.Character > l.col+len(l.key) {
+		return nil
+	}
+	path := parentPath(lines, pos.Line, l.col)
+	r := &Range{
+		Start: Position{Line: pos.Line, Character: l.col},
+		End:   Position{Line: pos.Line, Character: l.col + len(l.key)},
+	}
+
+	if d.kind == kindResource {
+		if !isAnnotationsPath(path) {
+			return nil
+		}
+		a := findAnnotation(l.key)
+		if a == nil {
+			return nil
+		}
+		return &Hover{Contents: markdown(fmt.Sprintf("**%s**\n\n%s", a.Name, a.Doc)), Range: r}
+	}
+
+	typ := types.ConfigSchemaTypes[d.kind.schemaName()]
+	ps, ok := yaml.SchemaPropertiesAtPath(typ, nil, path)[l.key]
+	if !ok {
+		return nil
+	}
+	text := fmt.Sprintf("**%s**", l.key)
+	if t := schemaTypeString(ps); t != "" {
+		text += fmt.Sprintf(" `%s`", t)
+	}
+	if desc, ok := ps["description"].(string); ok {
+		text += "\n\n" + desc
+	}
+	return &Hover{Contents: markdown(text), Range: r}
+}
+
+func (s *Server) definition(d *document, pos Position) *Location {
+	lines := d.lines()
+	if pos.Line >= len(lines) || d.kind == kindResource {
+		return nil
+	}
+	l := parseYamlLine(lines[pos.Line])
+	if !l.hasColon || l.value == "" || strings.Contains(l.value, "{{") {
+		return nil
+	}
+	path := parentPath(lines, pos.Line, l.col)
+	value := strings.Trim(l.value, `"'`)
+	dir := filepath.Dir(d.path)
+
+	var target string
+	switch {
+	case d.kind == kindDeployment && len(path) == 2 && path[0] == "deployments" && l.key == "include":
+		target = yaml.FixPathExt(filepath.Join(dir, value, "deployment.yml"))
+	case d.kind == kindDeployment && len(path) == 2 && path[0] == "deployments" && l.key == "path":
+		for _, n := range []string{"kustomization.yml", "helm-chart.yml"} {
+			p := yaml.FixPathExt(filepath.Join(dir, value, n))
+			if yaml.Exists(p) {
+				target = p
+				break
+			}
+		}
+	case l.key == "file" && len(path) >= 2 && path[len(path)-2] == "vars":
+		target = filepath.Join(dir, value)
+	}
+	if target == "" {
+		return nil
+	}
+	if st, err := os.Stat(target); err != nil || st.IsDir() {
+		return nil
+	}
+	return &Location{URI: pathToUri(target)}
+}
@@ -0,0 +1,125 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// JSON-RPC 2.0 error codes used by LSP
+const (
+	codeParseError     = -32700
+	codeMethodNotFound = -32601
+	codeInvalidParams  = -32602
+	codeInternalError  = -32603
+)
+
+// nullId is used for responses to requests with an id that could not be determined, e.g. on parse errors. A nil id
+// would be omitted completely, while JSON-RPC requires an explicit null in this case.
+var nullId = json.RawMessage("null")
+
+type rpcMessage struct {
+	JsonRpc string           `json:"jsonrpc"`
+	Id      *json.RawMessage `json:"id,omitempty"`
+	Method  string           `json:"method,omitempty"`
+	Params  json.RawMessage  `json:"params,omitempty"`
+	Result  any              `json:"result,omitempty"`
+	Error   *rpcError        `json:"error,omitempty"`
+}
+
+type rpcError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *rpcError) Error() string {
+	return e.Message
+}
+
+// conn implements the base protocol of LSP, which is JSON-RPC with a header part containing the Content-Length.
+type conn struct {
+	r *bufio.Reader
+	w io.Writer
+
+	writeMutex sync.Mutex
+}
+
+func newConn(r io.Reader, w io.Writer) *conn {
+	return &conn{
+		r: bufio.NewReader(r),
+		w: w,
+	}
+}
+
+func (c *conn) read() (*rpcMessage, error) {
+	tp := textproto.NewReader(c.r)
+	h, err := tp.ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+	l, err := strconv.Atoi(strings.TrimSpace(h.Get("Content-Length")))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length header: %w", err)
+	}
+
+	b := make([]byte, l)
+	_, err = io.ReadFull(c.r, b)
+	if err != nil {
+		return nil, err
+	}
+
+	var m rpcMessage
+	err = json.Unmarshal(b, &m)
+	if err != nil {
+		return nil, &rpcError{Code: codeParseError, Message: err.Error()}
+	}
+	return &m, nil
+}
+
+func (c *conn) write(m *rpcMessage) error {
+	m.JsonRpc = "2.0"
+	b, err := json.Marshal(m)
+	if err != nil {
+		return err
+	}
+
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+	_, err = fmt.Fprintf(c.w, "Content-Length: %d\r\n\r\n", len(b))
+	if err != nil {
+		return err
+	}
+	_, err = c.w.Write(b)
+	return err
+}
+
+func (c *conn) reply(id *json.RawMessage, result any, err error) error {
+	m := &rpcMessage{Id: id}
+	if err != nil {
+		re, ok := err.(*rpcError)
+		if !ok {
+			re = &rpcError{Code: codeInternalError, Message: err.Error()}
+		}
+		m.Error = re
+	} else {
+		if result == nil {
+			// LSP requires 'result' to be present on success, even if it's null
+			result = json.RawMessage("null")
+		}
+		m.Result = result
+	}
+	return c.write(m)
+}
+
+func (c *conn) notify(method string, params any) error {
+	b, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	return c.write(&rpcMessage{Method: method, Params: b})
+}
@@ -0,0 +1,149 @@
+package lsp
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/kluctl/kluctl/v2/pkg/yaml"
+)
+
+// projectInfo contains everything that is known about args and vars for a document. It's collected on a best-effort
+// basis from the raw (unrendered) files, so templated or dynamic vars sources are not included.
+type projectInfo struct {
+	dir    string
+	target *types.Target
+	args   *uo.UnstructuredObject
+	vars   *uo.UnstructuredObject
+}
+
+func (p *projectInfo) globals() map[string]any {
+	g := p.vars.Clone()
+	_ = g.SetNestedField(p.args.Object, "args")
+	t := map[string]any{}
+	if p.target != nil {
+		t["name"] = p.target.Name
+		if p.target.Context != nil {
+			t["context"] = *p.target.Context
+		}
+		t["args"] = p.args.Object
+	}
+	_ = g.SetNestedField(t, "target")
+	return g.Object
+}
+
+func findProjectDir(dir string, rootDir string) string {
+	for d := dir; ; {
+		if yaml.Exists(yaml.FixPathExt(filepath.Join(d, ".kluctl.yml"))) {
+			return d
+		}
+		parent := filepath.Dir(d)
+		if d == rootDir || parent == d {
+			break
+		}
+		d = parent
+	}
+	if rootDir != "" {
+		return rootDir
+	}
+	return dir
+}
+
+func (s *Server) loadProjectInfo(d *document) *projectInfo {
+	s.mutex.Lock()
+	rootDir := s.rootDir
+	targetName := s.settings.Target
+	s.mutex.Unlock()
+
+	docDir := filepath.Dir(d.path)
+	p := &projectInfo{
+		dir:  findProjectDir(docDir, rootDir),
+		args: uo.New(),
+		vars: uo.New(),
+	}
+
+	var config types.KluctlProject
+	if d.kind == kindKluctlProject {
+		_ = yaml.ReadYamlString(d.text, &config)
+	} else {
+		_ = yaml.ReadYamlFile(yaml.FixPathExt(filepath.Join(p.dir, ".kluctl.yml")), &config)
+	}
+
+	for _, a := range config.Args {
+		var v any
+		if a.Default != nil {
+			_ = yaml.ReadYamlBytes(a.Default.Raw, &v)
+		}
+		p.args.Merge(uo.FromMap(map[string]any{a.Name: v}))
+	}
+	for i, t := range config.Targets {
+		if t.Name == targetName || (targetName == "" && len(config.Targets) == 1) {
+			p.target = &config.Targets[i]
+			if t.Args != nil {
+				p.args.Merge(t.Args)
+			}
+			break
+		}
+	}
+
+	// collect vars from all deployment projects between the project root and the current document
+	var dirs []string
+	for dir := docDir; ; dir = filepath.Dir(dir) {
+		dirs = append([]string{dir}, dirs...)
+		if dir == p.dir || filepath.Dir(dir) == dir || !strings.HasPrefix(dir, p.dir) {
+			break
+		}
+	}
+	for _, dir := range dirs {
+		var text string
+		deploymentPath := yaml.FixPathExt(filepath.Join(dir, "deployment.yml"))
+		if d.kind == kindDeployment && dir == docDir {
+			text = d.text
+		} else {
+			b, err := os.ReadFile(deploymentPath)
+			if err != nil {
+				continue
+			}
+			text = string(b)
+		}
+		p.loadVars(dir, text)
+	}
+	return p
+}
+
+func (p *projectInfo) loadVars(dir string, text string) {
+	var config struct {
+		Vars []map[string]any `json:"vars"`
+	}
+	err := yaml.ReadYamlString(text, &config)
+	if err != nil {
+		return
+	}
+	for _, vs := range config.Vars {
+		var v *uo.UnstructuredObject
+		if values, ok := vs["values"].(map[string]any); ok {
+			v = uo.FromMap(values)
+		} else if f, ok := vs["file"].(string); ok && !strings.Contains(f, "{{") {
+			var m map[string]any
+			if err := yaml.ReadYamlFile(filepath.Join(dir, f), &m); err != nil {
+				continue
+			}
+			v = uo.FromMap(m)
+		}
+		if v == nil {
+			continue
+		}
+		if tp, ok := vs["targetPath"].(string); ok && tp != "" {
+			var keys []any
+			for _, k := range strings.Split(tp, ".") {
+				keys = append(keys, k)
+			}
+			x := uo.New()
+			_ = x.SetNestedField(v.Object, keys...)
+			v = x
+		}
+		p.vars.Merge(v)
+	}
+}
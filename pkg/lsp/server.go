@@ -0,0 +1,442 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/url"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/kluctl/go-jinja2"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_jinja2"
+	"github.com/kluctl/kluctl/v2/pkg/status"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/version"
+	"github.com/kluctl/kluctl/v2/pkg/yaml"
+)
+
+type documentKind int
+
+const (
+	kindResource documentKind = iota
+	kindKluctlProject
+	kindDeployment
+	kindHelmChart
+)
+
+// schemaName returns the name of the config schema (see types.ConfigSchemaTypes) for the document kind
+func (k documentKind) schemaName() string {
+	switch k {
+	case kindKluctlProject:
+		return "kluctl-project"
+	case kindDeployment:
+		return "deployment"
+	case kindHelmChart:
+		return "helm-chart"
+	}
+	return ""
+}
+
+func getDocumentKind(p string) documentKind {
+	switch filepath.Base(p) {
+	case ".kluctl.yaml", ".kluctl.yml":
+		return kindKluctlProject
+	case "deployment.yaml", "deployment.yml":
+		return kindDeployment
+	case "helm-chart.yaml", "helm-chart.yml":
+		return kindHelmChart
+	}
+	return kindResource
+}
+
+type document struct {
+	uri     string
+	path    string
+	kind    documentKind
+	version int
+	text    string
+}
+
+func (d *document) lines() []string {
+	return strings.Split(d.text, "\n")
+}
+
+// Server implements a language server for kluctl projects. It communicates via JSON-RPC over the given reader and
+// writer, which is usually stdin/stdout.
+type Server struct {
+	ctx context.Context
+
+	conn *conn
+
+	mutex    sync.Mutex
+	rootDir  string
+	settings ServerSettings
+	docs     map[string]*document
+
+	j2      *jinja2.Jinja2
+	j2Error error
+	j2Once  sync.Once
+
+	shutdown bool
+}
+
+func NewServer(ctx context.Context, settings ServerSettings) *Server {
+	return &Server{
+		ctx:      ctx,
+		settings: settings,
+		docs:     map[string]*document{},
+	}
+}
+
+// Serve handles requests until the client sends 'exit' or the connection is closed.
+func (s *Server) Serve(r io.Reader, w io.Writer) error {
+	s.conn = newConn(r, w)
+	defer s.close()
+
+	for {
+		m, err := s.conn.read()
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			var re *rpcError
+			if errors.As(err, &re) {
+				_ = s.conn.reply(&nullId, nil, re)
+				continue
+			}
+			return err
+		}
+
+		if m.Method == "exit" {
+			return nil
+		}
+
+		result, err := s.handle(m)
+		if m.Id == nil {
+			// notifications don't get a response
+			if err != nil {
+				status.Warningf(s.ctx, "Failed to handle %s: %s", m.Method, err.Error())
+			}
+			continue
+		}
+		err = s.conn.reply(m.Id, result, err)
+		if err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) close() {
+	if s.j2 != nil {
+		s.j2.Close()
+	}
+}
+
+func unmarshalParams(m *rpcMessage, out any) error {
+	err := json.Unmarshal(m.Params, out)
+	if err != nil {
+		return &rpcError{Code: codeInvalidParams, Message: err.Error()}
+	}
+	return nil
+}
+
+func (s *Server) handle(m *rpcMessage) (any, error) {
+	switch m.Method {
+	case "initialize":
+		var params InitializeParams
+		if err := unmarshalParams(m, &params); err != nil {
+			return nil, err
+		}
+		return s.initialize(&params), nil
+	case "initialized", "$/cancelRequest", "$/setTrace":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+	case "workspace/didChangeConfiguration":
+		var params DidChangeConfigurationParams
+		if err := unmarshalParams(m, &params); err != nil {
+			return nil, err
+		}
+		if params.Settings.Kluctl != nil {
+			s.mutex.Lock()
+			s.settings = *params.Settings.Kluctl
+			s.mutex.Unlock()
+		}
+		return nil, nil
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if err := unmarshalParams(m, &params); err != nil {
+			return nil, err
+		}
+		s.updateDocument(params.TextDocument.URI, params.TextDocument.Version, params.TextDocument.Text)
+		return nil, nil
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if err := unmarshalParams(m, &params); err != nil {
+			return nil, err
+		}
+		if len(params.ContentChanges) != 0 {
+			// we only support full sync, so the last change contains the whole document
+			text := params.ContentChanges[len(params.ContentChanges)-1].Text
+			s.updateDocument(params.TextDocument.URI, params.TextDocument.Version, text)
+		}
+		return nil, nil
+	case "textDocument/didSave":
+		var params DidSaveTextDocumentParams
+		if err := unmarshalParams(m, &params); err != nil {
+			return nil, err
+		}
+		if d := s.getDocument(params.TextDocument.URI); d != nil {
+			s.publishDiagnostics(d)
+		}
+		return nil, nil
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if err := unmarshalParams(m, &params); err != nil {
+			return nil, err
+		}
+		s.mutex.Lock()
+		delete(s.docs, params.TextDocument.URI)
+		s.mutex.Unlock()
+		return nil, s.conn.notify("textDocument/publishDiagnostics", &PublishDiagnosticsParams{
+			URI:         params.TextDocument.URI,
+			Diagnostics: []Diagnostic{},
+		})
+	case "textDocument/completion":
+		var params TextDocumentPositionParams
+		if err := unmarshalParams(m, &params); err != nil {
+			return nil, err
+		}
+		d := s.getDocument(params.TextDocument.URI)
+		if d == nil {
+			return nil, nil
+		}
+		return s.completion(d, params.Position), nil
+	case "textDocument/hover":
+		var params TextDocumentPositionParams
+		if err := unmarshalParams(m, &params); err != nil {
+			return nil, err
+		}
+		d := s.getDocument(params.TextDocument.URI)
+		if d == nil {
+			return nil, nil
+		}
+		h := s.hover(d, params.Position)
+		if h == nil {
+			return nil, nil
+		}
+		return h, nil
+	case "textDocument/definition":
+		var params TextDocumentPositionParams
+		if err := unmarshalParams(m, &params); err != nil {
+			return nil, err
+		}
+		d := s.getDocument(params.TextDocument.URI)
+		if d == nil {
+			return nil, nil
+		}
+		l := s.definition(d, params.Position)
+		if l == nil {
+			return nil, nil
+		}
+		return l, nil
+	}
+
+	if m.Id == nil || strings.HasPrefix(m.Method, "$/") {
+		// unknown notifications can be ignored
+		return nil, nil
+	}
+	return nil, &rpcError{Code: codeMethodNotFound, Message: "method not found: " + m.Method}
+}
+
+func (s *Server) initialize(params *InitializeParams) *InitializeResult {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if p, err := uriToPath(params.RootURI); err == nil {
+		s.rootDir = p
+	}
+	if params.InitializationOptions != nil && params.InitializationOptions.Target != "" {
+		s.settings.Target = params.InitializationOptions.Target
+	}
+
+	return &InitializeResult{
+		Capabilities: ServerCapabilities{
+			TextDocumentSync: 1,
+			CompletionProvider: &CompletionOptions{
+				TriggerCharacters: []string{".", ":", " ", "/"},
+			},
+			HoverProvider:      true,
+			DefinitionProvider: true,
+		},
+		ServerInfo: ServerInfo{
+			Name:    "kluctl",
+			Version: version.GetVersion(),
+		},
+	}
+}
+
+func (s *Server) getDocument(uri string) *document {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.docs[uri]
+}
+
+func (s *Server) getSettings() ServerSettings {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.settings
+}
+
+func (s *Server) updateDocument(uri string, version int, text string) {
+	p, err := uriToPath(uri)
+	if err != nil {
+		return
+	}
+	d := &document{
+		uri:     uri,
+		path:    p,
+		kind:    getDocumentKind(p),
+		version: version,
+		text:    text,
+	}
+	s.mutex.Lock()
+	s.docs[uri] = d
+	s.mutex.Unlock()
+
+	s.publishDiagnostics(d)
+}
+
+func uriToPath(uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", err
+	}
+	if u.Scheme != "file" {
+		return "", errors.New("only file:// URIs are supported")
+	}
+	return filepath.FromSlash(u.Path), nil
+}
+
+func pathToUri(p string) string {
+	u := url.URL{Scheme: "file", Path: filepath.ToSlash(p)}
+	return u.String()
+}
+
+func (s *Server) getJinja2() (*jinja2.Jinja2, error) {
+	s.j2Once.Do(func() {
+		s.j2, s.j2Error = kluctl_jinja2.NewKluctlJinja2(s.ctx, false)
+	})
+	return s.j2, s.j2Error
+}
+
+func (s *Server) publishDiagnostics(d *document) {
+	diags := s.diagnose(d)
+	if diags == nil {
+		diags = []Diagnostic{}
+	}
+	_ = s.conn.notify("textDocument/publishDiagnostics", &PublishDiagnosticsParams{
+		URI:         d.uri,
+		Version:     d.version,
+		Diagnostics: diags,
+	})
+}
+
+var templateErrorLineRegex = regexp.MustCompile(`line (\d+)`)
+
+func (s *Server) diagnose(d *document) []Diagnostic {
+	text := d.text
+
+	// .kluctl.yaml is the only config file that is not rendered
+	if d.kind != kindKluctlProject && (d.kind != kindResource || s.isTemplatedResource(d)) {
+		j2, err := s.getJinja2()
+		if err != nil {
+			return []Diagnostic{newDiagnostic(0, 0, "failed to initialize Jinja2: "+err.Error())}
+		}
+		p := s.loadProjectInfo(d)
+		rendered, err := j2.RenderString(text,
+			jinja2.WithGlobals(p.globals()),
+			jinja2.WithSearchDirs([]string{filepath.Dir(d.path), p.dir}))
+		if err != nil {
+			return []Diagnostic{templateErrorDiagnostic(err)}
+		}
+		text = rendered
+	}
+
+	name := d.kind.schemaName()
+	if name == "" {
+		return nil
+	}
+	typ := types.ConfigSchemaTypes[name]
+
+	var diags []Diagnostic
+	for _, e := range yaml.ValidateYamlWithSchema("", []byte(text), typ) {
+		msg := e.Message
+		if e.Path != "" {
+			msg = e.Path + ": " + msg
+		}
+		diags = append(diags, newDiagnostic(e.Line-1, e.Column-1, msg))
+	}
+	if len(diags) != 0 {
+		return diags
+	}
+
+	// catch everything that is not covered by the schema, e.g. syntax errors and custom validators
+	o := reflect.New(reflect.TypeOf(typ)).Interface()
+	err := yaml.ReadYamlString(text, o)
+	if err != nil {
+		diags = append(diags, newDiagnostic(0, 0, err.Error()))
+	}
+	return diags
+}
+
+// isTemplatedResource returns true if the resource is part of a kustomize deployment or deployment project, which
+// means that it's rendered by kluctl.
+func (s *Server) isTemplatedResource(d *document) bool {
+	dir := filepath.Dir(d.path)
+	for _, n := range []string{"kustomization.yml", "deployment.yml"} {
+		if yaml.Exists(yaml.FixPathExt(filepath.Join(dir, n))) {
+			return true
+		}
+	}
+	return false
+}
+
+func templateErrorDiagnostic(err error) Diagnostic {
+	msg := strings.TrimSpace(err.Error())
+	line := 0
+	if m := templateErrorLineRegex.FindStringSubmatch(msg); m != nil {
+		line, _ = strconv.Atoi(m[1])
+		line--
+	}
+	// the last line of the traceback contains the actual error
+	if i := strings.LastIndex(msg, "\n"); i != -1 {
+		msg = strings.TrimSpace(msg[i+1:])
+	}
+	return newDiagnostic(line, 0, msg)
+}
+
+func newDiagnostic(line int, col int, msg string) Diagnostic {
+	if line < 0 {
+		line = 0
+	}
+	if col < 0 {
+		col = 0
+	}
+	return Diagnostic{
+		Range: Range{
+			Start: Position{Line: line, Character: col},
+			End:   Position{Line: line, Character: col + 1},
+		},
+		Severity: SeverityError,
+		Source:   "kluctl",
+		Message:  msg,
+	}
+}
@@ -0,0 +1,198 @@
+package lsp
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParentPath(t *testing.T) {
+	lines := strings.Split(`deployments:
+  - path: a
+    tags:
+      - x
+  - include: b
+    vars:
+      - file: v.yaml
+
+vars:
+  - values:
+      a: 1`, "\n")
+
+	assert.Equal(t, []string{}, parentPath(lines, 0, 0))
+	assert.Equal(t, []string{"deployments", "0"}, parentPath(lines, 1, 4))
+	assert.Equal(t, []string{"deployments", "0", "tags", "0"}, parentPath(lines, 3, 8))
+	assert.Equal(t, []string{"deployments", "0", "vars", "0"}, parentPath(lines, 6, 8))
+	assert.Equal(t, []string{"deployments", "0", "vars", "0"}, parentPath(lines, 7, 8))
+	assert.Equal(t, []string{"vars", "0", "values"}, parentPath(lines, 10, 6))
+}
+
+type testClient struct {
+	t        *testing.T
+	c        *conn
+	id       int
+	messages chan *rpcMessage
+	notes    []*rpcMessage
+}
+
+func (c *testClient) call(method string, params any, result any) {
+	c.id++
+	id := json.RawMessage(mustJson(c.t, c.id))
+	err := c.c.write(&rpcMessage{Id: &id, Method: method, Params: mustJson(c.t, params)})
+	assert.NoError(c.t, err)
+	for m := range c.messages {
+		if m.Id == nil {
+			c.notes = append(c.notes, m)
+			continue
+		}
+		assert.Nil(c.t, m.Error)
+		if result != nil {
+			b := mustJson(c.t, m.Result)
+			assert.NoError(c.t, json.Unmarshal(b, result))
+		}
+		return
+	}
+	c.t.Fatal("connection closed")
+}
+
+func (c *testClient) notify(method string, params any) {
+	err := c.c.write(&rpcMessage{Method: method, Params: mustJson(c.t, params)})
+	assert.NoError(c.t, err)
+}
+
+func mustJson(t *testing.T, o any) []byte {
+	b, err := json.Marshal(o)
+	assert.NoError(t, err)
+	return b
+}
+
+func startTestServer(t *testing.T) *testClient {
+	r1, w1 := io.Pipe()
+	r2, w2 := io.Pipe()
+	s := NewServer(context.Background(), ServerSettings{Target: "test"})
+	go func() {
+		_ = s.Serve(r1, w2)
+		_ = w2.Close()
+	}()
+	t.Cleanup(func() {
+		_ = w1.Close()
+	})
+
+	c := &testClient{t: t, c: newConn(r2, w1), messages: make(chan *rpcMessage, 100)}
+	// the server sends notifications at any time, so we must read all the time to not block it
+	go func() {
+		defer close(c.messages)
+		for {
+			m, err := c.c.read()
+			if err != nil {
+				return
+			}
+			c.messages <- m
+		}
+	}()
+	return c
+}
+
+func TestServer(t *testing.T) {
+	dir := t.TempDir()
+	projectFile := filepath.Join(dir, ".kluctl.yaml")
+	text := `args:
+  - name: env
+    default: dev
+targets:
+  - name: test
+    args:
+      replicas: 3
+    foo: bar
+`
+	assert.NoError(t, os.WriteFile(projectFile, []byte(text), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "vars.yaml"), []byte("a: b\n"), 0o600))
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "deployment.yml"), []byte("vars:\n  - file: vars.yaml\n"), 0o600))
+
+	c := startTestServer(t)
+
+	var initResult InitializeResult
+	c.call("initialize", &InitializeParams{RootURI: pathToUri(dir)}, &initResult)
+	assert.True(t, initResult.Capabilities.HoverProvider)
+
+	uri := pathToUri(projectFile)
+	c.notify("textDocument/didOpen", &DidOpenTextDocumentParams{TextDocument: TextDocumentItem{URI: uri, Version: 1, Text: text}})
+
+	// key completion inside a target
+	var cl CompletionList
+	c.call("textDocument/completion", &TextDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     Position{Line: 7, Character: 4},
+	}, &cl)
+	var labels []string
+	for _, i := range cl.Items {
+		labels = append(labels, i.Label)
+	}
+	assert.Contains(t, labels, "context")
+	assert.Contains(t, labels, "args")
+
+	// hover documentation
+	var h Hover
+	c.call("textDocument/hover", &TextDocumentPositionParams{
+		TextDocument: TextDocumentIdentifier{URI: uri},
+		Position:     Position{Line: 0, Character: 1},
+	}, &h)
+	assert.Contains(t, h.Contents.Value, "Declares the arguments")
+
+	// diagnostics are sent before the response to the next request
+	var diags PublishDiagnosticsParams
+	for _, n := range c.notes {
+		if n.Method == "textDocument/publishDiagnostics" {
+			assert.NoError(t, json.Unmarshal(n.Params, &diags))
+		}
+	}
+	if assert.Len(t, diags.Diagnostics, 1) {
+		assert.Equal(t, 7, diags.Diagnostics[0].Range.Start.Line)
+		assert.Equal(t, `targets[0].foo: unknown field "foo"`, diags.Diagnostics[0].Message)
+	}
+
+	// go-to-definition and args/vars completion are tested without the connection, as opening deployment.yml
+	// files would trigger rendering
+	srv := NewServer(context.Background(), ServerSettings{Target: "test"})
+	srv.rootDir = dir
+
+	d := &document{path: filepath.Join(dir, "deployment.yml"), kind: kindDeployment, text: "vars:\n  - file: vars.yaml\n"}
+	loc := srv.definition(d, Position{Line: 1, Character: 12})
+	if assert.NotNil(t, loc) {
+		assert.Equal(t, pathToUri(filepath.Join(dir, "vars.yaml")), loc.URI)
+	}
+
+	d = &document{path: filepath.Join(dir, "a", "deployment.yml"), kind: kindDeployment, text: "x: {{ args.\n"}
+	assert.Equal(t, []string{"env", "replicas"}, completionLabels(srv, d, Position{Line: 0, Character: 11}))
+	d.text = "x: {{ a\n"
+	assert.Equal(t, []string{"a", "args", "target"}, completionLabels(srv, d, Position{Line: 0, Character: 7}))
+}
+
+func completionLabels(s *Server, d *document, pos Position) []string {
+	var ret []string
+	for _, i := range s.completion(d, pos).Items {
+		ret = append(ret, i.Label)
+	}
+	return ret
+}
+
+func TestServerParseError(t *testing.T) {
+	var out strings.Builder
+	s := NewServer(context.Background(), ServerSettings{})
+	err := s.Serve(strings.NewReader("Content-Length: 5\r\n\r\n{bad}"), &out)
+	assert.NoError(t, err)
+
+	_, body, ok := strings.Cut(out.String(), "\r\n\r\n")
+	assert.True(t, ok)
+	var m map[string]any
+	assert.NoError(t, json.Unmarshal([]byte(body), &m))
+	assert.Contains(t, m, "id")
+	assert.Nil(t, m["id"])
+	assert.Equal(t, float64(codeParseError), m["error"].(map[string]any)["code"])
+}
@@ -0,0 +1,231 @@
+package lsp
+
+import (
+	"strings"
+)
+
+// The functions in this file determine the YAML path at a given position. We can't use a real YAML parser here, as
+// documents are usually incomplete while being edited and might contain Jinja2 templates. Instead, indentation and
+// list markers are used to find the parents of the current line.
+
+// yamlLine is the result of tokenizing a single line of YAML
+type yamlLine struct {
+	// dashes contains the columns of all list item markers at the beginning of the line
+	dashes []int
+	// col is the column of the first character after the list item markers
+	col int
+	// key is only set if the line contains a 'key:'
+	key      string
+	hasColon bool
+	// value is the raw value after 'key:' or the whole rest of the line if no key is present
+	value    string
+	valueCol int
+	empty    bool
+}
+
+func (l *yamlLine) firstCol() int {
+	if len(l.dashes) != 0 {
+		return l.dashes[0]
+	}
+	return l.col
+}
+
+func parseYamlLine(s string) yamlLine {
+	var l yamlLine
+	i := 0
+	skipSpaces := func() {
+		for i < len(s) && s[i] == ' ' {
+			i++
+		}
+	}
+	skipSpaces()
+	for i < len(s) && s[i] == '-' && (i+1 == len(s) || s[i+1] == ' ') {
+		l.dashes = append(l.dashes, i)
+		i++
+		skipSpaces()
+	}
+	l.col = i
+	rest := s[i:]
+	if strings.TrimSpace(rest) == "" || strings.HasPrefix(rest, "#") {
+		l.empty = len(l.dashes) == 0
+		l.valueCol = i
+		return l
+	}
+
+	if c := findKeyColon(rest); c != -1 {
+		l.key = unquoteKey(strings.TrimSpace(rest[:c]))
+		l.hasColon = true
+		v := rest[c+1:]
+		trimmed := strings.TrimLeft(v, " ")
+		l.valueCol = i + c + 1 + len(v) - len(trimmed)
+		l.value = strings.TrimRight(trimmed, " \r")
+	} else {
+		l.valueCol = i
+		l.value = strings.TrimRight(rest, " \r")
+	}
+	return l
+}
+
+// findKeyColon returns the index of the colon that ends a mapping key, or -1 if s does not start with a key
+func findKeyColon(s string) int {
+	if strings.HasPrefix(s, "{{") || strings.HasPrefix(s, "{%") {
+		return -1
+	}
+	var quote byte
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if quote != 0 {
+			if c == quote {
+				quote = 0
+			}
+			continue
+		}
+		switch c {
+		case '"', '\'':
+			if i == 0 {
+				quote = c
+			}
+		case ':':
+			if i+1 == len(s) || s[i+1] == ' ' || s[i+1] == '\r' {
+				return i
+			}
+		case '#', '{', '[':
+			return -1
+		}
+	}
+	return -1
+}
+
+func unquoteKey(k string) string {
+	if len(k) >= 2 && (k[0] == '"' || k[0] == '\'') && k[len(k)-1] == k[0] {
+		return k[1 : len(k)-1]
+	}
+	return k
+}
+
+// parentPath returns the path of the mapping that contains the token at the given line and column. Array indexes are
+// always returned as "0", as we are only interested in the structure.
+func parentPath(lines []string, line int, col int) []string {
+	var rev []string
+	c := col
+	if line < len(lines) {
+		l := parseYamlLine(lines[line])
+		for i := len(l.dashes) - 1; i >= 0; i-- {
+			if l.dashes[i] < col {
+				rev = append(rev, "0")
+				c = l.dashes[i]
+			}
+		}
+	}
+
+	for i := line - 1; i >= 0 && c > 0; i-- {
+		if strings.HasPrefix(lines[i], "---") {
+			break
+		}
+		l := parseYamlLine(lines[i])
+		if l.empty || l.firstCol() >= c {
+			continue
+		}
+		if l.hasColon && l.col < c {
+			rev = append(rev, l.key)
+		}
+		for j := len(l.dashes) - 1; j >= 0; j-- {
+			if l.dashes[j] < c {
+				rev = append(rev, "0")
+			}
+		}
+		c = l.firstCol()
+	}
+
+	ret := make([]string, 0, len(rev))
+	for i := len(rev) - 1; i >= 0; i-- {
+		ret = append(ret, rev[i])
+	}
+	return ret
+}
+
+// cursorContext describes what's at the cursor position
+type cursorContext struct {
+	// path of the mapping that contains the key at the cursor
+	path []string
+	// isValue is true if the cursor is behind 'key:'
+	isValue bool
+	key     string
+	// prefix is the text of the key or value that is left of the cursor
+	prefix string
+}
+
+func getCursorContext(lines []string, pos Position) cursorContext {
+	var ctx cursorContext
+	if pos.Line >= len(lines) {
+		return ctx
+	}
+	s := lines[pos.Line]
+	col := pos.Character
+	if col > len(s) {
+		col = len(s)
+	}
+	l := parseYamlLine(s[:col])
+	ctx.path = parentPath(lines, pos.Line, l.col)
+	if l.hasColon {
+		ctx.isValue = true
+		ctx.key = l.key
+		ctx.prefix = l.value
+	} else {
+		ctx.prefix = strings.TrimSpace(l.value)
+	}
+	return ctx
+}
+
+// templateExpressionAt returns the text of the Jinja2 expression/statement left of the cursor, or false if the
+// cursor is not inside an expression.
+func templateExpressionAt(line string, col int) (string, bool) {
+	if col > len(line) {
+		col = len(line)
+	}
+	s := line[:col]
+	start := strings.LastIndex(s, "{{")
+	if i := strings.LastIndex(s, "{%"); i > start {
+		start = i
+	}
+	if start == -1 {
+		return "", false
+	}
+	end := strings.LastIndex(s, "}}")
+	if i := strings.LastIndex(s, "%}"); i > end {
+		end = i
+	}
+	if end > start {
+		return "", false
+	}
+	return s[start+2:], true
+}
+
+func isIdentChar(c byte) bool {
+	return c == '_' || c == '.' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// trailingIdentifier returns the dotted identifier at the end of s, e.g. "args.foo." for "{{ args.foo."
+func trailingIdentifier(s string) string {
+	i := len(s)
+	for i > 0 && isIdentChar(s[i-1]) {
+		i--
+	}
+	return s[i:]
+}
+
+// identifierAt returns the dotted identifier around col, cut at the end of the segment the cursor is in
+func identifierAt(line string, col int) string {
+	if col > len(line) {
+		col = len(line)
+	}
+	start := col
+	for start > 0 && isIdentChar(line[start-1]) {
+		start--
+	}
+	end := col
+	for end < len(line) && isIdentChar(line[end]) && line[end] != '.' {
+		end++
+	}
+	return strings.Trim(line[start:end], ".")
+}
@@ -121,6 +121,39 @@ func (c *Client) List(ctx context.Context, url string, opts ListOptions) ([]Meta
 	return metas, nil
 }
 
+// LatestSemverTag returns the highest tag of the given OCI repository that matches the semver constraint.
+func (c *Client) LatestSemverTag(ctx context.Context, url string, semverFilter string) (string, error) {
+	constraint, err := semver.NewConstraint(semverFilter)
+	if err != nil {
+		return "", fmt.Errorf("semver '%s' parse error: %w", semverFilter, err)
+	}
+
+	tags, err := crane.ListTags(url, c.optionsWithContext(ctx)...)
+	if err != nil {
+		return "", fmt.Errorf("listing tags failed: %w", err)
+	}
+
+	var latestTag string
+	var latest *semver.Version
+	for _, tag := range tags {
+		v, err := version.ParseVersion(tag)
+		if err != nil {
+			continue
+		}
+		if !constraint.Check(v) {
+			continue
+		}
+		if latest == nil || v.GreaterThan(latest) {
+			latest = v
+			latestTag = tag
+		}
+	}
+	if latest == nil {
+		return "", fmt.Errorf("no tag found in %s that matches semver '%s'", url, semverFilter)
+	}
+	return latestTag, nil
+}
+
 // IsCosignArtifact will return true if the tag has one of the following suffices:
 // ".att", ".sbom", or ".sig". These are the suffices used by cosign to store the
 // attestations, SBOMs, and signatures respectively.
@@ -142,3 +142,31 @@ func Test_List(t *testing.T) {
 		})
 	}
 }
+
+func Test_LatestSemverTag(t *testing.T) {
+	g := NewWithT(t)
+	ctx := context.Background()
+	c := NewClient(DefaultOptions())
+	repo := "test-semver" + randStringRunes(5)
+
+	for _, tag := range []string{"v0.0.1", "v1.2.0", "v1.10.0", "v1.10.1-rc.1", "v2.0.0", "latest"} {
+		dst := fmt.Sprintf("%s/%s:%s", dockerReg, repo, tag)
+		img, err := random.Image(1024, 1)
+		g.Expect(err).ToNot(HaveOccurred())
+		err = crane.Push(img, dst, c.options...)
+		g.Expect(err).ToNot(HaveOccurred())
+	}
+
+	url := fmt.Sprintf("%s/%s", dockerReg, repo)
+
+	tag, err := c.LatestSemverTag(ctx, url, "1.x")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(tag).To(Equal("v1.10.0"))
+
+	tag, err = c.LatestSemverTag(ctx, url, ">=1.10.1-rc")
+	g.Expect(err).ToNot(HaveOccurred())
+	g.Expect(tag).To(Equal("v2.0.0"))
+
+	_, err = c.LatestSemverTag(ctx, url, ">=3.0.0")
+	g.Expect(err).To(HaveOccurred())
+}
@@ -180,7 +180,15 @@ func (e *OciCacheEntry) GetExtractedDir(ref *types.OciRef) (string, git.Checkout
 		return ociDir, git.CheckoutInfo{}, err
 	}
 
-	image := strings.TrimPrefix(e.url.String(), "oci://") + ":" + ref.String()
+	repo := strings.TrimPrefix(e.url.String(), "oci://")
+	tag := ref.Tag
+	if ref.SemVer != "" && ref.Digest == "" {
+		tag, err = e.ociClient.LatestSemverTag(e.rp.ctx, repo, ref.SemVer)
+		if err != nil {
+			return "", git.CheckoutInfo{}, err
+		}
+	}
+	image := ref.ImageRef(repo, tag)
 
 	md, err := e.ociClient.Pull(e.rp.ctx, image, ociDir)
 	if err != nil {
@@ -400,6 +400,26 @@ func (s *ResultStoreSecrets) cleanupOrphanedResults() error {
 		}
 		tryDeleteResult(e.name, e.summary.KluctlDeployment, e.summary.Id, "validate result")
 	}
+
+	for idLabel, t := range map[string]string{
+		"kluctl.io/validate-history-id": "validate history",
+		"kluctl.io/project-outputs-id":  "project outputs",
+	} {
+		var l metav1.PartialObjectMetadataList
+		l.SetGroupVersionKind(schema.GroupVersionKind{Version: "v1", Kind: "SecretList"})
+		err = s.cache.List(s.ctx, &l, client.HasLabels{idLabel, "kluctl.io/result-deployment-name"})
+		if err != nil {
+			return err
+		}
+		for _, x := range l.Items {
+			deployment := &result.KluctlDeploymentInfo{
+				Name:      x.GetLabels()["kluctl.io/result-deployment-name"],
+				Namespace: x.GetLabels()["kluctl.io/result-deployment-namespace"],
+				ClusterId: s.clusterId,
+			}
+			tryDeleteResult(client.ObjectKeyFromObject(&x), deployment, x.GetLabels()[idLabel], t)
+		}
+	}
 	return nil
 }
 
@@ -839,6 +859,209 @@ func (s *ResultStoreSecrets) GetValidateResult(options GetValidateResultOptions)
 	return &vr, nil
 }
 
+func (s *ResultStoreSecrets) buildValidateHistoryId(projectKey result.ProjectKey, targetKey result.TargetKey) (string, error) {
+	j, err := yaml.WriteJsonString(GetValidateHistoryOptions{ProjectKey: projectKey, TargetKey: targetKey})
+	if err != nil {
+		return "", err
+	}
+	return utils.Sha256String(j)[:32], nil
+}
+
+func (s *ResultStoreSecrets) WriteValidateHistory(vh *result.ValidateHistory) error {
+	if !s.allowWrite {
+		return fmt.Errorf("result store is read-only")
+	}
+
+	err := s.ensureWriteNamespace()
+	if err != nil {
+		return err
+	}
+
+	id, err := s.buildValidateHistoryId(vh.ProjectKey, vh.TargetKey)
+	if err != nil {
+		return err
+	}
+
+	vhJson, err := yaml.WriteJsonString(vh)
+	if err != nil {
+		return err
+	}
+	compressedVh, err := utils.CompressGzip([]byte(vhJson), gzip.BestCompression)
+	if err != nil {
+		return err
+	}
+
+	secret := corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      s.buildName("vh", id, vh.ProjectKey),
+			Namespace: s.writeNamespace,
+			Labels: map[string]string{
+				"kluctl.io/result":              "true",
+				"kluctl.io/validate-history-id": id,
+			},
+			Annotations: map[string]string{},
+		},
+		Data: map[string][]byte{
+			"history": compressedVh,
+		},
+	}
+	if vh.ProjectKey.RepoKey.String() != "" {
+		secret.Annotations["kluctl.io/result-project-repo-key"] = vh.ProjectKey.RepoKey.String()
+	}
+	if vh.ProjectKey.SubDir != "" {
+		secret.Annotations["kluctl.io/result-project-subdir"] = vh.ProjectKey.SubDir
+	}
+	if vh.KluctlDeployment != nil {
+		secret.Labels["kluctl.io/result-deployment-name"] = vh.KluctlDeployment.Name
+		secret.Labels["kluctl.io/result-deployment-namespace"] = vh.KluctlDeployment.Namespace
+	}
+
+	return s.client.Patch(s.ctx, &secret, client.Apply, client.FieldOwner("kluctl-results"))
+}
+
+func (s *ResultStoreSecrets) GetValidateHistory(options GetValidateHistoryOptions) (*result.ValidateHistory, error) {
+	id, err := s.buildValidateHistoryId(options.ProjectKey, options.TargetKey)
+	if err != nil {
+		return nil, err
+	}
+
+	var l corev1.SecretList
+	err = s.client.List(s.ctx, &l, client.MatchingLabels{
+		"kluctl.io/validate-history-id": id,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if len(l.Items) == 0 {
+		return nil, nil
+	}
+
+	secret := l.Items[0]
+
+	j, ok := secret.Data["history"]
+	if !ok {
+		return nil, fmt.Errorf("history field not present for %s", secret.Name)
+	}
+	j, err = utils.UncompressGzip(j)
+	if err != nil {
+		return nil, err
+	}
+
+	var vh result.ValidateHistory
+	err = yaml.ReadYamlBytes(j, &vh)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vh, nil
+}
+
+type projectOutputsId struct {
+	ProjectKey result.ProjectKey `json:"projectKey"`
+	TargetKey  result.TargetKey  `json:"targetKey"`
+}
+
+func (s *ResultStoreSecrets) buildProjectOutputsId(projectKey result.ProjectKey, targetKey result.TargetKey) (string, error) {
+	j, err := yaml.WriteJsonString(projectOutputsId{ProjectKey: projectKey, TargetKey: targetKey})
+	if err != nil {
+		return "", err
+	}
+	return utils.Sha256String("outputs:" + j)[:32], nil
+}
+
+// BuildProjectOutputsProjectId returns the value of the kluctl.io/project-outputs-project-id label, which allows to
+// select all project outputs of a single project, independent of the target
+func BuildProjectOutputsProjectId(projectKey result.ProjectKey) (string, error) {
+	j, err := yaml.WriteJsonString(projectKey)
+	if err != nil {
+		return "", err
+	}
+	return utils.Sha256String("outputs-project:" + j)[:32], nil
+}
+
+func (s *ResultStoreSecrets) WriteProjectOutputs(po *result.ProjectOutputs) error {
+	if !s.allowWrite {
+		return fmt.Errorf("result store is read-only")
+	}
+
+	err := s.ensureWriteNamespace()
+	if err != nil {
+		return err
+	}
+
+	id, err := s.buildProjectOutputsId(po.ProjectKey, po.TargetKey)
+	if err != nil {
+		return err
+	}
+	projectId, err := BuildProjectOutputsProjectId(po.ProjectKey)
+	if err != nil {
+		return err
+	}
+
+	poJson, err := yaml.WriteJsonString(po)
+	if err != nil {
+		return err
+	}
+	compressedPo, err := utils.CompressGzip([]byte(poJson), gzip.BestCompression)
+	if err != nil {
+		return err
+	}
+
+	secret := corev1.Secret{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "Secret",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      s.buildName("po", id, po.ProjectKey),
+			Namespace: s.writeNamespace,
+			Labels: map[string]string{
+				"kluctl.io/result":                     "true",
+				"kluctl.io/project-outputs-id":         id,
+				"kluctl.io/project-outputs-project-id": projectId,
+			},
+			Annotations: map[string]string{},
+		},
+		Data: map[string][]byte{
+			"outputs": compressedPo,
+		},
+	}
+	if po.ProjectKey.RepoKey.String() != "" {
+		secret.Annotations["kluctl.io/result-project-repo-key"] = po.ProjectKey.RepoKey.String()
+	}
+	if po.ProjectKey.SubDir != "" {
+		secret.Annotations["kluctl.io/result-project-subdir"] = po.ProjectKey.SubDir
+	}
+	if po.KluctlDeployment != nil {
+		secret.Labels["kluctl.io/result-deployment-name"] = po.KluctlDeployment.Name
+		secret.Labels["kluctl.io/result-deployment-namespace"] = po.KluctlDeployment.Namespace
+	}
+
+	return s.client.Patch(s.ctx, &secret, client.Apply, client.FieldOwner("kluctl-results"))
+}
+
+// DecodeProjectOutputs decodes the 'outputs' field of a project outputs Secret as written by
+// ResultStoreSecrets.WriteProjectOutputs
+func DecodeProjectOutputs(data []byte) (*result.ProjectOutputs, error) {
+	if len(data) == 0 {
+		return nil, fmt.Errorf("outputs field not present")
+	}
+	j, err := utils.UncompressGzip(data)
+	if err != nil {
+		return nil, err
+	}
+	var po result.ProjectOutputs
+	err = yaml.ReadYamlBytes(j, &po)
+	if err != nil {
+		return nil, err
+	}
+	return &po, nil
+}
+
 func (s *ResultStoreSecrets) ListKluctlDeployments() ([]WatchKluctlDeploymentEvent, error) {
 	var l kluctlv1.KluctlDeploymentList
 	err := s.cache.List(s.ctx, &l)
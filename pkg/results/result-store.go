@@ -19,6 +19,11 @@ type GetValidateResultOptions struct {
 	Id string `json:"id"`
 }
 
+type GetValidateHistoryOptions struct {
+	ProjectKey result.ProjectKey `json:"projectKey"`
+	TargetKey  result.TargetKey  `json:"targetKey"`
+}
+
 type WatchCommandResultSummaryEvent struct {
 	Summary *result.CommandResultSummary `json:"summary"`
 	Delete  bool                         `json:"delete"`
@@ -48,6 +53,11 @@ type ResultStore interface {
 	WatchValidateResultSummaries(options ListResultSummariesOptions) (<-chan WatchValidateResultSummaryEvent, context.CancelFunc, error)
 	GetValidateResult(options GetValidateResultOptions) (*result.ValidateResult, error)
 
+	WriteValidateHistory(vh *result.ValidateHistory) error
+	GetValidateHistory(options GetValidateHistoryOptions) (*result.ValidateHistory, error)
+
+	WriteProjectOutputs(po *result.ProjectOutputs) error
+
 	ListKluctlDeployments() ([]WatchKluctlDeploymentEvent, error)
 	WatchKluctlDeployments() (<-chan WatchKluctlDeploymentEvent, context.CancelFunc, error)
 	GetKluctlDeployment(clusterId string, name string, namespace string) (*kluctlv1.KluctlDeployment, error)
@@ -395,6 +395,30 @@ func (rc *ResultsCollector) GetValidateResult(options GetValidateResultOptions)
 	return se.store.GetValidateResult(options)
 }
 
+func (rc *ResultsCollector) WriteValidateHistory(vh *result.ValidateHistory) error {
+	return fmt.Errorf("WriteValidateHistory is not supported in ResultsCollector")
+}
+
+func (rc *ResultsCollector) GetValidateHistory(options GetValidateHistoryOptions) (*result.ValidateHistory, error) {
+	rc.mutex.Lock()
+	var store ResultStore
+	for _, se := range rc.validateResultSummaries {
+		if se.summary.ProjectKey == options.ProjectKey && se.summary.TargetKey == options.TargetKey {
+			store = se.store
+			break
+		}
+	}
+	rc.mutex.Unlock()
+	if store == nil {
+		return nil, nil
+	}
+	return store.GetValidateHistory(options)
+}
+
+func (rc *ResultsCollector) WriteProjectOutputs(po *result.ProjectOutputs) error {
+	return fmt.Errorf("WriteProjectOutputs is not supported in ResultsCollector")
+}
+
 func (rc *ResultsCollector) ListKluctlDeployments() ([]WatchKluctlDeploymentEvent, error) {
 	rc.mutex.Lock()
 	defer rc.mutex.Unlock()
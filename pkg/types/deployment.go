@@ -1,10 +1,14 @@
 package types
 
 import (
+	"fmt"
 	"github.com/go-playground/validator/v10"
 	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
 	"github.com/kluctl/kluctl/v2/pkg/yaml"
+	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	"regexp"
+	"strings"
 )
 
 type DeploymentItemConfig struct {
@@ -12,8 +16,14 @@ type DeploymentItemConfig struct {
 	Include       *string                  `json:"include,omitempty"`
 	Git           *GitProject              `json:"git,omitempty"`
 	Oci           *OciProject              `json:"oci,omitempty"`
+	Jsonnet       *JsonnetItemConfig       `json:"jsonnet,omitempty"`
+	Cue           *CueItemConfig           `json:"cue,omitempty"`
+	Manifest      *ManifestItemConfig      `json:"manifest,omitempty"`
 	DeleteObjects []DeleteObjectItemConfig `json:"deleteObjects,omitempty"`
 
+	// Functions are KRM functions that are run on the objects of the item
+	Functions []KrmFunctionConfig `json:"functions,omitempty"`
+
 	Tags    []string `json:"tags,omitempty"`
 	Barrier bool     `json:"barrier,omitempty"`
 	Message *string  `json:"message,omitempty"`
@@ -34,6 +44,7 @@ type DeploymentItemConfig struct {
 	RenderedHelmChartConfig *HelmChartConfig         `json:"renderedHelmChartConfig,omitempty"`
 	RenderedObjects         []k8s.ObjectRef          `json:"renderedObjects,omitempty"`
 	RenderedInclude         *DeploymentProjectConfig `json:"renderedInclude,omitempty"`
+	RenderedRemoteResources []RemoteResourceInfo     `json:"renderedRemoteResources,omitempty"`
 }
 
 func ValidateDeploymentItemConfig(sl validator.StructLevel) {
@@ -55,11 +66,24 @@ func ValidateDeploymentItemConfig(sl validator.StructLevel) {
 		cnt += 1
 		isInclude = true
 	}
+	if s.Jsonnet != nil {
+		cnt += 1
+	}
+	if s.Cue != nil {
+		cnt += 1
+	}
+	if s.Manifest != nil {
+		cnt += 1
+	}
 	if cnt > 1 {
-		sl.ReportError(s, "self", "self", "only one of path, include, git and oci can be set at the same time", "")
+		sl.ReportError(s, "self", "self", "only one of path, include, git, oci, jsonnet, cue and manifest can be set at the same time", "")
+	}
+	isRendered := s.Path != nil || s.Jsonnet != nil || s.Cue != nil || s.Manifest != nil
+	if !isRendered && s.WaitReadiness {
+		sl.ReportError(s, "waitReadiness", "WaitReadiness", "only kustomize, jsonnet, cue and manifest deployments are allowed to have waitReadiness set", "")
 	}
-	if s.Path == nil && s.WaitReadiness {
-		sl.ReportError(s, "waitReadiness", "WaitReadiness", "only kustomize deployments are allowed to have waitReadiness set", "")
+	if !isRendered && len(s.Functions) != 0 {
+		sl.ReportError(s, "functions", "Functions", "only kustomize, jsonnet, cue and manifest deployments are allowed to have functions", "")
 	}
 	if !s.Args.IsZero() && !isInclude {
 		sl.ReportError(s, "self", "self", "args are only allowed when another project is included (via include, git or oci)", "")
@@ -69,6 +93,108 @@ func ValidateDeploymentItemConfig(sl validator.StructLevel) {
 	}
 }
 
+type JsonnetItemConfig struct {
+	// Main is the path to the Jsonnet file to evaluate, relative to the deployment project
+	Main string `json:"main" validate:"required"`
+	// Libs are additional library search paths, relative to the deployment project
+	Libs []string `json:"libs,omitempty"`
+	// Tlas are passed as top-level arguments to the function returned by Main
+	Tlas *uo.UnstructuredObject `json:"tlas,omitempty"`
+}
+
+type CueItemConfig struct {
+	// Dir is the directory of the CUE package to evaluate, relative to the deployment project
+	Dir string `json:"dir" validate:"required"`
+	// Package selects the package to load in case Dir contains multiple packages
+	Package string `json:"package,omitempty"`
+	// ObjectsPath is the path of the value that contains the objects to export, defaults to "objects"
+	ObjectsPath string `json:"objectsPath,omitempty"`
+	// VarsPath is the path at which Kluctl vars are injected, defaults to "vars"
+	VarsPath string `json:"varsPath,omitempty"`
+}
+
+type ManifestItemConfig struct {
+	// Url is the http(s) url of a single multi-document YAML file, e.g. an install.yaml from a release page
+	Url string `json:"url,omitempty"`
+	// Sha256 is the expected hex encoded SHA256 checksum of the file behind Url
+	Sha256 string `json:"sha256,omitempty"`
+	// Oci is an OCI artifact that contains the manifests. All .yaml and .yml files found in the root of the
+	// artifact (or in subDir) are loaded in alphabetical order
+	Oci *OciProject `json:"oci,omitempty"`
+
+	// SkipTemplating disables Jinja2 rendering of the downloaded manifests
+	SkipTemplating bool `json:"skipTemplating,omitempty"`
+
+	// UpdateConstraints restricts the versions that manifest-update upgrades to, e.g. "~1.2.0"
+	UpdateConstraints *string `json:"updateConstraints,omitempty"`
+	// UpdateGitUrl is the git repository whose tags are used by manifest-update to find new versions of Url.
+	// Defaults to the repository of GitHub release download urls
+	UpdateGitUrl *GitUrl `json:"updateGitUrl,omitempty"`
+	// SkipUpdate excludes the manifest from manifest-update
+	SkipUpdate bool `json:"skipUpdate,omitempty"`
+}
+
+var manifestSha256Regex = regexp.MustCompile(`^[a-f0-9]{64}$`)
+
+func ValidateManifestItemConfig(sl validator.StructLevel) {
+	s := sl.Current().Interface().(ManifestItemConfig)
+	if (s.Url == "") == (s.Oci == nil) {
+		sl.ReportError(s, "self", "self", "exactly one of url or oci must be set", "")
+	}
+	if s.Url != "" {
+		if !strings.HasPrefix(s.Url, "https://") && !strings.HasPrefix(s.Url, "http://") {
+			sl.ReportError(s.Url, "url", "Url", "url must be a http or https url", "")
+		}
+		if !manifestSha256Regex.MatchString(s.Sha256) {
+			sl.ReportError(s.Sha256, "sha256", "Sha256", "sha256 must be set to the hex encoded SHA256 checksum of the manifest", "")
+		}
+	} else if s.Sha256 != "" {
+		sl.ReportError(s.Sha256, "sha256", "Sha256", "sha256 is only allowed for url, use oci.ref.digest to pin OCI artifacts", "")
+	}
+	if s.UpdateGitUrl != nil && s.Url == "" {
+		sl.ReportError(s, "updateGitUrl", "UpdateGitUrl", "updateGitUrl is only allowed for url", "")
+	}
+}
+
+type KrmFunctionConfig struct {
+	Exec     *KrmExecFunction     `json:"exec,omitempty"`
+	Starlark *KrmStarlarkFunction `json:"starlark,omitempty"`
+	// Config is passed to the function as functionConfig
+	Config *uo.UnstructuredObject `json:"config,omitempty"`
+	// Generator functions don't receive the item's objects and their output is appended to the item's objects
+	Generator bool `json:"generator,omitempty"`
+}
+
+type KrmExecFunction struct {
+	// Path is the executable to run. Paths containing a slash are relative to the deployment project, other paths
+	// are looked up in PATH
+	Path string   `json:"path" validate:"required"`
+	Args []string `json:"args,omitempty"`
+}
+
+type KrmStarlarkFunction struct {
+	// Path is the Starlark script to run, relative to the deployment project
+	Path string `json:"path" validate:"required"`
+}
+
+func ValidateKrmFunctionConfig(sl validator.StructLevel) {
+	s := sl.Current().Interface().(KrmFunctionConfig)
+	if (s.Exec == nil) == (s.Starlark == nil) {
+		sl.ReportError(s, "self", "self", "exactly one of exec or starlark must be set", "")
+	}
+}
+
+// RemoteResourceInfo describes a remote kustomize resource or component that was fetched while building a
+// kustomize deployment
+type RemoteResourceInfo struct {
+	// Url is the reference as found in the kustomization file or the url of a manifest item
+	Url string `json:"url"`
+	// Ref is the resolved git ref or OCI ref
+	Ref string `json:"ref,omitempty"`
+	// Commit is the commit that the remote resolved to
+	Commit string `json:"commit,omitempty"`
+}
+
 type ObjectRefItem struct {
 	Group     *string `json:"group,omitempty"`
 	Kind      *string `json:"kind,omitempty"`
@@ -176,12 +302,158 @@ type DeploymentProjectConfig struct {
 
 	IgnoreForDiff      []IgnoreForDiffItemConfig  `json:"ignoreForDiff,omitempty"`
 	ConflictResolution []ConflictResolutionConfig `json:"conflictResolution,omitempty"`
+
+	Transformations []TransformationConfig `json:"transformations,omitempty"`
+
+	Outputs []DeploymentOutput `json:"outputs,omitempty"`
+}
+
+// TransformationConfig describes a mutation that is applied to all matching rendered objects of a project and all
+// its includes. The patch, jsonPatch, set and remove operations are applied in that order.
+type TransformationConfig struct {
+	// Name is used in errors and in the template trace
+	Name   string                `json:"name,omitempty"`
+	Target *TransformationTarget `json:"target,omitempty"`
+
+	// Patch is a strategic merge patch
+	Patch     *uo.UnstructuredObject `json:"patch,omitempty"`
+	JsonPatch []JsonPatchOperation   `json:"jsonPatch,omitempty"`
+	Set       []TransformationSetter `json:"set,omitempty"`
+	Remove    SingleStringOrList     `json:"remove,omitempty"`
+}
+
+func ValidateTransformationConfig(sl validator.StructLevel) {
+	s := sl.Current().Interface().(TransformationConfig)
+	if s.Patch == nil && len(s.JsonPatch) == 0 && len(s.Set) == 0 && len(s.Remove) == 0 {
+		sl.ReportError(s, "self", "self", "at least one of patch, jsonPatch, set or remove must be set", "")
+	}
+}
+
+// TransformationTarget selects the objects a transformation is applied to. Unset fields match all objects.
+type TransformationTarget struct {
+	Group     *string `json:"group,omitempty"`
+	Version   *string `json:"version,omitempty"`
+	Kind      *string `json:"kind,omitempty"`
+	Name      *string `json:"name,omitempty"`
+	Namespace *string `json:"namespace,omitempty"`
+	// LabelSelector uses the same syntax as kubectl's --selector
+	LabelSelector string `json:"labelSelector,omitempty"`
+}
+
+// JsonPatchOperation is a single RFC 6902 operation
+type JsonPatchOperation struct {
+	Op    string                `json:"op" validate:"required,oneof=add remove replace move copy test"`
+	Path  string                `json:"path" validate:"required"`
+	From  string                `json:"from,omitempty"`
+	Value *apiextensionsv1.JSON `json:"value,omitempty"`
+}
+
+// TransformationSetter sets all fields matching FieldPath to Value or, if Regex is set, replaces all matches of Regex
+// inside matching string fields with Replacement
+type TransformationSetter struct {
+	FieldPath   string                `json:"fieldPath" validate:"required"`
+	Value       *apiextensionsv1.JSON `json:"value,omitempty"`
+	Regex       string                `json:"regex,omitempty"`
+	Replacement string                `json:"replacement,omitempty"`
+}
+
+func ValidateTransformationSetter(sl validator.StructLevel) {
+	s := sl.Current().Interface().(TransformationSetter)
+	if (s.Value == nil) == (s.Regex == "") {
+		sl.ReportError(s, "self", "self", "exactly one of value or regex must be set", "")
+	}
+	if s.Replacement != "" && s.Regex == "" {
+		sl.ReportError(s, "replacement", "Replacement", "replacement can only be used together with regex", "")
+	}
+}
+
+// DeploymentOutput declares a value that is computed after a successful deployment and stored in the result store,
+// so that other projects can consume it via the kluctlOutputs vars source.
+type DeploymentOutput struct {
+	Name string `json:"name" validate:"required"`
+	// Value is a static (usually templated) value
+	Value *apiextensionsv1.JSON `json:"value,omitempty"`
+	// Object reads the value from an applied object via JSONPath
+	Object *DeploymentOutputObject `json:"object,omitempty"`
+}
+
+type DeploymentOutputObject struct {
+	ObjectRefItem
+	JsonPath string `json:"jsonPath" validate:"required"`
+}
+
+func ValidateDeploymentOutput(sl validator.StructLevel) {
+	s := sl.Current().Interface().(DeploymentOutput)
+	if (s.Value == nil) == (s.Object == nil) {
+		sl.ReportError(s, "self", "self", "exactly one of value or object must be set", "")
+	}
+	if s.Object != nil && s.Object.Group == nil && s.Object.Kind == nil {
+		sl.ReportError(s, "object", "Object", "at least one of group or kind must be set", "")
+	}
 }
 
 func init() {
 	yaml.Validator.RegisterStructValidation(ValidateDeploymentItemConfig, DeploymentItemConfig{})
+	yaml.Validator.RegisterStructValidation(ValidateManifestItemConfig, ManifestItemConfig{})
+	yaml.Validator.RegisterStructValidation(ValidateKrmFunctionConfig, KrmFunctionConfig{})
 	yaml.Validator.RegisterStructValidation(ValidateDeleteObjectItemConfig, DeleteObjectItemConfig{})
 	yaml.Validator.RegisterStructValidation(ValidateWaitReadinessObjectItemConfig, WaitReadinessObjectItemConfig{})
 	yaml.Validator.RegisterStructValidation(ValidateIgnoreForDiffItemConfig, IgnoreForDiffItemConfig{})
 	yaml.Validator.RegisterStructValidation(ValidateConflictResolutionConfig, ConflictResolutionConfig{})
+	yaml.Validator.RegisterStructValidation(ValidateTransformationConfig, TransformationConfig{})
+	yaml.Validator.RegisterStructValidation(ValidateTransformationSetter, TransformationSetter{})
+	yaml.Validator.RegisterStructValidation(ValidateDeploymentOutput, DeploymentOutput{})
+
+	yaml.RegisterSchemaExtension(func(s yaml.JSONSchema) yaml.JSONSchema {
+		yaml.SchemaRemoveProperties(s, "renderedHelmChartConfig", "renderedObjects", "renderedInclude", "renderedRemoteResources")
+		yaml.SchemaMutuallyExclusive(s, "path", "include", "git", "oci")
+		yaml.SchemaIfThen(s, yaml.JSONSchema{"properties": yaml.JSONSchema{"waitReadiness": yaml.JSONSchema{"const": true}}, "required": []any{"waitReadiness"}},
+			yaml.JSONSchema{"required": []any{"path"}},
+			"only kustomize deployments are allowed to have waitReadiness set")
+		for _, p := range []string{"args", "passVars"} {
+			yaml.SchemaIfThen(s, yaml.JSONSchema{"required": []any{p}},
+				yaml.JSONSchema{"anyOf": []any{yaml.JSONSchema{"required": []any{"include"}}, yaml.JSONSchema{"required": []any{"git"}}, yaml.JSONSchema{"required": []any{"oci"}}}},
+				fmt.Sprintf("%s is only allowed when another project is included (via include, git or oci)", p))
+		}
+		return s
+	}, DeploymentItemConfig{})
+	yaml.RegisterSchemaExtension(func(s yaml.JSONSchema) yaml.JSONSchema {
+		yaml.SchemaRequireAnyOf(s, "group", "kind")
+		return s
+	}, DeleteObjectItemConfig{})
+	yaml.RegisterSchemaExtension(func(s yaml.JSONSchema) yaml.JSONSchema {
+		yaml.SchemaRequireOneOf(s, "url", "oci")
+		return s
+	}, ManifestItemConfig{})
+	yaml.RegisterSchemaExtension(func(s yaml.JSONSchema) yaml.JSONSchema {
+		yaml.SchemaRequireAnyOf(s, "group", "kind")
+		return s
+	}, WaitReadinessObjectItemConfig{})
+	yaml.RegisterSchemaExtension(func(s yaml.JSONSchema) yaml.JSONSchema {
+		yaml.SchemaRequireAnyOf(s, "fieldPath", "fieldPathRegex")
+		return s
+	}, IgnoreForDiffItemConfig{})
+	yaml.RegisterSchemaExtension(func(s yaml.JSONSchema) yaml.JSONSchema {
+		yaml.SchemaRequireAnyOf(s, "fieldPath", "fieldPathRegex", "manager")
+		return s
+	}, ConflictResolutionConfig{})
+	yaml.RegisterSchemaExtension(func(s yaml.JSONSchema) yaml.JSONSchema {
+		yaml.SchemaRequireAnyOf(s, "patch", "jsonPatch", "set", "remove")
+		return s
+	}, TransformationConfig{})
+	yaml.RegisterSchemaExtension(func(s yaml.JSONSchema) yaml.JSONSchema {
+		yaml.SchemaRequireOneOf(s, "value", "regex")
+		return s
+	}, TransformationSetter{})
+	yaml.RegisterSchemaExtension(func(s yaml.JSONSchema) yaml.JSONSchema {
+		yaml.SchemaRequireOneOf(s, "value", "object")
+		return s
+	}, DeploymentOutput{})
+	yaml.RegisterSchemaExtension(func(s yaml.JSONSchema) yaml.JSONSchema {
+		yaml.SchemaRequireAnyOf(s, "group", "kind")
+		return s
+	}, DeploymentOutputObject{})
+	yaml.RegisterSchemaExtension(func(s yaml.JSONSchema) yaml.JSONSchema {
+		return yaml.SchemaStringOr(yaml.JSONSchema{"type": "array", "items": yaml.JSONSchema{"type": "string"}})
+	}, SingleStringOrList{})
 }
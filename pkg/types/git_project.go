@@ -135,4 +135,8 @@ func ValidateGitProject(sl validator.StructLevel) {
 
 func init() {
 	yaml.Validator.RegisterStructValidation(ValidateGitProject, GitProject{})
+
+	// GitProject and GitRef can also be specified as plain strings
+	yaml.RegisterSchemaExtension(yaml.SchemaStringOr, GitProject{})
+	yaml.RegisterSchemaExtension(yaml.SchemaStringOr, GitRef{})
 }
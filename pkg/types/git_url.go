@@ -271,3 +271,11 @@ func (u RepoKey) MarshalJSON() ([]byte, error) {
 
 	return b, err
 }
+
+func init() {
+	stringSchema := func(s yaml.JSONSchema) yaml.JSONSchema {
+		return yaml.JSONSchema{"type": "string"}
+	}
+	yaml.RegisterSchemaExtension(stringSchema, GitUrl{})
+	yaml.RegisterSchemaExtension(stringSchema, RepoKey{})
+}
@@ -59,4 +59,25 @@ type HelmChartConfig struct {
 
 func init() {
 	yaml.Validator.RegisterStructValidation(ValidateHelmChartConfig2, HelmChartConfig2{})
+
+	yaml.RegisterSchemaExtension(func(s yaml.JSONSchema) yaml.JSONSchema {
+		yaml.SchemaRequireOneOf(s, "repo", "path")
+		yaml.SchemaIfThen(s, yaml.JSONSchema{"required": []any{"repo"}}, yaml.JSONSchema{"required": []any{"chartVersion"}},
+			"chartVersion must be specified when repo is specified")
+		yaml.SchemaIfThen(s, yaml.JSONSchema{"required": []any{"path"}}, yaml.JSONSchema{
+			"not": yaml.JSONSchema{"anyOf": []any{
+				yaml.JSONSchema{"required": []any{"chartName"}},
+				yaml.JSONSchema{"required": []any{"chartVersion"}},
+				yaml.JSONSchema{"required": []any{"updateConstraints"}},
+			}},
+		}, "chartName, chartVersion and updateConstraints can not be specified for local Helm charts")
+		// OCI repos must not specify chartName, while normal Helm repos require it
+		yaml.SchemaIfThen(s, yaml.JSONSchema{"required": []any{"repo"}, "properties": yaml.JSONSchema{"repo": yaml.JSONSchema{"pattern": "^oci://"}}},
+			yaml.JSONSchema{"not": yaml.JSONSchema{"required": []any{"chartName"}}},
+			"chartName can not be specified when repo is a OCI url")
+		yaml.SchemaIfThen(s, yaml.JSONSchema{"required": []any{"repo"}, "properties": yaml.JSONSchema{"repo": yaml.JSONSchema{"not": yaml.JSONSchema{"pattern": "^oci://"}}}},
+			yaml.JSONSchema{"required": []any{"chartName"}},
+			"chartName must be specified when repo is normal Helm repo")
+		return s
+	}, HelmChartConfig2{})
 }
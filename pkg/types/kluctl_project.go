@@ -1,7 +1,9 @@
 package types
 
 import (
+	"github.com/go-playground/validator/v10"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/kluctl/kluctl/v2/pkg/yaml"
 	apiextensionsv1 "k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
 )
 
@@ -29,11 +31,22 @@ type Target struct {
 	Aws           *AwsConfig             `json:"aws,omitempty"`
 	Images        []FixedImage           `json:"images,omitempty"`
 	Discriminator string                 `json:"discriminator,omitempty"`
+
+	GeneratedSecrets *TargetGeneratedSecrets `json:"generatedSecrets,omitempty"`
+}
+
+type TargetGeneratedSecrets struct {
+	// Namespace of the Secret that holds the generated secrets of the target. The namespace must already exist
+	Namespace string `json:"namespace,omitempty"`
 }
 
 type DeploymentArg struct {
-	Name    string                `json:"name" validate:"required"`
-	Default *apiextensionsv1.JSON `json:"default,omitempty"`
+	Name        string                `json:"name" validate:"required"`
+	Default     *apiextensionsv1.JSON `json:"default,omitempty"`
+	Description string                `json:"description,omitempty"`
+
+	// Schema is a JSON Schema that the arg value must conform to
+	Schema *apiextensionsv1.JSON `json:"schema,omitempty"`
 }
 
 type SecretSet struct {
@@ -52,14 +65,67 @@ type SecretsConfig struct {
 	SecretSets    []SecretSet                `json:"secretSets,omitempty"`
 }
 
+// TemplatingLibrary points to a directory inside the project or inside a git or oci library project
+type TemplatingLibrary struct {
+	// Path is relative to the project directory and must be inside the project's repository
+	Path *string     `json:"path,omitempty"`
+	Git  *GitProject `json:"git,omitempty"`
+	Oci  *OciProject `json:"oci,omitempty"`
+}
+
+func ValidateTemplatingLibrary(sl validator.StructLevel) {
+	s := sl.Current().Interface().(TemplatingLibrary)
+	cnt := 0
+	if s.Path != nil {
+		cnt++
+	}
+	if s.Git != nil {
+		cnt++
+	}
+	if s.Oci != nil {
+		cnt++
+	}
+	if cnt != 1 {
+		sl.ReportError(s, "self", "self", "exactly one of path, git or oci must be set", "")
+	}
+}
+
+type TemplatingConfig struct {
+	// Extensions are Jinja2 extension classes or Python modules, e.g. 'my_filters.MyExtension'
+	Extensions []string            `json:"extensions,omitempty"`
+	PythonPath []TemplatingLibrary `json:"pythonPath,omitempty"`
+	// SearchDirs are searched for includes and imports, e.g. for macro libraries
+	SearchDirs []TemplatingLibrary `json:"searchDirs,omitempty"`
+}
+
+type KustomizeConfig struct {
+	// RemoteHosts is the list of hosts that remote kustomize resources, components and manifests may be fetched from.
+	// Entries can contain shell patterns, e.g. '*.example.com'. If omitted, all hosts are allowed, except inside the
+	// controller.
+	RemoteHosts []string `json:"remoteHosts,omitempty"`
+}
+
+type KrmFunctionsConfig struct {
+	// ExecAllowList is the list of executables that exec based KRM functions may run. Entries can contain shell
+	// patterns. Exec functions additionally need to be allowed via --allow-krm-exec.
+	ExecAllowList []string `json:"execAllowList,omitempty"`
+}
+
 type KluctlProject struct {
-	Targets       []Target        `json:"targets,omitempty"`
-	Args          []DeploymentArg `json:"args,omitempty"`
-	SecretsConfig *SecretsConfig  `json:"secretsConfig,omitempty"`
-	Discriminator string          `json:"discriminator,omitempty"`
-	Aws           *AwsConfig      `json:"aws,omitempty"`
+	Targets       []Target            `json:"targets,omitempty"`
+	Args          []DeploymentArg     `json:"args,omitempty"`
+	SecretsConfig *SecretsConfig      `json:"secretsConfig,omitempty"`
+	Discriminator string              `json:"discriminator,omitempty"`
+	Aws           *AwsConfig          `json:"aws,omitempty"`
+	Templating    *TemplatingConfig   `json:"templating,omitempty"`
+	Kustomize     *KustomizeConfig    `json:"kustomize,omitempty"`
+	KrmFunctions  *KrmFunctionsConfig `json:"krmFunctions,omitempty"`
 }
 
 type KluctlLibraryProject struct {
 	Args []DeploymentArg `json:"args,omitempty"`
 }
+
+func init() {
+	yaml.Validator.RegisterStructValidation(ValidateTemplatingLibrary, TemplatingLibrary{})
+}
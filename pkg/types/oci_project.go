@@ -2,10 +2,14 @@ package types
 
 import (
 	"fmt"
+	"github.com/Masterminds/semver/v3"
 	"github.com/go-playground/validator/v10"
 	"github.com/kluctl/kluctl/v2/pkg/yaml"
+	"regexp"
 )
 
+var ociDigestRegex = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
 type OciProject struct {
 	Url    string  `json:"url" validate:"required"`
 	Ref    *OciRef `json:"ref,omitempty"`
@@ -18,6 +22,11 @@ type OciRef struct {
 	// +optional
 	Digest string `json:"digest,omitempty"`
 
+	// SemVer is the range of tags to pull selecting the latest within
+	// the range, takes precedence over Tag.
+	// +optional
+	SemVer string `json:"semver,omitempty"`
+
 	// Tag is the image tag to pull, defaults to latest.
 	// +optional
 	Tag string `json:"tag,omitempty"`
@@ -28,16 +37,46 @@ func (ref *OciRef) String() string {
 		return "latest"
 	}
 
-	if ref.Tag == "" && ref.Digest == "" {
+	if ref.Tag == "" && ref.Digest == "" && ref.SemVer == "" {
 		return "latest"
 	}
 
 	if ref.Digest != "" {
 		return ref.Tag + "@" + ref.Digest
 	}
+	if ref.SemVer != "" {
+		return "semver:" + ref.SemVer
+	}
 	return ref.Tag
 }
 
+// ImageRef builds the full image reference for the given repository. The tag must be resolved by the caller when
+// SemVer is used.
+func (ref *OciRef) ImageRef(repo string, tag string) string {
+	if tag == "" {
+		tag = "latest"
+	}
+	if ref != nil && ref.Digest != "" {
+		if ref.Tag == "" {
+			return repo + "@" + ref.Digest
+		}
+		return repo + ":" + ref.Tag + "@" + ref.Digest
+	}
+	return repo + ":" + tag
+}
+
+func ValidateOciRef(sl validator.StructLevel) {
+	ref := sl.Current().Interface().(OciRef)
+	if ref.Digest != "" && !ociDigestRegex.MatchString(ref.Digest) {
+		sl.ReportError(ref.Digest, "digest", "Digest", fmt.Sprintf("'%s' is not a valid digest, must be in the format 'sha256:<HASH>'", ref.Digest), "")
+	}
+	if ref.SemVer != "" {
+		if _, err := semver.NewConstraint(ref.SemVer); err != nil {
+			sl.ReportError(ref.SemVer, "semver", "SemVer", fmt.Sprintf("'%s' is not a valid semver range: %s", ref.SemVer, err.Error()), "")
+		}
+	}
+}
+
 func ValidateOciProject(sl validator.StructLevel) {
 	p := sl.Current().Interface().(OciProject)
 	if !validateGitSubDir(p.SubDir) {
@@ -47,4 +86,10 @@ func ValidateOciProject(sl validator.StructLevel) {
 
 func init() {
 	yaml.Validator.RegisterStructValidation(ValidateOciProject, OciProject{})
+	yaml.Validator.RegisterStructValidation(ValidateOciRef, OciRef{})
+
+	yaml.RegisterSchemaExtension(func(s yaml.JSONSchema) yaml.JSONSchema {
+		s["properties"].(yaml.JSONSchema)["digest"] = yaml.JSONSchema{"type": "string", "pattern": ociDigestRegex.String()}
+		return s
+	}, OciRef{})
 }
@@ -137,6 +137,7 @@ type CommandResult struct {
 	GitInfo          GitInfo                        `json:"gitInfo,omitempty"`
 	ClusterInfo      ClusterInfo                    `json:"clusterInfo"`
 	Deployment       *types.DeploymentProjectConfig `json:"deployment,omitempty"`
+	ProjectArgs      []types.DeploymentArg          `json:"projectArgs,omitempty"`
 
 	RenderedObjectsHash string         `json:"renderedObjectsHash,omitempty"`
 	Objects             []ResultObject `json:"objects,omitempty"`
@@ -144,6 +145,11 @@ type CommandResult struct {
 	Errors     []DeploymentError  `json:"errors,omitempty"`
 	Warnings   []DeploymentError  `json:"warnings,omitempty"`
 	SeenImages []types.FixedImage `json:"seenImages,omitempty"`
+
+	VarsProvenance *VarsProvenance `json:"varsProvenance,omitempty"`
+
+	// Outputs contains the computed outputs of the deployment project, see types.DeploymentOutput
+	Outputs *uo.UnstructuredObject `json:"outputs,omitempty"`
 }
 
 func (cr *CommandResult) ToCompacted() *CompactedCommandResult {
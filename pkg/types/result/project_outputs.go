@@ -0,0 +1,33 @@
+package result
+
+import (
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ProjectOutputs holds the outputs of the last successful deployment of a single target. It's stored separately from
+// the command result, so that consumers don't need to load full command results.
+type ProjectOutputs struct {
+	ProjectKey       ProjectKey            `json:"projectKey"`
+	TargetKey        TargetKey             `json:"targetKey"`
+	KluctlDeployment *KluctlDeploymentInfo `json:"kluctlDeployment,omitempty"`
+
+	CommandResultId string                 `json:"commandResultId"`
+	Time            metav1.Time            `json:"time"`
+	Outputs         *uo.UnstructuredObject `json:"outputs"`
+}
+
+func NewProjectOutputs(cr *CommandResult) *ProjectOutputs {
+	ret := &ProjectOutputs{
+		ProjectKey:       cr.ProjectKey,
+		TargetKey:        cr.TargetKey,
+		KluctlDeployment: cr.KluctlDeployment,
+		CommandResultId:  cr.Id,
+		Time:             cr.Command.EndTime,
+		Outputs:          cr.Outputs,
+	}
+	if ret.Outputs == nil {
+		ret.Outputs = uo.New()
+	}
+	return ret
+}
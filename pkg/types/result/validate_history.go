@@ -0,0 +1,163 @@
+package result
+
+import (
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sort"
+	"time"
+)
+
+// ValidateHistoryTransition marks the point in time where the readiness of a target or object changed.
+type ValidateHistoryTransition struct {
+	Time             metav1.Time `json:"time"`
+	Ready            bool        `json:"ready"`
+	Message          string      `json:"message,omitempty"`
+	ValidateResultId string      `json:"validateResultId,omitempty"`
+}
+
+type ValidateHistoryObject struct {
+	Ref         k8s.ObjectRef               `json:"ref"`
+	Transitions []ValidateHistoryTransition `json:"transitions,omitempty"`
+}
+
+// ValidateHistory is a compact time-series of readiness transitions of a single target. Only changes in readiness
+// are recorded, so that the history stays small even when validation is performed very often.
+type ValidateHistory struct {
+	ProjectKey       ProjectKey            `json:"projectKey"`
+	TargetKey        TargetKey             `json:"targetKey"`
+	KluctlDeployment *KluctlDeploymentInfo `json:"kluctlDeployment,omitempty"`
+
+	LastValidateTime metav1.Time                 `json:"lastValidateTime"`
+	Transitions      []ValidateHistoryTransition `json:"transitions,omitempty"`
+	Objects          []ValidateHistoryObject     `json:"objects,omitempty"`
+}
+
+func NewValidateHistory(projectKey ProjectKey, targetKey TargetKey) *ValidateHistory {
+	return &ValidateHistory{
+		ProjectKey: projectKey,
+		TargetKey:  targetKey,
+	}
+}
+
+func lastTransition(transitions []ValidateHistoryTransition) *ValidateHistoryTransition {
+	if len(transitions) == 0 {
+		return nil
+	}
+	return &transitions[len(transitions)-1]
+}
+
+func addTransition(transitions []ValidateHistoryTransition, t ValidateHistoryTransition) []ValidateHistoryTransition {
+	if lt := lastTransition(transitions); lt != nil && lt.Ready == t.Ready {
+		return transitions
+	}
+	return append(transitions, t)
+}
+
+// AddValidateResult records all readiness changes found in the given validate result. Objects are considered
+// not ready when at least one error references them and ready again as soon as no errors reference them anymore.
+func (h *ValidateHistory) AddValidateResult(vr *ValidateResult) {
+	t := vr.EndTime
+	if t.IsZero() {
+		t = vr.StartTime
+	}
+
+	h.KluctlDeployment = vr.KluctlDeployment
+	h.LastValidateTime = t
+
+	message := ""
+	if !vr.Ready && len(vr.Errors) != 0 {
+		message = vr.Errors[0].Message
+	}
+	h.Transitions = addTransition(h.Transitions, ValidateHistoryTransition{
+		Time:             t,
+		Ready:            vr.Ready,
+		Message:          message,
+		ValidateResultId: vr.Id,
+	})
+
+	notReady := map[k8s.ObjectRef]string{}
+	for _, e := range vr.Errors {
+		if e.Ref == (k8s.ObjectRef{}) {
+			continue
+		}
+		if _, ok := notReady[e.Ref]; !ok {
+			notReady[e.Ref] = e.Message
+		}
+	}
+
+	for i := range h.Objects {
+		o := &h.Objects[i]
+		msg, ok := notReady[o.Ref]
+		o.Transitions = addTransition(o.Transitions, ValidateHistoryTransition{
+			Time:             t,
+			Ready:            !ok,
+			Message:          msg,
+			ValidateResultId: vr.Id,
+		})
+		delete(notReady, o.Ref)
+	}
+
+	// objects that were never seen before are implicitly considered ready up to now
+	for ref, msg := range notReady {
+		h.Objects = append(h.Objects, ValidateHistoryObject{
+			Ref: ref,
+			Transitions: []ValidateHistoryTransition{{
+				Time:             t,
+				Ready:            false,
+				Message:          msg,
+				ValidateResultId: vr.Id,
+			}},
+		})
+	}
+
+	sort.SliceStable(h.Objects, func(i, j int) bool {
+		return h.Objects[i].Ref.Less(h.Objects[j].Ref)
+	})
+}
+
+func pruneTransitions(transitions []ValidateHistoryTransition, before time.Time) []ValidateHistoryTransition {
+	var ret []ValidateHistoryTransition
+	for i, t := range transitions {
+		// always keep the last transition so that the current state is not lost
+		if t.Time.Time.Before(before) && i != len(transitions)-1 {
+			continue
+		}
+		ret = append(ret, t)
+	}
+	return ret
+}
+
+// Prune removes all transitions that happened before the given time. The most recent transition is always kept,
+// except for objects that are ready since before the given time, which are removed completely.
+func (h *ValidateHistory) Prune(before time.Time) {
+	h.Transitions = pruneTransitions(h.Transitions, before)
+
+	objects := make([]ValidateHistoryObject, 0, len(h.Objects))
+	for _, o := range h.Objects {
+		o.Transitions = pruneTransitions(o.Transitions, before)
+		lt := lastTransition(o.Transitions)
+		if lt == nil || (lt.Ready && lt.Time.Time.Before(before)) {
+			continue
+		}
+		objects = append(objects, o)
+	}
+	h.Objects = objects
+}
+
+// FindFlappingObjects returns all objects that changed their readiness more than maxTransitions times since the
+// given time, together with the number of transitions.
+func (h *ValidateHistory) FindFlappingObjects(since time.Time, maxTransitions int) map[k8s.ObjectRef]int {
+	ret := map[k8s.ObjectRef]int{}
+	for _, o := range h.Objects {
+		cnt := 0
+		for _, t := range o.Transitions {
+			if !t.Time.Time.Before(since) {
+				cnt++
+			}
+		}
+		if cnt > maxTransitions {
+			ret[o.Ref] = cnt
+		}
+	}
+	return ret
+}
@@ -0,0 +1,73 @@
+package result
+
+import (
+	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+	"time"
+)
+
+func buildTestValidateResult(t time.Time, notReady ...k8s.ObjectRef) *ValidateResult {
+	vr := &ValidateResult{
+		Id:      t.String(),
+		EndTime: metav1.NewTime(t),
+		Ready:   len(notReady) == 0,
+	}
+	for _, ref := range notReady {
+		vr.Errors = append(vr.Errors, DeploymentError{Ref: ref, Message: "not ready"})
+	}
+	return vr
+}
+
+func TestValidateHistoryTransitions(t *testing.T) {
+	ref1 := k8s.ObjectRef{Kind: "Deployment", Name: "d1", Namespace: "ns"}
+	ref2 := k8s.ObjectRef{Kind: "Deployment", Name: "d2", Namespace: "ns"}
+
+	start := time.Now().Add(-time.Hour)
+	vh := NewValidateHistory(ProjectKey{}, TargetKey{})
+	vh.AddValidateResult(buildTestValidateResult(start))
+	vh.AddValidateResult(buildTestValidateResult(start.Add(time.Minute), ref1))
+	vh.AddValidateResult(buildTestValidateResult(start.Add(2*time.Minute), ref1, ref2))
+	vh.AddValidateResult(buildTestValidateResult(start.Add(3*time.Minute), ref2))
+	vh.AddValidateResult(buildTestValidateResult(start.Add(4*time.Minute), ref2))
+
+	assert.Len(t, vh.Transitions, 2)
+	assert.True(t, vh.Transitions[0].Ready)
+	assert.False(t, vh.Transitions[1].Ready)
+
+	assert.Len(t, vh.Objects, 2)
+	assert.Equal(t, ref1, vh.Objects[0].Ref)
+	assert.Len(t, vh.Objects[0].Transitions, 2)
+	assert.False(t, vh.Objects[0].Transitions[0].Ready)
+	assert.True(t, vh.Objects[0].Transitions[1].Ready)
+	assert.Equal(t, ref2, vh.Objects[1].Ref)
+	assert.Len(t, vh.Objects[1].Transitions, 1)
+
+	// ref1 is ready since before the prune time, ref2 is still not ready
+	vh.Prune(start.Add(30 * time.Minute))
+	assert.Len(t, vh.Transitions, 1)
+	assert.Len(t, vh.Objects, 1)
+	assert.Equal(t, ref2, vh.Objects[0].Ref)
+}
+
+func TestValidateHistoryFlapping(t *testing.T) {
+	ref1 := k8s.ObjectRef{Kind: "Deployment", Name: "d1", Namespace: "ns"}
+	ref2 := k8s.ObjectRef{Kind: "Deployment", Name: "d2", Namespace: "ns"}
+
+	start := time.Now().Add(-time.Hour)
+	vh := NewValidateHistory(ProjectKey{}, TargetKey{})
+	for i := 0; i < 6; i++ {
+		if i%2 == 0 {
+			vh.AddValidateResult(buildTestValidateResult(start.Add(time.Duration(i)*time.Minute), ref1, ref2))
+		} else {
+			vh.AddValidateResult(buildTestValidateResult(start.Add(time.Duration(i)*time.Minute), ref2))
+		}
+	}
+
+	flapping := vh.FindFlappingObjects(start, 4)
+	assert.Equal(t, map[k8s.ObjectRef]int{ref1: 6}, flapping)
+
+	flapping = vh.FindFlappingObjects(start.Add(3*time.Minute), 4)
+	assert.Empty(t, flapping)
+}
@@ -0,0 +1,42 @@
+package result
+
+// VarsProvenanceEntry describes a single change of a variable.
+type VarsProvenanceEntry struct {
+	// Origin is the place where the vars source is declared, e.g. 'deployment.yml vars[1]'
+	Origin string `json:"origin"`
+	// Source describes the vars source, e.g. 'file: vars/prod.yaml'
+	Source string `json:"source"`
+	// Action is one of 'set', 'override' or 'ignored'. 'ignored' means that noOverride prevented the change.
+	Action    string `json:"action"`
+	Value     string `json:"value,omitempty"`
+	Sensitive bool   `json:"sensitive,omitempty"`
+}
+
+type VarsProvenanceKey struct {
+	Path    string                `json:"path"`
+	Entries []VarsProvenanceEntry `json:"entries"`
+}
+
+// VarsProvenanceSkipped describes a vars source that was not loaded because its 'when' condition was false.
+type VarsProvenanceSkipped struct {
+	Origin string `json:"origin"`
+	Source string `json:"source"`
+	When   string `json:"when"`
+}
+
+// VarsProvenance records where each variable came from, in the order the changes happened.
+type VarsProvenance struct {
+	Keys    []VarsProvenanceKey     `json:"keys,omitempty"`
+	Skipped []VarsProvenanceSkipped `json:"skipped,omitempty"`
+}
+
+// ToCompacted returns a copy without values, suitable to be stored in command results.
+func (p *VarsProvenance) ToCompacted() *VarsProvenance {
+	ret := p.DeepCopy()
+	for i := range ret.Keys {
+		for j := range ret.Keys[i].Entries {
+			ret.Keys[i].Entries[j].Value = ""
+		}
+	}
+	return ret
+}
@@ -182,6 +182,13 @@ func (in *CommandResult) DeepCopyInto(out *CommandResult) {
 		*out = new(types.DeploymentProjectConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.ProjectArgs != nil {
+		in, out := &in.ProjectArgs, &out.ProjectArgs
+		*out = make([]types.DeploymentArg, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Objects != nil {
 		in, out := &in.Objects, &out.Objects
 		*out = make([]ResultObject, len(*in))
@@ -206,6 +213,15 @@ func (in *CommandResult) DeepCopyInto(out *CommandResult) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.VarsProvenance != nil {
+		in, out := &in.VarsProvenance, &out.VarsProvenance
+		*out = new(VarsProvenance)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Outputs != nil {
+		in, out := &in.Outputs, &out.Outputs
+		*out = (*in).DeepCopy()
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CommandResult.
@@ -442,6 +458,33 @@ func (in *ProjectKey) DeepCopy() *ProjectKey {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProjectOutputs) DeepCopyInto(out *ProjectOutputs) {
+	*out = *in
+	out.ProjectKey = in.ProjectKey
+	out.TargetKey = in.TargetKey
+	if in.KluctlDeployment != nil {
+		in, out := &in.KluctlDeployment, &out.KluctlDeployment
+		*out = new(KluctlDeploymentInfo)
+		**out = **in
+	}
+	in.Time.DeepCopyInto(&out.Time)
+	if in.Outputs != nil {
+		in, out := &in.Outputs, &out.Outputs
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProjectOutputs.
+func (in *ProjectOutputs) DeepCopy() *ProjectOutputs {
+	if in == nil {
+		return nil
+	}
+	out := new(ProjectOutputs)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ResultObject) DeepCopyInto(out *ResultObject) {
 	*out = *in
@@ -485,6 +528,82 @@ func (in *TargetKey) DeepCopy() *TargetKey {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ValidateHistory) DeepCopyInto(out *ValidateHistory) {
+	*out = *in
+	out.ProjectKey = in.ProjectKey
+	out.TargetKey = in.TargetKey
+	if in.KluctlDeployment != nil {
+		in, out := &in.KluctlDeployment, &out.KluctlDeployment
+		*out = new(KluctlDeploymentInfo)
+		**out = **in
+	}
+	in.LastValidateTime.DeepCopyInto(&out.LastValidateTime)
+	if in.Transitions != nil {
+		in, out := &in.Transitions, &out.Transitions
+		*out = make([]ValidateHistoryTransition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Objects != nil {
+		in, out := &in.Objects, &out.Objects
+		*out = make([]ValidateHistoryObject, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ValidateHistory.
+func (in *ValidateHistory) DeepCopy() *ValidateHistory {
+	if in == nil {
+		return nil
+	}
+	out := new(ValidateHistory)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ValidateHistoryObject) DeepCopyInto(out *ValidateHistoryObject) {
+	*out = *in
+	out.Ref = in.Ref
+	if in.Transitions != nil {
+		in, out := &in.Transitions, &out.Transitions
+		*out = make([]ValidateHistoryTransition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ValidateHistoryObject.
+func (in *ValidateHistoryObject) DeepCopy() *ValidateHistoryObject {
+	if in == nil {
+		return nil
+	}
+	out := new(ValidateHistoryObject)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ValidateHistoryTransition) DeepCopyInto(out *ValidateHistoryTransition) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ValidateHistoryTransition.
+func (in *ValidateHistoryTransition) DeepCopy() *ValidateHistoryTransition {
+	if in == nil {
+		return nil
+	}
+	out := new(ValidateHistoryTransition)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ValidateResult) DeepCopyInto(out *ValidateResult) {
 	*out = *in
@@ -567,3 +686,80 @@ func (in *ValidateResultSummary) DeepCopy() *ValidateResultSummary {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VarsProvenance) DeepCopyInto(out *VarsProvenance) {
+	*out = *in
+	if in.Keys != nil {
+		in, out := &in.Keys, &out.Keys
+		*out = make([]VarsProvenanceKey, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Skipped != nil {
+		in, out := &in.Skipped, &out.Skipped
+		*out = make([]VarsProvenanceSkipped, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VarsProvenance.
+func (in *VarsProvenance) DeepCopy() *VarsProvenance {
+	if in == nil {
+		return nil
+	}
+	out := new(VarsProvenance)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VarsProvenanceEntry) DeepCopyInto(out *VarsProvenanceEntry) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VarsProvenanceEntry.
+func (in *VarsProvenanceEntry) DeepCopy() *VarsProvenanceEntry {
+	if in == nil {
+		return nil
+	}
+	out := new(VarsProvenanceEntry)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VarsProvenanceKey) DeepCopyInto(out *VarsProvenanceKey) {
+	*out = *in
+	if in.Entries != nil {
+		in, out := &in.Entries, &out.Entries
+		*out = make([]VarsProvenanceEntry, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VarsProvenanceKey.
+func (in *VarsProvenanceKey) DeepCopy() *VarsProvenanceKey {
+	if in == nil {
+		return nil
+	}
+	out := new(VarsProvenanceKey)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VarsProvenanceSkipped) DeepCopyInto(out *VarsProvenanceSkipped) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VarsProvenanceSkipped.
+func (in *VarsProvenanceSkipped) DeepCopy() *VarsProvenanceSkipped {
+	if in == nil {
+		return nil
+	}
+	out := new(VarsProvenanceSkipped)
+	in.DeepCopyInto(out)
+	return out
+}
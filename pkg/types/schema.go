@@ -0,0 +1,33 @@
+package types
+
+import (
+	"sort"
+
+	"github.com/kluctl/kluctl/v2/pkg/yaml"
+)
+
+// ConfigSchemaTypes maps the names of all Kluctl configuration files to the types they are loaded into.
+var ConfigSchemaTypes = map[string]any{
+	"kluctl-project": KluctlProject{},
+	"deployment":     DeploymentProjectConfig{},
+	"helm-chart":     HelmChartConfig{},
+}
+
+// ConfigSchemaNames returns the sorted names of all entries in ConfigSchemaTypes
+func ConfigSchemaNames() []string {
+	var ret []string
+	for n := range ConfigSchemaTypes {
+		ret = append(ret, n)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// GetConfigSchema returns the JSON schema for the config file with the given name, or nil if the name is unknown.
+func GetConfigSchema(name string) yaml.JSONSchema {
+	t, ok := ConfigSchemaTypes[name]
+	if !ok {
+		return nil
+	}
+	return yaml.GenerateSchema(t)
+}
@@ -0,0 +1,252 @@
+package types
+
+import "github.com/kluctl/kluctl/v2/pkg/yaml"
+
+// The descriptions registered here end up in the generated JSON schemas and are shown by editors and `kluctl lsp`.
+// They are short summaries of the corresponding sections in docs/kluctl.
+
+func init() {
+	yaml.RegisterSchemaDescriptions(map[string]string{
+		"targets":       "List of targets. Each target describes a deployment environment, e.g. prod or test.",
+		"args":          "Declares the arguments that the project accepts, including defaults, descriptions and schemas.",
+		"secretsConfig": "Configures how secrets are sealed and which secret sets are available.",
+		"discriminator": "Template for the discriminator that is used to identify objects of this deployment for pruning and deletion.",
+		"aws":           "Default AWS configuration used by AWS related vars sources.",
+		"templating":    "Configures project specific Jinja2 extensions, Python paths and template search dirs.",
+		"kustomize":     "Configures how remote kustomize resources and components are fetched.",
+		"krmFunctions":  "Configures which KRM functions may be run.",
+	}, KluctlProject{})
+
+	yaml.RegisterSchemaDescriptions(map[string]string{
+		"execAllowList": "Executables that exec based KRM functions may run. Supports shell patterns. Exec functions must additionally be allowed via --allow-krm-exec.",
+	}, KrmFunctionsConfig{})
+
+	yaml.RegisterSchemaDescriptions(map[string]string{
+		"remoteHosts": "Hosts that remote kustomize resources, components and manifest urls may be fetched from. Supports shell patterns, e.g. *.example.com. All hosts are allowed if omitted, except inside the controller, which requires an explicit list.",
+	}, KustomizeConfig{})
+
+	yaml.RegisterSchemaDescriptions(map[string]string{
+		"extensions": "Jinja2 extension classes or Python modules to load, e.g. my_filters.MyExtension.",
+		"pythonPath": "Directories added to the Python path, so that extensions can be imported from them.",
+		"searchDirs": "Directories searched for includes and imports, e.g. macro libraries.",
+	}, TemplatingConfig{})
+
+	yaml.RegisterSchemaDescriptions(map[string]string{
+		"path": "Directory relative to the project directory. Must be inside the project's repository.",
+		"git":  "Directory inside a git repository.",
+		"oci":  "Directory inside an OCI artifact.",
+	}, TemplatingLibrary{})
+
+	yaml.RegisterSchemaDescriptions(map[string]string{
+		"name":          "Name of the target. Used via --target and available as target.name in templates.",
+		"context":       "Kubernetes context to use for this target. Defaults to the current context.",
+		"args":          "Arguments passed to the deployment. They are available as args.* in templates.",
+		"sealingConfig": "Configures sealing of secrets for this target.",
+		"aws":           "AWS configuration used by AWS related vars sources for this target.",
+		"images":        "Fixed images that override the results of images.get_image().",
+		"discriminator": "Overrides the project level discriminator for this target.",
+	}, Target{})
+
+	yaml.RegisterSchemaDescriptions(map[string]string{
+		"name":        "Name of the argument. Dots can be used to declare nested arguments.",
+		"default":     "Default value used when the argument is not passed.",
+		"description": "Human readable description shown by `kluctl list-args`.",
+		"schema":      "JSON schema that passed values must validate against.",
+	}, DeploymentArg{})
+
+	yaml.RegisterSchemaDescriptions(map[string]string{
+		"sealedSecrets": "Global configuration for sealed secrets.",
+		"secretSets":    "Named sets of vars sources that are used while sealing secrets.",
+	}, SecretsConfig{})
+
+	yaml.RegisterSchemaDescriptions(map[string]string{
+		"vars":               "Vars sources that are loaded before this deployment project is rendered.",
+		"sealedSecrets":      "Configures where sealed secrets are written to.",
+		"when":               "Jinja2 expression. The deployment project is only processed when it evaluates to true.",
+		"deployments":        "List of deployment items. Each item is a kustomize deployment, an include or a git/oci include.",
+		"commonLabels":       "Labels added to all objects of this and all included deployment projects.",
+		"commonAnnotations":  "Annotations added to all objects of this and all included deployment projects.",
+		"overrideNamespace":  "Namespace to use for all namespaced objects that don't specify one.",
+		"tags":               "Tags added to all deployment items of this deployment project.",
+		"ignoreForDiff":      "Fields that are ignored when showing diffs.",
+		"conflictResolution": "Rules to resolve field ownership conflicts on apply.",
+		"transformations":    "Transformations applied to all rendered objects of this and all included deployment projects.",
+		"outputs":            "Values that are stored in the result store after a successful deployment. Other projects can read them via the kluctlOutputs vars source.",
+	}, DeploymentProjectConfig{})
+
+	yaml.RegisterSchemaDescriptions(map[string]string{
+		"name":      "Name of the transformation, used in errors and in the template trace.",
+		"target":    "Selects the objects the transformation is applied to. If omitted, all objects are selected.",
+		"patch":     "Strategic merge patch applied to the selected objects.",
+		"jsonPatch": "JSON6902 patch applied to the selected objects.",
+		"set":       "Fields that are set on the selected objects.",
+		"remove":    "JSONPaths of fields that are removed from the selected objects.",
+	}, TransformationConfig{})
+
+	yaml.RegisterSchemaDescriptions(map[string]string{
+		"group":         "Matches the API group of objects.",
+		"version":       "Matches the API version of objects.",
+		"kind":          "Matches the kind of objects.",
+		"name":          "Matches the name of objects.",
+		"namespace":     "Matches the namespace of objects.",
+		"labelSelector": "Label selector in the same format as used by `kubectl --selector`.",
+	}, TransformationTarget{})
+
+	yaml.RegisterSchemaDescriptions(map[string]string{
+		"fieldPath":   "JSONPath of the fields to set, e.g. `spec.template.spec.containers[*].imagePullPolicy`.",
+		"value":       "Value to set.",
+		"regex":       "Regular expression that is replaced inside the matching string fields.",
+		"replacement": "Replacement for regex matches. Can refer to capture groups via `$1`.",
+	}, TransformationSetter{})
+
+	yaml.RegisterSchemaDescriptions(map[string]string{
+		"op":    "The JSON6902 operation.",
+		"path":  "JSON pointer to the field the operation is applied to, e.g. `/spec/replicas`.",
+		"from":  "JSON pointer to the source field of move and copy operations.",
+		"value": "Value used by add, replace and test operations.",
+	}, JsonPatchOperation{})
+
+	yaml.RegisterSchemaDescriptions(map[string]string{
+		"name":   "Name of the output.",
+		"value":  "Static value of the output, usually computed via templating.",
+		"object": "Reads the value from an object in the target cluster after deployment.",
+	}, DeploymentOutput{})
+
+	yaml.RegisterSchemaDescriptions(map[string]string{
+		"jsonPath": "JSONPath of the field inside the object, e.g. `status.loadBalancer.ingress[0].ip`.",
+	}, DeploymentOutputObject{})
+
+	yaml.RegisterSchemaDescriptions(map[string]string{
+		"path":                 "Relative path to a kustomize deployment (directory with a kustomization.yaml).",
+		"include":              "Relative path to a directory with a deployment.yml that is included.",
+		"git":                  "Includes a deployment project from a git repository.",
+		"oci":                  "Includes a deployment project from an OCI repository.",
+		"jsonnet":              "Renders the deployment item by evaluating a Jsonnet file.",
+		"cue":                  "Renders the deployment item by evaluating a CUE package.",
+		"manifest":             "Deploys a manifest that is downloaded from an url or pulled from an OCI repository.",
+		"deleteObjects":        "Objects that are deleted when this item is processed.",
+		"functions":            "KRM functions that are run on the objects of this item.",
+		"tags":                 "Tags used by --include-tag and --exclude-tag.",
+		"barrier":              "Wait for all previous deployment items to finish before proceeding.",
+		"message":              "Message printed when the barrier is reached.",
+		"waitReadiness":        "Wait for all objects of this item to become ready before proceeding.",
+		"waitReadinessObjects": "Objects to wait for before proceeding.",
+		"args":                 "Arguments passed to the included deployment project.",
+		"passVars":             "Pass all vars of the current deployment project to the included project.",
+		"vars":                 "Vars sources that are only loaded for this deployment item.",
+		"skipDeleteIfTags":     "Skip deletion of this item when tags are specified on the command line.",
+		"onlyRender":           "Only render this item, without deploying it. Useful for kustomize components.",
+		"alwaysDeploy":         "Deploy this item even when it is excluded via inclusion/exclusion tags.",
+		"when":                 "Jinja2 expression. The item is only processed when it evaluates to true.",
+	}, DeploymentItemConfig{})
+
+	yaml.RegisterSchemaDescriptions(map[string]string{
+		"main": "Path to the Jsonnet file to evaluate, relative to the deployment project.",
+		"libs": "Additional library search paths for imports, relative to the deployment project.",
+		"tlas": "Top-level arguments passed to the function returned by the main file.",
+	}, JsonnetItemConfig{})
+
+	yaml.RegisterSchemaDescriptions(map[string]string{
+		"dir":         "Directory of the CUE package to evaluate, relative to the deployment project.",
+		"package":     "Name of the package to load, in case the directory contains multiple packages.",
+		"objectsPath": "Path of the value containing the objects to export. Defaults to `objects`.",
+		"varsPath":    "Path at which Kluctl vars are injected. Defaults to `vars`.",
+	}, CueItemConfig{})
+
+	yaml.RegisterSchemaDescriptions(map[string]string{
+		"url":               "HTTP(S) url of a multi-document YAML file, e.g. an install.yaml from a release page.",
+		"sha256":            "Hex encoded SHA256 checksum of the file behind url. Required for url.",
+		"oci":               "OCI artifact containing the manifests. All .yaml and .yml files in its root (or in subDir) are loaded.",
+		"skipTemplating":    "Don't render the manifests with Jinja2.",
+		"updateConstraints": "Version constraints used by manifest-update, e.g. `~1.2.0`.",
+		"updateGitUrl":      "Git repository whose tags are used by manifest-update. Defaults to the repository of GitHub release urls.",
+		"skipUpdate":        "Exclude this manifest from manifest-update.",
+	}, ManifestItemConfig{})
+
+	yaml.RegisterSchemaDescriptions(map[string]string{
+		"exec":      "Runs an executable as KRM function. Must be allowed by krmFunctions.execAllowList and --allow-krm-exec.",
+		"starlark":  "Runs a Starlark script as KRM function.",
+		"config":    "Passed to the function as functionConfig.",
+		"generator": "Run as generator. Generators don't receive the item's objects and their output is appended.",
+	}, KrmFunctionConfig{})
+
+	yaml.RegisterSchemaDescriptions(map[string]string{
+		"path": "Executable to run. Paths containing a slash are relative to the deployment project, others are looked up in PATH.",
+		"args": "Arguments passed to the executable.",
+	}, KrmExecFunction{})
+
+	yaml.RegisterSchemaDescriptions(map[string]string{
+		"path": "Starlark script to run, relative to the deployment project.",
+	}, KrmStarlarkFunction{})
+
+	yaml.RegisterSchemaDescriptions(map[string]string{
+		"ignoreMissing":     "Don't fail when the vars source can not be found.",
+		"noOverride":        "Don't override vars that are already set.",
+		"sensitive":         "Mark the loaded vars as sensitive, masking them in output.",
+		"values":            "Inline values.",
+		"file":              "Loads vars from a YAML file, relative to the current deployment project.",
+		"git":               "Loads vars from a YAML file inside a git repository.",
+		"gitFiles":          "Loads multiple files from a git repository.",
+		"oci":               "Loads vars from a YAML file inside an OCI artifact.",
+		"clusterConfigMap":  "Loads vars from a ConfigMap in the target cluster.",
+		"clusterSecret":     "Loads vars from a Secret in the target cluster.",
+		"clusterObject":     "Loads vars from arbitrary objects in the target cluster.",
+		"systemEnvVars":     "Loads vars from environment variables.",
+		"http":              "Loads vars from an HTTP(s) endpoint.",
+		"command":           "Loads vars from the output of a command. Requires --allow-exec-vars.",
+		"awsSecretsManager": "Loads vars from AWS Secrets Manager.",
+		"awsParameterStore": "Loads vars from AWS SSM Parameter Store.",
+		"awsAppConfig":      "Loads vars from an AWS AppConfig configuration profile.",
+		"gcpSecretManager":  "Loads vars from GCP Secret Manager.",
+		"vault":             "Loads vars from HashiCorp Vault.",
+		"azureKeyVault":     "Loads vars from Azure Key Vault.",
+		"kluctlOutputs":     "Loads the outputs of another kluctl deployment project from the result store.",
+		"generatedSecrets":  "Loads random values that are generated once per target and persisted in the target cluster.",
+		"targetPath":        "Path under which the loaded vars are stored.",
+		"when":              "Jinja2 expression. The vars source is only loaded when it evaluates to true.",
+		"format":            "Format of the loaded file, one of yaml, json, toml, ini, dotenv or hcl. Detected from the file extension if omitted.",
+		"cacheTTL":          "Enables caching for remote vars sources. Cached values younger than the TTL are used instead of reloading them.",
+	}, VarsSource{})
+
+	yaml.RegisterSchemaDescriptions(map[string]string{
+		"name":           "Name or ARN of a single parameter.",
+		"path":           "Path of a parameter hierarchy, loaded into a nested dictionary.",
+		"recursive":      "Also load parameters from nested hierarchy levels. Defaults to true.",
+		"withDecryption": "Decrypt SecureString parameters. Defaults to true.",
+		"region":         "The AWS region.",
+		"profile":        "AWS credentials profile to use.",
+	}, VarsSourceAwsParameterStore{})
+
+	yaml.RegisterSchemaDescriptions(map[string]string{
+		"application":          "Name or ID of the AppConfig application.",
+		"environment":          "Name or ID of the AppConfig environment.",
+		"configurationProfile": "Name or ID of the configuration profile.",
+		"region":               "The AWS region.",
+		"profile":              "AWS credentials profile to use.",
+	}, VarsSourceAwsAppConfig{})
+
+	yaml.RegisterSchemaDescriptions(map[string]string{
+		"name":    "Name of the generated secret. It identifies the value inside the target.",
+		"length":  "Length of the generated value, defaults to 32.",
+		"charset": "Characters used to generate the value, defaults to alphanumeric characters.",
+	}, GeneratedSecret{})
+
+	yaml.RegisterSchemaDescriptions(map[string]string{
+		"repo":              "URL of the Helm repository or OCI registry.",
+		"path":              "Local path to the Helm chart.",
+		"credentialsId":     "ID of the credentials passed via --helm-* arguments.",
+		"chartName":         "Name of the chart inside the repository.",
+		"chartVersion":      "Version of the chart.",
+		"updateConstraints": "Semver constraints used by `kluctl helm-update`.",
+		"releaseName":       "Name of the Helm release.",
+		"namespace":         "Namespace the chart is rendered for.",
+		"output":            "Name of the file the rendered chart is written to.",
+		"skipCRDs":          "Don't render the CRDs of the chart.",
+		"skipUpdate":        "Exclude this chart from `kluctl helm-update`.",
+		"skipPrePull":       "Don't pre-pull the chart.",
+	}, HelmChartConfig2{})
+
+	yaml.RegisterSchemaDescriptions(map[string]string{
+		"outputPattern": "Pattern for the path sealed secrets are written to.",
+	}, SealedSecretsConfig{})
+}
@@ -0,0 +1,76 @@
+package types
+
+import (
+	"github.com/kluctl/kluctl/v2/pkg/yaml"
+	"github.com/stretchr/testify/assert"
+	"github.com/xeipuuv/gojsonschema"
+	"testing"
+)
+
+func TestConfigSchemasCompile(t *testing.T) {
+	for _, n := range ConfigSchemaNames() {
+		_, err := gojsonschema.NewSchema(gojsonschema.NewGoLoader(GetConfigSchema(n)))
+		assert.NoError(t, err, n)
+	}
+}
+
+func TestConfigSchemaErrors(t *testing.T) {
+	type testCase struct {
+		name     string
+		o        any
+		yaml     string
+		expected []string
+	}
+	tests := []testCase{
+		{name: "helm-repo-and-path", o: &HelmChartConfig{}, yaml: `helmChart:
+  repo: https://charts.example.com
+  path: ./chart
+  releaseName: x
+`, expected: []string{
+			"x.yaml:2:3: helmChart: exactly one of repo, path must be set",
+			"x.yaml:2:3: helmChart: chartVersion must be specified when repo is specified",
+			"x.yaml:2:3: helmChart: chartName must be specified when repo is normal Helm repo",
+		}},
+		{name: "helm-missing-version", o: &HelmChartConfig{}, yaml: `helmChart:
+  repo: https://charts.example.com
+  chartName: x
+  releaseName: x
+`, expected: []string{"x.yaml:2:3: helmChart: chartVersion must be specified when repo is specified"}},
+		{name: "helm-oci-chart-name", o: &HelmChartConfig{}, yaml: `helmChart:
+  repo: oci://example.com/chart
+  chartName: x
+  chartVersion: 1.0.0
+  releaseName: x
+`, expected: []string{"x.yaml:2:3: helmChart: chartName can not be specified when repo is a OCI url"}},
+		{name: "deployment-item", o: &DeploymentProjectConfig{}, yaml: `deployments:
+- path: a
+  git: https://example.com/x.git
+- path: b
+  args:
+    a: b
+`, expected: []string{
+			"x.yaml:2:3: deployments[0]: only one of path, include, git, oci can be set at the same time",
+			"x.yaml:4:3: deployments[1]: args is only allowed when another project is included (via include, git or oci)",
+		}},
+		{name: "git-project-string", o: &DeploymentProjectConfig{}, yaml: `deployments:
+- git: https://example.com/x.git
+  unknown: x
+`, expected: []string{"x.yaml:3:3: deployments[0].unknown: unknown field \"unknown\""}},
+		{name: "target-images", o: &KluctlProject{}, yaml: `targets:
+- name: t1
+  images:
+  - resultImage: x
+`, expected: []string{"x.yaml:4:5: targets[0].images[0]: exactly one of image, imageRegex must be set"}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			errs := yaml.ValidateYamlWithSchema("x.yaml", []byte(tc.yaml), tc.o)
+			var l []string
+			for _, e := range errs {
+				l = append(l, e.Error())
+			}
+			assert.Equal(t, tc.expected, l)
+		})
+	}
+}
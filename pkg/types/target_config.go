@@ -34,4 +34,9 @@ func ValidateFixedImage(sl validator.StructLevel) {
 
 func init() {
 	yaml.Validator.RegisterStructValidation(ValidateFixedImage, FixedImage{})
+
+	yaml.RegisterSchemaExtension(func(s yaml.JSONSchema) yaml.JSONSchema {
+		yaml.SchemaRequireOneOf(s, "image", "imageRegex")
+		return s
+	}, FixedImage{})
 }
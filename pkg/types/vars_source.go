@@ -1,11 +1,17 @@
 package types
 
 import (
+	"bytes"
+	"encoding/json"
+	"fmt"
 	"github.com/go-playground/validator/v10"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
 	"github.com/kluctl/kluctl/v2/pkg/yaml"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 	"reflect"
+	"sort"
+	"strings"
 )
 
 type VarsSourceGit struct {
@@ -21,6 +27,12 @@ type VarsSourceGitFiles struct {
 	Files []GitFile `json:"files,omitempty"`
 }
 
+type VarsSourceOci struct {
+	Url  string  `json:"url" validate:"required"`
+	Ref  *OciRef `json:"ref,omitempty"`
+	Path string  `json:"path" validate:"required"`
+}
+
 type GitFile struct {
 	Glob         string `json:"glob" validate:"required"`
 	Render       bool   `json:"render,omitempty"`
@@ -96,6 +108,18 @@ type VarsSourceHttp struct {
 	JsonPath *string           `json:"jsonPath,omitempty"`
 }
 
+type VarsSourceCommand struct {
+	// Command is the argv of the command to execute. No shell is involved
+	Command []string          `json:"command" validate:"required,min=1"`
+	Env     map[string]string `json:"env,omitempty"`
+	// WorkDir is the working directory of the command. Relative paths are resolved relative to the current
+	// deployment project directory
+	WorkDir string           `json:"workDir,omitempty"`
+	Timeout *metav1.Duration `json:"timeout,omitempty"`
+	// Format specifies how to interpret the output of the command, defaults to yaml
+	Format string `json:"format,omitempty" validate:"omitempty,oneof=yaml json dotenv"`
+}
+
 type VarsSourceAwsSecretsManager struct {
 	// Name or ARN of the secret. In case a name is given, the region must be specified as well
 	SecretName string `json:"secretName" validate:"required"`
@@ -105,6 +129,44 @@ type VarsSourceAwsSecretsManager struct {
 	Profile *string `json:"profile,omitempty"`
 }
 
+type VarsSourceAwsParameterStore struct {
+	// Name or ARN of a single parameter. The value of the parameter must be a YAML dictionary, unless targetPath is used
+	Name *string `json:"name,omitempty"`
+	// Path of a parameter hierarchy. All parameters below the path are loaded into a nested dictionary
+	Path *string `json:"path,omitempty"`
+	// Also load parameters from nested hierarchy levels. Only applies to path and defaults to true
+	Recursive *bool `json:"recursive,omitempty"`
+	// Decrypt SecureString parameters. Defaults to true
+	WithDecryption *bool `json:"withDecryption,omitempty"`
+	// The aws region
+	Region *string `json:"region,omitempty"`
+	// AWS credentials profile to use. The AWS_PROFILE environemnt variables will take precedence in case it is also set
+	Profile *string `json:"profile,omitempty"`
+}
+
+func ValidateVarsSourceAwsParameterStore(sl validator.StructLevel) {
+	s := sl.Current().Interface().(VarsSourceAwsParameterStore)
+	if (s.Name == nil) == (s.Path == nil) {
+		sl.ReportError(s, "self", "self", "exactly one of name or path must be set", "")
+	}
+	if s.Recursive != nil && s.Path == nil {
+		sl.ReportError(s, "self", "self", "recursive can only be used together with path", "")
+	}
+}
+
+type VarsSourceAwsAppConfig struct {
+	// Name or ID of the AppConfig application
+	Application string `json:"application" validate:"required"`
+	// Name or ID of the AppConfig environment
+	Environment string `json:"environment" validate:"required"`
+	// Name or ID of the configuration profile. The configuration must be a YAML or JSON dictionary
+	ConfigurationProfile string `json:"configurationProfile" validate:"required"`
+	// The aws region
+	Region *string `json:"region,omitempty"`
+	// AWS credentials profile to use. The AWS_PROFILE environemnt variables will take precedence in case it is also set
+	Profile *string `json:"profile,omitempty"`
+}
+
 type VarSourceAzureKeyVault struct {
 	// Name or ARN of the secret. In case a name is given, the region must be specified as well
 	VaultUri string `json:"vaultUri" validate:"required"`
@@ -120,6 +182,135 @@ type VarsSourceGcpSecretManager struct {
 type VarsSourceVault struct {
 	Address string `json:"address" validate:"required"`
 	Path    string `json:"path" validate:"required"`
+
+	// Namespace is the Vault Enterprise namespace to use
+	Namespace string `json:"namespace,omitempty"`
+
+	// Kv enables explicit handling of KV secrets engines, in which case Path is relative to the mount
+	Kv *VarsSourceVaultKv `json:"kv,omitempty"`
+
+	// Auth configures how to authenticate against Vault. If omitted, the token from VAULT_TOKEN is used
+	Auth *VarsSourceVaultAuth `json:"auth,omitempty"`
+
+	// IncludeMetadata moves the secret data into 'data' and makes KV v2 metadata and lease information of dynamic
+	// secrets available via 'metadata' and 'lease'
+	IncludeMetadata bool `json:"includeMetadata,omitempty"`
+}
+
+type VarsSourceVaultKv struct {
+	Mount string `json:"mount" validate:"required"`
+	// Version of the KV secrets engine, either 1 or 2. Defaults to 2
+	Version int `json:"version,omitempty" validate:"omitempty,oneof=1 2"`
+	// SecretVersion selects a specific version of a KV v2 secret
+	SecretVersion *int `json:"secretVersion,omitempty"`
+}
+
+type VarsSourceVaultAuth struct {
+	// TokenFile is a file containing the Vault token. Reading files requires --allow-vault-token-files
+	TokenFile  *string                        `json:"tokenFile,omitempty"`
+	Kubernetes *VarsSourceVaultAuthKubernetes `json:"kubernetes,omitempty"`
+	AppRole    *VarsSourceVaultAuthAppRole    `json:"appRole,omitempty"`
+	Jwt        *VarsSourceVaultAuthJwt        `json:"jwt,omitempty"`
+}
+
+type VarsSourceVaultAuthKubernetes struct {
+	Role string `json:"role" validate:"required"`
+	// MountPath of the auth method, defaults to 'kubernetes'
+	MountPath string `json:"mountPath,omitempty"`
+	// ServiceAccount requests a token for the given ServiceAccount in the target cluster. Exactly one of
+	// ServiceAccount or TokenFile must be set
+	ServiceAccount *VarsSourceVaultServiceAccount `json:"serviceAccount,omitempty"`
+	TokenFile      string                         `json:"tokenFile,omitempty"`
+}
+
+type VarsSourceVaultServiceAccount struct {
+	Name      string   `json:"name" validate:"required"`
+	Namespace string   `json:"namespace" validate:"required"`
+	Audiences []string `json:"audiences,omitempty"`
+}
+
+type VarsSourceVaultAuthAppRole struct {
+	RoleId string `json:"roleId" validate:"required"`
+	// MountPath of the auth method, defaults to 'approle'
+	MountPath    string  `json:"mountPath,omitempty"`
+	SecretId     *string `json:"secretId,omitempty"`
+	SecretIdFile *string `json:"secretIdFile,omitempty"`
+}
+
+type VarsSourceVaultAuthJwt struct {
+	Role string `json:"role" validate:"required"`
+	// MountPath of the auth method, defaults to 'jwt'
+	MountPath string  `json:"mountPath,omitempty"`
+	Token     *string `json:"token,omitempty"`
+	TokenFile *string `json:"tokenFile,omitempty"`
+}
+
+type VarsSourceKluctlOutputs struct {
+	// RepoKey of the project that has written the outputs, e.g. 'git.example.com/org/repo'
+	RepoKey RepoKey `json:"repoKey" validate:"required"`
+	// SubDir of the project inside the repository
+	SubDir string `json:"subDir,omitempty"`
+	// Target and Discriminator select the target the outputs were written by. If omitted, any target matches
+	Target        *string `json:"target,omitempty"`
+	Discriminator *string `json:"discriminator,omitempty"`
+	// MaxAge causes an error if the outputs are older than the given duration
+	MaxAge *metav1.Duration `json:"maxAge,omitempty"`
+	// Namespace of the result store, defaults to 'kluctl-results'
+	Namespace string `json:"namespace,omitempty"`
+}
+
+type VarsSourceGeneratedSecrets struct {
+	// Secrets is the list of generated secrets to load. Each secret is stored under its name
+	Secrets []GeneratedSecret `json:"secrets" validate:"required,dive"`
+}
+
+type GeneratedSecret struct {
+	Name string `json:"name" validate:"required"`
+	// Length defaults to 32
+	Length int `json:"length,omitempty"`
+	// Charset defaults to alphanumeric characters
+	Charset string `json:"charset,omitempty"`
+}
+
+func ValidateVarsSourceVault(sl validator.StructLevel) {
+	s := sl.Current().Interface().(VarsSourceVault)
+
+	if s.Kv != nil && s.Kv.SecretVersion != nil && s.Kv.Version == 1 {
+		sl.ReportError(s, "self", "self", "secretVersion is only supported for KV version 2", "")
+	}
+}
+
+func ValidateVarsSourceVaultAuth(sl validator.StructLevel) {
+	s := sl.Current().Interface().(VarsSourceVaultAuth)
+
+	count := 0
+	if s.TokenFile != nil {
+		count++
+	}
+	if s.Kubernetes != nil {
+		count++
+		if (s.Kubernetes.ServiceAccount == nil) == (s.Kubernetes.TokenFile == "") {
+			sl.ReportError(s, "self", "self", "exactly one of serviceAccount or tokenFile must be set", "")
+		}
+	}
+	if s.AppRole != nil {
+		count++
+		if (s.AppRole.SecretId == nil) == (s.AppRole.SecretIdFile == nil) {
+			sl.ReportError(s, "self", "self", "exactly one of secretId or secretIdFile must be set", "")
+		}
+	}
+	if s.Jwt != nil {
+		count++
+		if (s.Jwt.Token == nil) == (s.Jwt.TokenFile == nil) {
+			sl.ReportError(s, "self", "self", "exactly one of token or tokenFile must be set", "")
+		}
+	}
+
+	if count == 0 {
+		sl.ReportError(s, "self", "self", "unknown vault auth method", "")
+	} else if count != 1 {
+		sl.ReportError(s, "self", "self", "more then one vault auth method", "")
+	}
 }
 
 type VarsSource struct {
@@ -131,46 +322,181 @@ type VarsSource struct {
 	File              *string                             `json:"file,omitempty" isVarsSource:"true"`
 	Git               *VarsSourceGit                      `json:"git,omitempty" isVarsSource:"true"`
 	GitFiles          *VarsSourceGitFiles                 `json:"gitFiles,omitempty" isVarsSource:"true"`
+	Oci               *VarsSourceOci                      `json:"oci,omitempty" isVarsSource:"true"`
 	ClusterConfigMap  *VarsSourceClusterConfigMapOrSecret `json:"clusterConfigMap,omitempty" isVarsSource:"true"`
 	ClusterSecret     *VarsSourceClusterConfigMapOrSecret `json:"clusterSecret,omitempty" isVarsSource:"true"`
 	ClusterObject     *VarsSourceClusterObject            `json:"clusterObject,omitempty" isVarsSource:"true"`
 	SystemEnvVars     *uo.UnstructuredObject              `json:"systemEnvVars,omitempty" isVarsSource:"true"`
 	Http              *VarsSourceHttp                     `json:"http,omitempty" isVarsSource:"true" isVarsSource:"true"`
+	Command           *VarsSourceCommand                  `json:"command,omitempty" isVarsSource:"true"`
 	AwsSecretsManager *VarsSourceAwsSecretsManager        `json:"awsSecretsManager,omitempty" isVarsSource:"true"`
+	AwsParameterStore *VarsSourceAwsParameterStore        `json:"awsParameterStore,omitempty" isVarsSource:"true"`
+	AwsAppConfig      *VarsSourceAwsAppConfig             `json:"awsAppConfig,omitempty" isVarsSource:"true"`
 	GcpSecretManager  *VarsSourceGcpSecretManager         `json:"gcpSecretManager,omitempty" isVarsSource:"true"`
 	Vault             *VarsSourceVault                    `json:"vault,omitempty" isVarsSource:"true"`
 	AzureKeyVault     *VarSourceAzureKeyVault             `json:"azureKeyVault,omitempty" isVarsSource:"true"`
+	KluctlOutputs     *VarsSourceKluctlOutputs            `json:"kluctlOutputs,omitempty" isVarsSource:"true"`
+	GeneratedSecrets  *VarsSourceGeneratedSecrets         `json:"generatedSecrets,omitempty" isVarsSource:"true"`
+
+	// Plugins holds the configuration of vars sources which are not built into Kluctl, keyed by their YAML key. These
+	// are handled by VarsSourceProvider plugins
+	Plugins map[string]*uo.UnstructuredObject `json:"-"`
 
 	TargetPath string `json:"targetPath,omitempty"`
 
+	// Format overrides the file format detection of file based vars sources (file, git and oci)
+	Format string `json:"format,omitempty" validate:"omitempty,oneof=yaml json toml ini dotenv hcl"`
+
 	When string `json:"when,omitempty"`
 
+	// CacheTTL enables caching of remote vars sources. Cached values younger than CacheTTL are used instead of
+	// reloading them. Older values are only used when reloading fails.
+	CacheTTL *metav1.Duration `json:"cacheTTL,omitempty"`
+
 	// these are only allowed when writing the command result
 	RenderedSensitive bool                   `json:"renderedSensitive,omitempty"`
 	RenderedVars      *uo.UnstructuredObject `json:"renderedVars,omitempty"`
 }
 
-func ValidateVarsSource(sl validator.StructLevel) {
-	s := sl.Current().Interface().(VarsSource)
+// varsSourceNoMethods is used to avoid recursion in VarsSource.MarshalJSON/UnmarshalJSON
+type varsSourceNoMethods VarsSource
 
-	count := 0
-	v := reflect.ValueOf(s)
+var varsSourceJsonFields = map[string]bool{}
+
+func (s *VarsSource) UnmarshalJSON(b []byte) error {
+	var m map[string]json.RawMessage
+	err := json.Unmarshal(b, &m)
+	if err != nil {
+		return err
+	}
+
+	known := map[string]json.RawMessage{}
+	var plugins map[string]*uo.UnstructuredObject
+	for k, v := range m {
+		if varsSourceJsonFields[k] {
+			known[k] = v
+			continue
+		}
+		o := uo.New()
+		err = json.Unmarshal(v, o)
+		if err != nil {
+			// not a dict, so it can't be a plugin vars source
+			return fmt.Errorf("json: unknown field \"%s\"", k)
+		}
+		if plugins == nil {
+			plugins = map[string]*uo.UnstructuredObject{}
+		}
+		plugins[k] = o
+	}
+
+	b, err = json.Marshal(known)
+	if err != nil {
+		return err
+	}
+	d := json.NewDecoder(bytes.NewReader(b))
+	d.DisallowUnknownFields()
+
+	var x varsSourceNoMethods
+	err = d.Decode(&x)
+	if err != nil {
+		return err
+	}
+	*s = VarsSource(x)
+	s.Plugins = plugins
+	return nil
+}
+
+func (s VarsSource) MarshalJSON() ([]byte, error) {
+	b, err := json.Marshal(varsSourceNoMethods(s))
+	if err != nil || len(s.Plugins) == 0 {
+		return b, err
+	}
+
+	var m map[string]any
+	err = json.Unmarshal(b, &m)
+	if err != nil {
+		return nil, err
+	}
+	for k, v := range s.Plugins {
+		m[k] = v
+	}
+	return json.Marshal(m)
+}
+
+// GetSourceKeys returns the YAML keys of all vars source types that are set. A valid VarsSource has exactly one.
+func (s *VarsSource) GetSourceKeys() []string {
+	var ret []string
+	v := reflect.ValueOf(*s)
 	for i := 0; i < v.NumField(); i++ {
 		f := v.Type().Field(i)
 		if f.Tag.Get("isVarsSource") == "true" && !v.Field(i).IsNil() {
-			count += 1
+			ret = append(ret, strings.Split(f.Tag.Get("json"), ",")[0])
 		}
 	}
+	for k := range s.Plugins {
+		ret = append(ret, k)
+	}
+	sort.Strings(ret)
+	return ret
+}
 
-	if count == 0 {
+func ValidateVarsSource(sl validator.StructLevel) {
+	s := sl.Current().Interface().(VarsSource)
+
+	keys := s.GetSourceKeys()
+	if len(keys) == 0 {
 		sl.ReportError(s, "self", "self", "unknown vars source type", "")
-	} else if count != 1 {
-		sl.ReportError(s, "self", "self", "more then one vars source type", "")
+	} else if len(keys) != 1 {
+		if len(s.Plugins) != 0 {
+			sl.ReportError(s, "self", "self", fmt.Sprintf("more then one vars source type (%s), note that unknown fields are treated as plugin vars sources", strings.Join(keys, ", ")), "")
+		} else {
+			sl.ReportError(s, "self", "self", "more then one vars source type", "")
+		}
 	}
 }
 
 func init() {
+	t := reflect.TypeOf(VarsSource{})
+	for i := 0; i < t.NumField(); i++ {
+		n := strings.Split(t.Field(i).Tag.Get("json"), ",")[0]
+		if n != "" && n != "-" {
+			varsSourceJsonFields[n] = true
+		}
+	}
+
 	yaml.Validator.RegisterStructValidation(ValidateVarsSourceClusterConfigMapOrSecret, VarsSourceClusterConfigMapOrSecret{})
 	yaml.Validator.RegisterStructValidation(ValidateVarsSourceClusterObject, VarsSourceClusterObject{})
 	yaml.Validator.RegisterStructValidation(ValidateVarsSource, VarsSource{})
+	yaml.Validator.RegisterStructValidation(ValidateVarsSourceVault, VarsSourceVault{})
+	yaml.Validator.RegisterStructValidation(ValidateVarsSourceVaultAuth, VarsSourceVaultAuth{})
+	yaml.Validator.RegisterStructValidation(ValidateVarsSourceAwsParameterStore, VarsSourceAwsParameterStore{})
+
+	nameOrLabels := func(s yaml.JSONSchema) yaml.JSONSchema {
+		yaml.SchemaRequireOneOf(s, "name", "labels")
+		return s
+	}
+	yaml.RegisterSchemaExtension(nameOrLabels, VarsSourceClusterConfigMapOrSecret{})
+	yaml.RegisterSchemaExtension(nameOrLabels, VarsSourceClusterObject{})
+	yaml.RegisterSchemaExtension(func(s yaml.JSONSchema) yaml.JSONSchema {
+		yaml.SchemaRequireOneOf(s, "tokenFile", "kubernetes", "appRole", "jwt")
+		return s
+	}, VarsSourceVaultAuth{})
+	yaml.RegisterSchemaExtension(func(s yaml.JSONSchema) yaml.JSONSchema {
+		yaml.SchemaRequireOneOf(s, "serviceAccount", "tokenFile")
+		return s
+	}, VarsSourceVaultAuthKubernetes{})
+	yaml.RegisterSchemaExtension(func(s yaml.JSONSchema) yaml.JSONSchema {
+		yaml.SchemaRequireOneOf(s, "secretId", "secretIdFile")
+		return s
+	}, VarsSourceVaultAuthAppRole{})
+	yaml.RegisterSchemaExtension(func(s yaml.JSONSchema) yaml.JSONSchema {
+		yaml.SchemaRequireOneOf(s, "token", "tokenFile")
+		return s
+	}, VarsSourceVaultAuthJwt{})
+	yaml.RegisterSchemaExtension(func(s yaml.JSONSchema) yaml.JSONSchema {
+		yaml.SchemaRemoveProperties(s, "renderedSensitive", "renderedVars")
+		// unknown keys are treated as plugin vars sources, which can only be validated at runtime
+		s["additionalProperties"] = yaml.JSONSchema{"type": "object"}
+		return s
+	}, VarsSource{})
 }
@@ -0,0 +1,44 @@
+package types
+
+import (
+	"encoding/json"
+	"github.com/kluctl/kluctl/v2/pkg/yaml"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestVarsSourcePlugins(t *testing.T) {
+	var vs VarsSource
+	err := yaml.ReadYamlString(`
+myBackend:
+  path: a/b
+targetPath: x
+`, &vs)
+	assert.NoError(t, err)
+	assert.Equal(t, "x", vs.TargetPath)
+	assert.Equal(t, []string{"myBackend"}, vs.GetSourceKeys())
+	p, _, _ := vs.Plugins["myBackend"].GetNestedString("path")
+	assert.Equal(t, "a/b", p)
+
+	b, err := json.Marshal(&vs)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"myBackend": {"path": "a/b"}, "targetPath": "x"}`, string(b))
+
+	err = yaml.ReadYamlString(`
+file: a.yaml
+`, &vs)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"file"}, vs.GetSourceKeys())
+
+	err = yaml.ReadYamlString(`
+file: a.yaml
+unknown: x
+`, &vs)
+	assert.ErrorContains(t, err, `unknown field "unknown"`)
+
+	err = yaml.ReadYamlString(`
+file: a.yaml
+myBackend: {}
+`, &vs)
+	assert.Error(t, err)
+}
@@ -34,3 +34,9 @@ func (in *YamlUrl) DeepCopyInto(out *YamlUrl) {
 		out.URL.User = &*in.URL.User
 	}
 }
+
+func init() {
+	yaml.RegisterSchemaExtension(func(s yaml.JSONSchema) yaml.JSONSchema {
+		return yaml.JSONSchema{"type": "string"}
+	}, YamlUrl{})
+}
@@ -22,7 +22,9 @@ package types
 
 import (
 	"github.com/kluctl/kluctl/v2/pkg/types/k8s"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
 	"k8s.io/apiextensions-apiserver/pkg/apis/apiextensions/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/runtime"
 )
 
@@ -101,6 +103,21 @@ func (in *ConflictResolutionConfig) DeepCopy() *ConflictResolutionConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CueItemConfig) DeepCopyInto(out *CueItemConfig) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CueItemConfig.
+func (in *CueItemConfig) DeepCopy() *CueItemConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(CueItemConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeleteObjectItemConfig) DeepCopyInto(out *DeleteObjectItemConfig) {
 	*out = *in
@@ -125,6 +142,11 @@ func (in *DeploymentArg) DeepCopyInto(out *DeploymentArg) {
 		*out = new(v1.JSON)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Schema != nil {
+		in, out := &in.Schema, &out.Schema
+		*out = new(v1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentArg.
@@ -160,6 +182,21 @@ func (in *DeploymentItemConfig) DeepCopyInto(out *DeploymentItemConfig) {
 		*out = new(OciProject)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Jsonnet != nil {
+		in, out := &in.Jsonnet, &out.Jsonnet
+		*out = new(JsonnetItemConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Cue != nil {
+		in, out := &in.Cue, &out.Cue
+		*out = new(CueItemConfig)
+		**out = **in
+	}
+	if in.Manifest != nil {
+		in, out := &in.Manifest, &out.Manifest
+		*out = new(ManifestItemConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.DeleteObjects != nil {
 		in, out := &in.DeleteObjects, &out.DeleteObjects
 		*out = make([]DeleteObjectItemConfig, len(*in))
@@ -167,6 +204,13 @@ func (in *DeploymentItemConfig) DeepCopyInto(out *DeploymentItemConfig) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Functions != nil {
+		in, out := &in.Functions, &out.Functions
+		*out = make([]KrmFunctionConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 	if in.Tags != nil {
 		in, out := &in.Tags, &out.Tags
 		*out = make([]string, len(*in))
@@ -210,6 +254,11 @@ func (in *DeploymentItemConfig) DeepCopyInto(out *DeploymentItemConfig) {
 		*out = new(DeploymentProjectConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.RenderedRemoteResources != nil {
+		in, out := &in.RenderedRemoteResources, &out.RenderedRemoteResources
+		*out = make([]RemoteResourceInfo, len(*in))
+		copy(*out, *in)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentItemConfig.
@@ -222,6 +271,47 @@ func (in *DeploymentItemConfig) DeepCopy() *DeploymentItemConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentOutput) DeepCopyInto(out *DeploymentOutput) {
+	*out = *in
+	if in.Value != nil {
+		in, out := &in.Value, &out.Value
+		*out = new(v1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Object != nil {
+		in, out := &in.Object, &out.Object
+		*out = new(DeploymentOutputObject)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentOutput.
+func (in *DeploymentOutput) DeepCopy() *DeploymentOutput {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentOutput)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DeploymentOutputObject) DeepCopyInto(out *DeploymentOutputObject) {
+	*out = *in
+	in.ObjectRefItem.DeepCopyInto(&out.ObjectRefItem)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentOutputObject.
+func (in *DeploymentOutputObject) DeepCopy() *DeploymentOutputObject {
+	if in == nil {
+		return nil
+	}
+	out := new(DeploymentOutputObject)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *DeploymentProjectConfig) DeepCopyInto(out *DeploymentProjectConfig) {
 	*out = *in
@@ -282,6 +372,20 @@ func (in *DeploymentProjectConfig) DeepCopyInto(out *DeploymentProjectConfig) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.Transformations != nil {
+		in, out := &in.Transformations, &out.Transformations
+		*out = make([]TransformationConfig, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Outputs != nil {
+		in, out := &in.Outputs, &out.Outputs
+		*out = make([]DeploymentOutput, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DeploymentProjectConfig.
@@ -376,6 +480,21 @@ func (in *FixedImagesConfig) DeepCopy() *FixedImagesConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GeneratedSecret) DeepCopyInto(out *GeneratedSecret) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GeneratedSecret.
+func (in *GeneratedSecret) DeepCopy() *GeneratedSecret {
+	if in == nil {
+		return nil
+	}
+	out := new(GeneratedSecret)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GitFile) DeepCopyInto(out *GitFile) {
 	*out = *in
@@ -618,6 +737,50 @@ func (in *IgnoreForDiffItemConfig) DeepCopy() *IgnoreForDiffItemConfig {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JsonPatchOperation) DeepCopyInto(out *JsonPatchOperation) {
+	*out = *in
+	if in.Value != nil {
+		in, out := &in.Value, &out.Value
+		*out = new(v1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JsonPatchOperation.
+func (in *JsonPatchOperation) DeepCopy() *JsonPatchOperation {
+	if in == nil {
+		return nil
+	}
+	out := new(JsonPatchOperation)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *JsonnetItemConfig) DeepCopyInto(out *JsonnetItemConfig) {
+	*out = *in
+	if in.Libs != nil {
+		in, out := &in.Libs, &out.Libs
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Tlas != nil {
+		in, out := &in.Tlas, &out.Tlas
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new JsonnetItemConfig.
+func (in *JsonnetItemConfig) DeepCopy() *JsonnetItemConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(JsonnetItemConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *KluctlLibraryProject) DeepCopyInto(out *KluctlLibraryProject) {
 	*out = *in
@@ -667,6 +830,21 @@ func (in *KluctlProject) DeepCopyInto(out *KluctlProject) {
 		*out = new(AwsConfig)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Templating != nil {
+		in, out := &in.Templating, &out.Templating
+		*out = new(TemplatingConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Kustomize != nil {
+		in, out := &in.Kustomize, &out.Kustomize
+		*out = new(KustomizeConfig)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.KrmFunctions != nil {
+		in, out := &in.KrmFunctions, &out.KrmFunctions
+		*out = new(KrmFunctionsConfig)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KluctlProject.
@@ -679,6 +857,139 @@ func (in *KluctlProject) DeepCopy() *KluctlProject {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KrmExecFunction) DeepCopyInto(out *KrmExecFunction) {
+	*out = *in
+	if in.Args != nil {
+		in, out := &in.Args, &out.Args
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KrmExecFunction.
+func (in *KrmExecFunction) DeepCopy() *KrmExecFunction {
+	if in == nil {
+		return nil
+	}
+	out := new(KrmExecFunction)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KrmFunctionConfig) DeepCopyInto(out *KrmFunctionConfig) {
+	*out = *in
+	if in.Exec != nil {
+		in, out := &in.Exec, &out.Exec
+		*out = new(KrmExecFunction)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Starlark != nil {
+		in, out := &in.Starlark, &out.Starlark
+		*out = new(KrmStarlarkFunction)
+		**out = **in
+	}
+	if in.Config != nil {
+		in, out := &in.Config, &out.Config
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KrmFunctionConfig.
+func (in *KrmFunctionConfig) DeepCopy() *KrmFunctionConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(KrmFunctionConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KrmFunctionsConfig) DeepCopyInto(out *KrmFunctionsConfig) {
+	*out = *in
+	if in.ExecAllowList != nil {
+		in, out := &in.ExecAllowList, &out.ExecAllowList
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KrmFunctionsConfig.
+func (in *KrmFunctionsConfig) DeepCopy() *KrmFunctionsConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(KrmFunctionsConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KrmStarlarkFunction) DeepCopyInto(out *KrmStarlarkFunction) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KrmStarlarkFunction.
+func (in *KrmStarlarkFunction) DeepCopy() *KrmStarlarkFunction {
+	if in == nil {
+		return nil
+	}
+	out := new(KrmStarlarkFunction)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *KustomizeConfig) DeepCopyInto(out *KustomizeConfig) {
+	*out = *in
+	if in.RemoteHosts != nil {
+		in, out := &in.RemoteHosts, &out.RemoteHosts
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new KustomizeConfig.
+func (in *KustomizeConfig) DeepCopy() *KustomizeConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(KustomizeConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ManifestItemConfig) DeepCopyInto(out *ManifestItemConfig) {
+	*out = *in
+	if in.Oci != nil {
+		in, out := &in.Oci, &out.Oci
+		*out = new(OciProject)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.UpdateConstraints != nil {
+		in, out := &in.UpdateConstraints, &out.UpdateConstraints
+		*out = new(string)
+		**out = **in
+	}
+	if in.UpdateGitUrl != nil {
+		in, out := &in.UpdateGitUrl, &out.UpdateGitUrl
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ManifestItemConfig.
+func (in *ManifestItemConfig) DeepCopy() *ManifestItemConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(ManifestItemConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *ObjectRefItem) DeepCopyInto(out *ObjectRefItem) {
 	*out = *in
@@ -739,6 +1050,21 @@ func (in *OciRef) DeepCopy() *OciRef {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *RemoteResourceInfo) DeepCopyInto(out *RemoteResourceInfo) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new RemoteResourceInfo.
+func (in *RemoteResourceInfo) DeepCopy() *RemoteResourceInfo {
+	if in == nil {
+		return nil
+	}
+	out := new(RemoteResourceInfo)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *RepoKey) DeepCopyInto(out *RepoKey) {
 	*out = *in
@@ -915,6 +1241,11 @@ func (in *Target) DeepCopyInto(out *Target) {
 			(*in)[i].DeepCopyInto(&(*out)[i])
 		}
 	}
+	if in.GeneratedSecrets != nil {
+		in, out := &in.GeneratedSecrets, &out.GeneratedSecrets
+		*out = new(TargetGeneratedSecrets)
+		**out = **in
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Target.
@@ -928,46 +1259,228 @@ func (in *Target) DeepCopy() *Target {
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VarSourceAzureKeyVault) DeepCopyInto(out *VarSourceAzureKeyVault) {
+func (in *TargetGeneratedSecrets) DeepCopyInto(out *TargetGeneratedSecrets) {
 	*out = *in
 }
 
-// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VarSourceAzureKeyVault.
-func (in *VarSourceAzureKeyVault) DeepCopy() *VarSourceAzureKeyVault {
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TargetGeneratedSecrets.
+func (in *TargetGeneratedSecrets) DeepCopy() *TargetGeneratedSecrets {
 	if in == nil {
 		return nil
 	}
-	out := new(VarSourceAzureKeyVault)
+	out := new(TargetGeneratedSecrets)
 	in.DeepCopyInto(out)
 	return out
 }
 
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
-func (in *VarsSource) DeepCopyInto(out *VarsSource) {
+func (in *TemplatingConfig) DeepCopyInto(out *TemplatingConfig) {
 	*out = *in
-	if in.IgnoreMissing != nil {
-		in, out := &in.IgnoreMissing, &out.IgnoreMissing
-		*out = new(bool)
-		**out = **in
-	}
-	if in.NoOverride != nil {
-		in, out := &in.NoOverride, &out.NoOverride
-		*out = new(bool)
-		**out = **in
+	if in.Extensions != nil {
+		in, out := &in.Extensions, &out.Extensions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
 	}
-	if in.Sensitive != nil {
-		in, out := &in.Sensitive, &out.Sensitive
-		*out = new(bool)
-		**out = **in
+	if in.PythonPath != nil {
+		in, out := &in.PythonPath, &out.PythonPath
+		*out = make([]TemplatingLibrary, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	if in.Values != nil {
-		in, out := &in.Values, &out.Values
-		*out = (*in).DeepCopy()
+	if in.SearchDirs != nil {
+		in, out := &in.SearchDirs, &out.SearchDirs
+		*out = make([]TemplatingLibrary, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
 	}
-	if in.File != nil {
-		in, out := &in.File, &out.File
-		*out = new(string)
-		**out = **in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplatingConfig.
+func (in *TemplatingConfig) DeepCopy() *TemplatingConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplatingConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TemplatingLibrary) DeepCopyInto(out *TemplatingLibrary) {
+	*out = *in
+	if in.Path != nil {
+		in, out := &in.Path, &out.Path
+		*out = new(string)
+		**out = **in
+	}
+	if in.Git != nil {
+		in, out := &in.Git, &out.Git
+		*out = new(GitProject)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Oci != nil {
+		in, out := &in.Oci, &out.Oci
+		*out = new(OciProject)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TemplatingLibrary.
+func (in *TemplatingLibrary) DeepCopy() *TemplatingLibrary {
+	if in == nil {
+		return nil
+	}
+	out := new(TemplatingLibrary)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransformationConfig) DeepCopyInto(out *TransformationConfig) {
+	*out = *in
+	if in.Target != nil {
+		in, out := &in.Target, &out.Target
+		*out = new(TransformationTarget)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Patch != nil {
+		in, out := &in.Patch, &out.Patch
+		*out = (*in).DeepCopy()
+	}
+	if in.JsonPatch != nil {
+		in, out := &in.JsonPatch, &out.JsonPatch
+		*out = make([]JsonPatchOperation, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Set != nil {
+		in, out := &in.Set, &out.Set
+		*out = make([]TransformationSetter, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Remove != nil {
+		in, out := &in.Remove, &out.Remove
+		*out = make(SingleStringOrList, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransformationConfig.
+func (in *TransformationConfig) DeepCopy() *TransformationConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(TransformationConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransformationSetter) DeepCopyInto(out *TransformationSetter) {
+	*out = *in
+	if in.Value != nil {
+		in, out := &in.Value, &out.Value
+		*out = new(v1.JSON)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransformationSetter.
+func (in *TransformationSetter) DeepCopy() *TransformationSetter {
+	if in == nil {
+		return nil
+	}
+	out := new(TransformationSetter)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TransformationTarget) DeepCopyInto(out *TransformationTarget) {
+	*out = *in
+	if in.Group != nil {
+		in, out := &in.Group, &out.Group
+		*out = new(string)
+		**out = **in
+	}
+	if in.Version != nil {
+		in, out := &in.Version, &out.Version
+		*out = new(string)
+		**out = **in
+	}
+	if in.Kind != nil {
+		in, out := &in.Kind, &out.Kind
+		*out = new(string)
+		**out = **in
+	}
+	if in.Name != nil {
+		in, out := &in.Name, &out.Name
+		*out = new(string)
+		**out = **in
+	}
+	if in.Namespace != nil {
+		in, out := &in.Namespace, &out.Namespace
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TransformationTarget.
+func (in *TransformationTarget) DeepCopy() *TransformationTarget {
+	if in == nil {
+		return nil
+	}
+	out := new(TransformationTarget)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VarSourceAzureKeyVault) DeepCopyInto(out *VarSourceAzureKeyVault) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VarSourceAzureKeyVault.
+func (in *VarSourceAzureKeyVault) DeepCopy() *VarSourceAzureKeyVault {
+	if in == nil {
+		return nil
+	}
+	out := new(VarSourceAzureKeyVault)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VarsSource) DeepCopyInto(out *VarsSource) {
+	*out = *in
+	if in.IgnoreMissing != nil {
+		in, out := &in.IgnoreMissing, &out.IgnoreMissing
+		*out = new(bool)
+		**out = **in
+	}
+	if in.NoOverride != nil {
+		in, out := &in.NoOverride, &out.NoOverride
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Sensitive != nil {
+		in, out := &in.Sensitive, &out.Sensitive
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Values != nil {
+		in, out := &in.Values, &out.Values
+		*out = (*in).DeepCopy()
+	}
+	if in.File != nil {
+		in, out := &in.File, &out.File
+		*out = new(string)
+		**out = **in
 	}
 	if in.Git != nil {
 		in, out := &in.Git, &out.Git
@@ -979,6 +1492,11 @@ func (in *VarsSource) DeepCopyInto(out *VarsSource) {
 		*out = new(VarsSourceGitFiles)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Oci != nil {
+		in, out := &in.Oci, &out.Oci
+		*out = new(VarsSourceOci)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.ClusterConfigMap != nil {
 		in, out := &in.ClusterConfigMap, &out.ClusterConfigMap
 		*out = new(VarsSourceClusterConfigMapOrSecret)
@@ -1003,11 +1521,26 @@ func (in *VarsSource) DeepCopyInto(out *VarsSource) {
 		*out = new(VarsSourceHttp)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = new(VarsSourceCommand)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.AwsSecretsManager != nil {
 		in, out := &in.AwsSecretsManager, &out.AwsSecretsManager
 		*out = new(VarsSourceAwsSecretsManager)
 		(*in).DeepCopyInto(*out)
 	}
+	if in.AwsParameterStore != nil {
+		in, out := &in.AwsParameterStore, &out.AwsParameterStore
+		*out = new(VarsSourceAwsParameterStore)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AwsAppConfig != nil {
+		in, out := &in.AwsAppConfig, &out.AwsAppConfig
+		*out = new(VarsSourceAwsAppConfig)
+		(*in).DeepCopyInto(*out)
+	}
 	if in.GcpSecretManager != nil {
 		in, out := &in.GcpSecretManager, &out.GcpSecretManager
 		*out = new(VarsSourceGcpSecretManager)
@@ -1016,13 +1549,43 @@ func (in *VarsSource) DeepCopyInto(out *VarsSource) {
 	if in.Vault != nil {
 		in, out := &in.Vault, &out.Vault
 		*out = new(VarsSourceVault)
-		**out = **in
+		(*in).DeepCopyInto(*out)
 	}
 	if in.AzureKeyVault != nil {
 		in, out := &in.AzureKeyVault, &out.AzureKeyVault
 		*out = new(VarSourceAzureKeyVault)
 		**out = **in
 	}
+	if in.KluctlOutputs != nil {
+		in, out := &in.KluctlOutputs, &out.KluctlOutputs
+		*out = new(VarsSourceKluctlOutputs)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GeneratedSecrets != nil {
+		in, out := &in.GeneratedSecrets, &out.GeneratedSecrets
+		*out = new(VarsSourceGeneratedSecrets)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Plugins != nil {
+		in, out := &in.Plugins, &out.Plugins
+		*out = make(map[string]*uo.UnstructuredObject, len(*in))
+		for key, val := range *in {
+			var outVal *uo.UnstructuredObject
+			if val == nil {
+				(*out)[key] = nil
+			} else {
+				inVal := (*in)[key]
+				in, out := &inVal, &outVal
+				*out = (*in).DeepCopy()
+			}
+			(*out)[key] = outVal
+		}
+	}
+	if in.CacheTTL != nil {
+		in, out := &in.CacheTTL, &out.CacheTTL
+		*out = new(metav1.Duration)
+		**out = **in
+	}
 	if in.RenderedVars != nil {
 		in, out := &in.RenderedVars, &out.RenderedVars
 		*out = (*in).DeepCopy()
@@ -1039,6 +1602,76 @@ func (in *VarsSource) DeepCopy() *VarsSource {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VarsSourceAwsAppConfig) DeepCopyInto(out *VarsSourceAwsAppConfig) {
+	*out = *in
+	if in.Region != nil {
+		in, out := &in.Region, &out.Region
+		*out = new(string)
+		**out = **in
+	}
+	if in.Profile != nil {
+		in, out := &in.Profile, &out.Profile
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VarsSourceAwsAppConfig.
+func (in *VarsSourceAwsAppConfig) DeepCopy() *VarsSourceAwsAppConfig {
+	if in == nil {
+		return nil
+	}
+	out := new(VarsSourceAwsAppConfig)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VarsSourceAwsParameterStore) DeepCopyInto(out *VarsSourceAwsParameterStore) {
+	*out = *in
+	if in.Name != nil {
+		in, out := &in.Name, &out.Name
+		*out = new(string)
+		**out = **in
+	}
+	if in.Path != nil {
+		in, out := &in.Path, &out.Path
+		*out = new(string)
+		**out = **in
+	}
+	if in.Recursive != nil {
+		in, out := &in.Recursive, &out.Recursive
+		*out = new(bool)
+		**out = **in
+	}
+	if in.WithDecryption != nil {
+		in, out := &in.WithDecryption, &out.WithDecryption
+		*out = new(bool)
+		**out = **in
+	}
+	if in.Region != nil {
+		in, out := &in.Region, &out.Region
+		*out = new(string)
+		**out = **in
+	}
+	if in.Profile != nil {
+		in, out := &in.Profile, &out.Profile
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VarsSourceAwsParameterStore.
+func (in *VarsSourceAwsParameterStore) DeepCopy() *VarsSourceAwsParameterStore {
+	if in == nil {
+		return nil
+	}
+	out := new(VarsSourceAwsParameterStore)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VarsSourceAwsSecretsManager) DeepCopyInto(out *VarsSourceAwsSecretsManager) {
 	*out = *in
@@ -1108,6 +1741,38 @@ func (in *VarsSourceClusterObject) DeepCopy() *VarsSourceClusterObject {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VarsSourceCommand) DeepCopyInto(out *VarsSourceCommand) {
+	*out = *in
+	if in.Command != nil {
+		in, out := &in.Command, &out.Command
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Env != nil {
+		in, out := &in.Env, &out.Env
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.Timeout != nil {
+		in, out := &in.Timeout, &out.Timeout
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VarsSourceCommand.
+func (in *VarsSourceCommand) DeepCopy() *VarsSourceCommand {
+	if in == nil {
+		return nil
+	}
+	out := new(VarsSourceCommand)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VarsSourceGcpSecretManager) DeepCopyInto(out *VarsSourceGcpSecretManager) {
 	*out = *in
@@ -1123,6 +1788,26 @@ func (in *VarsSourceGcpSecretManager) DeepCopy() *VarsSourceGcpSecretManager {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VarsSourceGeneratedSecrets) DeepCopyInto(out *VarsSourceGeneratedSecrets) {
+	*out = *in
+	if in.Secrets != nil {
+		in, out := &in.Secrets, &out.Secrets
+		*out = make([]GeneratedSecret, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VarsSourceGeneratedSecrets.
+func (in *VarsSourceGeneratedSecrets) DeepCopy() *VarsSourceGeneratedSecrets {
+	if in == nil {
+		return nil
+	}
+	out := new(VarsSourceGeneratedSecrets)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VarsSourceGit) DeepCopyInto(out *VarsSourceGit) {
 	*out = *in
@@ -1170,6 +1855,37 @@ func (in *VarsSourceGitFiles) DeepCopy() *VarsSourceGitFiles {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VarsSourceKluctlOutputs) DeepCopyInto(out *VarsSourceKluctlOutputs) {
+	*out = *in
+	out.RepoKey = in.RepoKey
+	if in.Target != nil {
+		in, out := &in.Target, &out.Target
+		*out = new(string)
+		**out = **in
+	}
+	if in.Discriminator != nil {
+		in, out := &in.Discriminator, &out.Discriminator
+		*out = new(string)
+		**out = **in
+	}
+	if in.MaxAge != nil {
+		in, out := &in.MaxAge, &out.MaxAge
+		*out = new(metav1.Duration)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VarsSourceKluctlOutputs.
+func (in *VarsSourceKluctlOutputs) DeepCopy() *VarsSourceKluctlOutputs {
+	if in == nil {
+		return nil
+	}
+	out := new(VarsSourceKluctlOutputs)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VarsSourceHttp) DeepCopyInto(out *VarsSourceHttp) {
 	*out = *in
@@ -1208,9 +1924,39 @@ func (in *VarsSourceHttp) DeepCopy() *VarsSourceHttp {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VarsSourceOci) DeepCopyInto(out *VarsSourceOci) {
+	*out = *in
+	if in.Ref != nil {
+		in, out := &in.Ref, &out.Ref
+		*out = new(OciRef)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VarsSourceOci.
+func (in *VarsSourceOci) DeepCopy() *VarsSourceOci {
+	if in == nil {
+		return nil
+	}
+	out := new(VarsSourceOci)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *VarsSourceVault) DeepCopyInto(out *VarsSourceVault) {
 	*out = *in
+	if in.Kv != nil {
+		in, out := &in.Kv, &out.Kv
+		*out = new(VarsSourceVaultKv)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Auth != nil {
+		in, out := &in.Auth, &out.Auth
+		*out = new(VarsSourceVaultAuth)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VarsSourceVault.
@@ -1223,6 +1969,151 @@ func (in *VarsSourceVault) DeepCopy() *VarsSourceVault {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VarsSourceVaultAuth) DeepCopyInto(out *VarsSourceVaultAuth) {
+	*out = *in
+	if in.TokenFile != nil {
+		in, out := &in.TokenFile, &out.TokenFile
+		*out = new(string)
+		**out = **in
+	}
+	if in.Kubernetes != nil {
+		in, out := &in.Kubernetes, &out.Kubernetes
+		*out = new(VarsSourceVaultAuthKubernetes)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.AppRole != nil {
+		in, out := &in.AppRole, &out.AppRole
+		*out = new(VarsSourceVaultAuthAppRole)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.Jwt != nil {
+		in, out := &in.Jwt, &out.Jwt
+		*out = new(VarsSourceVaultAuthJwt)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VarsSourceVaultAuth.
+func (in *VarsSourceVaultAuth) DeepCopy() *VarsSourceVaultAuth {
+	if in == nil {
+		return nil
+	}
+	out := new(VarsSourceVaultAuth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VarsSourceVaultAuthAppRole) DeepCopyInto(out *VarsSourceVaultAuthAppRole) {
+	*out = *in
+	if in.SecretId != nil {
+		in, out := &in.SecretId, &out.SecretId
+		*out = new(string)
+		**out = **in
+	}
+	if in.SecretIdFile != nil {
+		in, out := &in.SecretIdFile, &out.SecretIdFile
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VarsSourceVaultAuthAppRole.
+func (in *VarsSourceVaultAuthAppRole) DeepCopy() *VarsSourceVaultAuthAppRole {
+	if in == nil {
+		return nil
+	}
+	out := new(VarsSourceVaultAuthAppRole)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VarsSourceVaultAuthJwt) DeepCopyInto(out *VarsSourceVaultAuthJwt) {
+	*out = *in
+	if in.Token != nil {
+		in, out := &in.Token, &out.Token
+		*out = new(string)
+		**out = **in
+	}
+	if in.TokenFile != nil {
+		in, out := &in.TokenFile, &out.TokenFile
+		*out = new(string)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VarsSourceVaultAuthJwt.
+func (in *VarsSourceVaultAuthJwt) DeepCopy() *VarsSourceVaultAuthJwt {
+	if in == nil {
+		return nil
+	}
+	out := new(VarsSourceVaultAuthJwt)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VarsSourceVaultAuthKubernetes) DeepCopyInto(out *VarsSourceVaultAuthKubernetes) {
+	*out = *in
+	if in.ServiceAccount != nil {
+		in, out := &in.ServiceAccount, &out.ServiceAccount
+		*out = new(VarsSourceVaultServiceAccount)
+		(*in).DeepCopyInto(*out)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VarsSourceVaultAuthKubernetes.
+func (in *VarsSourceVaultAuthKubernetes) DeepCopy() *VarsSourceVaultAuthKubernetes {
+	if in == nil {
+		return nil
+	}
+	out := new(VarsSourceVaultAuthKubernetes)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VarsSourceVaultKv) DeepCopyInto(out *VarsSourceVaultKv) {
+	*out = *in
+	if in.SecretVersion != nil {
+		in, out := &in.SecretVersion, &out.SecretVersion
+		*out = new(int)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VarsSourceVaultKv.
+func (in *VarsSourceVaultKv) DeepCopy() *VarsSourceVaultKv {
+	if in == nil {
+		return nil
+	}
+	out := new(VarsSourceVaultKv)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *VarsSourceVaultServiceAccount) DeepCopyInto(out *VarsSourceVaultServiceAccount) {
+	*out = *in
+	if in.Audiences != nil {
+		in, out := &in.Audiences, &out.Audiences
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new VarsSourceVaultServiceAccount.
+func (in *VarsSourceVaultServiceAccount) DeepCopy() *VarsSourceVaultServiceAccount {
+	if in == nil {
+		return nil
+	}
+	out := new(VarsSourceVaultServiceAccount)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *WaitReadinessObjectItemConfig) DeepCopyInto(out *WaitReadinessObjectItemConfig) {
 	*out = *in
@@ -22,6 +22,12 @@ func (uo *UnstructuredObject) UnmarshalJSON(b []byte) error {
 	return yaml.ReadYamlBytes(b, &uo.Object)
 }
 
+func init() {
+	yaml.RegisterSchemaExtension(func(s yaml.JSONSchema) yaml.JSONSchema {
+		return yaml.JSONSchema{"type": "object"}
+	}, UnstructuredObject{})
+}
+
 func (uo *UnstructuredObject) IsZero() bool {
 	return uo == nil || len(uo.Object) == 0
 }
@@ -0,0 +1,162 @@
+package file_formats
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	sops_formats "github.com/getsops/sops/v3/cmd/sops/formats"
+	"github.com/kluctl/kluctl/v2/pkg/sops"
+	"github.com/kluctl/kluctl/v2/pkg/sops/decryptor"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/kluctl/kluctl/v2/pkg/yaml"
+)
+
+// Format specifies how a vars or args file is parsed
+type Format string
+
+const (
+	Yaml Format = "yaml"
+	// Json also allows comments and trailing commas
+	Json   Format = "json"
+	Toml   Format = "toml"
+	Ini    Format = "ini"
+	Dotenv Format = "dotenv"
+	// Hcl also covers Terraform .tfvars files
+	Hcl Format = "hcl"
+)
+
+var formatsByExt = map[string]Format{
+	".yaml":   Yaml,
+	".yml":    Yaml,
+	".json":   Json,
+	".jsonc":  Json,
+	".toml":   Toml,
+	".ini":    Ini,
+	".env":    Dotenv,
+	".hcl":    Hcl,
+	".tfvars": Hcl,
+}
+
+func ParseFormat(s string) (Format, error) {
+	switch f := Format(s); f {
+	case Yaml, Json, Toml, Ini, Dotenv, Hcl:
+		return f, nil
+	default:
+		return "", fmt.Errorf("unsupported file format '%s'", s)
+	}
+}
+
+// FormatForPath detects the format based on the file extension. Files with unknown extensions are treated as YAML.
+func FormatForPath(path string) Format {
+	base := filepath.Base(path)
+	if base == ".env" || strings.HasPrefix(base, ".env.") {
+		return Dotenv
+	}
+	if f, ok := formatsByExt[strings.ToLower(filepath.Ext(path))]; ok {
+		return f
+	}
+	return Yaml
+}
+
+// FormatForPathOrString returns the format given by s or detects it from path if s is empty
+func FormatForPathOrString(path string, s string) (Format, error) {
+	if s != "" {
+		return ParseFormat(s)
+	}
+	return FormatForPath(path), nil
+}
+
+// SopsFormat returns the format that must be used to decrypt SOPS encrypted files of the given format. Formats that
+// SOPS can not handle natively must be encrypted in binary mode, which results in a JSON document.
+func (f Format) SopsFormat(data []byte) (sops_formats.Format, sops_formats.Format) {
+	switch f {
+	case Yaml:
+		return sops_formats.Yaml, sops_formats.Yaml
+	case Json:
+		return sops_formats.Json, sops_formats.Json
+	case Ini:
+		return sops_formats.Ini, sops_formats.Ini
+	case Dotenv:
+		return sops_formats.Dotenv, sops_formats.Dotenv
+	default:
+		if json.Valid(data) {
+			return sops_formats.Json, sops_formats.Binary
+		}
+		return sops_formats.Binary, sops_formats.Binary
+	}
+}
+
+// Parse parses data in the given format. The result is always a dictionary.
+func Parse(data []byte, f Format) (*uo.UnstructuredObject, error) {
+	var m map[string]any
+	var err error
+	switch f {
+	case Yaml:
+		return parseYaml(data)
+	case Json:
+		// JSON is valid YAML, so we get the same value types as for YAML files
+		return parseYaml(StandardizeJson(data))
+	case Toml:
+		m, err = parseToml(data)
+	case Ini:
+		m, err = parseIni(data)
+	case Dotenv:
+		m, err = parseDotenv(data)
+	case Hcl:
+		m, err = parseHcl(data)
+	default:
+		return nil, fmt.Errorf("unsupported file format '%s'", f)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", f, err)
+	}
+
+	// this converts all values to the types that are used for YAML files, e.g. time values to strings
+	b, err := json.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return parseYaml(b)
+}
+
+// Decrypt decrypts SOPS encrypted data of the given format. Data that is not encrypted is returned as is. The
+// returned bool is true if the data was encrypted.
+func Decrypt(decrypter *decryptor.Decryptor, data []byte, f Format) ([]byte, bool, error) {
+	if f == Json {
+		// SOPS can't handle comments
+		data = StandardizeJson(data)
+	}
+	inputFormat, outputFormat := f.SopsFormat(data)
+	return sops.MaybeDecrypt(decrypter, data, inputFormat, outputFormat)
+}
+
+// ParseFile reads, decrypts and parses the given file, detecting the format from the file extension. The returned
+// bool is true if the file was encrypted.
+func ParseFile(decrypter *decryptor.Decryptor, path string) (*uo.UnstructuredObject, bool, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false, err
+	}
+	f := FormatForPath(path)
+	b, sensitive, err := Decrypt(decrypter, b, f)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to decrypt %s: %w", path, err)
+	}
+	ret, err := Parse(b, f)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load %s: %w", path, err)
+	}
+	return ret, sensitive, nil
+}
+
+func parseYaml(data []byte) (*uo.UnstructuredObject, error) {
+	ret := uo.New()
+	err := yaml.ReadYamlBytes(data, ret)
+	if err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
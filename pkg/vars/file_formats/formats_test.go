@@ -0,0 +1,121 @@
+package file_formats
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatForPath(t *testing.T) {
+	assert.Equal(t, Yaml, FormatForPath("a/vars.yml"))
+	assert.Equal(t, Yaml, FormatForPath("vars"))
+	assert.Equal(t, Json, FormatForPath("vars.jsonc"))
+	assert.Equal(t, Toml, FormatForPath("vars.TOML"))
+	assert.Equal(t, Ini, FormatForPath("vars.ini"))
+	assert.Equal(t, Dotenv, FormatForPath("dir/.env"))
+	assert.Equal(t, Dotenv, FormatForPath(".env.prod"))
+	assert.Equal(t, Dotenv, FormatForPath("prod.env"))
+	assert.Equal(t, Hcl, FormatForPath("prod.tfvars"))
+
+	_, err := ParseFormat("xml")
+	assert.Error(t, err)
+}
+
+func TestStandardizeJson(t *testing.T) {
+	s := StandardizeJson([]byte(`{
+  // comment
+  "a": "x // not a comment, /* neither */",
+  "b": [1, 2, /* inline */ 3,],
+  "c": {"d": "\"quoted\",",},
+}`))
+	o, err := Parse(s, Json)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"a": "x // not a comment, /* neither */",
+		"b": []any{float64(1), float64(2), float64(3)},
+		"c": map[string]any{"d": `"quoted",`},
+	}, o.Object)
+}
+
+func TestParseFormats(t *testing.T) {
+	tests := []struct {
+		name   string
+		format Format
+		data   string
+		result map[string]any
+	}{
+		{name: "toml", format: Toml, data: `
+a = "x"
+n = 5
+
+[section]
+b = true
+list = [1, 2]
+`, result: map[string]any{
+			"a": "x",
+			"n": float64(5),
+			"section": map[string]any{
+				"b":    true,
+				"list": []any{float64(1), float64(2)},
+			},
+		}},
+		{name: "ini", format: Ini, data: `
+a = x
+
+[section]
+b = y
+`, result: map[string]any{
+			"a": "x",
+			"section": map[string]any{
+				"b": "y",
+			},
+		}},
+		{name: "dotenv", format: Dotenv, data: `
+# comment
+A=x
+export B="line1\nline2"
+C='raw\n'
+D=value # comment
+E=
+`, result: map[string]any{
+			"A": "x",
+			"B": "line1\nline2",
+			"C": `raw\n`,
+			"D": "value",
+			"E": "",
+		}},
+		{name: "hcl", format: Hcl, data: `
+region = "eu-central-1"
+count = 3
+enabled = true
+zones = ["a", "b"]
+tags = {
+  team = "infra"
+}
+cluster "main" {
+  size = 2
+}
+`, result: map[string]any{
+			"region":  "eu-central-1",
+			"count":   float64(3),
+			"enabled": true,
+			"zones":   []any{"a", "b"},
+			"tags": map[string]any{
+				"team": "infra",
+			},
+			"cluster": map[string]any{
+				"main": map[string]any{
+					"size": float64(2),
+				},
+			},
+		}},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			o, err := Parse([]byte(tc.data), tc.format)
+			assert.NoError(t, err)
+			assert.Equal(t, tc.result, o.Object)
+		})
+	}
+}
@@ -0,0 +1,228 @@
+package file_formats
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hashicorp/hcl"
+	"github.com/hashicorp/hcl/hcl/ast"
+	"github.com/hashicorp/hcl/hcl/token"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/ini.v1"
+)
+
+// StandardizeJson removes comments and trailing commas from JSON documents. Everything inside strings is kept as is.
+func StandardizeJson(data []byte) []byte {
+	ret := make([]byte, 0, len(data))
+	// position in ret of the last comma that might turn out to be a trailing comma
+	lastComma := -1
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case c == '"':
+			start := i
+			for i++; i < len(data) && data[i] != '"'; i++ {
+				if data[i] == '\\' {
+					i++
+				}
+			}
+			end := min(i+1, len(data))
+			ret = append(ret, data[start:end]...)
+			lastComma = -1
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i < len(data) && data[i] != '\n' {
+				i++
+			}
+			if i < len(data) {
+				ret = append(ret, '\n')
+			}
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			end := bytes.Index(data[i+2:], []byte("*/"))
+			if end == -1 {
+				i = len(data)
+			} else {
+				i += 2 + end + 1
+			}
+			ret = append(ret, ' ')
+		case c == ',':
+			lastComma = len(ret)
+			ret = append(ret, c)
+		case c == '}' || c == ']':
+			if lastComma != -1 {
+				ret[lastComma] = ' '
+			}
+			lastComma = -1
+			ret = append(ret, c)
+		case c == ' ' || c == '\t' || c == '\r' || c == '\n':
+			ret = append(ret, c)
+		default:
+			lastComma = -1
+			ret = append(ret, c)
+		}
+	}
+	return ret
+}
+
+func parseToml(data []byte) (map[string]any, error) {
+	var m map[string]any
+	err := toml.Unmarshal(data, &m)
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// parseIni returns keys of the default section as top-level values and all other sections as dictionaries
+func parseIni(data []byte) (map[string]any, error) {
+	f, err := ini.Load(data)
+	if err != nil {
+		return nil, err
+	}
+	ret := map[string]any{}
+	for _, s := range f.Sections() {
+		m := ret
+		if s.Name() != ini.DefaultSection {
+			m = map[string]any{}
+			ret[s.Name()] = m
+		}
+		for _, k := range s.Keys() {
+			m[k.Name()] = k.Value()
+		}
+	}
+	return ret, nil
+}
+
+// parseDotenv parses KEY=VALUE lines. Lines may be prefixed with 'export'. Values can be single quoted (taken
+// literally) or double quoted (supporting escape sequences). All values are strings.
+func parseDotenv(data []byte) (map[string]any, error) {
+	ret := map[string]any{}
+	s := bufio.NewScanner(bytes.NewReader(data))
+	lineNum := 0
+	for s.Scan() {
+		lineNum++
+		line := strings.TrimSpace(s.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+		k, v, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: missing '='", lineNum)
+		}
+		k = strings.TrimSpace(k)
+		v = strings.TrimSpace(v)
+		if k == "" {
+			return nil, fmt.Errorf("line %d: missing key", lineNum)
+		}
+
+		switch {
+		case strings.HasPrefix(v, `"`):
+			end := strings.LastIndex(v, `"`)
+			if end == 0 {
+				return nil, fmt.Errorf("line %d: unterminated quoted value", lineNum)
+			}
+			uq, err := strconv.Unquote(v[:end+1])
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNum, err)
+			}
+			v = uq
+		case strings.HasPrefix(v, `'`):
+			end := strings.LastIndex(v, `'`)
+			if end == 0 {
+				return nil, fmt.Errorf("line %d: unterminated quoted value", lineNum)
+			}
+			v = v[1:end]
+		default:
+			if i := strings.Index(v, " #"); i != -1 {
+				v = strings.TrimSpace(v[:i])
+			}
+		}
+		ret[k] = v
+	}
+	if err := s.Err(); err != nil {
+		return nil, err
+	}
+	return ret, nil
+}
+
+// parseHcl parses HCL (v1) documents, which also covers most Terraform .tfvars files. In contrast to hcl.Unmarshal,
+// objects are returned as dictionaries instead of lists of dictionaries.
+func parseHcl(data []byte) (map[string]any, error) {
+	f, err := hcl.ParseBytes(data)
+	if err != nil {
+		return nil, err
+	}
+	l, ok := f.Node.(*ast.ObjectList)
+	if !ok {
+		return nil, fmt.Errorf("unexpected HCL root node")
+	}
+	return convertHclObjectList(l)
+}
+
+func convertHclObjectList(l *ast.ObjectList) (map[string]any, error) {
+	ret := map[string]any{}
+	for _, item := range l.Items {
+		v, err := convertHclNode(item.Val)
+		if err != nil {
+			return nil, err
+		}
+
+		// blocks like 'a "b" { ... }' are nested into a.b
+		m := ret
+		for i, k := range item.Keys {
+			ks := hclKeyString(k)
+			if i == len(item.Keys)-1 {
+				existing, ok1 := m[ks].(map[string]any)
+				vm, ok2 := v.(map[string]any)
+				if ok1 && ok2 {
+					for k2, v2 := range vm {
+						existing[k2] = v2
+					}
+				} else {
+					m[ks] = v
+				}
+				break
+			}
+			child, ok := m[ks].(map[string]any)
+			if !ok {
+				child = map[string]any{}
+				m[ks] = child
+			}
+			m = child
+		}
+	}
+	return ret, nil
+}
+
+func hclKeyString(k *ast.ObjectKey) string {
+	if k.Token.Type == token.STRING {
+		if s, ok := k.Token.Value().(string); ok {
+			return s
+		}
+	}
+	return k.Token.Text
+}
+
+func convertHclNode(n ast.Node) (any, error) {
+	switch n := n.(type) {
+	case *ast.LiteralType:
+		return n.Token.Value(), nil
+	case *ast.ListType:
+		ret := make([]any, 0, len(n.List))
+		for _, e := range n.List {
+			v, err := convertHclNode(e)
+			if err != nil {
+				return nil, err
+			}
+			ret = append(ret, v)
+		}
+		return ret, nil
+	case *ast.ObjectType:
+		return convertHclObjectList(n.List)
+	default:
+		return nil, fmt.Errorf("unsupported HCL node at %s", n.Pos().String())
+	}
+}
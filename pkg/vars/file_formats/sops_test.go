@@ -0,0 +1,94 @@
+package file_formats
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	extage "filippo.io/age"
+	"github.com/getsops/sops/v3"
+	"github.com/getsops/sops/v3/aes"
+	sopsage "github.com/getsops/sops/v3/age"
+	"github.com/getsops/sops/v3/cmd/sops/common"
+	sops_formats "github.com/getsops/sops/v3/cmd/sops/formats"
+	"github.com/getsops/sops/v3/keyservice"
+	"github.com/kluctl/kluctl/v2/pkg/sops/decryptor"
+	"github.com/stretchr/testify/assert"
+)
+
+func sopsEncrypt(t *testing.T, recipient string, data []byte, format sops_formats.Format) []byte {
+	store := common.StoreForFormat(format)
+	branches, err := store.LoadPlainFile(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree := sops.Tree{
+		Branches: branches,
+		Metadata: sops.Metadata{
+			KeyGroups: []sops.KeyGroup{{&sopsage.MasterKey{Recipient: recipient}}},
+		},
+	}
+	dataKey, errs := tree.GenerateDataKeyWithKeyServices([]keyservice.KeyServiceClient{keyservice.NewLocalClient()})
+	if len(errs) != 0 {
+		t.Fatal(errs)
+	}
+	cipher := aes.NewCipher()
+	mac, err := tree.Encrypt(dataKey, cipher)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tree.Metadata.LastModified = time.Now().UTC()
+	tree.Metadata.MessageAuthenticationCode, err = cipher.Encrypt(mac, dataKey, tree.Metadata.LastModified.Format(time.RFC3339))
+	if err != nil {
+		t.Fatal(err)
+	}
+	out, err := store.EmitEncryptedFile(tree)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return out
+}
+
+func TestParseFileSops(t *testing.T) {
+	ageID, err := extage.GenerateX25519Identity()
+	assert.NoError(t, err)
+	t.Setenv(sopsage.SopsAgeKeyEnv, ageID.String())
+
+	dir := t.TempDir()
+	d := decryptor.NewDecryptor(dir, decryptor.MaxEncryptedFileSize)
+	d.AddLocalKeyService()
+
+	files := map[string][]byte{
+		// TOML can only be encrypted in binary mode, which results in a JSON document
+		"args.toml": sopsEncrypt(t, ageID.Recipient().String(), []byte("a = \"x\"\nb = 1\n"), sops_formats.Binary),
+		"args.json": sopsEncrypt(t, ageID.Recipient().String(), []byte(`{"a": "x", "b": 1}`), sops_formats.Json),
+		"plain.json": []byte(`{"a": "x", // comment
+"b": 1}`),
+	}
+	for name, data := range files {
+		t.Run(name, func(t *testing.T) {
+			p := filepath.Join(dir, name)
+			assert.NoError(t, os.WriteFile(p, data, 0o600))
+
+			o, sensitive, err := ParseFile(d, p)
+			assert.NoError(t, err)
+			assert.Equal(t, name != "plain.json", sensitive)
+			a, _, _ := o.GetNestedString("a")
+			assert.Equal(t, "x", a)
+
+			// without a decryptor, the encrypted values are returned as is
+			o, sensitive, err = ParseFile(nil, p)
+			if name == "plain.json" {
+				assert.NoError(t, err)
+				assert.False(t, sensitive)
+				return
+			}
+			if err == nil {
+				a, _, _ = o.GetNestedString("a")
+				assert.NotEqual(t, "x", a)
+			}
+			assert.False(t, sensitive)
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/kluctl/kluctl/v2/pkg/vars"
+	"github.com/kluctl/kluctl/v2/pkg/yaml"
+	"github.com/xeipuuv/gojsonschema"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"io"
+	"net"
+	"os"
+	"os/exec"
+	"strings"
+)
+
+// Plugin is a running vars source plugin.
+type Plugin struct {
+	name string
+	cmd  *exec.Cmd
+
+	grpcConn *grpc.ClientConn
+	client   VarsPluginClient
+
+	providers []*pluginProvider
+}
+
+// Start starts the plugin binary found at path and talks to it via gRPC over its stdin/stdout. The caller must call
+// Close when the plugin is not needed anymore.
+func Start(ctx context.Context, path string, args ...string) (*Plugin, error) {
+	cmd := exec.Command(path, args...)
+	cmd.Stderr = os.Stderr
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	err = cmd.Start()
+	if err != nil {
+		return nil, fmt.Errorf("failed to start vars plugin %s: %w", path, err)
+	}
+
+	p, err := NewPlugin(ctx, path, stdout, stdin)
+	if err != nil {
+		_ = cmd.Process.Kill()
+		_ = cmd.Wait()
+		return nil, err
+	}
+	p.cmd = cmd
+	return p, nil
+}
+
+// NewPlugin connects to an already running plugin via the given reader/writer pair.
+func NewPlugin(ctx context.Context, name string, r io.ReadCloser, w io.WriteCloser) (*Plugin, error) {
+	conn := newStdioConn(r, w)
+	dialed := false
+	grpcConn, err := grpc.DialContext(ctx, "passthrough:///"+name,
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithContextDialer(func(ctx context.Context, s string) (net.Conn, error) {
+			// there is only a single stdin/stdout pair, so we can't reconnect
+			if dialed {
+				return nil, fmt.Errorf("connection to vars plugin %s was lost", name)
+			}
+			dialed = true
+			return conn, nil
+		}),
+	)
+	if err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+
+	p := &Plugin{
+		name:     name,
+		grpcConn: grpcConn,
+		client:   NewVarsPluginClient(grpcConn),
+	}
+
+	info, err := p.client.GetInfo(ctx, &GetInfoRequest{ProtocolVersion: ProtocolVersion})
+	if err != nil {
+		_ = p.Close()
+		return nil, fmt.Errorf("failed to get info from vars plugin %s: %w", name, err)
+	}
+	if info.ProtocolVersion != ProtocolVersion {
+		_ = p.Close()
+		return nil, fmt.Errorf("vars plugin %s uses protocol version %d, while version %d is required", name, info.ProtocolVersion, ProtocolVersion)
+	}
+
+	for _, pi := range info.Providers {
+		pp := &pluginProvider{
+			plugin: p,
+			info:   pi,
+		}
+		if pi.Schema != nil {
+			pp.schema, err = gojsonschema.NewSchema(gojsonschema.NewGoLoader(pi.Schema))
+			if err != nil {
+				_ = p.Close()
+				return nil, fmt.Errorf("vars plugin %s returned an invalid schema for %s: %w", name, pi.Key, err)
+			}
+		}
+		p.providers = append(p.providers, pp)
+	}
+
+	return p, nil
+}
+
+// Providers returns the vars source providers implemented by the plugin, ready to be registered in a
+// vars.VarsSourceRegistry.
+func (p *Plugin) Providers() []vars.VarsSourceProvider {
+	ret := make([]vars.VarsSourceProvider, 0, len(p.providers))
+	for _, pp := range p.providers {
+		ret = append(ret, pp)
+	}
+	return ret
+}
+
+func (p *Plugin) Close() error {
+	err := p.grpcConn.Close()
+	if p.cmd != nil {
+		// the plugin is expected to exit when stdin gets closed
+		err2 := p.cmd.Wait()
+		if err == nil {
+			err = err2
+		}
+	}
+	return err
+}
+
+type pluginProvider struct {
+	plugin *Plugin
+	info   ProviderInfo
+	schema *gojsonschema.Schema
+}
+
+func (pp *pluginProvider) Key() string {
+	return pp.info.Key
+}
+
+func (pp *pluginProvider) Schema() map[string]any {
+	return pp.info.Schema
+}
+
+func (pp *pluginProvider) Load(ctx context.Context, v *vars.VarsLoader, req *vars.VarsSourceLoadRequest) (any, bool, error) {
+	var config map[string]any
+	if c, ok := req.Source.Plugins[pp.info.Key]; ok && c != nil {
+		config = c.Object
+	} else {
+		config = map[string]any{}
+	}
+
+	if pp.schema != nil {
+		result, err := pp.schema.Validate(gojsonschema.NewGoLoader(config))
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to validate %s vars source: %w", pp.info.Key, err)
+		}
+		if !result.Valid() {
+			var errs []string
+			for _, e := range result.Errors() {
+				errs = append(errs, e.String())
+			}
+			return nil, false, fmt.Errorf("invalid %s vars source: %s", pp.info.Key, strings.Join(errs, ", "))
+		}
+	}
+
+	resp, err := pp.plugin.client.Load(ctx, &LoadRequest{
+		Key:           pp.info.Key,
+		Config:        config,
+		IgnoreMissing: req.IgnoreMissing,
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("vars plugin %s failed to load %s: %w", pp.plugin.name, pp.info.Key, err)
+	}
+
+	sensitive := pp.info.Sensitive
+	if resp.Sensitive != nil {
+		sensitive = *resp.Sensitive
+	}
+
+	if resp.NotFound {
+		if req.IgnoreMissing {
+			return uo.New(), sensitive, nil
+		}
+		return nil, false, fmt.Errorf("vars for %s vars source not found", pp.info.Key)
+	}
+
+	var value any
+	if len(resp.Vars) != 0 {
+		// go through the yaml reader so that numbers are handled the same way as in all other vars sources
+		err = yaml.ReadYamlBytes(resp.Vars, &value)
+		if err != nil {
+			return nil, false, fmt.Errorf("vars plugin %s returned invalid vars: %w", pp.plugin.name, err)
+		}
+	}
+	if m, ok := value.(map[string]any); ok {
+		return uo.FromMap(m), sensitive, nil
+	} else if value == nil {
+		return uo.New(), sensitive, nil
+	}
+	return value, sensitive, nil
+}
+
+// NewRegistryWithPlugins starts all given plugin binaries and returns a registry with all built-in vars sources and
+// all vars sources provided by the plugins. The returned function must be called to stop the plugins.
+func NewRegistryWithPlugins(ctx context.Context, paths []string) (*vars.VarsSourceRegistry, func(), error) {
+	var plugins []*Plugin
+	closeAll := func() {
+		for _, p := range plugins {
+			_ = p.Close()
+		}
+	}
+
+	registry := vars.NewVarsSourceRegistryWithBuiltins()
+	for _, path := range paths {
+		p, err := Start(ctx, path)
+		if err != nil {
+			closeAll()
+			return nil, nil, err
+		}
+		plugins = append(plugins, p)
+		for _, pp := range p.Providers() {
+			err = registry.Register(pp)
+			if err != nil {
+				closeAll()
+				return nil, nil, fmt.Errorf("failed to register vars source from plugin %s: %w", path, err)
+			}
+		}
+	}
+	return registry, closeAll, nil
+}
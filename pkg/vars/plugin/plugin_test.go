@@ -0,0 +1,135 @@
+package plugin
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/kluctl_jinja2"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/kluctl/kluctl/v2/pkg/vars"
+	"github.com/kluctl/kluctl/v2/pkg/yaml"
+	"github.com/stretchr/testify/assert"
+	"io"
+	"testing"
+)
+
+type testProvider struct{}
+
+func (p *testProvider) Info() ProviderInfo {
+	return ProviderInfo{
+		Key: "testSecrets",
+		Schema: map[string]any{
+			"type":                 "object",
+			"required":             []any{"name"},
+			"additionalProperties": false,
+			"properties": map[string]any{
+				"name": map[string]any{"type": "string"},
+			},
+		},
+		Sensitive: true,
+	}
+}
+
+func (p *testProvider) Load(ctx context.Context, config map[string]any) (map[string]any, error) {
+	name := config["name"].(string)
+	if name == "missing" {
+		return nil, ErrNotFound
+	}
+	if name == "fail" {
+		return nil, fmt.Errorf("backend failed")
+	}
+	return map[string]any{
+		"secret": map[string]any{
+			"name":  name,
+			"count": 3,
+		},
+	}, nil
+}
+
+func startTestPlugin(t *testing.T) *Plugin {
+	clientR, serverW := io.Pipe()
+	serverR, clientW := io.Pipe()
+
+	done := make(chan error)
+	go func() {
+		done <- ServeConn(serverR, serverW, &testProvider{})
+	}()
+
+	p, err := NewPlugin(context.Background(), "test", clientR, clientW)
+	assert.NoError(t, err)
+	t.Cleanup(func() {
+		_ = p.Close()
+		assert.NoError(t, <-done)
+	})
+	return p
+}
+
+func TestPlugin(t *testing.T) {
+	p := startTestPlugin(t)
+
+	registry := vars.NewVarsSourceRegistryWithBuiltins()
+	for _, pp := range p.Providers() {
+		assert.NoError(t, registry.Register(pp))
+	}
+	assert.Contains(t, registry.Keys(), "testSecrets")
+	assert.Contains(t, registry.Keys(), "file")
+
+	j2, err := kluctl_jinja2.NewKluctlJinja2(context.Background(), true)
+	assert.NoError(t, err)
+	defer j2.Close()
+
+	load := func(s string) (*vars.VarsCtx, *types.VarsSource, error) {
+		var vs types.VarsSource
+		err := yaml.ReadYamlString(s, &vs)
+		assert.NoError(t, err)
+
+		vl := vars.NewVarsLoader(context.Background(), nil, nil, nil, nil, nil, nil)
+		vl.SetRegistry(registry)
+		vc := vars.NewVarsCtx(j2)
+		vc.UpdateChild("prefix", uo.FromMap(map[string]any{"v": "p"}))
+		err = vl.LoadVars(context.Background(), vc, &vs, nil, "")
+		return vc, &vs, err
+	}
+
+	vc, vs, err := load(`
+testSecrets:
+  name: "{{ prefix.v }}-x"
+`)
+	assert.NoError(t, err)
+	assert.True(t, vs.RenderedSensitive)
+	s, _, _ := vc.Vars.GetNestedString("secret", "name")
+	assert.Equal(t, "p-x", s)
+	i, _, _ := vc.Vars.GetNestedInt("secret", "count")
+	assert.Equal(t, int64(3), i)
+
+	_, _, err = load(`
+testSecrets:
+  other: x
+`)
+	assert.ErrorContains(t, err, "invalid testSecrets vars source")
+
+	_, _, err = load(`
+testSecrets:
+  name: missing
+`)
+	assert.ErrorContains(t, err, "not found")
+
+	_, _, err = load(`
+testSecrets:
+  name: missing
+ignoreMissing: true
+`)
+	assert.NoError(t, err)
+
+	_, _, err = load(`
+testSecrets:
+  name: fail
+`)
+	assert.ErrorContains(t, err, "backend failed")
+
+	_, _, err = load(`
+otherSecrets:
+  name: x
+`)
+	assert.ErrorContains(t, err, "unknown vars source type 'otherSecrets'")
+}
@@ -0,0 +1,162 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/encoding"
+)
+
+// ProtocolVersion is the version of the vars source plugin protocol. Plugins must report the same version in GetInfo.
+const ProtocolVersion = 1
+
+const serviceName = "kluctl.varsplugin.v1.VarsPlugin"
+
+// codecName is the gRPC content subtype used by the plugin protocol. Messages are plain JSON so that plugins can be
+// implemented without protobuf code generation.
+const codecName = "json"
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
+
+func (jsonCodec) Name() string {
+	return codecName
+}
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
+
+type GetInfoRequest struct {
+	ProtocolVersion int `json:"protocolVersion"`
+}
+
+type ProviderInfo struct {
+	// Key is the YAML key used inside vars sources, e.g. 'myCompanySecrets'
+	Key string `json:"key"`
+	// Schema is the JSON schema used to validate the vars source configuration
+	Schema map[string]any `json:"schema,omitempty"`
+	// Sensitive specifies whether loaded variables are sensitive by default
+	Sensitive bool `json:"sensitive,omitempty"`
+}
+
+type GetInfoResponse struct {
+	ProtocolVersion int            `json:"protocolVersion"`
+	Providers       []ProviderInfo `json:"providers"`
+}
+
+type LoadRequest struct {
+	Key           string         `json:"key"`
+	Config        map[string]any `json:"config"`
+	IgnoreMissing bool           `json:"ignoreMissing,omitempty"`
+}
+
+type LoadResponse struct {
+	Vars json.RawMessage `json:"vars,omitempty"`
+	// NotFound signals that the requested variables do not exist. Kluctl will then either fail or ignore the vars
+	// source, depending on 'ignoreMissing'.
+	NotFound bool `json:"notFound,omitempty"`
+	// Sensitive overrides ProviderInfo.Sensitive when set
+	Sensitive *bool `json:"sensitive,omitempty"`
+}
+
+type VarsPluginClient interface {
+	GetInfo(ctx context.Context, in *GetInfoRequest, opts ...grpc.CallOption) (*GetInfoResponse, error)
+	Load(ctx context.Context, in *LoadRequest, opts ...grpc.CallOption) (*LoadResponse, error)
+}
+
+type varsPluginClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewVarsPluginClient(cc grpc.ClientConnInterface) VarsPluginClient {
+	return &varsPluginClient{cc}
+}
+
+func (c *varsPluginClient) GetInfo(ctx context.Context, in *GetInfoRequest, opts ...grpc.CallOption) (*GetInfoResponse, error) {
+	out := new(GetInfoResponse)
+	opts = append([]grpc.CallOption{grpc.CallContentSubtype(codecName)}, opts...)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/GetInfo", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *varsPluginClient) Load(ctx context.Context, in *LoadRequest, opts ...grpc.CallOption) (*LoadResponse, error) {
+	out := new(LoadResponse)
+	opts = append([]grpc.CallOption{grpc.CallContentSubtype(codecName)}, opts...)
+	err := c.cc.Invoke(ctx, "/"+serviceName+"/Load", in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type VarsPluginServer interface {
+	GetInfo(ctx context.Context, in *GetInfoRequest) (*GetInfoResponse, error)
+	Load(ctx context.Context, in *LoadRequest) (*LoadResponse, error)
+}
+
+func RegisterVarsPluginServer(s grpc.ServiceRegistrar, srv VarsPluginServer) {
+	s.RegisterService(&varsPluginServiceDesc, srv)
+}
+
+func getInfoHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(GetInfoRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VarsPluginServer).GetInfo(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/GetInfo",
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(VarsPluginServer).GetInfo(ctx, req.(*GetInfoRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func loadHandler(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error) {
+	in := new(LoadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(VarsPluginServer).Load(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: "/" + serviceName + "/Load",
+	}
+	handler := func(ctx context.Context, req any) (any, error) {
+		return srv.(VarsPluginServer).Load(ctx, req.(*LoadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+var varsPluginServiceDesc = grpc.ServiceDesc{
+	ServiceName: serviceName,
+	HandlerType: (*VarsPluginServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetInfo",
+			Handler:    getInfoHandler,
+		},
+		{
+			MethodName: "Load",
+			Handler:    loadHandler,
+		},
+	},
+	Streams: []grpc.StreamDesc{},
+}
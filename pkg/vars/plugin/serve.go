@@ -0,0 +1,91 @@
+package plugin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"google.golang.org/grpc"
+	"io"
+	"net"
+	"os"
+)
+
+// ErrNotFound must be returned by Provider.Load when the requested variables do not exist.
+var ErrNotFound = errors.New("not found")
+
+// Provider is implemented by plugin authors to provide a single vars source type.
+type Provider interface {
+	Info() ProviderInfo
+	Load(ctx context.Context, config map[string]any) (map[string]any, error)
+}
+
+type server struct {
+	providers map[string]Provider
+}
+
+func (s *server) GetInfo(ctx context.Context, in *GetInfoRequest) (*GetInfoResponse, error) {
+	ret := &GetInfoResponse{
+		ProtocolVersion: ProtocolVersion,
+	}
+	for _, p := range s.providers {
+		ret.Providers = append(ret.Providers, p.Info())
+	}
+	return ret, nil
+}
+
+func (s *server) Load(ctx context.Context, in *LoadRequest) (*LoadResponse, error) {
+	p, ok := s.providers[in.Key]
+	if !ok {
+		return nil, fmt.Errorf("unknown vars source %s", in.Key)
+	}
+	vars, err := p.Load(ctx, in.Config)
+	if err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return &LoadResponse{NotFound: true}, nil
+		}
+		return nil, err
+	}
+	b, err := json.Marshal(vars)
+	if err != nil {
+		return nil, err
+	}
+	return &LoadResponse{Vars: b}, nil
+}
+
+func newServer(providers []Provider) (*grpc.Server, error) {
+	s := &server{
+		providers: map[string]Provider{},
+	}
+	for _, p := range providers {
+		key := p.Info().Key
+		if _, ok := s.providers[key]; ok {
+			return nil, fmt.Errorf("duplicate provider %s", key)
+		}
+		s.providers[key] = p
+	}
+
+	grpcServer := grpc.NewServer()
+	RegisterVarsPluginServer(grpcServer, s)
+	return grpcServer, nil
+}
+
+// ServeConn serves the given providers on a single connection until the connection is closed by the other side.
+func ServeConn(r io.ReadCloser, w io.WriteCloser, providers ...Provider) error {
+	grpcServer, err := newServer(providers)
+	if err != nil {
+		return err
+	}
+	conn := newStdioConn(r, w)
+	err = grpcServer.Serve(&singleConnListener{conn: conn})
+	if err != nil && !errors.Is(err, net.ErrClosed) {
+		return err
+	}
+	return nil
+}
+
+// Serve must be called from the main function of a plugin binary. It serves the given providers on stdin/stdout until
+// kluctl closes stdin. Plugins must never write anything else to stdout, logging must happen on stderr.
+func Serve(providers ...Provider) error {
+	return ServeConn(os.Stdin, os.Stdout, providers...)
+}
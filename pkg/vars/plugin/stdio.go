@@ -0,0 +1,85 @@
+package plugin
+
+import (
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+type stdioAddr struct{}
+
+func (stdioAddr) Network() string { return "stdio" }
+func (stdioAddr) String() string  { return "stdio" }
+
+// stdioConn turns a reader/writer pair (e.g. the stdin/stdout of a process) into a net.Conn so that it can be used
+// as gRPC transport.
+type stdioConn struct {
+	r io.ReadCloser
+	w io.WriteCloser
+
+	closeOnce sync.Once
+	closed    chan struct{}
+}
+
+func newStdioConn(r io.ReadCloser, w io.WriteCloser) *stdioConn {
+	return &stdioConn{
+		r:      r,
+		w:      w,
+		closed: make(chan struct{}),
+	}
+}
+
+func (c *stdioConn) Read(b []byte) (int, error) {
+	return c.r.Read(b)
+}
+
+func (c *stdioConn) Write(b []byte) (int, error) {
+	return c.w.Write(b)
+}
+
+func (c *stdioConn) Close() error {
+	var err error
+	c.closeOnce.Do(func() {
+		err = c.w.Close()
+		err2 := c.r.Close()
+		if err == nil {
+			err = err2
+		}
+		close(c.closed)
+	})
+	return err
+}
+
+func (c *stdioConn) LocalAddr() net.Addr                { return stdioAddr{} }
+func (c *stdioConn) RemoteAddr() net.Addr               { return stdioAddr{} }
+func (c *stdioConn) SetDeadline(t time.Time) error      { return nil }
+func (c *stdioConn) SetReadDeadline(t time.Time) error  { return nil }
+func (c *stdioConn) SetWriteDeadline(t time.Time) error { return nil }
+
+// singleConnListener hands out a single connection and then blocks until that connection gets closed, which
+// makes the gRPC server return from Serve.
+type singleConnListener struct {
+	conn *stdioConn
+	once sync.Once
+}
+
+func (l *singleConnListener) Accept() (net.Conn, error) {
+	var c net.Conn
+	l.once.Do(func() {
+		c = l.conn
+	})
+	if c != nil {
+		return c, nil
+	}
+	<-l.conn.closed
+	return nil, net.ErrClosed
+}
+
+func (l *singleConnListener) Close() error {
+	return l.conn.Close()
+}
+
+func (l *singleConnListener) Addr() net.Addr {
+	return stdioAddr{}
+}
@@ -0,0 +1,223 @@
+package vars
+
+import (
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/kluctl/kluctl/v2/pkg/yaml"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	ProvenanceActionSet      = "set"
+	ProvenanceActionOverride = "override"
+	ProvenanceActionIgnored  = "ignored"
+
+	maxProvenanceValueLen = 200
+)
+
+// ProvenanceRecorder records which vars source set or overrode each variable. It is shared between all vars loaders
+// and deployment projects of a single target.
+type ProvenanceRecorder struct {
+	mutex      sync.Mutex
+	provenance result.VarsProvenance
+	keyIndex   map[string]int
+}
+
+func NewProvenanceRecorder() *ProvenanceRecorder {
+	return &ProvenanceRecorder{
+		keyIndex: map[string]int{},
+	}
+}
+
+// Result returns a copy of everything recorded so far.
+func (r *ProvenanceRecorder) Result() *result.VarsProvenance {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.provenance.DeepCopy()
+}
+
+// RecordMerge records all leafs of newVars (merged at rootKey) as being set by the given source. existing is the vars
+// object before the merge happened.
+func (r *ProvenanceRecorder) RecordMerge(origin string, source string, existing *uo.UnstructuredObject, newVars *uo.UnstructuredObject, rootKey string, sensitive bool, noOverride bool) {
+	if r == nil {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var prefix uo.KeyPath
+	if rootKey != "" {
+		prefix = uo.KeyPath{rootKey}
+	}
+
+	walkProvenanceLeafs(newVars.Object, prefix, func(path uo.KeyPath, value any) {
+		_, exists, _ := existing.GetNestedField(path...)
+		action := ProvenanceActionSet
+		if exists {
+			if noOverride {
+				action = ProvenanceActionIgnored
+			} else {
+				action = ProvenanceActionOverride
+			}
+		}
+		r.addEntry(path.ToJsonPath(), result.VarsProvenanceEntry{
+			Origin:    origin,
+			Source:    source,
+			Action:    action,
+			Value:     formatProvenanceValue(value, sensitive),
+			Sensitive: sensitive,
+		})
+	})
+}
+
+// RecordSkipped records a vars source that was skipped due to its 'when' condition.
+func (r *ProvenanceRecorder) RecordSkipped(origin string, source string, when string) {
+	if r == nil {
+		return
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.provenance.Skipped = append(r.provenance.Skipped, result.VarsProvenanceSkipped{
+		Origin: origin,
+		Source: source,
+		When:   when,
+	})
+}
+
+func (r *ProvenanceRecorder) addEntry(path string, e result.VarsProvenanceEntry) {
+	i, ok := r.keyIndex[path]
+	if !ok {
+		i = len(r.provenance.Keys)
+		r.keyIndex[path] = i
+		r.provenance.Keys = append(r.provenance.Keys, result.VarsProvenanceKey{Path: path})
+	}
+	r.provenance.Keys[i].Entries = append(r.provenance.Keys[i].Entries, e)
+}
+
+// walkProvenanceLeafs only descends into dicts, as lists are always replaced as a whole when vars are merged.
+func walkProvenanceLeafs(o map[string]any, path uo.KeyPath, cb func(path uo.KeyPath, value any)) {
+	keys := make([]string, 0, len(o))
+	for k := range o {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		v := o[k]
+		p := append(append(uo.KeyPath{}, path...), k)
+		var m map[string]any
+		switch x := v.(type) {
+		case map[string]any:
+			m = x
+		case *uo.UnstructuredObject:
+			m = x.Object
+		}
+		if m != nil && len(m) != 0 {
+			walkProvenanceLeafs(m, p, cb)
+		} else {
+			cb(p, v)
+		}
+	}
+}
+
+func formatProvenanceValue(v any, sensitive bool) string {
+	if sensitive {
+		return "*****"
+	}
+	var s string
+	if x, ok := v.(string); ok {
+		s = x
+	} else {
+		x, err := yaml.WriteJsonString(v)
+		if err != nil {
+			s = fmt.Sprintf("%v", v)
+		} else {
+			s = strings.TrimSpace(x)
+		}
+	}
+	if len(s) > maxProvenanceValueLen {
+		s = s[:maxProvenanceValueLen] + "..."
+	}
+	return s
+}
+
+// DescribeVarsSource returns a short human-readable description of the vars source, e.g. 'file: vars/prod.yaml'.
+func DescribeVarsSource(source *types.VarsSource) string {
+	keys := source.GetSourceKeys()
+	if len(keys) != 1 {
+		return "invalid"
+	}
+	key := keys[0]
+
+	var detail string
+	switch key {
+	case "file":
+		detail = *source.File
+	case "git":
+		detail = fmt.Sprintf("%s, path=%s", source.Git.Url.String(), source.Git.Path)
+	case "gitFiles":
+		detail = source.GitFiles.Url.String()
+	case "oci":
+		detail = fmt.Sprintf("%s, path=%s", source.Oci.Url, source.Oci.Path)
+	case "clusterConfigMap":
+		detail = describeConfigMapOrSecret(source.ClusterConfigMap)
+	case "clusterSecret":
+		detail = describeConfigMapOrSecret(source.ClusterSecret)
+	case "clusterObject":
+		detail = fmt.Sprintf("%s %s/%s, path=%s", source.ClusterObject.Kind, source.ClusterObject.Namespace, source.ClusterObject.Name, source.ClusterObject.Path)
+	case "http":
+		// credentials must not end up in the command result
+		detail = source.Http.Url.Redacted()
+	case "command":
+		// arguments might contain secrets, so only the program is shown
+		if len(source.Command.Command) != 0 {
+			detail = source.Command.Command[0]
+		}
+	case "awsSecretsManager":
+		detail = source.AwsSecretsManager.SecretName
+	case "awsParameterStore":
+		if source.AwsParameterStore.Name != nil {
+			detail = *source.AwsParameterStore.Name
+		} else if source.AwsParameterStore.Path != nil {
+			detail = fmt.Sprintf("path=%s", *source.AwsParameterStore.Path)
+		}
+	case "awsAppConfig":
+		detail = fmt.Sprintf("%s/%s/%s", source.AwsAppConfig.Application, source.AwsAppConfig.Environment, source.AwsAppConfig.ConfigurationProfile)
+	case "gcpSecretManager":
+		detail = source.GcpSecretManager.SecretName
+	case "vault":
+		detail = fmt.Sprintf("%s, path=%s", source.Vault.Address, source.Vault.Path)
+	case "azureKeyVault":
+		detail = fmt.Sprintf("%s, secretName=%s", source.AzureKeyVault.VaultUri, source.AzureKeyVault.SecretName)
+	case "kluctlOutputs":
+		detail = describeKluctlOutputs(source.KluctlOutputs)
+	case "generatedSecrets":
+		var names []string
+		for _, s := range source.GeneratedSecrets.Secrets {
+			names = append(names, s.Name)
+		}
+		detail = strings.Join(names, ", ")
+	}
+	if detail == "" {
+		return key
+	}
+	return fmt.Sprintf("%s: %s", key, detail)
+}
+
+func describeConfigMapOrSecret(s *types.VarsSourceClusterConfigMapOrSecret) string {
+	name := s.Name
+	if name == "" {
+		var labels []string
+		for k, v := range s.Labels {
+			labels = append(labels, fmt.Sprintf("%s=%s", k, v))
+		}
+		sort.Strings(labels)
+		name = "{" + strings.Join(labels, ",") + "}"
+	}
+	return fmt.Sprintf("%s/%s, key=%s", s.Namespace, name, s.Key)
+}
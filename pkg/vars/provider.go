@@ -0,0 +1,201 @@
+package vars
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/kluctl/kluctl/v2/pkg/yaml"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// VarsSourceLoadRequest is passed to VarsSourceProvider.Load. Source is already rendered and its 'when' condition
+// was already evaluated.
+type VarsSourceLoadRequest struct {
+	VarsCtx       *VarsCtx
+	Source        *types.VarsSource
+	SearchDirs    []string
+	RootKey       string
+	IgnoreMissing bool
+}
+
+// VarsSourceProvider implements a single vars source type, identified by its YAML key inside the vars source.
+type VarsSourceProvider interface {
+	// Key returns the YAML key of the vars source type, e.g. 'file' or 'vault'
+	Key() string
+
+	// Schema returns the JSON schema of the vars source configuration. Built-in providers return nil, as their
+	// configuration is already defined and validated by types.VarsSource.
+	Schema() map[string]any
+
+	// Load loads the variables. The returned value must be a *uo.UnstructuredObject unless 'targetPath' is set.
+	// The returned bool specifies whether the loaded variables are considered sensitive by default.
+	Load(ctx context.Context, v *VarsLoader, req *VarsSourceLoadRequest) (any, bool, error)
+}
+
+type VarsSourceRegistry struct {
+	providers map[string]VarsSourceProvider
+	mutex     sync.Mutex
+}
+
+func NewVarsSourceRegistry() *VarsSourceRegistry {
+	return &VarsSourceRegistry{
+		providers: map[string]VarsSourceProvider{},
+	}
+}
+
+// NewVarsSourceRegistryWithBuiltins returns a new registry that has all built-in vars sources registered.
+func NewVarsSourceRegistryWithBuiltins() *VarsSourceRegistry {
+	r := NewVarsSourceRegistry()
+	for _, p := range builtinProviders {
+		_ = r.Register(p)
+	}
+	return r
+}
+
+func (r *VarsSourceRegistry) Register(p VarsSourceProvider) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, ok := r.providers[p.Key()]; ok {
+		return fmt.Errorf("vars source %s is already registered", p.Key())
+	}
+	r.providers[p.Key()] = p
+	return nil
+}
+
+func (r *VarsSourceRegistry) Get(key string) VarsSourceProvider {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.providers[key]
+}
+
+func (r *VarsSourceRegistry) Keys() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	ret := make([]string, 0, len(r.providers))
+	for k := range r.providers {
+		ret = append(ret, k)
+	}
+	sort.Strings(ret)
+	return ret
+}
+
+// CheckVarsSources reports all vars sources in varsList with a type that is not registered, e.g. due to a typo in the
+// key of a built-in vars source. path is the location of varsList inside the YAML document b.
+func (r *VarsSourceRegistry) CheckVarsSources(file string, b []byte, path []string, varsList []types.VarsSource) yaml.PositionedErrors {
+	var ret yaml.PositionedErrors
+	for i, source := range varsList {
+		// only plugin keys can be unknown, built-in keys are handled by types.VarsSource
+		keys := make([]string, 0, len(source.Plugins))
+		for k := range source.Plugins {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			if r.Get(k) != nil {
+				continue
+			}
+			p := append(append([]string{}, path...), strconv.Itoa(i))
+			ret = append(ret, yaml.NewPositionedError(file, b, p, k, fmt.Sprintf("unknown vars source type '%s'", k)))
+		}
+	}
+	return ret
+}
+
+var defaultVarsSourceRegistry = NewVarsSourceRegistryWithBuiltins()
+
+type builtinProvider struct {
+	key  string
+	load func(ctx context.Context, v *VarsLoader, req *VarsSourceLoadRequest) (any, bool, error)
+}
+
+func (p *builtinProvider) Key() string {
+	return p.key
+}
+
+func (p *builtinProvider) Schema() map[string]any {
+	return nil
+}
+
+func (p *builtinProvider) Load(ctx context.Context, v *VarsLoader, req *VarsSourceLoadRequest) (any, bool, error) {
+	return p.load(ctx, v, req)
+}
+
+func withSensitive(o *uo.UnstructuredObject, err error) (any, bool, error) {
+	return o, true, err
+}
+
+func withoutSensitive[T any](o T, err error) (any, bool, error) {
+	return o, false, err
+}
+
+var builtinProviders = []VarsSourceProvider{
+	&builtinProvider{key: "values", load: func(ctx context.Context, v *VarsLoader, req *VarsSourceLoadRequest) (any, bool, error) {
+		if req.RootKey != "" {
+			return uo.FromMap(map[string]interface{}{
+				req.RootKey: req.Source.Values.Object,
+			}), false, nil
+		}
+		return req.Source.Values, false, nil
+	}},
+	&builtinProvider{key: "file", load: func(ctx context.Context, v *VarsLoader, req *VarsSourceLoadRequest) (any, bool, error) {
+		return v.loadFile(req.VarsCtx, *req.Source.File, req.Source.Format, req.IgnoreMissing, req.SearchDirs)
+	}},
+	&builtinProvider{key: "git", load: func(ctx context.Context, v *VarsLoader, req *VarsSourceLoadRequest) (any, bool, error) {
+		return v.loadGit(ctx, req.VarsCtx, req.Source.Git, req.Source.Format, req.IgnoreMissing)
+	}},
+	&builtinProvider{key: "gitFiles", load: func(ctx context.Context, v *VarsLoader, req *VarsSourceLoadRequest) (any, bool, error) {
+		return v.loadGitFiles(ctx, req.VarsCtx, req.Source.GitFiles, req.IgnoreMissing)
+	}},
+	&builtinProvider{key: "oci", load: func(ctx context.Context, v *VarsLoader, req *VarsSourceLoadRequest) (any, bool, error) {
+		return v.loadOci(req.VarsCtx, req.Source.Oci, req.Source.Format, req.IgnoreMissing)
+	}},
+	&builtinProvider{key: "clusterConfigMap", load: func(ctx context.Context, v *VarsLoader, req *VarsSourceLoadRequest) (any, bool, error) {
+		return withoutSensitive(v.loadFromK8sConfigMapOrSecret(req.VarsCtx, *req.Source.ClusterConfigMap, "ConfigMap", req.IgnoreMissing, false))
+	}},
+	&builtinProvider{key: "clusterSecret", load: func(ctx context.Context, v *VarsLoader, req *VarsSourceLoadRequest) (any, bool, error) {
+		return withSensitive(v.loadFromK8sConfigMapOrSecret(req.VarsCtx, *req.Source.ClusterSecret, "Secret", req.IgnoreMissing, true))
+	}},
+	&builtinProvider{key: "clusterObject", load: func(ctx context.Context, v *VarsLoader, req *VarsSourceLoadRequest) (any, bool, error) {
+		o, err := v.loadFromK8sObject(req.VarsCtx, *req.Source.ClusterObject, req.IgnoreMissing)
+		return o, true, err
+	}},
+	&builtinProvider{key: "systemEnvVars", load: func(ctx context.Context, v *VarsLoader, req *VarsSourceLoadRequest) (any, bool, error) {
+		return withSensitive(v.loadSystemEnvs(req.VarsCtx, req.Source, req.IgnoreMissing, req.RootKey))
+	}},
+	&builtinProvider{key: "http", load: func(ctx context.Context, v *VarsLoader, req *VarsSourceLoadRequest) (any, bool, error) {
+		return v.loadHttp(req.VarsCtx, req.Source, req.IgnoreMissing)
+	}},
+	&builtinProvider{key: "command", load: func(ctx context.Context, v *VarsLoader, req *VarsSourceLoadRequest) (any, bool, error) {
+		return withoutSensitive(v.loadCommand(req.VarsCtx, req.Source.Command, req.SearchDirs))
+	}},
+	&builtinProvider{key: "awsSecretsManager", load: func(ctx context.Context, v *VarsLoader, req *VarsSourceLoadRequest) (any, bool, error) {
+		return withSensitive(v.loadAwsSecretsManager(req.VarsCtx, req.Source, req.IgnoreMissing))
+	}},
+	&builtinProvider{key: "awsParameterStore", load: func(ctx context.Context, v *VarsLoader, req *VarsSourceLoadRequest) (any, bool, error) {
+		return v.loadAwsParameterStore(req.VarsCtx, req.Source, req.IgnoreMissing)
+	}},
+	&builtinProvider{key: "awsAppConfig", load: func(ctx context.Context, v *VarsLoader, req *VarsSourceLoadRequest) (any, bool, error) {
+		return withSensitive(v.loadAwsAppConfig(req.VarsCtx, req.Source, req.IgnoreMissing))
+	}},
+	&builtinProvider{key: "gcpSecretManager", load: func(ctx context.Context, v *VarsLoader, req *VarsSourceLoadRequest) (any, bool, error) {
+		return withSensitive(v.loadGcpSecretManager(req.VarsCtx, req.Source, req.IgnoreMissing))
+	}},
+	&builtinProvider{key: "vault", load: func(ctx context.Context, v *VarsLoader, req *VarsSourceLoadRequest) (any, bool, error) {
+		return withSensitive(v.loadVault(req.VarsCtx, req.Source, req.IgnoreMissing))
+	}},
+	&builtinProvider{key: "azureKeyVault", load: func(ctx context.Context, v *VarsLoader, req *VarsSourceLoadRequest) (any, bool, error) {
+		return withSensitive(v.loadAzureKeyVault(req.VarsCtx, req.Source, req.IgnoreMissing))
+	}},
+	&builtinProvider{key: "kluctlOutputs", load: func(ctx context.Context, v *VarsLoader, req *VarsSourceLoadRequest) (any, bool, error) {
+		return withoutSensitive(v.loadKluctlOutputs(req.Source.KluctlOutputs, req.IgnoreMissing))
+	}},
+	&builtinProvider{key: "generatedSecrets", load: func(ctx context.Context, v *VarsLoader, req *VarsSourceLoadRequest) (any, bool, error) {
+		return withSensitive(v.loadGeneratedSecrets(req.Source.GeneratedSecrets))
+	}},
+}
@@ -0,0 +1,33 @@
+package vars
+
+import (
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/yaml"
+	"github.com/stretchr/testify/assert"
+	"testing"
+)
+
+func TestCheckVarsSources(t *testing.T) {
+	b := []byte(`deployments:
+  - path: app
+    vars:
+      - file: vars.yaml
+      - clustrConfigMap:
+          name: cm
+      - myPlugin:
+          x: y
+`)
+	var c types.DeploymentProjectConfig
+	assert.NoError(t, yaml.ReadYamlBytes(b, &c))
+
+	r := NewVarsSourceRegistryWithBuiltins()
+	assert.NoError(t, r.Register(&builtinProvider{key: "myPlugin"}))
+
+	perrs := r.CheckVarsSources("deployment.yml", b, []string{"deployments", "0", "vars"}, c.Deployments[0].Vars)
+	assert.Equal(t, yaml.PositionedErrors{
+		{File: "deployment.yml", Line: 5, Column: 9, Path: "deployments[0].vars[1].clustrConfigMap", Message: "unknown vars source type 'clustrConfigMap'"},
+	}, perrs)
+	assert.EqualError(t, perrs, "deployment.yml:5:9: deployments[0].vars[1].clustrConfigMap: unknown vars source type 'clustrConfigMap'")
+
+	assert.Empty(t, r.CheckVarsSources("deployment.yml", b, []string{"vars"}, c.Vars))
+}
@@ -10,6 +10,12 @@ import (
 type VarsCtx struct {
 	J2   *jinja2.Jinja2
 	Vars *uo.UnstructuredObject
+
+	// J2Opts are passed to all render calls, after the call specific options
+	J2Opts []jinja2.Jinja2Opt
+
+	// Sensitive is true if vars from a sensitive vars source were merged into Vars
+	Sensitive bool
 }
 
 func NewVarsCtx(j2 *jinja2.Jinja2) *VarsCtx {
@@ -22,12 +28,21 @@ func NewVarsCtx(j2 *jinja2.Jinja2) *VarsCtx {
 
 func (vc *VarsCtx) Copy() *VarsCtx {
 	cp := &VarsCtx{
-		J2:   vc.J2,
-		Vars: vc.Vars.Clone(),
+		J2:        vc.J2,
+		Vars:      vc.Vars.Clone(),
+		J2Opts:    vc.J2Opts,
+		Sensitive: vc.Sensitive,
 	}
 	return cp
 }
 
+func (vc *VarsCtx) renderOpts(opts ...jinja2.Jinja2Opt) []jinja2.Jinja2Opt {
+	ret := make([]jinja2.Jinja2Opt, 0, len(opts)+len(vc.J2Opts))
+	ret = append(ret, opts...)
+	ret = append(ret, vc.J2Opts...)
+	return ret
+}
+
 func (vc *VarsCtx) Update(vars *uo.UnstructuredObject) {
 	vc.Vars.Merge(vars)
 }
@@ -50,10 +65,10 @@ func (vc *VarsCtx) RenderString(t string, searchDirs []string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	return vc.J2.RenderString(t,
+	return vc.J2.RenderString(t, vc.renderOpts(
 		jinja2.WithSearchDirs(searchDirs),
 		jinja2.WithGlobals(globals),
-	)
+	)...)
 }
 
 func (vc *VarsCtx) RenderStruct(o interface{}) (bool, error) {
@@ -61,7 +76,7 @@ func (vc *VarsCtx) RenderStruct(o interface{}) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	return vc.J2.RenderStruct(o, jinja2.WithGlobals(globals))
+	return vc.J2.RenderStruct(o, vc.renderOpts(jinja2.WithGlobals(globals))...)
 }
 
 func (vc *VarsCtx) RenderFile(p string, searchDirs []string) (string, error) {
@@ -69,10 +84,10 @@ func (vc *VarsCtx) RenderFile(p string, searchDirs []string) (string, error) {
 	if err != nil {
 		return "", err
 	}
-	ret, err := vc.J2.RenderFile(p,
+	ret, err := vc.J2.RenderFile(p, vc.renderOpts(
 		jinja2.WithSearchDirs(searchDirs),
 		jinja2.WithGlobals(globals),
-	)
+	)...)
 	if err != nil {
 		return "", err
 	}
@@ -80,16 +95,21 @@ func (vc *VarsCtx) RenderFile(p string, searchDirs []string) (string, error) {
 	return ret, nil
 }
 
-func (vc *VarsCtx) RenderYamlFile(p string, searchDirs []string, out interface{}) error {
+// RenderYamlFile renders the given file and parses the result into out. The rendered YAML is returned as well.
+func (vc *VarsCtx) RenderYamlFile(p string, searchDirs []string, out interface{}) (string, error) {
 	rendered, err := vc.RenderFile(p, searchDirs)
 	if err != nil {
-		return err
+		return "", err
 	}
 	err = yaml.ReadYamlString(rendered, out)
 	if err != nil {
-		return err
+		// try to report the error(s) with line/column information
+		if perrs := yaml.ValidateYamlWithSchema(p, []byte(rendered), out); len(perrs) != 0 {
+			return "", perrs
+		}
+		return "", err
 	}
-	return nil
+	return rendered, nil
 }
 
 func (vc *VarsCtx) RenderDirectory(sourceDir string, targetDir string, excludePatterns []string, searchDirs []string, templateIgnoreRoot string) error {
@@ -97,7 +117,7 @@ func (vc *VarsCtx) RenderDirectory(sourceDir string, targetDir string, excludePa
 	if err != nil {
 		return err
 	}
-	return vc.J2.RenderDirectory(sourceDir, targetDir, excludePatterns, jinja2.WithGlobals(globals), jinja2.WithSearchDirs(searchDirs), jinja2.WithTemplateIgnoreRootDir(templateIgnoreRoot))
+	return vc.J2.RenderDirectory(sourceDir, targetDir, excludePatterns, vc.renderOpts(jinja2.WithGlobals(globals), jinja2.WithSearchDirs(searchDirs), jinja2.WithTemplateIgnoreRootDir(templateIgnoreRoot))...)
 }
 
 func (vc *VarsCtx) CheckConditional(c string) (bool, error) {
@@ -109,7 +129,7 @@ func (vc *VarsCtx) CheckConditional(c string) (bool, error) {
 	if err != nil {
 		return false, err
 	}
-	c, err = kluctl_jinja2.RenderConditional(vc.J2, m, c)
+	c, err = kluctl_jinja2.RenderConditional(vc.J2, m, c, vc.J2Opts...)
 	if err != nil {
 		return false, err
 	}
@@ -0,0 +1,328 @@
+package vars
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/status"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+type VarsCacheMode string
+
+const (
+	// VarsCacheUse uses cached values that are younger than the source's cacheTTL
+	VarsCacheUse VarsCacheMode = "use"
+	// VarsCacheRefresh always reloads values, but still updates the cache and falls back to stale values on errors
+	VarsCacheRefresh VarsCacheMode = "refresh"
+	// VarsCacheOff disables the cache completely
+	VarsCacheOff VarsCacheMode = "off"
+)
+
+func ParseVarsCacheMode(s string) (VarsCacheMode, error) {
+	switch VarsCacheMode(s) {
+	case "":
+		return VarsCacheUse, nil
+	case VarsCacheUse, VarsCacheRefresh, VarsCacheOff:
+		return VarsCacheMode(s), nil
+	default:
+		return "", fmt.Errorf("invalid vars cache mode '%s', must be one of 'use', 'refresh' or 'off'", s)
+	}
+}
+
+// cacheableVarsSources contains the vars source types that support 'cacheTTL'
+var cacheableVarsSources = map[string]bool{
+	"http":              true,
+	"awsSecretsManager": true,
+	"awsParameterStore": true,
+	"awsAppConfig":      true,
+	"gcpSecretManager":  true,
+	"azureKeyVault":     true,
+	"vault":             true,
+}
+
+type VarsCacheEntry struct {
+	Time      time.Time       `json:"time"`
+	TTL       time.Duration   `json:"ttl"`
+	Sensitive bool            `json:"sensitive"`
+	IsObject  bool            `json:"isObject"`
+	Value     json.RawMessage `json:"value"`
+}
+
+func newVarsCacheEntry(value any, ttl time.Duration, sensitive bool) (*VarsCacheEntry, error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	_, isObject := value.(*uo.UnstructuredObject)
+	return &VarsCacheEntry{
+		Time:      time.Now(),
+		TTL:       ttl,
+		Sensitive: sensitive,
+		IsObject:  isObject,
+		Value:     b,
+	}, nil
+}
+
+// isEvictable returns true if the entry is older than its TTL plus maxStale, meaning that it's not even useful as
+// stale fallback anymore
+func (e *VarsCacheEntry) isEvictable(maxStale time.Duration) bool {
+	return time.Since(e.Time) > e.TTL+maxStale
+}
+
+func (e *VarsCacheEntry) decode() (any, error) {
+	if e.IsObject {
+		o := uo.New()
+		err := json.Unmarshal(e.Value, o)
+		if err != nil {
+			return nil, err
+		}
+		return o, nil
+	}
+	var v any
+	err := json.Unmarshal(e.Value, &v)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+// VarsCache stores values loaded by remote vars sources, keyed by a hash of the cache scope and the rendered vars
+// source.
+type VarsCache interface {
+	// Get returns the cached entry or nil if it does not exist
+	Get(key string) (*VarsCacheEntry, error)
+	Put(key string, e *VarsCacheEntry) error
+
+	// CanStoreSensitive returns true if sensitive values may be stored in the cache
+	CanStoreSensitive() bool
+}
+
+// MemoryVarsCache is used by the controller, which reuses the same cache for all reconciliations. Entries are
+// evicted when they are older than their TTL plus maxStale. As the controller serves multiple tenants, the
+// VarsLoader must be configured with a cache scope that is unique per KluctlDeployment.
+type MemoryVarsCache struct {
+	maxStale time.Duration
+	entries  map[string]*VarsCacheEntry
+	mutex    sync.Mutex
+}
+
+func NewMemoryVarsCache(maxStale time.Duration) *MemoryVarsCache {
+	return &MemoryVarsCache{
+		maxStale: maxStale,
+		entries:  map[string]*VarsCacheEntry{},
+	}
+}
+
+func (c *MemoryVarsCache) Get(key string) (*VarsCacheEntry, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, nil
+	}
+	if e.isEvictable(c.maxStale) {
+		delete(c.entries, key)
+		return nil, nil
+	}
+	return e, nil
+}
+
+func (c *MemoryVarsCache) Put(key string, e *VarsCacheEntry) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for k, e2 := range c.entries {
+		if e2.isEvictable(c.maxStale) {
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = e
+	return nil
+}
+
+func (c *MemoryVarsCache) CanStoreSensitive() bool {
+	return true
+}
+
+// FileVarsCache is used by the CLI and stores entries inside the kluctl cache dir. If an encryption key is
+// configured, all entries are encrypted with AES-GCM. Without an encryption key, entries are stored unencrypted
+// and only values of non-sensitive sources are stored.
+type FileVarsCache struct {
+	dir  string
+	aead cipher.AEAD
+}
+
+func NewFileVarsCache(dir string, encryptionKey string) (*FileVarsCache, error) {
+	c := &FileVarsCache{
+		dir: dir,
+	}
+	if encryptionKey != "" {
+		key := sha256.Sum256([]byte(encryptionKey))
+		block, err := aes.NewCipher(key[:])
+		if err != nil {
+			return nil, err
+		}
+		c.aead, err = cipher.NewGCM(block)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return c, nil
+}
+
+func (c *FileVarsCache) path(key string) string {
+	if c.aead != nil {
+		return filepath.Join(c.dir, key+".enc")
+	}
+	return filepath.Join(c.dir, key+".json")
+}
+
+func (c *FileVarsCache) Get(key string) (*VarsCacheEntry, error) {
+	b, err := os.ReadFile(c.path(key))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if c.aead != nil {
+		ns := c.aead.NonceSize()
+		if len(b) < ns {
+			return nil, fmt.Errorf("invalid vars cache entry %s", key)
+		}
+		b, err = c.aead.Open(nil, b[:ns], b[ns:], []byte(key))
+		if err != nil {
+			// most likely the encryption key has changed, so treat it as a cache miss
+			return nil, nil
+		}
+	}
+	var e VarsCacheEntry
+	err = json.Unmarshal(b, &e)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+func (c *FileVarsCache) Put(key string, e *VarsCacheEntry) error {
+	b, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	if c.aead != nil {
+		nonce := make([]byte, c.aead.NonceSize())
+		_, err = rand.Read(nonce)
+		if err != nil {
+			return err
+		}
+		b = c.aead.Seal(nonce, nonce, b, []byte(key))
+	}
+	err = os.MkdirAll(c.dir, 0o700)
+	if err != nil {
+		return err
+	}
+
+	// write to a temporary file first so that concurrent kluctl invocations never see partial entries
+	f, err := os.CreateTemp(c.dir, key+".tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(f.Name())
+	_, err = f.Write(b)
+	_ = f.Close()
+	if err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), c.path(key))
+}
+
+func (c *FileVarsCache) CanStoreSensitive() bool {
+	return c.aead != nil
+}
+
+func buildVarsCacheKey(scope string, source *types.VarsSource) (string, error) {
+	b, err := json.Marshal(source)
+	if err != nil {
+		return "", err
+	}
+	return utils.Sha256String(scope + "\n" + string(b)), nil
+}
+
+// loadWithCache calls provider.Load and handles caching if the source specifies 'cacheTTL'. The returned sensitive
+// flag already respects the 'sensitive' override of the source.
+func (v *VarsLoader) loadWithCache(ctx context.Context, provider VarsSourceProvider, req *VarsSourceLoadRequest) (any, bool, error) {
+	source := req.Source
+	doLoad := func() (any, bool, error) {
+		value, sensitive, err := provider.Load(ctx, v, req)
+		if source.Sensitive != nil {
+			// override the default
+			sensitive = *source.Sensitive
+		}
+		return value, sensitive, err
+	}
+
+	if source.CacheTTL == nil {
+		return doLoad()
+	}
+	if !cacheableVarsSources[provider.Key()] {
+		return nil, false, fmt.Errorf("cacheTTL is not supported for vars source type '%s'", provider.Key())
+	}
+	if v.cache == nil || v.cacheMode == VarsCacheOff {
+		return doLoad()
+	}
+
+	key, err := buildVarsCacheKey(v.cacheScope, source)
+	if err != nil {
+		return nil, false, err
+	}
+	desc := DescribeVarsSource(source)
+
+	cached, err := v.cache.Get(key)
+	if err != nil {
+		status.Warningf(ctx, "Failed to read vars cache for %s: %s", desc, err.Error())
+		cached = nil
+	}
+	if cached != nil && v.cacheMode == VarsCacheUse && time.Since(cached.Time) < source.CacheTTL.Duration {
+		value, err := cached.decode()
+		if err == nil {
+			return value, cached.Sensitive, nil
+		}
+		status.Warningf(ctx, "Failed to decode cached vars for %s: %s", desc, err.Error())
+		cached = nil
+	}
+
+	value, sensitive, err := doLoad()
+	if err != nil {
+		if cached == nil {
+			return nil, false, err
+		}
+		cachedValue, err2 := cached.decode()
+		if err2 != nil {
+			return nil, false, err
+		}
+		status.Warningf(ctx, "Failed to load %s, using cached values from %s ago: %s", desc, time.Since(cached.Time).Round(time.Second), err.Error())
+		return cachedValue, cached.Sensitive, nil
+	}
+
+	if sensitive && !v.cache.CanStoreSensitive() {
+		return value, sensitive, nil
+	}
+	e, err := newVarsCacheEntry(value, source.CacheTTL.Duration, sensitive)
+	if err == nil {
+		err = v.cache.Put(key, e)
+	}
+	if err != nil {
+		status.Warningf(ctx, "Failed to write vars cache for %s: %s", desc, err.Error())
+	}
+	return value, sensitive, nil
+}
@@ -0,0 +1,185 @@
+package vars
+
+import (
+	"context"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+	"time"
+)
+
+type fakeCacheProvider struct {
+	key       string
+	calls     int
+	value     string
+	sensitive bool
+	err       error
+}
+
+func (p *fakeCacheProvider) Key() string {
+	return p.key
+}
+
+func (p *fakeCacheProvider) Schema() map[string]any {
+	return nil
+}
+
+func (p *fakeCacheProvider) Load(ctx context.Context, v *VarsLoader, req *VarsSourceLoadRequest) (any, bool, error) {
+	p.calls++
+	if p.err != nil {
+		return nil, false, p.err
+	}
+	return uo.FromMap(map[string]any{"v": p.value}), p.sensitive, nil
+}
+
+func newCacheTestRequest(ttl time.Duration) *VarsSourceLoadRequest {
+	return &VarsSourceLoadRequest{
+		Source: &types.VarsSource{
+			Http: &types.VarsSourceHttp{
+				Url: types.YamlUrl{},
+			},
+			CacheTTL: &metav1.Duration{Duration: ttl},
+		},
+	}
+}
+
+func getCachedTestValue(t *testing.T, v any) string {
+	s, _, err := v.(*uo.UnstructuredObject).GetNestedString("v")
+	assert.NoError(t, err)
+	return s
+}
+
+func TestVarsCacheTTL(t *testing.T) {
+	ctx := context.Background()
+	p := &fakeCacheProvider{key: "http", value: "a"}
+	v := &VarsLoader{}
+	v.SetCache(NewMemoryVarsCache(time.Hour), VarsCacheUse, "")
+
+	req := newCacheTestRequest(time.Hour)
+	r, _, err := v.loadWithCache(ctx, p, req)
+	assert.NoError(t, err)
+	assert.Equal(t, "a", getCachedTestValue(t, r))
+
+	p.value = "b"
+	r, _, err = v.loadWithCache(ctx, p, req)
+	assert.NoError(t, err)
+	assert.Equal(t, "a", getCachedTestValue(t, r))
+	assert.Equal(t, 1, p.calls)
+
+	v.SetCache(v.cache, VarsCacheRefresh, "")
+	r, _, err = v.loadWithCache(ctx, p, req)
+	assert.NoError(t, err)
+	assert.Equal(t, "b", getCachedTestValue(t, r))
+	assert.Equal(t, 2, p.calls)
+
+	v.SetCache(v.cache, VarsCacheOff, "")
+	p.value = "c"
+	r, _, err = v.loadWithCache(ctx, p, req)
+	assert.NoError(t, err)
+	assert.Equal(t, "c", getCachedTestValue(t, r))
+	assert.Equal(t, 3, p.calls)
+}
+
+func TestVarsCacheStaleFallback(t *testing.T) {
+	ctx := context.Background()
+	p := &fakeCacheProvider{key: "http", value: "a"}
+	v := &VarsLoader{}
+	v.SetCache(NewMemoryVarsCache(time.Hour), VarsCacheUse, "")
+
+	req := newCacheTestRequest(time.Nanosecond)
+	_, _, err := v.loadWithCache(ctx, p, req)
+	assert.NoError(t, err)
+
+	p.err = fmt.Errorf("backend down")
+	r, _, err := v.loadWithCache(ctx, p, req)
+	assert.NoError(t, err)
+	assert.Equal(t, "a", getCachedTestValue(t, r))
+	assert.Equal(t, 2, p.calls)
+}
+
+func TestVarsCacheSensitive(t *testing.T) {
+	ctx := context.Background()
+	p := &fakeCacheProvider{key: "vault", value: "a", sensitive: true}
+
+	c, err := NewFileVarsCache(t.TempDir(), "")
+	assert.NoError(t, err)
+	v := &VarsLoader{}
+	v.SetCache(c, VarsCacheUse, "")
+
+	req := newCacheTestRequest(time.Hour)
+	key, err := buildVarsCacheKey("", req.Source)
+	assert.NoError(t, err)
+
+	_, _, err = v.loadWithCache(ctx, p, req)
+	assert.NoError(t, err)
+	e, err := c.Get(key)
+	assert.NoError(t, err)
+	assert.Nil(t, e)
+
+	c, err = NewFileVarsCache(t.TempDir(), "my-key")
+	assert.NoError(t, err)
+	v.SetCache(c, VarsCacheUse, "")
+	_, _, err = v.loadWithCache(ctx, p, req)
+	assert.NoError(t, err)
+	e, err = c.Get(key)
+	assert.NoError(t, err)
+	assert.NotNil(t, e)
+	assert.True(t, e.Sensitive)
+
+	p.value = "b"
+	r, sensitive, err := v.loadWithCache(ctx, p, req)
+	assert.NoError(t, err)
+	assert.True(t, sensitive)
+	assert.Equal(t, "a", getCachedTestValue(t, r))
+}
+
+func TestVarsCacheNotSupported(t *testing.T) {
+	p := &fakeCacheProvider{key: "file"}
+	v := &VarsLoader{}
+	_, _, err := v.loadWithCache(context.Background(), p, newCacheTestRequest(time.Hour))
+	assert.ErrorContains(t, err, "cacheTTL is not supported")
+}
+
+func TestVarsCacheScope(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryVarsCache(time.Hour)
+	req := newCacheTestRequest(time.Hour)
+
+	p1 := &fakeCacheProvider{key: "vault", value: "a", sensitive: true}
+	v1 := &VarsLoader{}
+	v1.SetCache(c, VarsCacheUse, "kluctldeployment=ns1/d1")
+	_, _, err := v1.loadWithCache(ctx, p1, req)
+	assert.NoError(t, err)
+
+	// the same source loaded for another deployment must not see the cached values
+	p2 := &fakeCacheProvider{key: "vault", value: "b", sensitive: true}
+	v2 := &VarsLoader{}
+	v2.SetCache(c, VarsCacheUse, "kluctldeployment=ns2/d2")
+	r, _, err := v2.loadWithCache(ctx, p2, req)
+	assert.NoError(t, err)
+	assert.Equal(t, "b", getCachedTestValue(t, r))
+	assert.Equal(t, 1, p2.calls)
+}
+
+func TestMemoryVarsCacheEviction(t *testing.T) {
+	c := NewMemoryVarsCache(time.Minute)
+
+	old := &VarsCacheEntry{Time: time.Now().Add(-time.Hour), TTL: time.Minute}
+	stale := &VarsCacheEntry{Time: time.Now().Add(-90 * time.Second), TTL: time.Minute}
+	assert.NoError(t, c.Put("old", old))
+	assert.NoError(t, c.Put("stale", stale))
+
+	e, err := c.Get("stale")
+	assert.NoError(t, err)
+	assert.Same(t, stale, e)
+	e, err = c.Get("old")
+	assert.NoError(t, err)
+	assert.Nil(t, e)
+
+	assert.NoError(t, c.Put("new", &VarsCacheEntry{Time: time.Now(), TTL: time.Minute}))
+	assert.NotContains(t, c.entries, "old")
+	assert.Contains(t, c.entries, "stale")
+}
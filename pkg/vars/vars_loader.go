@@ -3,23 +3,24 @@ package vars
 import (
 	"context"
 	"encoding/base64"
+	"encoding/json"
 	errors2 "errors"
 	"fmt"
 	types2 "github.com/aws/aws-sdk-go-v2/service/secretsmanager/types"
-	"github.com/getsops/sops/v3/cmd/sops/formats"
 	"github.com/kluctl/go-jinja2"
 	"github.com/kluctl/kluctl/v2/pkg/clouds/aws"
 	"github.com/kluctl/kluctl/v2/pkg/clouds/azure"
 	"github.com/kluctl/kluctl/v2/pkg/clouds/gcp"
+	"github.com/kluctl/kluctl/v2/pkg/generated_secrets"
 	"github.com/kluctl/kluctl/v2/pkg/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/repocache"
-	"github.com/kluctl/kluctl/v2/pkg/sops"
 	"github.com/kluctl/kluctl/v2/pkg/sops/decryptor"
 	"github.com/kluctl/kluctl/v2/pkg/status"
 	"github.com/kluctl/kluctl/v2/pkg/types"
 	k8s2 "github.com/kluctl/kluctl/v2/pkg/types/k8s"
 	"github.com/kluctl/kluctl/v2/pkg/utils"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/kluctl/kluctl/v2/pkg/vars/file_formats"
 	"github.com/kluctl/kluctl/v2/pkg/vars/vault"
 	"github.com/kluctl/kluctl/v2/pkg/yaml"
 	"k8s.io/apimachinery/pkg/api/errors"
@@ -36,32 +37,98 @@ type usernamePassword struct {
 }
 
 type VarsLoader struct {
-	ctx  context.Context
-	k    *k8s.K8sCluster
-	sops *decryptor.Decryptor
-	rp   *repocache.GitRepoCache
-	aws  aws.AwsClientFactory
-	gcp  gcp.GcpClientFactory
+	ctx   context.Context
+	k     *k8s.K8sCluster
+	sops  *decryptor.Decryptor
+	rp    *repocache.GitRepoCache
+	ociRp *repocache.OciRepoCache
+	aws   aws.AwsClientFactory
+	gcp   gcp.GcpClientFactory
+
+	allowExec            bool
+	allowVaultTokenFiles bool
+	registry             *VarsSourceRegistry
+	provenance           *ProvenanceRecorder
+
+	generatedSecrets *generated_secrets.Store
+
+	cache      VarsCache
+	cacheMode  VarsCacheMode
+	cacheScope string
 
 	credentialsCache map[string]usernamePassword
 }
 
-func NewVarsLoader(ctx context.Context, k *k8s.K8sCluster, sops *decryptor.Decryptor, rp *repocache.GitRepoCache, aws aws.AwsClientFactory, gcp gcp.GcpClientFactory) *VarsLoader {
+func NewVarsLoader(ctx context.Context, k *k8s.K8sCluster, sops *decryptor.Decryptor, rp *repocache.GitRepoCache, ociRp *repocache.OciRepoCache, aws aws.AwsClientFactory, gcp gcp.GcpClientFactory) *VarsLoader {
 	return &VarsLoader{
 		ctx:              ctx,
 		k:                k,
 		sops:             sops,
 		rp:               rp,
+		ociRp:            ociRp,
 		aws:              aws,
 		gcp:              gcp,
+		registry:         defaultVarsSourceRegistry,
 		credentialsCache: map[string]usernamePassword{},
 	}
 }
 
+// SetAllowExec controls whether the command vars source is allowed to execute local commands.
+func (v *VarsLoader) SetAllowExec(allowExec bool) {
+	v.allowExec = allowExec
+}
+
+// SetAllowVaultTokenFiles controls whether the vault vars source is allowed to read credentials from local files.
+func (v *VarsLoader) SetAllowVaultTokenFiles(allowVaultTokenFiles bool) {
+	v.allowVaultTokenFiles = allowVaultTokenFiles
+}
+
+// SetRegistry replaces the registry used to lookup vars source providers. This is used to make plugin provided
+// vars sources available.
+func (v *VarsLoader) SetRegistry(registry *VarsSourceRegistry) {
+	v.registry = registry
+}
+
+// CheckVarsSources reports all vars sources in varsList with a type that is unknown to the registry. See
+// VarsSourceRegistry.CheckVarsSources for details.
+func (v *VarsLoader) CheckVarsSources(file string, b []byte, path []string, varsList []types.VarsSource) yaml.PositionedErrors {
+	return v.registry.CheckVarsSources(file, b, path, varsList)
+}
+
+// SetProvenanceRecorder enables recording of vars provenance.
+func (v *VarsLoader) SetProvenanceRecorder(r *ProvenanceRecorder) {
+	v.provenance = r
+}
+
+// SetCache enables caching of remote vars sources that specify 'cacheTTL'. The scope is part of all cache keys and
+// must be unique for every identity and set of credentials that loads vars, so that cached values are never shared
+// between them.
+func (v *VarsLoader) SetCache(cache VarsCache, mode VarsCacheMode, scope string) {
+	v.cache = cache
+	v.cacheMode = mode
+	v.cacheScope = scope
+}
+
+// SetGeneratedSecretsStore sets the store used by the generatedSecrets vars source.
+func (v *VarsLoader) SetGeneratedSecretsStore(s *generated_secrets.Store) {
+	v.generatedSecrets = s
+}
+
+// RecordProvenance records vars that were not loaded via a vars source, e.g. args, in the provenance recorder.
+func (v *VarsLoader) RecordProvenance(origin string, source string, existing *uo.UnstructuredObject, newVars *uo.UnstructuredObject, rootKey string) {
+	v.provenance.RecordMerge(origin, source, existing, newVars, rootKey, false, false)
+}
+
 func (v *VarsLoader) LoadVarsList(ctx context.Context, varsCtx *VarsCtx, varsList []types.VarsSource, searchDirs []string, rootKey string) error {
+	return v.LoadVarsListWithOrigin(ctx, varsCtx, varsList, searchDirs, rootKey, "vars")
+}
+
+// LoadVarsListWithOrigin is like LoadVarsList, but uses origin (e.g. 'deployment.yml vars') to describe where the
+// vars list was declared when recording provenance.
+func (v *VarsLoader) LoadVarsListWithOrigin(ctx context.Context, varsCtx *VarsCtx, varsList []types.VarsSource, searchDirs []string, rootKey string, origin string) error {
 	for i, _ := range varsList {
 		source := &varsList[i]
-		err := v.LoadVars(ctx, varsCtx, source, searchDirs, rootKey)
+		err := v.loadVars(ctx, varsCtx, source, searchDirs, rootKey, fmt.Sprintf("%s[%d]", origin, i))
 		if err != nil {
 			return err
 		}
@@ -70,6 +137,10 @@ func (v *VarsLoader) LoadVarsList(ctx context.Context, varsCtx *VarsCtx, varsLis
 }
 
 func (v *VarsLoader) LoadVars(ctx context.Context, varsCtx *VarsCtx, sourceIn *types.VarsSource, searchDirs []string, rootKey string) error {
+	return v.loadVars(ctx, varsCtx, sourceIn, searchDirs, rootKey, "vars")
+}
+
+func (v *VarsLoader) loadVars(ctx context.Context, varsCtx *VarsCtx, sourceIn *types.VarsSource, searchDirs []string, rootKey string, origin string) error {
 	if sourceIn.RenderedVars != nil && len(sourceIn.RenderedVars.Object) != 0 {
 		return fmt.Errorf("renderedVars is not allowed here")
 	}
@@ -85,7 +156,7 @@ func (v *VarsLoader) LoadVars(ctx context.Context, varsCtx *VarsCtx, sourceIn *t
 		return err
 	}
 
-	_, err = varsCtx.J2.RenderStruct(&source, jinja2.WithGlobals(globals))
+	_, err = varsCtx.J2.RenderStruct(&source, varsCtx.renderOpts(jinja2.WithGlobals(globals))...)
 	if err != nil {
 		return err
 	}
@@ -95,6 +166,7 @@ func (v *VarsLoader) LoadVars(ctx context.Context, varsCtx *VarsCtx, sourceIn *t
 		return err
 	}
 	if !whenTrue {
+		v.provenance.RecordSkipped(origin, DescribeVarsSource(&source), sourceIn.When)
 		return nil
 	}
 
@@ -103,57 +175,24 @@ func (v *VarsLoader) LoadVars(ctx context.Context, varsCtx *VarsCtx, sourceIn *t
 		ignoreMissing = *source.IgnoreMissing
 	}
 
-	var newValue any
-	var sensitive bool
-	if source.Values != nil {
-		if rootKey != "" {
-			newValue = uo.FromMap(map[string]interface{}{
-				rootKey: source.Values.Object,
-			})
-		} else {
-			newValue = source.Values
-		}
-	} else if source.File != nil {
-		newValue, sensitive, err = v.loadFile(varsCtx, *source.File, ignoreMissing, searchDirs)
-	} else if source.Git != nil {
-		newValue, sensitive, err = v.loadGit(ctx, varsCtx, source.Git, ignoreMissing)
-	} else if source.GitFiles != nil {
-		newValue, sensitive, err = v.loadGitFiles(ctx, varsCtx, source.GitFiles, ignoreMissing)
-	} else if source.ClusterConfigMap != nil {
-		newValue, err = v.loadFromK8sConfigMapOrSecret(varsCtx, *source.ClusterConfigMap, "ConfigMap", ignoreMissing, false)
-	} else if source.ClusterSecret != nil {
-		newValue, err = v.loadFromK8sConfigMapOrSecret(varsCtx, *source.ClusterSecret, "Secret", ignoreMissing, true)
-		sensitive = true
-	} else if source.ClusterObject != nil {
-		newValue, err = v.loadFromK8sObject(varsCtx, *source.ClusterObject, ignoreMissing)
-		sensitive = true
-	} else if source.SystemEnvVars != nil {
-		newValue, err = v.loadSystemEnvs(varsCtx, &source, ignoreMissing, rootKey)
-		sensitive = true
-	} else if source.Http != nil {
-		newValue, sensitive, err = v.loadHttp(varsCtx, &source, ignoreMissing)
-	} else if source.AwsSecretsManager != nil {
-		newValue, err = v.loadAwsSecretsManager(varsCtx, &source, ignoreMissing)
-		sensitive = true
-	} else if source.GcpSecretManager != nil {
-		newValue, err = v.loadGcpSecretManager(varsCtx, &source, ignoreMissing)
-		sensitive = true
-	} else if source.Vault != nil {
-		newValue, err = v.loadVault(varsCtx, &source, ignoreMissing)
-		sensitive = true
-	} else if source.AzureKeyVault != nil {
-		newValue, err = v.loadAzureKeyVault(varsCtx, &source, ignoreMissing)
-		sensitive = true
-	} else {
+	keys := source.GetSourceKeys()
+	if len(keys) != 1 {
 		return fmt.Errorf("invalid vars source")
 	}
-	if err != nil {
-		return err
+	provider := v.registry.Get(keys[0])
+	if provider == nil {
+		return fmt.Errorf("unknown vars source type '%s'", keys[0])
 	}
 
-	if sourceIn.Sensitive != nil {
-		// override the default
-		sensitive = *sourceIn.Sensitive
+	newValue, sensitive, err := v.loadWithCache(ctx, provider, &VarsSourceLoadRequest{
+		VarsCtx:       varsCtx,
+		Source:        &source,
+		SearchDirs:    searchDirs,
+		RootKey:       rootKey,
+		IgnoreMissing: ignoreMissing,
+	})
+	if err != nil {
+		return err
 	}
 
 	var newVars *uo.UnstructuredObject
@@ -177,8 +216,14 @@ func (v *VarsLoader) LoadVars(ctx context.Context, varsCtx *VarsCtx, sourceIn *t
 
 	sourceIn.RenderedSensitive = sensitive
 	sourceIn.RenderedVars = newVars.Clone()
+	if sensitive {
+		varsCtx.Sensitive = true
+	}
+
+	noOverride := source.NoOverride != nil && *source.NoOverride
+	v.provenance.RecordMerge(origin, DescribeVarsSource(&source), varsCtx.Vars, newVars, "", sensitive, noOverride)
 
-	if source.NoOverride == nil || !*source.NoOverride {
+	if !noOverride {
 		varsCtx.Vars.Merge(newVars)
 	} else {
 		newVars.Merge(varsCtx.Vars)
@@ -196,7 +241,12 @@ func (v *VarsLoader) mergeVars(varsCtx *VarsCtx, newVars *uo.UnstructuredObject,
 	}
 }
 
-func (v *VarsLoader) loadFile(varsCtx *VarsCtx, path string, ignoreMissing bool, searchDirs []string) (*uo.UnstructuredObject, bool, error) {
+func (v *VarsLoader) loadFile(varsCtx *VarsCtx, path string, format string, ignoreMissing bool, searchDirs []string) (*uo.UnstructuredObject, bool, error) {
+	fileFormat, err := file_formats.FormatForPathOrString(path, format)
+	if err != nil {
+		return nil, false, err
+	}
+
 	rendered, err := varsCtx.RenderFile(path, searchDirs)
 	if err != nil {
 		// TODO the Jinja2 renderer should be able to better report this error
@@ -207,18 +257,12 @@ func (v *VarsLoader) loadFile(varsCtx *VarsCtx, path string, ignoreMissing bool,
 		return nil, false, fmt.Errorf("failed to render vars file %s: %w", path, err)
 	}
 
-	format := formats.FormatForPath(path)
-	decrypted, sensitive, err := sops.MaybeDecrypt(v.sops, []byte(rendered), format, format)
+	decrypted, sensitive, err := file_formats.Decrypt(v.sops, []byte(rendered), fileFormat)
 	if err != nil {
 		return nil, false, fmt.Errorf("failed to decrypt vars file %s: %w", path, err)
 	}
-	rendered = string(decrypted)
 
-	newVars := uo.New()
-	err = yaml.ReadYamlString(rendered, newVars)
-	if err != nil {
-		return nil, false, err
-	}
+	newVars, err := file_formats.Parse(decrypted, fileFormat)
 	if err != nil {
 		return nil, false, fmt.Errorf("failed to load vars from %s: %w", path, err)
 	}
@@ -330,7 +374,16 @@ func (v *VarsLoader) loadAzureKeyVault(varsCtx *VarsCtx, source *types.VarsSourc
 }
 
 func (v *VarsLoader) loadVault(varsCtx *VarsCtx, source *types.VarsSource, ignoreMissing bool) (*uo.UnstructuredObject, error) {
-	secret, err := vault.GetSecret(source.Vault.Address, source.Vault.Path)
+	var saTokenFunc vault.ServiceAccountTokenFunc
+	if v.k != nil {
+		saTokenFunc = v.k.CreateServiceAccountToken
+	}
+
+	client, err := vault.NewClient(v.ctx, source.Vault, saTokenFunc, v.allowVaultTokenFiles)
+	if err != nil {
+		return nil, err
+	}
+	secret, err := vault.GetSecret(v.ctx, client, source.Vault)
 	if err != nil {
 		return nil, err
 	}
@@ -340,10 +393,14 @@ func (v *VarsLoader) loadVault(varsCtx *VarsCtx, source *types.VarsSource, ignor
 		}
 		return nil, fmt.Errorf("the specified vault secret was not found")
 	}
-	return v.loadFromString(varsCtx, *secret)
+	jsonData, err := json.Marshal(secret.ToVars(source.Vault.IncludeMetadata))
+	if err != nil {
+		return nil, err
+	}
+	return v.loadFromString(varsCtx, string(jsonData))
 }
 
-func (v *VarsLoader) loadGit(ctx context.Context, varsCtx *VarsCtx, gitFile *types.VarsSourceGit, ignoreMissing bool) (*uo.UnstructuredObject, bool, error) {
+func (v *VarsLoader) loadGit(ctx context.Context, varsCtx *VarsCtx, gitFile *types.VarsSourceGit, format string, ignoreMissing bool) (*uo.UnstructuredObject, bool, error) {
 	ge, err := v.rp.GetEntry(gitFile.Url.String())
 	if err != nil {
 		return nil, false, err
@@ -360,7 +417,25 @@ func (v *VarsLoader) loadGit(ctx context.Context, varsCtx *VarsCtx, gitFile *typ
 		return nil, false, fmt.Errorf("failed to load vars from git repository %s: %w", gitFile.Url.String(), err)
 	}
 
-	return v.loadFile(varsCtx, gitFile.Path, ignoreMissing, []string{clonedDir})
+	return v.loadFile(varsCtx, gitFile.Path, format, ignoreMissing, []string{clonedDir})
+}
+
+func (v *VarsLoader) loadOci(varsCtx *VarsCtx, ociFile *types.VarsSourceOci, format string, ignoreMissing bool) (*uo.UnstructuredObject, bool, error) {
+	if v.ociRp == nil {
+		return nil, false, fmt.Errorf("loading vars from OCI repositories is not supported here")
+	}
+
+	oe, err := v.ociRp.GetEntry(ociFile.Url)
+	if err != nil {
+		return nil, false, err
+	}
+
+	extractedDir, _, err := oe.GetExtractedDir(ociFile.Ref)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load vars from OCI repository %s: %w", ociFile.Url, err)
+	}
+
+	return v.loadFile(varsCtx, ociFile.Path, format, ignoreMissing, []string{extractedDir})
 }
 
 func (v *VarsLoader) loadFromK8sConfigMapOrSecret(varsCtx *VarsCtx, varsSource types.VarsSourceClusterConfigMapOrSecret, kind string, ignoreMissing bool, base64Decode bool) (*uo.UnstructuredObject, error) {
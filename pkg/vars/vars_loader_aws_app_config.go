@@ -0,0 +1,28 @@
+package vars
+
+import (
+	errors2 "errors"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/clouds/aws"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+)
+
+func (v *VarsLoader) loadAwsAppConfig(varsCtx *VarsCtx, source *types.VarsSource, ignoreMissing bool) (*uo.UnstructuredObject, error) {
+	if v.aws == nil {
+		return nil, fmt.Errorf("no AWS client factory provided")
+	}
+
+	ac := source.AwsAppConfig
+	c, err := aws.GetAwsAppConfigConfiguration(v.ctx, v.aws, ac.Profile, ac.Region, ac.Application, ac.Environment, ac.ConfigurationProfile)
+	if err != nil {
+		var nerr *aws.AppConfigNotFoundError
+		if errors2.As(err, &nerr) && ignoreMissing {
+			return uo.New(), nil
+		}
+		return nil, err
+	}
+
+	// AppConfig returns JSON for feature flags and JSON or YAML for freeform configurations, both can be parsed as YAML
+	return v.loadFromString(varsCtx, string(c.Content))
+}
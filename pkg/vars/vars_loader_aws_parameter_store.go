@@ -0,0 +1,107 @@
+package vars
+
+import (
+	errors2 "errors"
+	"fmt"
+	ssmtypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	"github.com/kluctl/kluctl/v2/pkg/clouds/aws"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"strings"
+)
+
+// loadAwsParameterStore loads either a single parameter or a whole parameter hierarchy. Parameters are always treated
+// as sensitive, as they might contain SecureString values.
+func (v *VarsLoader) loadAwsParameterStore(varsCtx *VarsCtx, source *types.VarsSource, ignoreMissing bool) (any, bool, error) {
+	if v.aws == nil {
+		return nil, false, fmt.Errorf("no AWS client factory provided")
+	}
+
+	ps := source.AwsParameterStore
+	withDecryption := ps.WithDecryption == nil || *ps.WithDecryption
+
+	if ps.Name != nil {
+		p, err := aws.GetAwsParameterStoreParameter(v.ctx, v.aws, ps.Profile, ps.Region, *ps.Name, withDecryption)
+		if err != nil {
+			var aerr *ssmtypes.ParameterNotFound
+			if errors2.As(err, &aerr) && ignoreMissing {
+				return uo.New(), true, nil
+			}
+			return nil, false, err
+		}
+
+		if source.TargetPath != "" {
+			// the value is assigned as is, without templating or yaml parsing, so that passwords and similar values
+			// don't get modified
+			return parameterValue(p), true, nil
+		}
+
+		var parsed any
+		err = v.renderYamlString(varsCtx, p.Value, &parsed)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to parse parameter %s: %w", *ps.Name, err)
+		}
+		if m, ok := parsed.(map[string]any); ok {
+			return uo.FromMap(m), true, nil
+		}
+		// non-dictionary values require targetPath, which is checked by the caller
+		return parsed, true, nil
+	}
+
+	recursive := ps.Recursive == nil || *ps.Recursive
+	params, err := aws.GetAwsParameterStoreParametersByPath(v.ctx, v.aws, ps.Profile, ps.Region, *ps.Path, recursive, withDecryption)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(params) == 0 && !ignoreMissing {
+		return nil, false, fmt.Errorf("no parameters found below path %s in AWS parameter store", *ps.Path)
+	}
+
+	ret := uo.New()
+	for _, p := range params {
+		keys := aws.SplitParameterPath(*ps.Path, p.Name)
+		if len(keys) == 0 {
+			continue
+		}
+
+		value := parameterValue(&p)
+
+		// SSM allows a parameter to have the same name as a hierarchy level (e.g. /a/b and /a/b/c), which can't be
+		// represented in a dictionary
+		for i := 1; i < len(keys); i++ {
+			parent, found, _ := ret.GetNestedField(uoKeys(keys[:i])...)
+			if _, isMap := parent.(map[string]any); found && !isMap {
+				return nil, false, fmt.Errorf("parameter %s conflicts with parameter %s/%s", p.Name, strings.TrimSuffix(*ps.Path, "/"), strings.Join(keys[:i], "/"))
+			}
+		}
+		if _, found, _ := ret.GetNestedField(uoKeys(keys)...); found {
+			return nil, false, fmt.Errorf("parameter %s conflicts with the parameters below it", p.Name)
+		}
+
+		err = ret.SetNestedField(value, uoKeys(keys)...)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+	return ret, true, nil
+}
+
+func uoKeys(keys []string) []any {
+	ret := make([]any, len(keys))
+	for i, k := range keys {
+		ret[i] = k
+	}
+	return ret
+}
+
+// parameterValue returns the raw value of the parameter, with StringList parameters converted to lists
+func parameterValue(p *aws.ParameterStoreParameter) any {
+	if p.Type != ssmtypes.ParameterTypeStringList {
+		return p.Value
+	}
+	var l []any
+	for _, x := range strings.Split(p.Value, ",") {
+		l = append(l, x)
+	}
+	return l
+}
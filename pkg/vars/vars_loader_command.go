@@ -0,0 +1,79 @@
+package vars
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/subosito/gotenv"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const defaultCommandTimeout = time.Minute
+
+func (v *VarsLoader) loadCommand(varsCtx *VarsCtx, source *types.VarsSourceCommand, searchDirs []string) (*uo.UnstructuredObject, error) {
+	if !v.allowExec {
+		return nil, fmt.Errorf("the command vars source is disabled, it must be explicitly enabled via --allow-exec-vars")
+	}
+
+	workDir := source.WorkDir
+	if !filepath.IsAbs(workDir) && len(searchDirs) != 0 {
+		workDir = filepath.Join(searchDirs[0], workDir)
+	}
+
+	timeout := defaultCommandTimeout
+	if source.Timeout != nil {
+		timeout = source.Timeout.Duration
+	}
+	ctx, cancel := context.WithTimeout(v.ctx, timeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, source.Command[0], source.Command[1:]...)
+	cmd.Dir = workDir
+	// don't wait forever for child processes that keep stdout/stderr open after the timeout
+	cmd.WaitDelay = 5 * time.Second
+	cmd.Env = os.Environ()
+	for k, v := range source.Env {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%s=%s", k, v))
+	}
+
+	stdout := bytes.NewBuffer(nil)
+	stderr := bytes.NewBuffer(nil)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	err := cmd.Run()
+	if err != nil {
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, fmt.Errorf("command %s timed out after %s", source.Command[0], timeout.String())
+		}
+		return nil, fmt.Errorf("command %s failed: %w, stderr: %s", source.Command[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	switch source.Format {
+	case "", "yaml", "json":
+		return v.loadFromString(varsCtx, stdout.String())
+	case "dotenv":
+		rendered, err := varsCtx.RenderString(stdout.String(), nil)
+		if err != nil {
+			return nil, err
+		}
+		env, err := gotenv.StrictParse(strings.NewReader(rendered))
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse output of command %s: %w", source.Command[0], err)
+		}
+		ret := uo.New()
+		for k, v := range env {
+			ret.Object[k] = v
+		}
+		return ret, nil
+	default:
+		return nil, fmt.Errorf("unsupported format %s", source.Format)
+	}
+}
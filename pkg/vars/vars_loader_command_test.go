@@ -0,0 +1,70 @@
+package vars
+
+import (
+	"context"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+)
+
+func TestVarsLoaderCommand(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires sh")
+	}
+
+	dir := t.TempDir()
+	err := os.WriteFile(filepath.Join(dir, "vars.json"), []byte(`{"a": {"b": "{{ '%s' | format('x') }}"}}`), 0o600)
+	assert.NoError(t, err)
+
+	load := func(allowExec bool, source types.VarsSource) (*VarsCtx, error) {
+		vl := NewVarsLoader(context.TODO(), nil, nil, nil, nil, nil, nil)
+		vl.SetAllowExec(allowExec)
+		vc := NewVarsCtx(newJinja2Must(t))
+		err := vl.LoadVars(context.TODO(), vc, &source, []string{dir}, "")
+		return vc, err
+	}
+
+	_, err = load(false, types.VarsSource{
+		Command: &types.VarsSourceCommand{Command: []string{"cat", "vars.json"}},
+	})
+	assert.ErrorContains(t, err, "--allow-exec-vars")
+
+	vc, err := load(true, types.VarsSource{
+		Command: &types.VarsSourceCommand{Command: []string{"cat", "vars.json"}, Format: "json"},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, uo.FromMap(map[string]interface{}{"a": map[string]interface{}{"b": "x"}}), vc.Vars)
+
+	vc, err = load(true, types.VarsSource{
+		Command: &types.VarsSourceCommand{
+			Command: []string{"sh", "-c", `echo "A=$MY_VAR"; echo "# comment"; echo "B='c d'"`},
+			Env:     map[string]string{"MY_VAR": "v"},
+			Format:  "dotenv",
+		},
+		TargetPath: "env",
+	})
+	assert.NoError(t, err)
+	v, _, _ := vc.Vars.GetNestedString("env", "A")
+	assert.Equal(t, "v", v)
+	v, _, _ = vc.Vars.GetNestedString("env", "B")
+	assert.Equal(t, "c d", v)
+
+	_, err = load(true, types.VarsSource{
+		Command: &types.VarsSourceCommand{Command: []string{"sh", "-c", "echo oops >&2; exit 1"}},
+	})
+	assert.ErrorContains(t, err, "oops")
+
+	_, err = load(true, types.VarsSource{
+		Command: &types.VarsSourceCommand{
+			Command: []string{"sleep", "5"},
+			Timeout: &metav1.Duration{Duration: 100 * time.Millisecond},
+		},
+	})
+	assert.ErrorContains(t, err, "timed out")
+}
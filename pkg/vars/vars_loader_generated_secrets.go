@@ -0,0 +1,31 @@
+package vars
+
+import (
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/generated_secrets"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+)
+
+func (v *VarsLoader) loadGeneratedSecrets(source *types.VarsSourceGeneratedSecrets) (*uo.UnstructuredObject, error) {
+	if v.generatedSecrets == nil {
+		return nil, fmt.Errorf("generated secrets are not available here")
+	}
+
+	ret := uo.New()
+	for _, s := range source.Secrets {
+		value, err := v.generatedSecrets.Get(generated_secrets.Spec{
+			Name:    s.Name,
+			Length:  s.Length,
+			Charset: s.Charset,
+		})
+		if err != nil {
+			return nil, err
+		}
+		err = ret.SetNestedField(value, s.Name)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return ret, nil
+}
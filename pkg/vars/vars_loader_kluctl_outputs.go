@@ -0,0 +1,103 @@
+package vars
+
+import (
+	"encoding/base64"
+	"fmt"
+	"github.com/kluctl/kluctl/v2/pkg/results"
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"time"
+)
+
+const defaultKluctlOutputsNamespace = "kluctl-results"
+
+func (v *VarsLoader) loadKluctlOutputs(source *types.VarsSourceKluctlOutputs, ignoreMissing bool) (*uo.UnstructuredObject, error) {
+	if v.k == nil {
+		return nil, fmt.Errorf("loading vars from cluster is disabled")
+	}
+
+	namespace := source.Namespace
+	if namespace == "" {
+		namespace = defaultKluctlOutputsNamespace
+	}
+
+	projectId, err := results.BuildProjectOutputsProjectId(result.ProjectKey{RepoKey: source.RepoKey, SubDir: source.SubDir})
+	if err != nil {
+		return nil, err
+	}
+	objs, _, err := v.k.ListObjects(schema.GroupVersionKind{Version: "v1", Kind: "Secret"}, namespace, map[string]string{
+		"kluctl.io/project-outputs-project-id": projectId,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	var candidates []result.ProjectOutputs
+	for _, o := range objs {
+		s, _, _ := o.GetNestedString("data", "outputs")
+		b, err := base64.StdEncoding.DecodeString(s)
+		if err != nil {
+			return nil, err
+		}
+		po, err := results.DecodeProjectOutputs(b)
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode project outputs from %s: %w", o.GetK8sRef().String(), err)
+		}
+		candidates = append(candidates, *po)
+	}
+
+	po := selectProjectOutputs(candidates, source)
+	if po == nil {
+		if ignoreMissing {
+			return uo.New(), nil
+		}
+		return nil, fmt.Errorf("no outputs found for project %s", describeKluctlOutputs(source))
+	}
+	if source.MaxAge != nil {
+		age := time.Since(po.Time.Time)
+		if age > source.MaxAge.Duration {
+			return nil, fmt.Errorf("outputs of project %s are stale, last update was %s ago (maxAge is %s)", describeKluctlOutputs(source), age.Round(time.Second), source.MaxAge.Duration)
+		}
+	}
+	if po.Outputs == nil {
+		return uo.New(), nil
+	}
+	return po.Outputs.Clone(), nil
+}
+
+// selectProjectOutputs returns the most recent outputs matching the given source, or nil if none match
+func selectProjectOutputs(l []result.ProjectOutputs, source *types.VarsSourceKluctlOutputs) *result.ProjectOutputs {
+	var ret *result.ProjectOutputs
+	for i := range l {
+		po := &l[i]
+		if po.ProjectKey.RepoKey != source.RepoKey || po.ProjectKey.SubDir != source.SubDir {
+			continue
+		}
+		if source.Target != nil && po.TargetKey.TargetName != *source.Target {
+			continue
+		}
+		if source.Discriminator != nil && po.TargetKey.Discriminator != *source.Discriminator {
+			continue
+		}
+		if ret == nil || po.Time.After(ret.Time.Time) {
+			ret = po
+		}
+	}
+	return ret
+}
+
+func describeKluctlOutputs(source *types.VarsSourceKluctlOutputs) string {
+	s := source.RepoKey.String()
+	if source.SubDir != "" {
+		s += ", subDir=" + source.SubDir
+	}
+	if source.Target != nil {
+		s += ", target=" + *source.Target
+	}
+	if source.Discriminator != nil {
+		s += ", discriminator=" + *source.Discriminator
+	}
+	return s
+}
@@ -0,0 +1,47 @@
+package vars
+
+import (
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/kluctl/kluctl/v2/pkg/types/result"
+	"github.com/kluctl/kluctl/v2/pkg/utils"
+	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
+	"github.com/stretchr/testify/assert"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"testing"
+	"time"
+)
+
+func TestSelectProjectOutputs(t *testing.T) {
+	repoKey := types.NewRepoKey("git", "example.com", "org/repo")
+	now := time.Now()
+
+	newOutputs := func(subDir string, target string, age time.Duration, v string) result.ProjectOutputs {
+		return result.ProjectOutputs{
+			ProjectKey: result.ProjectKey{RepoKey: repoKey, SubDir: subDir},
+			TargetKey:  result.TargetKey{TargetName: target},
+			Time:       metav1.NewTime(now.Add(-age)),
+			Outputs:    uo.FromMap(map[string]any{"v": v}),
+		}
+	}
+
+	l := []result.ProjectOutputs{
+		newOutputs("", "prod", time.Hour, "prod-old"),
+		newOutputs("", "prod", time.Minute, "prod-new"),
+		newOutputs("", "test", 0, "test"),
+		newOutputs("sub", "prod", 0, "sub"),
+	}
+
+	get := func(source types.VarsSourceKluctlOutputs) string {
+		po := selectProjectOutputs(l, &source)
+		if po == nil {
+			return ""
+		}
+		return po.Outputs.Object["v"].(string)
+	}
+
+	assert.Equal(t, "prod-new", get(types.VarsSourceKluctlOutputs{RepoKey: repoKey, Target: utils.Ptr("prod")}))
+	assert.Equal(t, "test", get(types.VarsSourceKluctlOutputs{RepoKey: repoKey}))
+	assert.Equal(t, "sub", get(types.VarsSourceKluctlOutputs{RepoKey: repoKey, SubDir: "sub"}))
+	assert.Equal(t, "", get(types.VarsSourceKluctlOutputs{RepoKey: repoKey, Target: utils.Ptr("prod"), Discriminator: utils.Ptr("x")}))
+	assert.Equal(t, "", get(types.VarsSourceKluctlOutputs{RepoKey: types.NewRepoKey("git", "example.com", "org/other")}))
+}
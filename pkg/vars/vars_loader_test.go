@@ -90,7 +90,7 @@ func (s *VarsLoaderTestSuite) testVarsLoader(test func(vl *VarsLoader, vc *VarsC
 	d := decryptor.NewDecryptor("", decryptor.MaxEncryptedFileSize)
 	d.AddLocalKeyService()
 
-	vl := NewVarsLoader(context.TODO(), s.k2, d, grc, fakeAws, fakeGcp)
+	vl := NewVarsLoader(context.TODO(), s.k2, d, grc, nil, fakeAws, fakeGcp)
 	vc := NewVarsCtx(newJinja2Must(s.T()))
 
 	test(vl, vc, fakeAws, fakeGcp)
@@ -1178,6 +1178,200 @@ func (s *VarsLoaderTestSuite) TestAwsSecretsManager() {
 	})
 }
 
+func (s *VarsLoaderTestSuite) TestAwsParameterStore() {
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		aws.Parameters = map[string]string{
+			"/app/vars": `{"test1": {"test2": 42}}`,
+		}
+
+		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			AwsParameterStore: &types.VarsSourceAwsParameterStore{
+				Name: utils.Ptr("/app/vars"),
+			},
+		}, nil, "")
+		assert.NoError(s.T(), err)
+
+		v, _, _ := vc.Vars.GetNestedInt("test1", "test2")
+		assert.Equal(s.T(), int64(42), v)
+	})
+
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		aws.SecureParameters = map[string]string{
+			"/app/password": "secret",
+		}
+
+		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			AwsParameterStore: &types.VarsSourceAwsParameterStore{
+				Name: utils.Ptr("arn:aws:ssm:eu-central-1:12345:parameter/app/password"),
+			},
+			TargetPath: "password",
+		}, nil, "")
+		assert.NoError(s.T(), err)
+
+		v, _, _ := vc.Vars.GetNestedString("password")
+		assert.Equal(s.T(), "secret", v)
+
+		err = vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			AwsParameterStore: &types.VarsSourceAwsParameterStore{
+				Name:           utils.Ptr("/app/password"),
+				WithDecryption: utils.Ptr(false),
+			},
+			TargetPath: "password",
+		}, nil, "")
+		assert.NoError(s.T(), err)
+
+		v, _, _ = vc.Vars.GetNestedString("password")
+		assert.Equal(s.T(), "encrypted:secret", v)
+	})
+
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		// values loaded into targetPath must neither be templated nor re-typed by yaml parsing
+		aws.SecureParameters = map[string]string{
+			"/app/password": "{{ 1 + 1 }}",
+			"/app/pin":      "0123",
+		}
+
+		for _, n := range []string{"password", "pin"} {
+			err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+				AwsParameterStore: &types.VarsSourceAwsParameterStore{
+					Name: utils.Ptr("/app/" + n),
+				},
+				TargetPath: n,
+			}, nil, "")
+			assert.NoError(s.T(), err)
+		}
+
+		v, _, _ := vc.Vars.GetNestedField("password")
+		assert.Equal(s.T(), "{{ 1 + 1 }}", v)
+		v, _, _ = vc.Vars.GetNestedField("pin")
+		assert.Equal(s.T(), "0123", v)
+	})
+
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		aws.Parameters = map[string]string{
+			"/app/prod/db/host":   "db.example.com",
+			"/app/prod/db/port":   "5432",
+			"/app/prod/log-level": "info",
+			"/app/test/log-level": "debug",
+		}
+		aws.SecureParameters = map[string]string{
+			"/app/prod/db/password": "secret",
+		}
+
+		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			AwsParameterStore: &types.VarsSourceAwsParameterStore{
+				Path: utils.Ptr("/app/prod"),
+			},
+		}, nil, "")
+		assert.NoError(s.T(), err)
+
+		assert.Equal(s.T(), map[string]any{
+			"db": map[string]any{
+				"host":     "db.example.com",
+				"port":     "5432",
+				"password": "secret",
+			},
+			"log-level": "info",
+		}, vc.Vars.Object)
+	})
+
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		aws.Parameters = map[string]string{
+			"/app/prod/db/host":   "db.example.com",
+			"/app/prod/log-level": "info",
+		}
+
+		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			AwsParameterStore: &types.VarsSourceAwsParameterStore{
+				Path:      utils.Ptr("/app/prod/"),
+				Recursive: utils.Ptr(false),
+			},
+		}, nil, "")
+		assert.NoError(s.T(), err)
+
+		assert.Equal(s.T(), map[string]any{
+			"log-level": "info",
+		}, vc.Vars.Object)
+	})
+
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		aws.Parameters = map[string]string{
+			"/app/prod/db":      "x",
+			"/app/prod/db/host": "db.example.com",
+		}
+
+		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			AwsParameterStore: &types.VarsSourceAwsParameterStore{
+				Path: utils.Ptr("/app/prod"),
+			},
+		}, nil, "")
+		assert.ErrorContains(s.T(), err, "parameter /app/prod/db/host conflicts with parameter /app/prod/db")
+	})
+
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			AwsParameterStore: &types.VarsSourceAwsParameterStore{
+				Name: utils.Ptr("/missing"),
+			},
+		}, nil, "")
+		assert.ErrorContains(s.T(), err, "parameter /missing not found")
+
+		err = vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			AwsParameterStore: &types.VarsSourceAwsParameterStore{
+				Path: utils.Ptr("/missing"),
+			},
+		}, nil, "")
+		assert.EqualError(s.T(), err, "no parameters found below path /missing in AWS parameter store")
+
+		err = vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			IgnoreMissing: utils.Ptr(true),
+			AwsParameterStore: &types.VarsSourceAwsParameterStore{
+				Name: utils.Ptr("/missing"),
+			},
+		}, nil, "")
+		assert.NoError(s.T(), err)
+	})
+}
+
+func (s *VarsLoaderTestSuite) TestAwsAppConfig() {
+	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
+		aws.AppConfigs = map[string]string{
+			"my-app/prod/vars": `{"test1": {"test2": 42}}`,
+		}
+
+		err := vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			AwsAppConfig: &types.VarsSourceAwsAppConfig{
+				Application:          "my-app",
+				Environment:          "prod",
+				ConfigurationProfile: "vars",
+			},
+		}, nil, "")
+		assert.NoError(s.T(), err)
+
+		v, _, _ := vc.Vars.GetNestedInt("test1", "test2")
+		assert.Equal(s.T(), int64(42), v)
+
+		err = vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			AwsAppConfig: &types.VarsSourceAwsAppConfig{
+				Application:          "my-app",
+				Environment:          "prod",
+				ConfigurationProfile: "missing",
+			},
+		}, nil, "")
+		assert.EqualError(s.T(), err, "getting configuration my-app/prod/missing from AWS AppConfig failed: configuration my-app/prod/missing not found")
+
+		err = vl.LoadVars(context.TODO(), vc, &types.VarsSource{
+			IgnoreMissing: utils.Ptr(true),
+			AwsAppConfig: &types.VarsSourceAwsAppConfig{
+				Application:          "my-app",
+				Environment:          "prod",
+				ConfigurationProfile: "missing",
+			},
+		}, nil, "")
+		assert.NoError(s.T(), err)
+	})
+}
+
 func (s *VarsLoaderTestSuite) TestGcpSecretManager() {
 	s.testVarsLoader(func(vl *VarsLoader, vc *VarsCtx, aws *aws.FakeAwsClientFactory, gcp *gcp.FakeClientFactory) {
 		gcp.Secrets = map[string]string{
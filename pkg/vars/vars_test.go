@@ -4,6 +4,7 @@ import (
 	"context"
 	"github.com/kluctl/go-jinja2"
 	"github.com/kluctl/kluctl/v2/pkg/kluctl_jinja2"
+	"github.com/kluctl/kluctl/v2/pkg/types"
 	"github.com/kluctl/kluctl/v2/pkg/utils/uo"
 	"github.com/stretchr/testify/assert"
 	"testing"
@@ -67,3 +68,23 @@ func TestVarsCtxStruct(t *testing.T) {
 	v, _, _ := varsCtx.Vars.GetNestedInt("child", "test1", "test2")
 	assert.Equal(t, int64(42), v)
 }
+
+func TestVarsCtxSensitive(t *testing.T) {
+	j2 := newJinja2Must(t)
+	t.Setenv("TEST_SENSITIVE_VAR", "secret")
+
+	v := NewVarsLoader(context.Background(), nil, nil, nil, nil, nil, nil)
+	varsCtx := NewVarsCtx(j2)
+	err := v.LoadVars(context.Background(), varsCtx, &types.VarsSource{
+		Values: uo.FromMap(map[string]any{"a": "b"}),
+	}, nil, "")
+	assert.NoError(t, err)
+	assert.False(t, varsCtx.Sensitive)
+
+	err = v.LoadVars(context.Background(), varsCtx, &types.VarsSource{
+		SystemEnvVars: uo.FromMap(map[string]any{"secret": "TEST_SENSITIVE_VAR"}),
+	}, nil, "")
+	assert.NoError(t, err)
+	assert.True(t, varsCtx.Sensitive)
+	assert.True(t, varsCtx.Copy().Sensitive)
+}
@@ -1,32 +1,238 @@
 package vault
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"net/http"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/hashicorp/vault/api"
+	"github.com/kluctl/kluctl/v2/pkg/types"
 )
 
-var httpClient = &http.Client{
-	Timeout: 15 * time.Second,
+// ServiceAccountTokenFunc is used to request tokens for named ServiceAccounts when the kubernetes auth method is used.
+type ServiceAccountTokenFunc func(namespace string, name string, audiences []string) (string, error)
+
+type Lease struct {
+	Id        string `json:"id"`
+	Duration  int    `json:"duration"`
+	Renewable bool   `json:"renewable"`
+}
+
+type Secret struct {
+	Data     map[string]interface{}
+	Metadata map[string]interface{}
+	Lease    *Lease
 }
 
-func GetSecret(server string, path string) (*string, error) {
-	client, err := api.NewClient(&api.Config{Address: server, HttpClient: httpClient})
+// ToVars returns the secret in the form that is merged into the vars.
+func (s *Secret) ToVars(includeMetadata bool) map[string]interface{} {
+	if !includeMetadata {
+		return s.Data
+	}
+	ret := map[string]interface{}{
+		"data": s.Data,
+	}
+	if s.Metadata != nil {
+		ret["metadata"] = s.Metadata
+	}
+	if s.Lease != nil {
+		ret["lease"] = map[string]interface{}{
+			"id":        s.Lease.Id,
+			"duration":  s.Lease.Duration,
+			"renewable": s.Lease.Renewable,
+		}
+	}
+	return ret
+}
+
+// NewClient creates a Vault client and performs the login for the configured auth method. If no auth method is
+// configured, the client falls back to the token found in VAULT_TOKEN. Credentials are only read from local files
+// when allowTokenFiles is true, as the files are read from the machine/pod that runs kluctl.
+func NewClient(ctx context.Context, config *types.VarsSourceVault, saTokenFunc ServiceAccountTokenFunc, allowTokenFiles bool) (*api.Client, error) {
+	clientConfig := api.DefaultConfig()
+	if clientConfig.Error != nil {
+		return nil, fmt.Errorf("failed to create vault %s client: %w", config.Address, clientConfig.Error)
+	}
+	clientConfig.Address = config.Address
+	clientConfig.Timeout = 15 * time.Second
+
+	client, err := api.NewClient(clientConfig)
 	if err != nil {
-		return nil, fmt.Errorf("failed to create vault %s client", server)
+		return nil, fmt.Errorf("failed to create vault %s client: %w", config.Address, err)
+	}
+	if config.Namespace != "" {
+		client.SetNamespace(config.Namespace)
+	}
+
+	if config.Auth != nil {
+		l := &loginCtx{saTokenFunc: saTokenFunc, allowTokenFiles: allowTokenFiles}
+		err = l.login(ctx, client, config.Auth)
+		if err != nil {
+			return nil, fmt.Errorf("vault login failed: %w", err)
+		}
+	}
+
+	return client, nil
+}
+
+type loginCtx struct {
+	saTokenFunc     ServiceAccountTokenFunc
+	allowTokenFiles bool
+}
+
+func (l *loginCtx) readTokenFile(path string) (string, error) {
+	if !l.allowTokenFiles {
+		return "", fmt.Errorf("reading vault credentials from files is disabled, it must be explicitly enabled via --allow-vault-token-files")
 	}
-	secret, err := client.Logical().Read(path)
+	b, err := os.ReadFile(path)
 	if err != nil {
-		return nil, fmt.Errorf("reading from vault failed: %v", err)
+		return "", err
+	}
+	return strings.TrimSpace(string(b)), nil
+}
+
+func withDefault(s string, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+func (l *loginCtx) login(ctx context.Context, client *api.Client, auth *types.VarsSourceVaultAuth) error {
+	if auth.TokenFile != nil {
+		token, err := l.readTokenFile(*auth.TokenFile)
+		if err != nil {
+			return err
+		}
+		client.SetToken(token)
+		return nil
+	}
+
+	var mountPath string
+	data := map[string]interface{}{}
+	if auth.Kubernetes != nil {
+		var jwt string
+		var err error
+		if auth.Kubernetes.ServiceAccount != nil {
+			if l.saTokenFunc == nil {
+				return fmt.Errorf("requesting ServiceAccount tokens is not supported without a cluster connection")
+			}
+			sa := auth.Kubernetes.ServiceAccount
+			jwt, err = l.saTokenFunc(sa.Namespace, sa.Name, sa.Audiences)
+		} else if auth.Kubernetes.TokenFile != "" {
+			jwt, err = l.readTokenFile(auth.Kubernetes.TokenFile)
+		} else {
+			return fmt.Errorf("kubernetes auth requires either serviceAccount or tokenFile to be set")
+		}
+		if err != nil {
+			return err
+		}
+		mountPath = withDefault(auth.Kubernetes.MountPath, "kubernetes")
+		data["role"] = auth.Kubernetes.Role
+		data["jwt"] = jwt
+	} else if auth.AppRole != nil {
+		var secretId string
+		if auth.AppRole.SecretIdFile != nil {
+			var err error
+			secretId, err = l.readTokenFile(*auth.AppRole.SecretIdFile)
+			if err != nil {
+				return err
+			}
+		} else if auth.AppRole.SecretId != nil {
+			secretId = *auth.AppRole.SecretId
+		}
+		mountPath = withDefault(auth.AppRole.MountPath, "approle")
+		data["role_id"] = auth.AppRole.RoleId
+		data["secret_id"] = secretId
+	} else if auth.Jwt != nil {
+		var jwt string
+		if auth.Jwt.TokenFile != nil {
+			var err error
+			jwt, err = l.readTokenFile(*auth.Jwt.TokenFile)
+			if err != nil {
+				return err
+			}
+		} else if auth.Jwt.Token != nil {
+			jwt = *auth.Jwt.Token
+		}
+		mountPath = withDefault(auth.Jwt.MountPath, "jwt")
+		data["role"] = auth.Jwt.Role
+		data["jwt"] = jwt
+	} else {
+		return fmt.Errorf("no auth method specified")
+	}
+
+	secret, err := client.Logical().WriteWithContext(ctx, fmt.Sprintf("auth/%s/login", strings.Trim(mountPath, "/")), data)
+	if err != nil {
+		return err
+	}
+	if secret == nil || secret.Auth == nil || secret.Auth.ClientToken == "" {
+		return fmt.Errorf("login via %s did not return a token", mountPath)
+	}
+	client.SetToken(secret.Auth.ClientToken)
+	return nil
+}
+
+// GetSecret reads the configured secret. It returns nil if the secret does not exist.
+func GetSecret(ctx context.Context, client *api.Client, config *types.VarsSourceVault) (*Secret, error) {
+	if config.Kv != nil {
+		return getKvSecret(ctx, client, config)
+	}
+
+	secret, err := client.Logical().ReadWithContext(ctx, config.Path)
+	if err != nil {
+		return nil, fmt.Errorf("reading from vault failed: %w", err)
 	}
 	if secret == nil || secret.Data == nil {
 		return nil, nil
 	}
-	data, _ := secret.Data["data"].(map[string]interface{})
-	jsonData, _ := json.Marshal(data)
-	ret := string(jsonData)
-	return &ret, nil
+
+	ret := &Secret{
+		Data: secret.Data,
+	}
+	// paths pointing into KV v2 mounts (e.g. secret/data/foo) return the actual secret wrapped into 'data'
+	if data, ok := secret.Data["data"].(map[string]interface{}); ok {
+		ret.Data = data
+		ret.Metadata, _ = secret.Data["metadata"].(map[string]interface{})
+	}
+	if secret.LeaseID != "" {
+		ret.Lease = &Lease{
+			Id:        secret.LeaseID,
+			Duration:  secret.LeaseDuration,
+			Renewable: secret.Renewable,
+		}
+	}
+	return ret, nil
+}
+
+func getKvSecret(ctx context.Context, client *api.Client, config *types.VarsSourceVault) (*Secret, error) {
+	mount := strings.Trim(config.Kv.Mount, "/")
+	path := strings.Trim(config.Path, "/")
+
+	var kvSecret *api.KVSecret
+	var err error
+	if config.Kv.Version == 1 {
+		kvSecret, err = client.KVv1(mount).Get(ctx, path)
+	} else if config.Kv.SecretVersion != nil {
+		kvSecret, err = client.KVv2(mount).GetVersion(ctx, path, *config.Kv.SecretVersion)
+	} else {
+		kvSecret, err = client.KVv2(mount).Get(ctx, path)
+	}
+	if err != nil {
+		if errors.Is(err, api.ErrSecretNotFound) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading from vault failed: %w", err)
+	}
+
+	ret := &Secret{
+		Data: kvSecret.Data,
+	}
+	if kvSecret.Raw != nil && config.Kv.Version != 1 {
+		ret.Metadata, _ = kvSecret.Raw.Data["metadata"].(map[string]interface{})
+	}
+	return ret, nil
 }
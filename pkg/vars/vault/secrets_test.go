@@ -0,0 +1,206 @@
+package vault
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/kluctl/kluctl/v2/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func newTestVaultServer(t *testing.T, secrets map[string]map[string]interface{}) *httptest.Server {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var resp map[string]interface{}
+		switch r.URL.Path {
+		case "/v1/auth/approle/login", "/v1/auth/jwt/login", "/v1/auth/kubernetes/login":
+			var body map[string]interface{}
+			_ = json.NewDecoder(r.Body).Decode(&body)
+			if body["secret_id"] != "my-secret-id" && body["jwt"] != "my-jwt" {
+				http.Error(w, "permission denied", http.StatusForbidden)
+				return
+			}
+			resp = map[string]interface{}{
+				"auth": map[string]interface{}{
+					"client_token": "login-token",
+				},
+			}
+		default:
+			if r.Header.Get("X-Vault-Token") != "login-token" {
+				http.Error(w, "permission denied", http.StatusForbidden)
+				return
+			}
+			s, ok := secrets[r.URL.Path]
+			if !ok {
+				// this is what Vault returns for missing secrets
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = w.Write([]byte(`{"errors":[]}`))
+				return
+			}
+			resp = s
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(resp)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestVaultLogin(t *testing.T) {
+	server := newTestVaultServer(t, map[string]map[string]interface{}{
+		"/v1/kv/data/s": {
+			"data": map[string]interface{}{
+				"data":     map[string]interface{}{"a": "b"},
+				"metadata": map[string]interface{}{"version": 3},
+			},
+		},
+	})
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	_ = os.WriteFile(tokenFile, []byte("my-jwt\n"), 0o600)
+
+	secretId := "my-secret-id"
+	wrongSecretId := "wrong"
+
+	tests := []struct {
+		name    string
+		auth    *types.VarsSourceVaultAuth
+		saFunc  ServiceAccountTokenFunc
+		noFiles bool
+		wantErr bool
+	}{
+		{name: "approle", auth: &types.VarsSourceVaultAuth{AppRole: &types.VarsSourceVaultAuthAppRole{RoleId: "r", SecretId: &secretId}}},
+		{name: "approle-denied", auth: &types.VarsSourceVaultAuth{AppRole: &types.VarsSourceVaultAuthAppRole{RoleId: "r", SecretId: &wrongSecretId}}, wantErr: true},
+		{name: "jwt", auth: &types.VarsSourceVaultAuth{Jwt: &types.VarsSourceVaultAuthJwt{Role: "r", TokenFile: &tokenFile}}},
+		{name: "jwt-files-not-allowed", auth: &types.VarsSourceVaultAuth{Jwt: &types.VarsSourceVaultAuthJwt{Role: "r", TokenFile: &tokenFile}}, noFiles: true, wantErr: true},
+		{name: "kubernetes-token-file", auth: &types.VarsSourceVaultAuth{Kubernetes: &types.VarsSourceVaultAuthKubernetes{Role: "r", TokenFile: tokenFile}}},
+		{name: "kubernetes-token-file-not-allowed", auth: &types.VarsSourceVaultAuth{Kubernetes: &types.VarsSourceVaultAuthKubernetes{Role: "r", TokenFile: tokenFile}}, noFiles: true, wantErr: true},
+		{name: "kubernetes-no-token", auth: &types.VarsSourceVaultAuth{Kubernetes: &types.VarsSourceVaultAuthKubernetes{Role: "r"}}, wantErr: true},
+		{name: "approle-secret-id-file-not-allowed", auth: &types.VarsSourceVaultAuth{AppRole: &types.VarsSourceVaultAuthAppRole{RoleId: "r", SecretIdFile: &tokenFile}}, noFiles: true, wantErr: true},
+		{name: "token-file-not-allowed", auth: &types.VarsSourceVaultAuth{TokenFile: &tokenFile}, noFiles: true, wantErr: true},
+		{
+			name: "kubernetes-sa",
+			auth: &types.VarsSourceVaultAuth{Kubernetes: &types.VarsSourceVaultAuthKubernetes{Role: "r", ServiceAccount: &types.VarsSourceVaultServiceAccount{Name: "sa", Namespace: "ns"}}},
+			saFunc: func(namespace string, name string, audiences []string) (string, error) {
+				assert.Equal(t, "ns", namespace)
+				assert.Equal(t, "sa", name)
+				return "my-jwt", nil
+			},
+		},
+		{name: "kubernetes-sa-no-cluster", auth: &types.VarsSourceVaultAuth{Kubernetes: &types.VarsSourceVaultAuthKubernetes{Role: "r", ServiceAccount: &types.VarsSourceVaultServiceAccount{Name: "sa", Namespace: "ns"}}}, wantErr: true},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			config := &types.VarsSourceVault{
+				Address: server.URL,
+				Path:    "s",
+				Kv:      &types.VarsSourceVaultKv{Mount: "kv"},
+				Auth:    tc.auth,
+			}
+			client, err := NewClient(context.Background(), config, tc.saFunc, !tc.noFiles)
+			if tc.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			s, err := GetSecret(context.Background(), client, config)
+			assert.NoError(t, err)
+			assert.Equal(t, map[string]interface{}{"a": "b"}, s.ToVars(false))
+		})
+	}
+}
+
+func TestVaultGetSecret(t *testing.T) {
+	server := newTestVaultServer(t, map[string]map[string]interface{}{
+		"/v1/secret/data/s": {
+			"data": map[string]interface{}{
+				"data":     map[string]interface{}{"a": "b"},
+				"metadata": map[string]interface{}{"version": 3},
+			},
+		},
+		"/v1/kv1/s": {
+			"data": map[string]interface{}{"c": "d"},
+		},
+		"/v1/database/creds/r": {
+			"lease_id":       "database/creds/r/abc",
+			"lease_duration": 3600,
+			"renewable":      true,
+			"data":           map[string]interface{}{"username": "u", "password": "p"},
+		},
+	})
+
+	tokenFile := filepath.Join(t.TempDir(), "token")
+	_ = os.WriteFile(tokenFile, []byte("login-token"), 0o600)
+	auth := &types.VarsSourceVaultAuth{TokenFile: &tokenFile}
+
+	get := func(config types.VarsSourceVault) *Secret {
+		config.Address = server.URL
+		config.Auth = auth
+		client, err := NewClient(context.Background(), &config, nil, true)
+		assert.NoError(t, err)
+		s, err := GetSecret(context.Background(), client, &config)
+		assert.NoError(t, err)
+		return s
+	}
+
+	s := get(types.VarsSourceVault{Path: "secret/data/s"})
+	assert.Equal(t, map[string]interface{}{
+		"data":     map[string]interface{}{"a": "b"},
+		"metadata": map[string]interface{}{"version": json.Number("3")},
+	}, s.ToVars(true))
+
+	s = get(types.VarsSourceVault{Path: "s", Kv: &types.VarsSourceVaultKv{Mount: "kv1", Version: 1}})
+	assert.Equal(t, map[string]interface{}{"c": "d"}, s.ToVars(false))
+
+	s = get(types.VarsSourceVault{Path: "database/creds/r"})
+	assert.Equal(t, map[string]interface{}{"username": "u", "password": "p"}, s.ToVars(false))
+	assert.Equal(t, &Lease{Id: "database/creds/r/abc", Duration: 3600, Renewable: true}, s.Lease)
+
+	s = get(types.VarsSourceVault{Path: "s", Kv: &types.VarsSourceVaultKv{Mount: "secret"}})
+	assert.Equal(t, map[string]interface{}{"a": "b"}, s.ToVars(false))
+
+	s = get(types.VarsSourceVault{Path: "missing", Kv: &types.VarsSourceVaultKv{Mount: "secret"}})
+	assert.Nil(t, s)
+	s = get(types.VarsSourceVault{Path: "missing"})
+	assert.Nil(t, s)
+}
+
+// TestVaultDevServer runs against a real Vault started via `vault server -dev -dev-root-token-id=root`
+func TestVaultDevServer(t *testing.T) {
+	addr := os.Getenv("KLUCTL_TEST_VAULT_ADDR")
+	if addr == "" {
+		t.Skip("KLUCTL_TEST_VAULT_ADDR not set")
+	}
+	t.Setenv("VAULT_TOKEN", "root")
+
+	config := &types.VarsSourceVault{
+		Address: addr,
+		Path:    t.Name(),
+		Kv:      &types.VarsSourceVaultKv{Mount: "secret"},
+	}
+	client, err := NewClient(context.Background(), config, nil, false)
+	assert.NoError(t, err)
+
+	_, err = client.KVv2("secret").Put(context.Background(), t.Name(), map[string]interface{}{"a": "v1"})
+	assert.NoError(t, err)
+	_, err = client.KVv2("secret").Put(context.Background(), t.Name(), map[string]interface{}{"a": "v2"})
+	assert.NoError(t, err)
+
+	s, err := GetSecret(context.Background(), client, config)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"a": "v2"}, s.Data)
+	assert.Equal(t, json.Number("2"), s.Metadata["version"])
+
+	v := 1
+	config.Kv.SecretVersion = &v
+	s, err = GetSecret(context.Background(), client, config)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]interface{}{"a": "v1"}, s.Data)
+}
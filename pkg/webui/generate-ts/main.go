@@ -17,6 +17,8 @@ func main() {
 		Add(result.CommandResultSummary{}).
 		Add(result.ValidateResult{}).
 		Add(result.ValidateResultSummary{}).
+		Add(result.ValidateHistory{}).
+		Add(result.ProjectOutputs{}).
 		Add(result.DriftDetectionResult{}).
 		Add(result.ChangedObject{}).
 		Add(webui.ShortName{}).
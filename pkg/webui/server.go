@@ -16,6 +16,7 @@ import (
 	"io/fs"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/runtime/schema"
 	corev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/rest"
@@ -134,6 +135,7 @@ func (s *CommandResultsServer) Run(host string, port int, openBrowser bool) erro
 	api.GET("/getCommandResult", s.getCommandResult)
 	api.GET("/getCommandResultObject", s.getCommandResultObject)
 	api.GET("/getValidateResult", s.getValidateResult)
+	api.GET("/getValidateHistory", s.getValidateHistory)
 	api.POST("/validateNow", s.validateNow)
 	api.POST("/reconcileNow", s.reconcileNow)
 	api.POST("/deployNow", s.deployNow)
@@ -465,6 +467,43 @@ func (s *CommandResultsServer) getValidateResult(c *gin.Context) {
 	c.JSON(http.StatusOK, vr)
 }
 
+func (s *CommandResultsServer) getValidateHistory(c *gin.Context) {
+	var params resultIdParam
+
+	err := c.Bind(&params)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	vr, err := s.store.GetValidateResult(results.GetValidateResultOptions{
+		Id: params.ResultId,
+	})
+	if err != nil {
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+	if vr == nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	vh, err := s.store.GetValidateHistory(results.GetValidateHistoryOptions{
+		ProjectKey: vr.ProjectKey,
+		TargetKey:  vr.TargetKey,
+	})
+	if err != nil {
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+	if vh == nil {
+		c.AbortWithStatus(http.StatusNotFound)
+		return
+	}
+
+	c.JSON(http.StatusOK, vh)
+}
+
 func (s *CommandResultsServer) doModifyKluctlDeployment(c *gin.Context, clusterId string, name string, namespace string, update func(obj *kluctlv1.KluctlDeployment) error) {
 	user := s.auth.getUser(c)
 
@@ -548,7 +587,33 @@ func (s *CommandResultsServer) reconcileNow(c *gin.Context) {
 }
 
 func (s *CommandResultsServer) deployNow(c *gin.Context) {
-	s.manualRequest(c, kluctlv1.KluctlRequestDeployAnnotation)
+	var params struct {
+		KluctlDeploymentParam
+		Args map[string]any `json:"args,omitempty"`
+	}
+	err := c.Bind(&params)
+	if err != nil {
+		_ = c.AbortWithError(http.StatusBadRequest, err)
+		return
+	}
+
+	mr := &kluctlv1.ManualRequest{
+		RequestValue: time.Now().Format(time.RFC3339Nano),
+	}
+	if params.Args != nil {
+		// the controller merge-patches the overrides onto the KluctlDeployment before deploying
+		patch := map[string]any{
+			"spec": map[string]any{
+				"args": params.Args,
+			},
+		}
+		mr.OverridesPatch = &runtime.RawExtension{Raw: []byte(yaml.WriteJsonStringMust(patch))}
+	}
+
+	s.doModifyKluctlDeployment(c, params.Cluster, params.Name, params.Namespace, func(obj *kluctlv1.KluctlDeployment) error {
+		metav1.SetMetaDataAnnotation(&obj.ObjectMeta, kluctlv1.KluctlRequestDeployAnnotation, yaml.WriteJsonStringMust(mr))
+		return nil
+	})
 }
 
 func (s *CommandResultsServer) pruneNow(c *gin.Context) {
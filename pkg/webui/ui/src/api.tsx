@@ -5,6 +5,7 @@ import {
     ObjectRef, OciRef,
     ResultObject,
     ShortName,
+    ValidateHistory,
     ValidateResult
 } from "./models";
 import _ from "lodash";
@@ -50,9 +51,10 @@ export interface Api {
     getCommandResult(resultId: string): Promise<CommandResult>
     getCommandResultObject(resultId: string, ref: ObjectRef, objectType: string): Promise<any>
     getValidateResult(resultId: string): Promise<ValidateResult>
+    getValidateHistory(resultId: string): Promise<ValidateHistory>
     validateNow(cluster: string, name: string, namespace: string): Promise<Response>
     reconcileNow(cluster: string, name: string, namespace: string): Promise<Response>
-    deployNow(cluster: string, name: string, namespace: string): Promise<Response>
+    deployNow(cluster: string, name: string, namespace: string, args?: any): Promise<Response>
     pruneNow(cluster: string, name: string, namespace: string): Promise<Response>
     setSuspended(cluster: string, name: string, namespace: string, suspend: boolean): Promise<Response>
     setManualObjectsHash(cluster: string, name: string, namespace: string, objectsHash: string): Promise<Response>
@@ -216,6 +218,13 @@ export class RealApi implements Api {
         return new ValidateResult(json)
     }
 
+    async getValidateHistory(resultId: string) {
+        const params = new URLSearchParams()
+        params.set("resultId", resultId)
+        const json = await this.doGet("/api/getValidateHistory", params)
+        return new ValidateHistory(json)
+    }
+
     async validateNow(cluster: string, name: string, namespace: string) {
         return this.doPost("/api/validateNow", {
             "cluster": cluster,
@@ -224,11 +233,12 @@ export class RealApi implements Api {
         })
     }
 
-    async deployNow(cluster: string, name: string, namespace: string): Promise<Response> {
+    async deployNow(cluster: string, name: string, namespace: string, args?: any): Promise<Response> {
         return this.doPost("/api/deployNow", {
             "cluster": cluster,
             "name": name,
             "namespace": namespace,
+            "args": args,
         })
     }
 
@@ -378,6 +388,10 @@ export class StaticApi implements Api {
         throw new Error("not implemented")
     }
 
+    async getValidateHistory(resultId: string): Promise<ValidateHistory> {
+        throw new Error("not implemented")
+    }
+
     validateNow(cluster: string, name: string, namespace: string): Promise<Response> {
         throw new Error("not implemented")
     }
@@ -386,7 +400,7 @@ export class StaticApi implements Api {
         throw new Error("not implemented")
     }
 
-    deployNow(cluster: string, name: string, namespace: string): Promise<Response> {
+    deployNow(cluster: string, name: string, namespace: string, args?: any): Promise<Response> {
         throw new Error("not implemented")
     }
 
@@ -0,0 +1,99 @@
+import React, { useMemo } from 'react';
+import Table from '@mui/material/Table';
+import TableBody from '@mui/material/TableBody';
+import TableCell from '@mui/material/TableCell';
+import TableContainer from '@mui/material/TableContainer';
+import TableHead from '@mui/material/TableHead';
+import TableRow from '@mui/material/TableRow';
+import { Box, Chip, Typography } from "@mui/material";
+import { ObjectRef, ValidateHistoryTransition } from "../models";
+import { useAppContext } from "./App";
+import { Loading, useLoadingHelper } from "./Loading";
+import { ErrorMessage } from "./ErrorMessage";
+import { Since } from "./Since";
+
+interface TimelineEntry {
+    ref?: ObjectRef
+    transition: ValidateHistoryTransition
+}
+
+function refToString(ref?: ObjectRef) {
+    if (!ref) {
+        return "Target"
+    }
+    let s = ref.kind + "/" + ref.name
+    if (ref.namespace) {
+        s = ref.namespace + "/" + s
+    }
+    return s
+}
+
+export function ValidateHistoryTimeline(props: { validateResultId: string }) {
+    const appCtx = useAppContext()
+
+    const [loading, error, vh] = useLoadingHelper(true, async () => {
+        return await appCtx.api.getValidateHistory(props.validateResultId)
+    }, [props.validateResultId])
+
+    const entries = useMemo(() => {
+        const ret: TimelineEntry[] = []
+        vh?.transitions?.forEach(t => ret.push({ transition: t }))
+        vh?.objects?.forEach(o => {
+            o.transitions?.forEach(t => ret.push({ ref: o.ref, transition: t }))
+        })
+        // newest first
+        ret.sort((a, b) => new Date(b.transition.time).getTime() - new Date(a.transition.time).getTime())
+        return ret
+    }, [vh])
+
+    if (loading) {
+        return <Loading/>
+    }
+    if (error) {
+        return <ErrorMessage>
+            {error.message}
+        </ErrorMessage>
+    }
+    if (!entries.length) {
+        return <Typography>No readiness transitions recorded.</Typography>
+    }
+
+    return <>
+        <Box height={"100%"}>
+            <TableContainer>
+                <Table>
+                    <TableHead>
+                        <TableRow>
+                            <TableCell>Time</TableCell>
+                            <TableCell>Object</TableCell>
+                            <TableCell>State</TableCell>
+                            <TableCell>Message</TableCell>
+                        </TableRow>
+                    </TableHead>
+                    <TableBody>
+                        {entries.map((e, i) => (
+                            <TableRow key={i}>
+                                <TableCell sx={{ minWidth: "100px" }}>
+                                    <Since startTime={e.transition.time}/>
+                                </TableCell>
+                                <TableCell sx={{ minWidth: "150px" }}>
+                                    <Typography>{refToString(e.ref)}</Typography>
+                                </TableCell>
+                                <TableCell>
+                                    <Chip
+                                        size={"small"}
+                                        color={e.transition.ready ? "success" : "error"}
+                                        label={e.transition.ready ? "ready" : "not ready"}
+                                    />
+                                </TableCell>
+                                <TableCell>
+                                    {e.transition.message}
+                                </TableCell>
+                            </TableRow>
+                        ))}
+                    </TableBody>
+                </Table>
+            </TableContainer>
+        </Box>
+    </>
+}
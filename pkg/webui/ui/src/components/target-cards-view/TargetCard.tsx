@@ -10,6 +10,7 @@ import { PropertiesEntry, PropertiesTable, pushProp } from "../PropertiesTable";
 import { Loading, useLoadingHelper } from "../Loading";
 import { ErrorMessage } from "../ErrorMessage";
 import { ValidateResultsTable } from "../ValidateResultsTable";
+import { ValidateHistoryTimeline } from "../ValidateHistoryTimeline";
 import { LogsViewer } from "../LogsViewer";
 import { K8sManifestViewer } from "../K8sManifestViewer";
 import { YamlViewer } from "../YamlViewer";
@@ -227,6 +228,13 @@ class TargetItemCardProvider implements CardTabsProvider {
             })
         }
 
+        if (!appCtx.isStatic && this.lastValidateResult) {
+            tabs.push({
+                label: "Validation History",
+                content: <ValidateHistoryTimeline validateResultId={this.lastValidateResult.id}/>
+            })
+        }
+
         if (!appCtx.isStatic && this.ts.kd) {
             tabs.push({
                 label: "Logs", content: <LogsViewer
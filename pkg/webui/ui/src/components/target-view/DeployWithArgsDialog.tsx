@@ -0,0 +1,166 @@
+import { TargetSummary } from "../../project-summaries";
+import { useAppContext } from "../App";
+import React, { useEffect, useState } from "react";
+import {
+    Alert,
+    Box,
+    Button,
+    Checkbox,
+    Dialog,
+    DialogActions,
+    DialogContent,
+    DialogTitle,
+    FormControlLabel,
+    MenuItem,
+    TextField
+} from "@mui/material";
+import { DeploymentArg } from "../../models";
+
+const getSchemaType = (schema: any): string | undefined => {
+    if (!schema) {
+        return undefined
+    }
+    if (Array.isArray(schema.type)) {
+        return schema.type.find((t: string) => t !== "null")
+    }
+    return schema.type
+}
+
+const ArgField = (props: { arg: DeploymentArg, value: any, onChange: (v: any, valid: boolean) => void }) => {
+    const schema = props.arg.schema
+    const t = getSchemaType(schema)
+    const label = props.arg.name
+    const helperText = props.arg.description || schema?.description
+    const [jsonText, setJsonText] = useState(() => JSON.stringify(props.value, null, 2) || "")
+    const [jsonError, setJsonError] = useState<string>()
+
+    if (Array.isArray(schema?.enum)) {
+        return <TextField select fullWidth label={label} helperText={helperText}
+                          value={JSON.stringify(props.value)}
+                          onChange={e => props.onChange(JSON.parse(e.target.value), true)}>
+            {schema.enum.map((e: any) => {
+                const s = JSON.stringify(e)
+                return <MenuItem key={s} value={s}>{typeof e === "string" ? e : s}</MenuItem>
+            })}
+        </TextField>
+    }
+
+    switch (t) {
+        case "boolean":
+            return <Box>
+                <FormControlLabel label={label} control={
+                    <Checkbox checked={!!props.value} onChange={e => props.onChange(e.target.checked, true)}/>
+                }/>
+            </Box>
+        case "string":
+            return <TextField fullWidth label={label} helperText={helperText}
+                              value={props.value ?? ""}
+                              inputProps={{ pattern: schema.pattern }}
+                              onChange={e => props.onChange(e.target.value, true)}/>
+        case "number":
+        case "integer":
+            return <TextField fullWidth type={"number"} label={label} helperText={helperText}
+                              value={props.value ?? ""}
+                              inputProps={{ min: schema.minimum, max: schema.maximum, step: t === "integer" ? 1 : "any" }}
+                              onChange={e => {
+                                  const n = t === "integer" ? parseInt(e.target.value) : parseFloat(e.target.value)
+                                  props.onChange(n, !isNaN(n))
+                              }}/>
+        default:
+            // objects, arrays and args without a schema are edited as raw JSON
+            return <TextField fullWidth multiline minRows={2} label={label}
+                              error={!!jsonError} helperText={jsonError || helperText}
+                              value={jsonText}
+                              onChange={e => {
+                                  setJsonText(e.target.value)
+                                  try {
+                                      const v = e.target.value.trim() === "" ? undefined : JSON.parse(e.target.value)
+                                      setJsonError(undefined)
+                                      props.onChange(v, true)
+                                  } catch (error) {
+                                      setJsonError("Invalid JSON")
+                                      props.onChange(undefined, false)
+                                  }
+                              }}/>
+    }
+}
+
+export const DeployWithArgsDialog = (props: { ts: TargetSummary, open: boolean, onClose: () => void }) => {
+    const appCtx = useAppContext()
+    const [projectArgs, setProjectArgs] = useState<DeploymentArg[]>()
+    const [values, setValues] = useState<{ [key: string]: any }>({})
+    const [invalid, setInvalid] = useState<{ [key: string]: boolean }>({})
+    const [error, setError] = useState<string>()
+
+    useEffect(() => {
+        if (!props.open) {
+            return
+        }
+        const rs = props.ts.commandResults?.[0]
+        if (!rs) {
+            setProjectArgs([])
+            return
+        }
+
+        let cancelled = false
+        appCtx.api.getCommandResult(rs.id).then(cr => {
+            if (cancelled) {
+                return
+            }
+            const specArgs = props.ts.kd?.deployment.spec.args || {}
+            const initial: { [key: string]: any } = {}
+            cr.projectArgs?.forEach(a => {
+                initial[a.name] = a.name in specArgs ? specArgs[a.name] : a.default
+            })
+            setValues(initial)
+            setInvalid({})
+            setProjectArgs(cr.projectArgs || [])
+        }).catch(e => {
+            if (!cancelled) {
+                setError(e.message)
+            }
+        })
+        return () => {
+            cancelled = true
+        }
+    }, [props.open, props.ts, appCtx.api])
+
+    const kd = props.ts.kd
+    const hasInvalid = Object.values(invalid).some(x => x)
+
+    const handleDeploy = () => {
+        if (!kd) {
+            return
+        }
+        appCtx.api.deployNow(kd.clusterId, kd.deployment.metadata.name, kd.deployment.metadata.namespace, values)
+        props.onClose()
+    }
+
+    let content: React.ReactNode
+    if (error) {
+        content = <Alert severity={"error"}>{error}</Alert>
+    } else if (!projectArgs) {
+        content = "Loading..."
+    } else if (!projectArgs.length) {
+        content = <Alert severity={"info"}>This project does not declare any arguments.</Alert>
+    } else {
+        content = <Box display={"flex"} flexDirection={"column"} gap={2} paddingTop={1}>
+            {projectArgs.map(a => <ArgField key={a.name} arg={a} value={values[a.name]} onChange={(v, valid) => {
+                setValues(old => ({ ...old, [a.name]: v }))
+                setInvalid(old => ({ ...old, [a.name]: !valid }))
+            }}/>)}
+        </Box>
+    }
+
+    return <Dialog open={props.open} onClose={props.onClose} maxWidth={"sm"} fullWidth={true}
+                   onClick={e => e.stopPropagation()}>
+        <DialogTitle>Deploy with arguments</DialogTitle>
+        <DialogContent>
+            {content}
+        </DialogContent>
+        <DialogActions>
+            <Button onClick={props.onClose}>Cancel</Button>
+            <Button onClick={handleDeploy} disabled={!projectArgs || !!error || hasInvalid}>Deploy</Button>
+        </DialogActions>
+    </Dialog>
+}
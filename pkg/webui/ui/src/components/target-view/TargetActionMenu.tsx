@@ -3,11 +3,13 @@ import { useAppContext } from "../App";
 import { ActionMenuItem, ActionsMenu } from "../ActionsMenu";
 import { Pause, PlayArrow, PublishedWithChanges, RocketLaunch, Troubleshoot } from "@mui/icons-material";
 import { Typography } from "@mui/material";
-import React, { useMemo } from "react";
+import React, { useMemo, useState } from "react";
 import { PruneIcon } from "../../icons/Icons";
+import { DeployWithArgsDialog } from "./DeployWithArgsDialog";
 
 export const TargetActionMenu = (props: {ts: TargetSummary}) => {
     const appCtx = useAppContext()
+    const [deployWithArgsOpen, setDeployWithArgsOpen] = useState(false)
 
     const actionMenuItems = useMemo(() => {
         const kd = props.ts.kd
@@ -38,6 +40,13 @@ export const TargetActionMenu = (props: {ts: TargetSummary}) => {
                 appCtx.api.deployNow(kd.clusterId, kd.deployment.metadata.name, kd.deployment.metadata.namespace)
             }
         })
+        actionMenuItems.push({
+            icon: <RocketLaunch/>,
+            text: <Typography>Deploy with args...</Typography>,
+            handler: () => {
+                setDeployWithArgsOpen(true)
+            }
+        })
         actionMenuItems.push({
             icon: <PruneIcon size={"24px"}/>,
             text: <Typography>Prune</Typography>,
@@ -66,5 +75,8 @@ export const TargetActionMenu = (props: {ts: TargetSummary}) => {
         return actionMenuItems
     }, [props.ts, appCtx.user, appCtx.api])
 
-    return <ActionsMenu menuItems={actionMenuItems}/>
+    return <React.Fragment>
+        <ActionsMenu menuItems={actionMenuItems}/>
+        <DeployWithArgsDialog ts={props.ts} open={deployWithArgsOpen} onClose={() => setDeployWithArgsOpen(false)}/>
+    </React.Fragment>
 }
\ No newline at end of file
@@ -2,6 +2,90 @@
 
 import { GitRef } from './models-static'
 
+export class VarsProvenanceSkipped {
+    origin: string;
+    source: string;
+    when: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.origin = source["origin"];
+        this.source = source["source"];
+        this.when = source["when"];
+    }
+}
+export class VarsProvenanceEntry {
+    origin: string;
+    source: string;
+    action: string;
+    value?: string;
+    sensitive?: boolean;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.origin = source["origin"];
+        this.source = source["source"];
+        this.action = source["action"];
+        this.value = source["value"];
+        this.sensitive = source["sensitive"];
+    }
+}
+export class VarsProvenanceKey {
+    path: string;
+    entries: VarsProvenanceEntry[];
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.path = source["path"];
+        this.entries = this.convertValues(source["entries"], VarsProvenanceEntry);
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (a.slice) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
+export class VarsProvenance {
+    keys?: VarsProvenanceKey[];
+    skipped?: VarsProvenanceSkipped[];
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.keys = this.convertValues(source["keys"], VarsProvenanceKey);
+        this.skipped = this.convertValues(source["skipped"], VarsProvenanceSkipped);
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (a.slice) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
 export class DeploymentError {
     ref: ObjectRef;
     message: string;
@@ -88,6 +172,148 @@ export class ResultObject {
 	    return a;
 	}
 }
+export class DeploymentArg {
+    name: string;
+    default?: any;
+    description?: string;
+    schema?: any;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.name = source["name"];
+        this.default = source["default"];
+        this.description = source["description"];
+        this.schema = source["schema"];
+    }
+}
+export class DeploymentOutputObject {
+    group?: string;
+    kind?: string;
+    name: string;
+    namespace?: string;
+    jsonPath: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.group = source["group"];
+        this.kind = source["kind"];
+        this.name = source["name"];
+        this.namespace = source["namespace"];
+        this.jsonPath = source["jsonPath"];
+    }
+}
+export class DeploymentOutput {
+    name: string;
+    value?: any;
+    object?: DeploymentOutputObject;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.name = source["name"];
+        this.value = source["value"];
+        this.object = this.convertValues(source["object"], DeploymentOutputObject);
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (a.slice) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
+export class TransformationSetter {
+    fieldPath: string;
+    value?: any;
+    regex?: string;
+    replacement?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.fieldPath = source["fieldPath"];
+        this.value = source["value"];
+        this.regex = source["regex"];
+        this.replacement = source["replacement"];
+    }
+}
+export class JsonPatchOperation {
+    op: string;
+    path: string;
+    from?: string;
+    value?: any;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.op = source["op"];
+        this.path = source["path"];
+        this.from = source["from"];
+        this.value = source["value"];
+    }
+}
+export class TransformationTarget {
+    group?: string;
+    version?: string;
+    kind?: string;
+    name?: string;
+    namespace?: string;
+    labelSelector?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.group = source["group"];
+        this.version = source["version"];
+        this.kind = source["kind"];
+        this.name = source["name"];
+        this.namespace = source["namespace"];
+        this.labelSelector = source["labelSelector"];
+    }
+}
+export class TransformationConfig {
+    name?: string;
+    target?: TransformationTarget;
+    patch?: any;
+    jsonPatch?: JsonPatchOperation[];
+    set?: TransformationSetter[];
+    remove?: string[];
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.name = source["name"];
+        this.target = this.convertValues(source["target"], TransformationTarget);
+        this.patch = source["patch"];
+        this.jsonPatch = this.convertValues(source["jsonPatch"], JsonPatchOperation);
+        this.set = this.convertValues(source["set"], TransformationSetter);
+        this.remove = source["remove"];
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (a.slice) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
 export class ConflictResolutionConfig {
     fieldPath?: string[];
     fieldPathRegex?: string[];
@@ -128,6 +354,18 @@ export class IgnoreForDiffItemConfig {
         this.namespace = source["namespace"];
     }
 }
+export class RemoteResourceInfo {
+    url: string;
+    ref?: string;
+    commit?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.url = source["url"];
+        this.ref = source["ref"];
+        this.commit = source["commit"];
+    }
+}
 export class HelmChartConfig {
     repo?: string;
     path?: string;
@@ -172,6 +410,56 @@ export class WaitReadinessObjectItemConfig {
         this.namespace = source["namespace"];
     }
 }
+export class KrmStarlarkFunction {
+    path: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.path = source["path"];
+    }
+}
+export class KrmExecFunction {
+    path: string;
+    args?: string[];
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.path = source["path"];
+        this.args = source["args"];
+    }
+}
+export class KrmFunctionConfig {
+    exec?: KrmExecFunction;
+    starlark?: KrmStarlarkFunction;
+    config?: any;
+    generator?: boolean;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.exec = this.convertValues(source["exec"], KrmExecFunction);
+        this.starlark = this.convertValues(source["starlark"], KrmStarlarkFunction);
+        this.config = source["config"];
+        this.generator = source["generator"];
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (a.slice) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
 export class DeleteObjectItemConfig {
     group?: string;
     kind?: string;
@@ -186,14 +474,68 @@ export class DeleteObjectItemConfig {
         this.namespace = source["namespace"];
     }
 }
-export class OciRef {
-    digest?: string;
-    tag?: string;
+export class ManifestItemConfig {
+    url?: string;
+    sha256?: string;
+    oci?: OciProject;
+    skipTemplating?: boolean;
+    updateConstraints?: string;
+    updateGitUrl?: string;
+    skipUpdate?: boolean;
 
     constructor(source: any = {}) {
         if ('string' === typeof source) source = JSON.parse(source);
-        this.digest = source["digest"];
-        this.tag = source["tag"];
+        this.url = source["url"];
+        this.sha256 = source["sha256"];
+        this.oci = this.convertValues(source["oci"], OciProject);
+        this.skipTemplating = source["skipTemplating"];
+        this.updateConstraints = source["updateConstraints"];
+        this.updateGitUrl = source["updateGitUrl"];
+        this.skipUpdate = source["skipUpdate"];
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (a.slice) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
+export class CueItemConfig {
+    dir: string;
+    package?: string;
+    objectsPath?: string;
+    varsPath?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.dir = source["dir"];
+        this.package = source["package"];
+        this.objectsPath = source["objectsPath"];
+        this.varsPath = source["varsPath"];
+    }
+}
+export class JsonnetItemConfig {
+    main: string;
+    libs?: string[];
+    tlas?: any;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.main = source["main"];
+        this.libs = source["libs"];
+        this.tlas = source["tlas"];
     }
 }
 export class OciProject {
@@ -203,9 +545,291 @@ export class OciProject {
 
     constructor(source: any = {}) {
         if ('string' === typeof source) source = JSON.parse(source);
-        this.url = source["url"];
-        this.ref = this.convertValues(source["ref"], OciRef);
-        this.subDir = source["subDir"];
+        this.url = source["url"];
+        this.ref = this.convertValues(source["ref"], OciRef);
+        this.subDir = source["subDir"];
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (a.slice) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
+export class GitProject {
+    url: string;
+    ref?: GitRef;
+    subDir?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.url = source["url"];
+        this.ref = new GitRef(source["ref"]);
+        this.subDir = source["subDir"];
+    }
+}
+export class DeploymentItemConfig {
+    path?: string;
+    include?: string;
+    git?: GitProject;
+    oci?: OciProject;
+    jsonnet?: JsonnetItemConfig;
+    cue?: CueItemConfig;
+    manifest?: ManifestItemConfig;
+    deleteObjects?: DeleteObjectItemConfig[];
+    functions?: KrmFunctionConfig[];
+    tags?: string[];
+    barrier?: boolean;
+    message?: string;
+    waitReadiness?: boolean;
+    waitReadinessObjects?: WaitReadinessObjectItemConfig[];
+    args?: any;
+    passVars?: boolean;
+    vars?: VarsSource[];
+    skipDeleteIfTags?: boolean;
+    onlyRender?: boolean;
+    alwaysDeploy?: boolean;
+    when?: string;
+    renderedHelmChartConfig?: HelmChartConfig;
+    renderedObjects?: ObjectRef[];
+    renderedInclude?: DeploymentProjectConfig;
+    renderedRemoteResources?: RemoteResourceInfo[];
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.path = source["path"];
+        this.include = source["include"];
+        this.git = this.convertValues(source["git"], GitProject);
+        this.oci = this.convertValues(source["oci"], OciProject);
+        this.jsonnet = this.convertValues(source["jsonnet"], JsonnetItemConfig);
+        this.cue = this.convertValues(source["cue"], CueItemConfig);
+        this.manifest = this.convertValues(source["manifest"], ManifestItemConfig);
+        this.deleteObjects = this.convertValues(source["deleteObjects"], DeleteObjectItemConfig);
+        this.functions = this.convertValues(source["functions"], KrmFunctionConfig);
+        this.tags = source["tags"];
+        this.barrier = source["barrier"];
+        this.message = source["message"];
+        this.waitReadiness = source["waitReadiness"];
+        this.waitReadinessObjects = this.convertValues(source["waitReadinessObjects"], WaitReadinessObjectItemConfig);
+        this.args = source["args"];
+        this.passVars = source["passVars"];
+        this.vars = this.convertValues(source["vars"], VarsSource);
+        this.skipDeleteIfTags = source["skipDeleteIfTags"];
+        this.onlyRender = source["onlyRender"];
+        this.alwaysDeploy = source["alwaysDeploy"];
+        this.when = source["when"];
+        this.renderedHelmChartConfig = this.convertValues(source["renderedHelmChartConfig"], HelmChartConfig);
+        this.renderedObjects = this.convertValues(source["renderedObjects"], ObjectRef);
+        this.renderedInclude = this.convertValues(source["renderedInclude"], DeploymentProjectConfig);
+        this.renderedRemoteResources = this.convertValues(source["renderedRemoteResources"], RemoteResourceInfo);
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (a.slice) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
+export class SealedSecretsConfig {
+    outputPattern?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.outputPattern = source["outputPattern"];
+    }
+}
+export class GeneratedSecret {
+    name: string;
+    length?: number;
+    charset?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.name = source["name"];
+        this.length = source["length"];
+        this.charset = source["charset"];
+    }
+}
+export class VarsSourceGeneratedSecrets {
+    secrets: GeneratedSecret[];
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.secrets = this.convertValues(source["secrets"], GeneratedSecret);
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (a.slice) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
+export class VarsSourceKluctlOutputs {
+    repoKey: string;
+    subDir?: string;
+    target?: string;
+    discriminator?: string;
+    maxAge?: Duration;
+    namespace?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.repoKey = source["repoKey"];
+        this.subDir = source["subDir"];
+        this.target = source["target"];
+        this.discriminator = source["discriminator"];
+        this.maxAge = this.convertValues(source["maxAge"], Duration);
+        this.namespace = source["namespace"];
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (a.slice) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
+export class VarSourceAzureKeyVault {
+    vaultUri: string;
+    secretName: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.vaultUri = source["vaultUri"];
+        this.secretName = source["secretName"];
+    }
+}
+export class VarsSourceVaultAuthJwt {
+    role: string;
+    mountPath?: string;
+    token?: string;
+    tokenFile?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.role = source["role"];
+        this.mountPath = source["mountPath"];
+        this.token = source["token"];
+        this.tokenFile = source["tokenFile"];
+    }
+}
+export class VarsSourceVaultAuthAppRole {
+    roleId: string;
+    mountPath?: string;
+    secretId?: string;
+    secretIdFile?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.roleId = source["roleId"];
+        this.mountPath = source["mountPath"];
+        this.secretId = source["secretId"];
+        this.secretIdFile = source["secretIdFile"];
+    }
+}
+export class VarsSourceVaultServiceAccount {
+    name: string;
+    namespace: string;
+    audiences?: string[];
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.name = source["name"];
+        this.namespace = source["namespace"];
+        this.audiences = source["audiences"];
+    }
+}
+export class VarsSourceVaultAuthKubernetes {
+    role: string;
+    mountPath?: string;
+    serviceAccount?: VarsSourceVaultServiceAccount;
+    tokenFile?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.role = source["role"];
+        this.mountPath = source["mountPath"];
+        this.serviceAccount = this.convertValues(source["serviceAccount"], VarsSourceVaultServiceAccount);
+        this.tokenFile = source["tokenFile"];
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (a.slice) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
+export class VarsSourceVaultAuth {
+    tokenFile?: string;
+    kubernetes?: VarsSourceVaultAuthKubernetes;
+    appRole?: VarsSourceVaultAuthAppRole;
+    jwt?: VarsSourceVaultAuthJwt;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.tokenFile = source["tokenFile"];
+        this.kubernetes = this.convertValues(source["kubernetes"], VarsSourceVaultAuthKubernetes);
+        this.appRole = this.convertValues(source["appRole"], VarsSourceVaultAuthAppRole);
+        this.jwt = this.convertValues(source["jwt"], VarsSourceVaultAuthJwt);
     }
 
 	convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -226,62 +850,34 @@ export class OciProject {
 	    return a;
 	}
 }
-export class GitProject {
-    url: string;
-    ref?: GitRef;
-    subDir?: string;
+export class VarsSourceVaultKv {
+    mount: string;
+    version?: number;
+    secretVersion?: number;
 
     constructor(source: any = {}) {
         if ('string' === typeof source) source = JSON.parse(source);
-        this.url = source["url"];
-        this.ref = new GitRef(source["ref"]);
-        this.subDir = source["subDir"];
+        this.mount = source["mount"];
+        this.version = source["version"];
+        this.secretVersion = source["secretVersion"];
     }
 }
-export class DeploymentItemConfig {
-    path?: string;
-    include?: string;
-    git?: GitProject;
-    oci?: OciProject;
-    deleteObjects?: DeleteObjectItemConfig[];
-    tags?: string[];
-    barrier?: boolean;
-    message?: string;
-    waitReadiness?: boolean;
-    waitReadinessObjects?: WaitReadinessObjectItemConfig[];
-    args?: any;
-    passVars?: boolean;
-    vars?: VarsSource[];
-    skipDeleteIfTags?: boolean;
-    onlyRender?: boolean;
-    alwaysDeploy?: boolean;
-    when?: string;
-    renderedHelmChartConfig?: HelmChartConfig;
-    renderedObjects?: ObjectRef[];
-    renderedInclude?: DeploymentProjectConfig;
+export class VarsSourceVault {
+    address: string;
+    path: string;
+    namespace?: string;
+    kv?: VarsSourceVaultKv;
+    auth?: VarsSourceVaultAuth;
+    includeMetadata?: boolean;
 
     constructor(source: any = {}) {
         if ('string' === typeof source) source = JSON.parse(source);
+        this.address = source["address"];
         this.path = source["path"];
-        this.include = source["include"];
-        this.git = this.convertValues(source["git"], GitProject);
-        this.oci = this.convertValues(source["oci"], OciProject);
-        this.deleteObjects = this.convertValues(source["deleteObjects"], DeleteObjectItemConfig);
-        this.tags = source["tags"];
-        this.barrier = source["barrier"];
-        this.message = source["message"];
-        this.waitReadiness = source["waitReadiness"];
-        this.waitReadinessObjects = this.convertValues(source["waitReadinessObjects"], WaitReadinessObjectItemConfig);
-        this.args = source["args"];
-        this.passVars = source["passVars"];
-        this.vars = this.convertValues(source["vars"], VarsSource);
-        this.skipDeleteIfTags = source["skipDeleteIfTags"];
-        this.onlyRender = source["onlyRender"];
-        this.alwaysDeploy = source["alwaysDeploy"];
-        this.when = source["when"];
-        this.renderedHelmChartConfig = this.convertValues(source["renderedHelmChartConfig"], HelmChartConfig);
-        this.renderedObjects = this.convertValues(source["renderedObjects"], ObjectRef);
-        this.renderedInclude = this.convertValues(source["renderedInclude"], DeploymentProjectConfig);
+        this.namespace = source["namespace"];
+        this.kv = this.convertValues(source["kv"], VarsSourceVaultKv);
+        this.auth = this.convertValues(source["auth"], VarsSourceVaultAuth);
+        this.includeMetadata = source["includeMetadata"];
     }
 
 	convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -302,40 +898,46 @@ export class DeploymentItemConfig {
 	    return a;
 	}
 }
-export class SealedSecretsConfig {
-    outputPattern?: string;
-
-    constructor(source: any = {}) {
-        if ('string' === typeof source) source = JSON.parse(source);
-        this.outputPattern = source["outputPattern"];
-    }
-}
-export class VarSourceAzureKeyVault {
-    vaultUri: string;
+export class VarsSourceGcpSecretManager {
     secretName: string;
 
     constructor(source: any = {}) {
         if ('string' === typeof source) source = JSON.parse(source);
-        this.vaultUri = source["vaultUri"];
         this.secretName = source["secretName"];
     }
 }
-export class VarsSourceVault {
-    address: string;
-    path: string;
+export class VarsSourceAwsAppConfig {
+    application: string;
+    environment: string;
+    configurationProfile: string;
+    region?: string;
+    profile?: string;
 
     constructor(source: any = {}) {
         if ('string' === typeof source) source = JSON.parse(source);
-        this.address = source["address"];
-        this.path = source["path"];
+        this.application = source["application"];
+        this.environment = source["environment"];
+        this.configurationProfile = source["configurationProfile"];
+        this.region = source["region"];
+        this.profile = source["profile"];
     }
 }
-export class VarsSourceGcpSecretManager {
-    secretName: string;
+export class VarsSourceAwsParameterStore {
+    name?: string;
+    path?: string;
+    recursive?: boolean;
+    withDecryption?: boolean;
+    region?: string;
+    profile?: string;
 
     constructor(source: any = {}) {
         if ('string' === typeof source) source = JSON.parse(source);
-        this.secretName = source["secretName"];
+        this.name = source["name"];
+        this.path = source["path"];
+        this.recursive = source["recursive"];
+        this.withDecryption = source["withDecryption"];
+        this.region = source["region"];
+        this.profile = source["profile"];
     }
 }
 export class VarsSourceAwsSecretsManager {
@@ -350,6 +952,48 @@ export class VarsSourceAwsSecretsManager {
         this.profile = source["profile"];
     }
 }
+export class Duration {
+    Duration: number;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.Duration = source["Duration"];
+    }
+}
+export class VarsSourceCommand {
+    command: string[];
+    env?: {[key: string]: string};
+    workDir?: string;
+    timeout?: Duration;
+    format?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.command = source["command"];
+        this.env = source["env"];
+        this.workDir = source["workDir"];
+        this.timeout = this.convertValues(source["timeout"], Duration);
+        this.format = source["format"];
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (a.slice) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
 export class VarsSourceHttp {
     url?: string;
     method?: string;
@@ -406,6 +1050,48 @@ export class VarsSourceClusterConfigMapOrSecret {
         this.targetPath = source["targetPath"];
     }
 }
+export class OciRef {
+    digest?: string;
+    semver?: string;
+    tag?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.digest = source["digest"];
+        this.semver = source["semver"];
+        this.tag = source["tag"];
+    }
+}
+export class VarsSourceOci {
+    url: string;
+    ref?: OciRef;
+    path: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.url = source["url"];
+        this.ref = this.convertValues(source["ref"], OciRef);
+        this.path = source["path"];
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (a.slice) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
 export class GitFile {
     glob: string;
     render?: boolean;
@@ -470,17 +1156,25 @@ export class VarsSource {
     file?: string;
     git?: VarsSourceGit;
     gitFiles?: VarsSourceGitFiles;
+    oci?: VarsSourceOci;
     clusterConfigMap?: VarsSourceClusterConfigMapOrSecret;
     clusterSecret?: VarsSourceClusterConfigMapOrSecret;
     clusterObject?: VarsSourceClusterObject;
     systemEnvVars?: any;
     http?: VarsSourceHttp;
+    command?: VarsSourceCommand;
     awsSecretsManager?: VarsSourceAwsSecretsManager;
+    awsParameterStore?: VarsSourceAwsParameterStore;
+    awsAppConfig?: VarsSourceAwsAppConfig;
     gcpSecretManager?: VarsSourceGcpSecretManager;
     vault?: VarsSourceVault;
     azureKeyVault?: VarSourceAzureKeyVault;
+    kluctlOutputs?: VarsSourceKluctlOutputs;
+    generatedSecrets?: VarsSourceGeneratedSecrets;
     targetPath?: string;
+    format?: string;
     when?: string;
+    cacheTTL?: Duration;
     renderedSensitive?: boolean;
     renderedVars?: any;
 
@@ -493,17 +1187,25 @@ export class VarsSource {
         this.file = source["file"];
         this.git = this.convertValues(source["git"], VarsSourceGit);
         this.gitFiles = this.convertValues(source["gitFiles"], VarsSourceGitFiles);
+        this.oci = this.convertValues(source["oci"], VarsSourceOci);
         this.clusterConfigMap = this.convertValues(source["clusterConfigMap"], VarsSourceClusterConfigMapOrSecret);
         this.clusterSecret = this.convertValues(source["clusterSecret"], VarsSourceClusterConfigMapOrSecret);
         this.clusterObject = this.convertValues(source["clusterObject"], VarsSourceClusterObject);
         this.systemEnvVars = source["systemEnvVars"];
         this.http = this.convertValues(source["http"], VarsSourceHttp);
+        this.command = this.convertValues(source["command"], VarsSourceCommand);
         this.awsSecretsManager = this.convertValues(source["awsSecretsManager"], VarsSourceAwsSecretsManager);
+        this.awsParameterStore = this.convertValues(source["awsParameterStore"], VarsSourceAwsParameterStore);
+        this.awsAppConfig = this.convertValues(source["awsAppConfig"], VarsSourceAwsAppConfig);
         this.gcpSecretManager = this.convertValues(source["gcpSecretManager"], VarsSourceGcpSecretManager);
         this.vault = this.convertValues(source["vault"], VarsSourceVault);
         this.azureKeyVault = this.convertValues(source["azureKeyVault"], VarSourceAzureKeyVault);
+        this.kluctlOutputs = this.convertValues(source["kluctlOutputs"], VarsSourceKluctlOutputs);
+        this.generatedSecrets = this.convertValues(source["generatedSecrets"], VarsSourceGeneratedSecrets);
         this.targetPath = source["targetPath"];
+        this.format = source["format"];
         this.when = source["when"];
+        this.cacheTTL = this.convertValues(source["cacheTTL"], Duration);
         this.renderedSensitive = source["renderedSensitive"];
         this.renderedVars = source["renderedVars"];
     }
@@ -537,6 +1239,8 @@ export class DeploymentProjectConfig {
     tags?: string[];
     ignoreForDiff?: IgnoreForDiffItemConfig[];
     conflictResolution?: ConflictResolutionConfig[];
+    transformations?: TransformationConfig[];
+    outputs?: DeploymentOutput[];
 
     constructor(source: any = {}) {
         if ('string' === typeof source) source = JSON.parse(source);
@@ -550,6 +1254,8 @@ export class DeploymentProjectConfig {
         this.tags = source["tags"];
         this.ignoreForDiff = this.convertValues(source["ignoreForDiff"], IgnoreForDiffItemConfig);
         this.conflictResolution = this.convertValues(source["conflictResolution"], ConflictResolutionConfig);
+        this.transformations = this.convertValues(source["transformations"], TransformationConfig);
+        this.outputs = this.convertValues(source["outputs"], DeploymentOutput);
     }
 
 	convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -668,6 +1374,14 @@ export class CommandInfo {
 	    return a;
 	}
 }
+export class TargetGeneratedSecrets {
+    namespace?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.namespace = source["namespace"];
+    }
+}
 export class ObjectRef {
     group?: string;
     version?: string;
@@ -786,6 +1500,7 @@ export class Target {
     aws?: AwsConfig;
     images?: FixedImage[];
     discriminator?: string;
+    generatedSecrets?: TargetGeneratedSecrets;
 
     constructor(source: any = {}) {
         if ('string' === typeof source) source = JSON.parse(source);
@@ -796,6 +1511,7 @@ export class Target {
         this.aws = this.convertValues(source["aws"], AwsConfig);
         this.images = this.convertValues(source["images"], FixedImage);
         this.discriminator = source["discriminator"];
+        this.generatedSecrets = this.convertValues(source["generatedSecrets"], TargetGeneratedSecrets);
     }
 
 	convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -850,11 +1566,14 @@ export class CommandResult {
     gitInfo?: GitInfo;
     clusterInfo: ClusterInfo;
     deployment?: DeploymentProjectConfig;
+    projectArgs?: DeploymentArg[];
     renderedObjectsHash?: string;
     objects?: ResultObject[];
     errors?: DeploymentError[];
     warnings?: DeploymentError[];
     seenImages?: FixedImage[];
+    varsProvenance?: VarsProvenance;
+    outputs?: any;
 
     constructor(source: any = {}) {
         if ('string' === typeof source) source = JSON.parse(source);
@@ -869,11 +1588,14 @@ export class CommandResult {
         this.gitInfo = this.convertValues(source["gitInfo"], GitInfo);
         this.clusterInfo = this.convertValues(source["clusterInfo"], ClusterInfo);
         this.deployment = this.convertValues(source["deployment"], DeploymentProjectConfig);
+        this.projectArgs = this.convertValues(source["projectArgs"], DeploymentArg);
         this.renderedObjectsHash = source["renderedObjectsHash"];
         this.objects = this.convertValues(source["objects"], ResultObject);
         this.errors = this.convertValues(source["errors"], DeploymentError);
         this.warnings = this.convertValues(source["warnings"], DeploymentError);
         this.seenImages = this.convertValues(source["seenImages"], FixedImage);
+        this.varsProvenance = this.convertValues(source["varsProvenance"], VarsProvenance);
+        this.outputs = source["outputs"];
     }
 
 	convertValues(a: any, classs: any, asMap: boolean = false): any {
@@ -1088,6 +1810,120 @@ export class ValidateResultSummary {
 	    return a;
 	}
 }
+export class ValidateHistoryObject {
+    ref: ObjectRef;
+    transitions?: ValidateHistoryTransition[];
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.ref = this.convertValues(source["ref"], ObjectRef);
+        this.transitions = this.convertValues(source["transitions"], ValidateHistoryTransition);
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (a.slice) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
+export class ValidateHistoryTransition {
+    time: string;
+    ready: boolean;
+    message?: string;
+    validateResultId?: string;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.time = source["time"];
+        this.ready = source["ready"];
+        this.message = source["message"];
+        this.validateResultId = source["validateResultId"];
+    }
+}
+export class ValidateHistory {
+    projectKey: ProjectKey;
+    targetKey: TargetKey;
+    kluctlDeployment?: KluctlDeploymentInfo;
+    lastValidateTime: string;
+    transitions?: ValidateHistoryTransition[];
+    objects?: ValidateHistoryObject[];
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.projectKey = this.convertValues(source["projectKey"], ProjectKey);
+        this.targetKey = this.convertValues(source["targetKey"], TargetKey);
+        this.kluctlDeployment = this.convertValues(source["kluctlDeployment"], KluctlDeploymentInfo);
+        this.lastValidateTime = source["lastValidateTime"];
+        this.transitions = this.convertValues(source["transitions"], ValidateHistoryTransition);
+        this.objects = this.convertValues(source["objects"], ValidateHistoryObject);
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (a.slice) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
+export class ProjectOutputs {
+    projectKey: ProjectKey;
+    targetKey: TargetKey;
+    kluctlDeployment?: KluctlDeploymentInfo;
+    commandResultId: string;
+    time: string;
+    outputs?: any;
+
+    constructor(source: any = {}) {
+        if ('string' === typeof source) source = JSON.parse(source);
+        this.projectKey = this.convertValues(source["projectKey"], ProjectKey);
+        this.targetKey = this.convertValues(source["targetKey"], TargetKey);
+        this.kluctlDeployment = this.convertValues(source["kluctlDeployment"], KluctlDeploymentInfo);
+        this.commandResultId = source["commandResultId"];
+        this.time = source["time"];
+        this.outputs = source["outputs"];
+    }
+
+	convertValues(a: any, classs: any, asMap: boolean = false): any {
+	    if (!a) {
+	        return a;
+	    }
+	    if (a.slice) {
+	        return (a as any[]).map(elem => this.convertValues(elem, classs));
+	    } else if ("object" === typeof a) {
+	        if (asMap) {
+	            for (const key of Object.keys(a)) {
+	                a[key] = new classs(a[key]);
+	            }
+	            return a;
+	        }
+	        return new classs(a);
+	    }
+	    return a;
+	}
+}
 export class DriftedObject {
     ref: ObjectRef;
     changes?: Change[];
@@ -0,0 +1,342 @@
+package yaml
+
+import (
+	"encoding/json"
+	"fmt"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// JSONSchema is a JSON schema in its generic map representation
+type JSONSchema = map[string]any
+
+// SchemaExtensionFunc is called with the generated schema of the type it was registered for. It can either modify
+// the schema in-place or return a completely new schema.
+type SchemaExtensionFunc func(s JSONSchema) JSONSchema
+
+var (
+	schemaExtensions      = map[reflect.Type]SchemaExtensionFunc{}
+	schemaExtensionsMutex sync.Mutex
+
+	schemaDescriptions = map[reflect.Type]map[string]string{}
+
+	schemaCache sync.Map
+
+	jsonUnmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	rawMessageType      = reflect.TypeOf(json.RawMessage{})
+)
+
+// RegisterSchemaExtension registers a function that is called whenever a schema for the given type is generated. This
+// is used to express custom validation rules (see Validator.RegisterStructValidation) and custom unmarshalling logic
+// in the generated schema.
+func RegisterSchemaExtension(fn SchemaExtensionFunc, typ any) {
+	schemaExtensionsMutex.Lock()
+	defer schemaExtensionsMutex.Unlock()
+	schemaExtensions[reflect.TypeOf(typ)] = fn
+}
+
+func init() {
+	RegisterSchemaExtension(func(s JSONSchema) JSONSchema {
+		return JSONSchema{"type": "string"}
+	}, metav1.Duration{})
+}
+
+// RegisterSchemaDescriptions registers descriptions for the fields of the given struct type. The keys of descs are
+// the JSON field names.
+func RegisterSchemaDescriptions(descs map[string]string, typ any) {
+	schemaExtensionsMutex.Lock()
+	defer schemaExtensionsMutex.Unlock()
+	schemaDescriptions[reflect.TypeOf(typ)] = descs
+}
+
+func getSchemaDescriptions(t reflect.Type) map[string]string {
+	schemaExtensionsMutex.Lock()
+	defer schemaExtensionsMutex.Unlock()
+	return schemaDescriptions[t]
+}
+
+func getSchemaExtension(t reflect.Type) SchemaExtensionFunc {
+	schemaExtensionsMutex.Lock()
+	defer schemaExtensionsMutex.Unlock()
+	return schemaExtensions[t]
+}
+
+// GenerateSchema generates a JSON schema (draft-07) for the type of the given object. Named struct types are put into
+// 'definitions' and referenced via '$ref'.
+func GenerateSchema(o any) JSONSchema {
+	t := reflect.TypeOf(o)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if s, ok := schemaCache.Load(t); ok {
+		return s.(JSONSchema)
+	}
+
+	g := schemaGenerator{
+		definitions: map[string]JSONSchema{},
+		names:       map[reflect.Type]string{},
+	}
+	s := g.typeSchema(t)
+	s["$schema"] = "http://json-schema.org/draft-07/schema#"
+	if len(g.definitions) != 0 {
+		s["definitions"] = g.definitions
+	}
+
+	schemaCache.Store(t, s)
+	return s
+}
+
+type schemaGenerator struct {
+	definitions map[string]JSONSchema
+	names       map[reflect.Type]string
+}
+
+func (g *schemaGenerator) typeSchema(t reflect.Type) JSONSchema {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() == reflect.Struct && t.Name() != "" {
+		return JSONSchema{"$ref": "#/definitions/" + g.defineStruct(t)}
+	}
+
+	s := g.buildTypeSchema(t)
+	if fn := getSchemaExtension(t); fn != nil {
+		s = fn(s)
+	} else if t != rawMessageType && t.Kind() != reflect.Interface && reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+		// we don't know what the custom unmarshaller accepts
+		s = JSONSchema{}
+	}
+	return s
+}
+
+func (g *schemaGenerator) defineStruct(t reflect.Type) string {
+	if name, ok := g.names[t]; ok {
+		return name
+	}
+
+	name := t.Name()
+	if _, ok := g.definitions[name]; ok {
+		// name clash with a type from another package
+		pkg := t.PkgPath()
+		pkg = pkg[strings.LastIndex(pkg, "/")+1:]
+		name = pkg + "." + name
+	}
+	g.names[t] = name
+	// reserve the name before recursing so that recursive types terminate
+	g.definitions[name] = JSONSchema{}
+
+	var s JSONSchema
+	if t == reflect.TypeOf(time.Time{}) {
+		s = JSONSchema{"type": "string", "format": "date-time"}
+	} else {
+		s = g.buildStructSchema(t)
+	}
+	if props, ok := s["properties"].(JSONSchema); ok {
+		for k, d := range getSchemaDescriptions(t) {
+			if ps, ok := props[k].(JSONSchema); ok {
+				ps["description"] = d
+			}
+		}
+	}
+	if fn := getSchemaExtension(t); fn != nil {
+		s = fn(s)
+	} else if reflect.PointerTo(t).Implements(jsonUnmarshalerType) {
+		s = JSONSchema{}
+	}
+	g.definitions[name] = s
+	return name
+}
+
+func (g *schemaGenerator) buildTypeSchema(t reflect.Type) JSONSchema {
+	switch t.Kind() {
+	case reflect.Bool:
+		return JSONSchema{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return JSONSchema{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return JSONSchema{"type": "number"}
+	case reflect.String:
+		return JSONSchema{"type": "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return JSONSchema{"type": "string"}
+		}
+		return JSONSchema{"type": "array", "items": g.typeSchema(t.Elem())}
+	case reflect.Map:
+		return JSONSchema{"type": "object", "additionalProperties": g.typeSchema(t.Elem())}
+	case reflect.Struct:
+		return g.buildStructSchema(t)
+	default:
+		return JSONSchema{}
+	}
+}
+
+func (g *schemaGenerator) buildStructSchema(t reflect.Type) JSONSchema {
+	props := JSONSchema{}
+	var required []any
+	g.addStructFields(t, props, &required)
+
+	s := JSONSchema{
+		"type":                 "object",
+		"properties":           props,
+		"additionalProperties": false,
+	}
+	if len(required) != 0 {
+		s["required"] = required
+	}
+	return s
+}
+
+func (g *schemaGenerator) addStructFields(t reflect.Type, props JSONSchema, required *[]any) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() && !f.Anonymous {
+			continue
+		}
+
+		jsonTag := f.Tag.Get("json")
+		name, opts, _ := strings.Cut(jsonTag, ",")
+		if name == "-" {
+			continue
+		}
+
+		ft := f.Type
+		for ft.Kind() == reflect.Pointer {
+			ft = ft.Elem()
+		}
+		if name == "" && (f.Anonymous || strings.Contains(opts, "inline")) && ft.Kind() == reflect.Struct {
+			g.addStructFields(ft, props, required)
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+
+		ps := g.typeSchema(f.Type)
+		isRequired := applyValidateTag(f.Tag.Get("validate"), ft, ps)
+		props[name] = ps
+		if isRequired {
+			*required = append(*required, name)
+		}
+	}
+}
+
+// applyValidateTag translates the validate tags that are used in kluctl types into their JSON schema counterparts.
+// It returns true if the field is required.
+func applyValidateTag(tag string, t reflect.Type, s JSONSchema) bool {
+	if tag == "" {
+		return false
+	}
+	isRequired := false
+	for _, rule := range strings.Split(tag, ",") {
+		k, v, _ := strings.Cut(rule, "=")
+		switch k {
+		case "required":
+			isRequired = true
+		case "oneof":
+			var enum []any
+			for _, x := range strings.Fields(v) {
+				enum = append(enum, convertValidateParam(t, x))
+			}
+			s["enum"] = enum
+		case "min":
+			n, err := strconv.Atoi(v)
+			if err != nil {
+				continue
+			}
+			switch t.Kind() {
+			case reflect.Slice, reflect.Array:
+				s["minItems"] = n
+			case reflect.String:
+				s["minLength"] = n
+			case reflect.Map:
+				s["minProperties"] = n
+			default:
+				s["minimum"] = n
+			}
+		}
+	}
+	return isRequired
+}
+
+func convertValidateParam(t reflect.Type, x string) any {
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		if n, err := strconv.Atoi(x); err == nil {
+			return n
+		}
+	}
+	return x
+}
+
+// schemaErrorMessageKey is used to attach human-readable messages to rules added via the Schema* helpers. The key is
+// also understood by some editors (e.g. yaml-language-server).
+const schemaErrorMessageKey = "errorMessage"
+
+// SchemaRemoveProperties removes the given properties from s. This is used for fields that are only meant to be written
+// by Kluctl itself.
+func SchemaRemoveProperties(s JSONSchema, props ...string) {
+	m, _ := s["properties"].(JSONSchema)
+	for _, p := range props {
+		delete(m, p)
+	}
+}
+
+func addSchemaRule(s JSONSchema, rule JSONSchema, msg string) {
+	rule[schemaErrorMessageKey] = msg
+	allOf, _ := s["allOf"].([]any)
+	s["allOf"] = append(allOf, rule)
+}
+
+func requiredRules(props []string) []any {
+	var ret []any
+	for _, p := range props {
+		ret = append(ret, JSONSchema{"required": []any{p}})
+	}
+	return ret
+}
+
+// SchemaRequireAnyOf adds a rule to s that requires at least one of the given properties to be set
+func SchemaRequireAnyOf(s JSONSchema, props ...string) {
+	addSchemaRule(s, JSONSchema{"anyOf": requiredRules(props)},
+		fmt.Sprintf("at least one of %s must be set", strings.Join(props, ", ")))
+}
+
+// SchemaRequireOneOf adds a rule to s that requires exactly one of the given properties to be set
+func SchemaRequireOneOf(s JSONSchema, props ...string) {
+	addSchemaRule(s, JSONSchema{"oneOf": requiredRules(props)},
+		fmt.Sprintf("exactly one of %s must be set", strings.Join(props, ", ")))
+}
+
+// SchemaMutuallyExclusive adds a rule to s that forbids setting more than one of the given properties
+func SchemaMutuallyExclusive(s JSONSchema, props ...string) {
+	var pairs []any
+	for i := 0; i < len(props); i++ {
+		for j := i + 1; j < len(props); j++ {
+			pairs = append(pairs, JSONSchema{"required": []any{props[i], props[j]}})
+		}
+	}
+	addSchemaRule(s, JSONSchema{"not": JSONSchema{"anyOf": pairs}},
+		fmt.Sprintf("only one of %s can be set at the same time", strings.Join(props, ", ")))
+}
+
+// SchemaIfThen adds a rule to s that applies 'then' when 'cond' matches. msg is reported when the rule is violated.
+func SchemaIfThen(s JSONSchema, cond JSONSchema, then JSONSchema, msg string) {
+	addSchemaRule(s, JSONSchema{"if": cond, "then": then}, msg)
+}
+
+// SchemaStringOr returns a schema that accepts either a plain string or whatever s accepts
+func SchemaStringOr(s JSONSchema) JSONSchema {
+	return JSONSchema{
+		"if":   JSONSchema{"type": "string"},
+		"then": JSONSchema{"type": "string"},
+		"else": s,
+	}
+}
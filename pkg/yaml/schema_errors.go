@@ -0,0 +1,434 @@
+package yaml
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/xeipuuv/gojsonschema"
+	yamlv3 "gopkg.in/yaml.v3"
+	"sigs.k8s.io/yaml"
+)
+
+// PositionedError is a validation error located at a specific position inside a YAML document
+type PositionedError struct {
+	File    string `json:"file,omitempty"`
+	Line    int    `json:"line"`
+	Column  int    `json:"column"`
+	Path    string `json:"path,omitempty"`
+	Message string `json:"message"`
+}
+
+func (e *PositionedError) Error() string {
+	var sb strings.Builder
+	if e.File != "" {
+		sb.WriteString(e.File)
+		sb.WriteString(":")
+	}
+	sb.WriteString(fmt.Sprintf("%d:%d: ", e.Line, e.Column))
+	if e.Path != "" {
+		sb.WriteString(e.Path)
+		sb.WriteString(": ")
+	}
+	sb.WriteString(e.Message)
+	return sb.String()
+}
+
+// PositionedErrors is returned when a YAML document failed validation against the schema of the target type
+type PositionedErrors []*PositionedError
+
+func (e PositionedErrors) Error() string {
+	var l []string
+	for _, x := range e {
+		l = append(l, x.Error())
+	}
+	return strings.Join(l, "\n")
+}
+
+var compiledSchemaCache sync.Map
+
+func getCompiledSchema(o any) (*gojsonschema.Schema, JSONSchema, error) {
+	t := reflect.TypeOf(o)
+	s := GenerateSchema(o)
+	if c, ok := compiledSchemaCache.Load(t); ok {
+		return c.(*gojsonschema.Schema), s, nil
+	}
+	c, err := gojsonschema.NewSchema(gojsonschema.NewGoLoader(s))
+	if err != nil {
+		return nil, nil, err
+	}
+	compiledSchemaCache.Store(t, c)
+	return c, s, nil
+}
+
+// ValidateYamlWithSchema validates the given YAML document against the JSON schema generated for the type of o. All
+// errors are returned with their line and column inside the document. It returns nil if the document is valid or if
+// it could not be parsed at all.
+func ValidateYamlWithSchema(file string, b []byte, o any) PositionedErrors {
+	var root yamlv3.Node
+	err := yamlv3.Unmarshal(b, &root)
+	if err != nil || len(root.Content) == 0 {
+		return nil
+	}
+	doc := root.Content[0]
+
+	var x any
+	err = yaml.Unmarshal(b, &x)
+	if err != nil {
+		return nil
+	}
+
+	compiled, schema, err := getCompiledSchema(o)
+	if err != nil {
+		return nil
+	}
+	result, err := compiled.Validate(gojsonschema.NewGoLoader(x))
+	if err != nil || result.Valid() {
+		return nil
+	}
+
+	type pathErrors struct {
+		path       []string
+		errs       []gojsonschema.ResultError
+		hasCombine bool
+	}
+	byPath := map[string]*pathErrors{}
+	var order []string
+	for _, e := range result.Errors() {
+		path := splitSchemaContext(e.Context())
+		k := strings.Join(path, "\x00")
+		pe, ok := byPath[k]
+		if !ok {
+			pe = &pathErrors{path: path}
+			byPath[k] = pe
+			order = append(order, k)
+		}
+		pe.errs = append(pe.errs, e)
+		if isCombinatorError(e) {
+			pe.hasCombine = true
+		}
+	}
+
+	var ret PositionedErrors
+	for _, k := range order {
+		pe := byPath[k]
+		value := getValueAtPath(x, pe.path)
+
+		var ruleMsgs []string
+		covered := map[string]bool{}
+		if pe.hasCombine {
+			ruleMsgs, covered = checkSchemaRules(schema, resolveSchemaAtPath(schema, schema, x, pe.path), value)
+		}
+
+		for _, e := range pe.errs {
+			if isCombinatorError(e) {
+				if len(ruleMsgs) == 0 {
+					ret = append(ret, buildPositionedError(file, doc, pe.path, "", e.Description()))
+				}
+				continue
+			}
+			prop, _ := e.Details()["property"].(string)
+			if e.Type() == "required" && covered[prop] {
+				continue
+			}
+			if e.Type() == "additional_property_not_allowed" {
+				ret = append(ret, buildPositionedError(file, doc, pe.path, prop, fmt.Sprintf("unknown field \"%s\"", prop)))
+				continue
+			}
+			ret = append(ret, buildPositionedError(file, doc, pe.path, "", e.Description()))
+		}
+		for _, m := range ruleMsgs {
+			ret = append(ret, buildPositionedError(file, doc, pe.path, "", m))
+		}
+	}
+
+	ret = dedupPositionedErrors(ret)
+	sort.SliceStable(ret, func(i, j int) bool {
+		if ret[i].Line != ret[j].Line {
+			return ret[i].Line < ret[j].Line
+		}
+		return ret[i].Column < ret[j].Column
+	})
+	return ret
+}
+
+func isCombinatorError(e gojsonschema.ResultError) bool {
+	switch e.Type() {
+	case "number_one_of", "number_any_of", "number_all_of", "number_not", "condition_then", "condition_else":
+		return true
+	}
+	return false
+}
+
+func splitSchemaContext(ctx *gojsonschema.JsonContext) []string {
+	l := strings.Split(ctx.String("\x00"), "\x00")
+	if len(l) != 0 && l[0] == gojsonschema.STRING_CONTEXT_ROOT {
+		l = l[1:]
+	}
+	return l
+}
+
+func getValueAtPath(x any, path []string) any {
+	for _, p := range path {
+		switch v := x.(type) {
+		case map[string]any:
+			x = v[p]
+		case []any:
+			i, err := strconv.Atoi(p)
+			if err != nil || i < 0 || i >= len(v) {
+				return nil
+			}
+			x = v[i]
+		default:
+			return nil
+		}
+	}
+	return x
+}
+
+func resolveRef(root JSONSchema, s JSONSchema) JSONSchema {
+	for {
+		ref, ok := s["$ref"].(string)
+		if !ok {
+			return s
+		}
+		name := strings.TrimPrefix(ref, "#/definitions/")
+		defs, _ := root["definitions"].(map[string]JSONSchema)
+		next, ok := defs[name]
+		if !ok {
+			return s
+		}
+		s = next
+	}
+}
+
+// resolveSchemaAtPath follows the given path through the schema. It only understands the constructs produced by
+// GenerateSchema.
+func resolveSchemaAtPath(root JSONSchema, s JSONSchema, value any, path []string) JSONSchema {
+	s = resolveRef(root, s)
+	if _, isString := value.(string); !isString {
+		// follow SchemaStringOr
+		if e, ok := s["else"].(JSONSchema); ok {
+			s = resolveRef(root, e)
+		}
+	}
+	if len(path) == 0 {
+		return s
+	}
+
+	var next JSONSchema
+	if props, ok := s["properties"].(JSONSchema); ok {
+		if ps, ok := props[path[0]].(JSONSchema); ok {
+			next = ps
+		}
+	}
+	if next == nil {
+		if items, ok := s["items"].(JSONSchema); ok {
+			next = items
+		} else if ap, ok := s["additionalProperties"].(JSONSchema); ok {
+			next = ap
+		}
+	}
+	if next == nil {
+		return nil
+	}
+	return resolveSchemaAtPath(root, next, getValueAtPath(value, path[:1]), path[1:])
+}
+
+// checkSchemaRules evaluates all rules added via the Schema* helpers and returns the messages of the violated rules.
+// It also returns the properties that are referenced by the violated rules, so that redundant 'required' errors can be
+// suppressed.
+func checkSchemaRules(root JSONSchema, s JSONSchema, value any) ([]string, map[string]bool) {
+	covered := map[string]bool{}
+	if s == nil {
+		return nil, covered
+	}
+	allOf, _ := s["allOf"].([]any)
+
+	var msgs []string
+	for _, r := range allOf {
+		rule, ok := r.(JSONSchema)
+		if !ok {
+			continue
+		}
+		msg, ok := rule[schemaErrorMessageKey].(string)
+		if !ok {
+			continue
+		}
+		c, err := gojsonschema.NewSchema(gojsonschema.NewGoLoader(rule))
+		if err != nil {
+			continue
+		}
+		result, err := c.Validate(gojsonschema.NewGoLoader(value))
+		if err != nil || result.Valid() {
+			continue
+		}
+		msgs = append(msgs, msg)
+		collectRequired(rule, covered)
+	}
+	return msgs, covered
+}
+
+func collectRequired(x any, out map[string]bool) {
+	switch v := x.(type) {
+	case JSONSchema:
+		for k, y := range v {
+			if k == "required" {
+				if l, ok := y.([]any); ok {
+					for _, p := range l {
+						if s, ok := p.(string); ok {
+							out[s] = true
+						}
+					}
+				}
+				continue
+			}
+			collectRequired(y, out)
+		}
+	case []any:
+		for _, y := range v {
+			collectRequired(y, out)
+		}
+	}
+}
+
+// NewPositionedError returns an error located at the given path inside the YAML document b. If childKey is set, the
+// error points to the key of the given child.
+func NewPositionedError(file string, b []byte, path []string, childKey string, msg string) *PositionedError {
+	var root yamlv3.Node
+	err := yamlv3.Unmarshal(b, &root)
+	if err != nil || len(root.Content) == 0 {
+		return &PositionedError{
+			File:    file,
+			Path:    formatPath(append(append([]string{}, path...), childKey)),
+			Message: msg,
+		}
+	}
+	return buildPositionedError(file, root.Content[0], path, childKey, msg)
+}
+
+func buildPositionedError(file string, doc *yamlv3.Node, path []string, childKey string, msg string) *PositionedError {
+	n := findYamlNode(doc, path, childKey)
+	displayPath := path
+	if childKey != "" {
+		displayPath = append(append([]string{}, path...), childKey)
+	}
+	return &PositionedError{
+		File:    file,
+		Line:    n.Line,
+		Column:  n.Column,
+		Path:    formatPath(displayPath),
+		Message: msg,
+	}
+}
+
+// findYamlNode returns the node at the given path. If childKey is set, the key node of the given child is returned.
+// If the path can not be followed completely, the deepest node that was found is returned.
+func findYamlNode(n *yamlv3.Node, path []string, childKey string) *yamlv3.Node {
+	for n.Kind == yamlv3.AliasNode && n.Alias != nil {
+		n = n.Alias
+	}
+	if len(path) == 0 {
+		if childKey != "" && n.Kind == yamlv3.MappingNode {
+			for i := 0; i+1 < len(n.Content); i += 2 {
+				if n.Content[i].Value == childKey {
+					return n.Content[i]
+				}
+			}
+		}
+		return n
+	}
+
+	switch n.Kind {
+	case yamlv3.MappingNode:
+		for i := 0; i+1 < len(n.Content); i += 2 {
+			if n.Content[i].Value == path[0] {
+				if len(path) == 1 && childKey == "" && n.Content[i+1].Kind != yamlv3.MappingNode && n.Content[i+1].Kind != yamlv3.SequenceNode {
+					// point to the key for scalar values, which is more helpful when the value is empty
+					return n.Content[i]
+				}
+				return findYamlNode(n.Content[i+1], path[1:], childKey)
+			}
+		}
+	case yamlv3.SequenceNode:
+		i, err := strconv.Atoi(path[0])
+		if err == nil && i >= 0 && i < len(n.Content) {
+			return findYamlNode(n.Content[i], path[1:], childKey)
+		}
+	}
+	return n
+}
+
+func formatPath(path []string) string {
+	var sb strings.Builder
+	for _, p := range path {
+		if _, err := strconv.Atoi(p); err == nil {
+			sb.WriteString("[" + p + "]")
+			continue
+		}
+		if sb.Len() != 0 {
+			sb.WriteString(".")
+		}
+		sb.WriteString(p)
+	}
+	return sb.String()
+}
+
+func dedupPositionedErrors(l PositionedErrors) PositionedErrors {
+	seen := map[string]bool{}
+	var ret PositionedErrors
+	for _, e := range l {
+		k := e.Error()
+		if seen[k] {
+			continue
+		}
+		seen[k] = true
+		ret = append(ret, e)
+	}
+	return ret
+}
+
+// SchemaAtPath returns the (resolved) schema that applies to the given path inside a document of the type of o. value
+// is the document itself and is used to decide between alternatives, it may be nil.
+func SchemaAtPath(o any, value any, path []string) JSONSchema {
+	root := GenerateSchema(o)
+	return resolveSchemaAtPath(root, root, value, path)
+}
+
+// SchemaPropertiesAtPath returns the properties that are allowed at the given path, together with their schemas.
+// References are resolved.
+func SchemaPropertiesAtPath(o any, value any, path []string) map[string]JSONSchema {
+	root := GenerateSchema(o)
+	s := resolveSchemaAtPath(root, root, value, path)
+	if s == nil {
+		return nil
+	}
+	props, _ := s["properties"].(JSONSchema)
+	ret := map[string]JSONSchema{}
+	for k, v := range props {
+		ps, ok := v.(JSONSchema)
+		if !ok {
+			continue
+		}
+		r := resolveRef(root, ps)
+		if d, ok := ps["description"]; ok {
+			if _, ok := r["description"]; !ok {
+				r = copySchemaWith(r, "description", d)
+			}
+		}
+		ret[k] = r
+	}
+	return ret
+}
+
+func copySchemaWith(s JSONSchema, k string, v any) JSONSchema {
+	ret := JSONSchema{}
+	for k2, v2 := range s {
+		ret[k2] = v2
+	}
+	ret[k] = v
+	return ret
+}
@@ -0,0 +1,99 @@
+package yaml
+
+import (
+	"github.com/stretchr/testify/assert"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+type schemaTestInline struct {
+	Inline string `json:"inline,omitempty"`
+}
+
+type schemaTestItem struct {
+	schemaTestInline
+
+	Name  string            `json:"name" validate:"required"`
+	Mode  string            `json:"mode,omitempty" validate:"omitempty,oneof=a b"`
+	List  []string          `json:"list,omitempty" validate:"omitempty,min=1"`
+	Map   map[string]int    `json:"map,omitempty"`
+	Child *schemaTestItem   `json:"child,omitempty"`
+	Other *schemaTestOther  `json:"other,omitempty"`
+	Any   any               `json:"any,omitempty"`
+	Skip  string            `json:"-"`
+	Extra map[string]string `json:"extra,omitempty"`
+}
+
+type schemaTestOther struct {
+	A *string `json:"a,omitempty"`
+	B *string `json:"b,omitempty"`
+}
+
+type schemaTestConfig struct {
+	Items []schemaTestItem `json:"items"`
+}
+
+func init() {
+	RegisterSchemaExtension(func(s JSONSchema) JSONSchema {
+		SchemaRequireOneOf(s, "a", "b")
+		return s
+	}, schemaTestOther{})
+}
+
+func TestGenerateSchema(t *testing.T) {
+	s := GenerateSchema(&schemaTestConfig{})
+	assert.Equal(t, "#/definitions/schemaTestConfig", s["$ref"])
+
+	defs := s["definitions"].(map[string]JSONSchema)
+	item := defs["schemaTestItem"]
+	props := item["properties"].(JSONSchema)
+
+	assert.Equal(t, false, item["additionalProperties"])
+	assert.Equal(t, []any{"name"}, item["required"])
+	assert.Contains(t, props, "inline")
+	assert.NotContains(t, props, "Skip")
+	assert.Equal(t, []any{"a", "b"}, props["mode"].(JSONSchema)["enum"])
+	assert.Equal(t, 1, props["list"].(JSONSchema)["minItems"])
+	assert.Equal(t, JSONSchema{"$ref": "#/definitions/schemaTestItem"}, props["child"])
+	assert.Equal(t, JSONSchema{"type": "integer"}, props["map"].(JSONSchema)["additionalProperties"])
+
+	other := defs["schemaTestOther"]
+	assert.Len(t, other["allOf"], 1)
+}
+
+func TestReadYamlFilePositionedErrors(t *testing.T) {
+	p := filepath.Join(t.TempDir(), "test.yaml")
+	err := os.WriteFile(p, []byte(`items:
+  - name: n1
+    mode: c
+  - unknown: x
+    name: n2
+  - name: n3
+    other: {}
+`), 0o600)
+	assert.NoError(t, err)
+
+	var c schemaTestConfig
+	err = ReadYamlFile(p, &c)
+	assert.Error(t, err)
+
+	var perrs PositionedErrors
+	assert.ErrorAs(t, err, &perrs)
+	assert.Len(t, perrs, 3)
+
+	assert.Equal(t, 3, perrs[0].Line)
+	assert.Equal(t, 5, perrs[0].Column)
+	assert.Equal(t, "items[0].mode", perrs[0].Path)
+
+	assert.Equal(t, 4, perrs[1].Line)
+	assert.Equal(t, 5, perrs[1].Column)
+	assert.Equal(t, "items[1].unknown", perrs[1].Path)
+	assert.Equal(t, `unknown field "unknown"`, perrs[1].Message)
+
+	assert.Equal(t, 7, perrs[2].Line)
+	assert.Equal(t, "items[2].other", perrs[2].Path)
+	assert.Equal(t, "exactly one of a, b must be set", perrs[2].Message)
+
+	assert.Contains(t, err.Error(), p+":3:5: items[0].mode: ")
+}
@@ -23,14 +23,17 @@ func newUnicodeReader(r io.Reader) io.Reader {
 }
 
 func ReadYamlFile(p string, o interface{}) error {
-	r, err := os.Open(p)
+	b, err := os.ReadFile(p)
 	if err != nil {
 		return fmt.Errorf("opening %v failed: %w", p, err)
 	}
-	defer r.Close()
 
-	err = ReadYamlStream(r, o)
+	err = ReadYamlBytes(b, o)
 	if err != nil {
+		// try to report the error(s) with line/column information
+		if perrs := ValidateYamlWithSchema(p, b, o); len(perrs) != 0 {
+			return perrs
+		}
 		return fmt.Errorf("unmarshalling %v failed: %w", p, err)
 	}
 	return nil